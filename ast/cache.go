@@ -13,7 +13,7 @@ import (
 	"github.com/rubiojr/rugo/util"
 )
 
-//go:embed check.go nodes.go walker.go parse.go lower.go transform.go factory.go implicit_return.go
+//go:embed check.go nodes.go walker.go parse.go fold.go lower.go transform.go factory.go implicit_return.go
 var Sources embed.FS
 
 // goModTemplate is the go.mod for the cached AST module.
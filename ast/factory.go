@@ -15,14 +15,15 @@ func (f *Factory) LoweredSpawn(body []Statement, resultExpr Expr) *LoweredSpawnE
 	return &LoweredSpawnExpr{Body: body, ResultExpr: resultExpr}
 }
 
-// LoweredParallel creates a LoweredParallelExpr with pre-categorized branches.
-func (f *Factory) LoweredParallel(branches []ParallelBranch) *LoweredParallelExpr {
-	return &LoweredParallelExpr{Branches: branches}
+// LoweredParallel creates a LoweredParallelExpr with pre-categorized branches
+// and an optional concurrency limit.
+func (f *Factory) LoweredParallel(branches []ParallelBranch, limit Expr) *LoweredParallelExpr {
+	return &LoweredParallelExpr{Branches: branches, Limit: limit}
 }
 
 // LoweredTry creates a LoweredTryExpr with the given fields.
-func (f *Factory) LoweredTry(expr Expr, errVar string, handler []Statement, resultExpr Expr) *LoweredTryExpr {
-	return &LoweredTryExpr{Expr: expr, ErrVar: errVar, Handler: handler, ResultExpr: resultExpr}
+func (f *Factory) LoweredTry(expr Expr, errVar string, handler []Statement, resultExpr Expr, ensure []Statement) *LoweredTryExpr {
+	return &LoweredTryExpr{Expr: expr, ErrVar: errVar, Handler: handler, ResultExpr: resultExpr, Ensure: ensure}
 }
 
 // ParallelBranchExpr creates a ParallelBranch for a single expression.
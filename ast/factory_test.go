@@ -35,8 +35,9 @@ func TestFactoryLoweredParallel(t *testing.T) {
 		f.ParallelBranchStmts([]Statement{&ExprStmt{Expression: &IntLiteral{Value: "2"}}}, 1),
 	}
 
-	node := f.LoweredParallel(branches)
+	node := f.LoweredParallel(branches, nil)
 	require.Len(t, node.Branches, 2)
+	assert.Nil(t, node.Limit)
 	assert.NotNil(t, node.Branches[0].Expr)
 	assert.Equal(t, 0, node.Branches[0].Index)
 	assert.NotNil(t, node.Branches[1].Stmts)
@@ -49,7 +50,7 @@ func TestFactoryLoweredTry(t *testing.T) {
 	handler := []Statement{&ExprStmt{Expression: &IntLiteral{Value: "2"}}}
 	result := &IntLiteral{Value: "3"}
 
-	node := f.LoweredTry(expr, "err", handler, result)
+	node := f.LoweredTry(expr, "err", handler, result, nil)
 	assert.Equal(t, expr, node.Expr)
 	assert.Equal(t, "err", node.ErrVar)
 	assert.Equal(t, handler, node.Handler)
@@ -58,7 +59,7 @@ func TestFactoryLoweredTry(t *testing.T) {
 
 func TestFactoryLoweredTryNilResult(t *testing.T) {
 	f := NewFactory()
-	node := f.LoweredTry(&IntLiteral{Value: "1"}, "e", nil, nil)
+	node := f.LoweredTry(&IntLiteral{Value: "1"}, "e", nil, nil, nil)
 	assert.Nil(t, node.Handler)
 	assert.Nil(t, node.ResultExpr)
 }
@@ -0,0 +1,466 @@
+package ast
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ConstantFolding returns a Transform that evaluates arithmetic on numeric
+// literals at compile time, so `2 * 60 * 60` becomes the literal 7200.
+//
+// Folding follows the runtime's semantics: int op int stays an int (with
+// truncating division), and an operation involving a float yields a float.
+// Anything whose result the runtime would decide differently is left alone:
+// division or modulo by zero (the runtime raises or produces Inf), int
+// overflow, and non-finite float results.
+func ConstantFolding() Transform {
+	return TransformFunc{
+		N: "constant-folding",
+		F: func(prog *Program) *Program {
+			f := &folder{}
+			stmts, changed := f.foldStmts(prog.Statements)
+			if !changed {
+				return prog
+			}
+			return NewFactory().ProgramFrom(prog, stmts)
+		},
+	}
+}
+
+type folder struct{}
+
+func (f *folder) foldStmts(stmts []Statement) ([]Statement, bool) {
+	return mapSlice(stmts, f.foldStmt)
+}
+
+func (f *folder) foldExprs(exprs []Expr) ([]Expr, bool) {
+	return mapSlice(exprs, f.foldExpr)
+}
+
+func (f *folder) foldStmt(s Statement) Statement {
+	switch st := s.(type) {
+	case *FuncDef:
+		params, pc := f.foldParams(st.Params)
+		body, bc := f.foldStmts(st.Body)
+		if !pc && !bc {
+			return s
+		}
+		cp := *st
+		cp.Params = params
+		cp.Body = body
+		return &cp
+
+	case *TestDef:
+		body, changed := f.foldStmts(st.Body)
+		if !changed {
+			return s
+		}
+		cp := *st
+		cp.Body = body
+		return &cp
+
+	case *BenchDef:
+		iters := f.foldExpr(st.Iterations)
+		minTime := f.foldExpr(st.MinTime)
+		body, changed := f.foldStmts(st.Body)
+		if !changed && iters == st.Iterations && minTime == st.MinTime {
+			return s
+		}
+		cp := *st
+		cp.Body, cp.Iterations, cp.MinTime = body, iters, minTime
+		return &cp
+
+	case *IfStmt:
+		cond := f.foldExpr(st.Condition)
+		body, bc := f.foldStmts(st.Body)
+		elsifs, ec := f.foldElsifs(st.ElsifClauses)
+		elseBody, ebc := f.foldStmts(st.ElseBody)
+		if cond == st.Condition && !bc && !ec && !ebc {
+			return s
+		}
+		cp := *st
+		cp.Condition = cond
+		cp.Body = body
+		cp.ElsifClauses = elsifs
+		cp.ElseBody = elseBody
+		return &cp
+
+	case *CaseStmt:
+		subject := f.foldExpr(st.Subject)
+		ofs, oc := f.foldOfClauses(st.OfClauses)
+		elsifs, ec := f.foldElsifs(st.ElsifClauses)
+		elseBody, ebc := f.foldStmts(st.ElseBody)
+		if subject == st.Subject && !oc && !ec && !ebc {
+			return s
+		}
+		cp := *st
+		cp.Subject = subject
+		cp.OfClauses = ofs
+		cp.ElsifClauses = elsifs
+		cp.ElseBody = elseBody
+		return &cp
+
+	case *WhileStmt:
+		cond := f.foldExpr(st.Condition)
+		body, bc := f.foldStmts(st.Body)
+		elseBody, ebc := f.foldStmts(st.ElseBody)
+		if cond == st.Condition && !bc && !ebc {
+			return s
+		}
+		cp := *st
+		cp.Condition = cond
+		cp.Body = body
+		cp.ElseBody = elseBody
+		return &cp
+
+	case *ForStmt:
+		coll := f.foldExpr(st.Collection)
+		body, bc := f.foldStmts(st.Body)
+		if coll == st.Collection && !bc {
+			return s
+		}
+		cp := *st
+		cp.Collection = coll
+		cp.Body = body
+		return &cp
+
+	case *ReturnStmt:
+		if st.Value == nil {
+			return s
+		}
+		val := f.foldExpr(st.Value)
+		if val == st.Value {
+			return s
+		}
+		return &ReturnStmt{BaseStmt: st.BaseStmt, Value: val}
+
+	case *ExprStmt:
+		expr := f.foldExpr(st.Expression)
+		if expr == st.Expression {
+			return s
+		}
+		return &ExprStmt{BaseStmt: st.BaseStmt, Expression: expr}
+
+	case *AssignStmt:
+		val := f.foldExpr(st.Value)
+		if val == st.Value {
+			return s
+		}
+		cp := *st
+		cp.Value = val
+		return &cp
+
+	case *IndexAssignStmt:
+		obj := f.foldExpr(st.Object)
+		idx := f.foldExpr(st.Index)
+		val := f.foldExpr(st.Value)
+		if obj == st.Object && idx == st.Index && val == st.Value {
+			return s
+		}
+		return &IndexAssignStmt{BaseStmt: st.BaseStmt, Object: obj, Index: idx, Value: val}
+
+	case *DotAssignStmt:
+		obj := f.foldExpr(st.Object)
+		val := f.foldExpr(st.Value)
+		if obj == st.Object && val == st.Value {
+			return s
+		}
+		return &DotAssignStmt{BaseStmt: st.BaseStmt, Object: obj, Field: st.Field, Value: val}
+
+	default:
+		return s
+	}
+}
+
+func (f *folder) foldElsifs(clauses []ElsifClause) ([]ElsifClause, bool) {
+	return mapSliceFunc(clauses, func(ec ElsifClause) (ElsifClause, bool) {
+		cond := f.foldExpr(ec.Condition)
+		body, bc := f.foldStmts(ec.Body)
+		return ElsifClause{Condition: cond, Body: body}, cond != ec.Condition || bc
+	})
+}
+
+func (f *folder) foldOfClauses(clauses []OfClause) ([]OfClause, bool) {
+	return mapSliceFunc(clauses, func(oc OfClause) (OfClause, bool) {
+		values, vc := f.foldExprs(oc.Values)
+		body, bc := f.foldStmts(oc.Body)
+		var arrow Expr
+		if oc.ArrowExpr != nil {
+			arrow = f.foldExpr(oc.ArrowExpr)
+		}
+		return OfClause{Values: values, Body: body, ArrowExpr: arrow}, vc || bc || arrow != oc.ArrowExpr
+	})
+}
+
+func (f *folder) foldParams(params []Param) ([]Param, bool) {
+	return mapSliceFunc(params, func(p Param) (Param, bool) {
+		if p.Default == nil {
+			return p, false
+		}
+		nd := f.foldExpr(p.Default)
+		return Param{Name: p.Name, Default: nd}, nd != p.Default
+	})
+}
+
+func (f *folder) foldPairs(pairs []HashPair) ([]HashPair, bool) {
+	return mapSliceFunc(pairs, func(p HashPair) (HashPair, bool) {
+		key := f.foldExpr(p.Key)
+		val := f.foldExpr(p.Value)
+		return HashPair{Key: key, Value: val}, key != p.Key || val != p.Value
+	})
+}
+
+func (f *folder) foldExpr(e Expr) Expr {
+	switch ex := e.(type) {
+	// --- Folding targets ---
+	case *BinaryExpr:
+		left := f.foldExpr(ex.Left)
+		right := f.foldExpr(ex.Right)
+		if lit := foldBinary(ex.Op, left, right); lit != nil {
+			return lit
+		}
+		if left == ex.Left && right == ex.Right {
+			return e
+		}
+		return &BinaryExpr{Left: left, Op: ex.Op, Right: right}
+
+	case *UnaryExpr:
+		operand := f.foldExpr(ex.Operand)
+		if lit := foldUnary(ex.Op, operand); lit != nil {
+			return lit
+		}
+		if operand == ex.Operand {
+			return e
+		}
+		return &UnaryExpr{Op: ex.Op, Operand: operand}
+
+	// --- Recursive descent ---
+	case *CallExpr:
+		fn := f.foldExpr(ex.Func)
+		args, ac := f.foldExprs(ex.Args)
+		if fn == ex.Func && !ac {
+			return e
+		}
+		return &CallExpr{Func: fn, Args: args}
+
+	case *IndexExpr:
+		obj := f.foldExpr(ex.Object)
+		idx := f.foldExpr(ex.Index)
+		if obj == ex.Object && idx == ex.Index {
+			return e
+		}
+		return &IndexExpr{Object: obj, Index: idx}
+
+	case *SliceExpr:
+		obj := f.foldExpr(ex.Object)
+		start := f.foldExpr(ex.Start)
+		length := f.foldExpr(ex.Length)
+		if obj == ex.Object && start == ex.Start && length == ex.Length {
+			return e
+		}
+		return &SliceExpr{Object: obj, Start: start, Length: length}
+
+	case *DotExpr:
+		obj := f.foldExpr(ex.Object)
+		if obj == ex.Object {
+			return e
+		}
+		return &DotExpr{Object: obj, Field: ex.Field, Safe: ex.Safe}
+
+	case *ArrayLiteral:
+		elems, changed := f.foldExprs(ex.Elements)
+		if !changed {
+			return e
+		}
+		return &ArrayLiteral{Elements: elems}
+
+	case *HashLiteral:
+		pairs, changed := f.foldPairs(ex.Pairs)
+		if !changed {
+			return e
+		}
+		return &HashLiteral{Pairs: pairs}
+
+	case *FnExpr:
+		params, pc := f.foldParams(ex.Params)
+		body, bc := f.foldStmts(ex.Body)
+		if !pc && !bc {
+			return e
+		}
+		return &FnExpr{Params: params, Body: body}
+
+	case *CaseExpr:
+		subject := f.foldExpr(ex.Subject)
+		ofs, oc := f.foldOfClauses(ex.OfClauses)
+		elsifs, ec := f.foldElsifs(ex.ElsifClauses)
+		elseBody, ebc := f.foldStmts(ex.ElseBody)
+		if subject == ex.Subject && !oc && !ec && !ebc {
+			return e
+		}
+		cp := *ex
+		cp.Subject = subject
+		cp.OfClauses = ofs
+		cp.ElsifClauses = elsifs
+		cp.ElseBody = elseBody
+		return &cp
+
+	case *TryExpr:
+		expr := f.foldExpr(ex.Expr)
+		handler, hc := f.foldStmts(ex.Handler)
+		ensure, ec := f.foldStmts(ex.Ensure)
+		if expr == ex.Expr && !hc && !ec {
+			return e
+		}
+		return &TryExpr{Expr: expr, ErrVar: ex.ErrVar, Handler: handler, Ensure: ensure}
+
+	case *SpawnExpr:
+		body, changed := f.foldStmts(ex.Body)
+		if !changed {
+			return e
+		}
+		return &SpawnExpr{Body: body}
+
+	case *ParallelExpr:
+		body, bc := f.foldStmts(ex.Body)
+		var limit Expr
+		if ex.Limit != nil {
+			limit = f.foldExpr(ex.Limit)
+		}
+		if !bc && limit == ex.Limit {
+			return e
+		}
+		return &ParallelExpr{Body: body, Limit: limit}
+
+	default:
+		return e
+	}
+}
+
+// numLit is the value of a numeric literal.
+type numLit struct {
+	i       int64
+	f       float64
+	isFloat bool
+}
+
+func literalNum(e Expr) (numLit, bool) {
+	switch lit := e.(type) {
+	case *IntLiteral:
+		n, err := strconv.ParseInt(lit.Value, 0, 64)
+		return numLit{i: n}, err == nil
+	case *FloatLiteral:
+		v, err := strconv.ParseFloat(lit.Value, 64)
+		return numLit{f: v, isFloat: true}, err == nil
+	}
+	return numLit{}, false
+}
+
+func (n numLit) float() float64 {
+	if n.isFloat {
+		return n.f
+	}
+	return float64(n.i)
+}
+
+// foldBinary returns the literal for left op right, or nil when the
+// operation can't (or shouldn't) be evaluated at compile time.
+func foldBinary(op string, left, right Expr) Expr {
+	a, ok := literalNum(left)
+	if !ok {
+		return nil
+	}
+	b, ok := literalNum(right)
+	if !ok {
+		return nil
+	}
+	if a.isFloat || b.isFloat {
+		x, y := a.float(), b.float()
+		var r float64
+		switch op {
+		case "+":
+			r = x + y
+		case "-":
+			r = x - y
+		case "*":
+			r = x * y
+		case "/":
+			if y == 0 {
+				return nil
+			}
+			r = x / y
+		case "%":
+			if y == 0 {
+				return nil
+			}
+			r = math.Mod(x, y)
+		default:
+			return nil
+		}
+		return floatLiteral(r)
+	}
+
+	x, y := a.i, b.i
+	var r int64
+	switch op {
+	case "+":
+		r = x + y
+		if (x > 0 && y > 0 && r < 0) || (x < 0 && y < 0 && r >= 0) {
+			return nil
+		}
+	case "-":
+		r = x - y
+		if (x >= 0 && y < 0 && r < 0) || (x < 0 && y > 0 && r >= 0) {
+			return nil
+		}
+	case "*":
+		r = x * y
+		if x != 0 && (r/x != y || (x == -1 && y == math.MinInt64)) {
+			return nil
+		}
+	case "/":
+		if y == 0 || (x == math.MinInt64 && y == -1) {
+			return nil
+		}
+		r = x / y
+	case "%":
+		if y == 0 {
+			return nil
+		}
+		r = x % y
+	default:
+		return nil
+	}
+	return &IntLiteral{Value: strconv.FormatInt(r, 10)}
+}
+
+// foldUnary folds negation of a numeric literal.
+func foldUnary(op string, operand Expr) Expr {
+	if op != "-" {
+		return nil
+	}
+	n, ok := literalNum(operand)
+	if !ok {
+		return nil
+	}
+	if n.isFloat {
+		return floatLiteral(-n.f)
+	}
+	if n.i == math.MinInt64 {
+		return nil
+	}
+	return &IntLiteral{Value: strconv.FormatInt(-n.i, 10)}
+}
+
+// floatLiteral formats v so it still reads as a float (6.0, not 6).
+// Non-finite results are left for the runtime.
+func floatLiteral(v float64) Expr {
+	if math.IsInf(v, 0) || math.IsNaN(v) {
+		return nil
+	}
+	s := strconv.FormatFloat(v, 'g', -1, 64)
+	if !strings.ContainsAny(s, ".e") {
+		s += ".0"
+	}
+	return &FloatLiteral{Value: s}
+}
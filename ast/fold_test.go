@@ -0,0 +1,86 @@
+package ast
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func intLit(v string) *IntLiteral     { return &IntLiteral{Value: v} }
+func floatLit(v string) *FloatLiteral { return &FloatLiteral{Value: v} }
+func bin(l Expr, op string, r Expr) *BinaryExpr {
+	return &BinaryExpr{Left: l, Op: op, Right: r}
+}
+
+// foldAssign folds `x = e` and returns the folded value.
+func foldAssign(t *testing.T, e Expr) Expr {
+	t.Helper()
+	prog := &Program{Statements: []Statement{&AssignStmt{Target: "x", Value: e}}}
+	result := ConstantFolding().Transform(prog)
+	require.Len(t, result.Statements, 1)
+	return result.Statements[0].(*AssignStmt).Value
+}
+
+func TestConstantFoldingNested(t *testing.T) {
+	// 2 * 60 * 60
+	got := foldAssign(t, bin(bin(intLit("2"), "*", intLit("60")), "*", intLit("60")))
+	assert.Equal(t, intLit("7200"), got)
+
+	// -(3 - 5) + 10 / 4
+	got = foldAssign(t, bin(&UnaryExpr{Op: "-", Operand: bin(intLit("3"), "-", intLit("5"))}, "+", bin(intLit("10"), "/", intLit("4"))))
+	assert.Equal(t, intLit("4"), got, "int division truncates")
+}
+
+func TestConstantFoldingMixedIntFloat(t *testing.T) {
+	tests := []struct {
+		expr Expr
+		want Expr
+	}{
+		{bin(intLit("7"), "/", floatLit("2.0")), floatLit("3.5")},
+		{bin(floatLit("2.0"), "*", intLit("3")), floatLit("6.0")},
+		{bin(floatLit("1.5"), "%", intLit("1")), floatLit("0.5")},
+		{bin(floatLit("0.1"), "+", floatLit("0.2")), floatLit("0.30000000000000004")},
+		{&UnaryExpr{Op: "-", Operand: floatLit("2.5")}, floatLit("-2.5")},
+		{bin(intLit("-7"), "%", intLit("3")), intLit("-1")},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, foldAssign(t, tt.expr))
+	}
+}
+
+func TestConstantFoldingLeavesRuntimeCases(t *testing.T) {
+	tests := []Expr{
+		bin(intLit("1"), "/", intLit("0")),
+		bin(intLit("1"), "%", intLit("0")),
+		bin(floatLit("1.0"), "/", intLit("0")),
+		bin(intLit("9223372036854775807"), "+", intLit("1")),
+		bin(intLit("3037000500"), "*", intLit("3037000500")),
+		bin(intLit("1"), "+", &IdentExpr{Name: "n"}),
+		bin(&StringLiteral{Value: "a"}, "+", intLit("1")),
+		bin(intLit("1"), "<", intLit("2")),
+	}
+	for _, e := range tests {
+		assert.Same(t, e, foldAssign(t, e), "%#v should not fold", e)
+	}
+}
+
+func TestConstantFoldingPartial(t *testing.T) {
+	// n + 2 * 3 → n + 6
+	got := foldAssign(t, bin(&IdentExpr{Name: "n"}, "+", bin(intLit("2"), "*", intLit("3"))))
+	b, ok := got.(*BinaryExpr)
+	require.True(t, ok)
+	assert.Equal(t, intLit("6"), b.Right)
+}
+
+func TestConstantFoldingNoMutation(t *testing.T) {
+	orig := bin(intLit("2"), "+", intLit("3"))
+	fn := &FuncDef{Name: "f", Body: []Statement{&ReturnStmt{Value: orig}}}
+	prog := &Program{Statements: []Statement{fn}}
+	result := ConstantFolding().Transform(prog)
+	assert.Same(t, orig, fn.Body[0].(*ReturnStmt).Value, "input must not be mutated")
+	assert.Equal(t, intLit("5"), result.Statements[0].(*FuncDef).Body[0].(*ReturnStmt).Value)
+
+	unchanged := &Program{Statements: []Statement{&AssignStmt{Target: "x", Value: intLit("1")}}}
+	assert.Same(t, unchanged, ConstantFolding().Transform(unchanged))
+}
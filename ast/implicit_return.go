@@ -83,11 +83,13 @@ func (ir *implicitReturner) walkStmt(s Statement) Statement {
 
 	case *WhileStmt:
 		body, changed := ir.walkStmts(st.Body)
-		if !changed {
+		elseBody, ebc := ir.walkStmts(st.ElseBody)
+		if !changed && !ebc {
 			return s
 		}
 		cp := *st
 		cp.Body = body
+		cp.ElseBody = elseBody
 		return &cp
 
 	case *ForStmt:
@@ -193,13 +195,13 @@ func (ir *implicitReturner) walkExpr(e Expr) Expr {
 				if handler != nil {
 					h = handler
 				}
-				return ir.f.LoweredTry(expr, ex.ErrVar, h, nil)
+				return ir.f.LoweredTry(expr, ex.ErrVar, h, nil, ex.Ensure)
 			}
 			return e
 		}
 		// ResultExpr already set or empty handler — just walk sub-expressions
 		if expr != ex.Expr {
-			return ir.f.LoweredTry(expr, ex.ErrVar, ex.Handler, ex.ResultExpr)
+			return ir.f.LoweredTry(expr, ex.ErrVar, ex.Handler, ex.ResultExpr, ex.Ensure)
 		}
 		return e
 
@@ -223,7 +225,7 @@ func (ir *implicitReturner) walkExpr(e Expr) Expr {
 		if !changed {
 			return e
 		}
-		return ir.f.LoweredParallel(branches)
+		return ir.f.LoweredParallel(branches, ex.Limit)
 
 	case *BinaryExpr:
 		left := ir.walkExpr(ex.Left)
@@ -270,7 +272,7 @@ func (ir *implicitReturner) walkExpr(e Expr) Expr {
 		if obj == ex.Object {
 			return e
 		}
-		return &DotExpr{Object: obj, Field: ex.Field}
+		return &DotExpr{Object: obj, Field: ex.Field, Safe: ex.Safe}
 
 	case *ArrayLiteral:
 		elems, changed := ir.walkExprs(ex.Elements)
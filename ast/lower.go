@@ -97,12 +97,14 @@ func (l *lowerer) lowerStmt(s Statement) Statement {
 	case *WhileStmt:
 		cond := l.lowerExpr(st.Condition)
 		body, bc := l.lowerStmts(st.Body)
-		if cond == st.Condition && !bc {
+		elseBody, ebc := l.lowerStmts(st.ElseBody)
+		if cond == st.Condition && !bc && !ebc {
 			return s
 		}
 		cp := *st
 		cp.Condition = cond
 		cp.Body = body
+		cp.ElseBody = elseBody
 		return &cp
 
 	case *ForStmt:
@@ -278,7 +280,7 @@ func (l *lowerer) lowerExpr(e Expr) Expr {
 		if obj == ex.Object {
 			return e
 		}
-		return &DotExpr{Object: obj, Field: ex.Field}
+		return &DotExpr{Object: obj, Field: ex.Field, Safe: ex.Safe}
 
 	case *ArrayLiteral:
 		elems, changed := l.lowerExprs(ex.Elements)
@@ -357,7 +359,11 @@ func (l *lowerer) lowerParallel(e *ParallelExpr) Expr {
 			branches[i] = l.f.ParallelBranchStmts([]Statement{ns}, i)
 		}
 	}
-	return l.f.LoweredParallel(branches)
+	var limit Expr
+	if e.Limit != nil {
+		limit = l.lowerExpr(e.Limit)
+	}
+	return l.f.LoweredParallel(branches, limit)
 }
 
 func (l *lowerer) lowerTry(e *TryExpr) Expr {
@@ -365,14 +371,18 @@ func (l *lowerer) lowerTry(e *TryExpr) Expr {
 	saved := l.ctx
 	l.ctx = lowerInTryHandler
 	handler, _ := l.lowerStmts(e.Handler)
+	var ensure []Statement
+	if e.Ensure != nil {
+		ensure, _ = l.lowerStmts(e.Ensure)
+	}
 	l.ctx = saved
 
 	// Extract last ExprStmt as ResultExpr (simple case)
 	if len(handler) > 0 {
 		if es, ok := handler[len(handler)-1].(*ExprStmt); ok {
-			return l.f.LoweredTry(expr, e.ErrVar, handler[:len(handler)-1], es.Expression)
+			return l.f.LoweredTry(expr, e.ErrVar, handler[:len(handler)-1], es.Expression, ensure)
 		}
 	}
 	// Complex case (IfStmt result) or empty handler: codegen handles it
-	return l.f.LoweredTry(expr, e.ErrVar, handler, nil)
+	return l.f.LoweredTry(expr, e.ErrVar, handler, nil, ensure)
 }
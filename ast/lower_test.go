@@ -128,6 +128,27 @@ func TestLowerTryExpr_ExtractsSimpleResult(t *testing.T) {
 	assert.Equal(t, "fallback", lt.ResultExpr.(*StringLiteral).Value)
 }
 
+func TestLowerTryExpr_KeepsEnsure(t *testing.T) {
+	prog := &Program{
+		Statements: []Statement{
+			&ExprStmt{Expression: &TryExpr{
+				Expr:    &IdentExpr{Name: "x"},
+				ErrVar:  "e",
+				Handler: []Statement{&ExprStmt{Expression: &IntLiteral{Value: "0"}}},
+				Ensure: []Statement{
+					&ExprStmt{Expression: &CallExpr{Func: &IdentExpr{Name: "cleanup"}, Args: nil}},
+				},
+			}},
+		},
+	}
+
+	lowered := Lower(prog)
+	es := lowered.Statements[0].(*ExprStmt)
+	lt := es.Expression.(*LoweredTryExpr)
+	assert.Equal(t, "0", lt.ResultExpr.(*IntLiteral).Value)
+	assert.Equal(t, 1, len(lt.Ensure))
+}
+
 func TestLowerTryExpr_ComplexResult(t *testing.T) {
 	// When last handler statement is IfStmt, ResultExpr should be nil
 	prog := &Program{
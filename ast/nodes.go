@@ -43,31 +43,45 @@ type Program struct {
 
 func (p *Program) node() {}
 
-// UseStmt represents use "rugo_module" (Rugo stdlib module).
+// UseStmt represents use "rugo_module" [as alias] (Rugo stdlib module).
 type UseStmt struct {
 	BaseStmt
-	Module string
+	Module string // module name (e.g. "http")
+	Alias  string // optional alias (e.g. "web" for http)
 }
 
 func (u *UseStmt) node() {}
 func (u *UseStmt) stmt() {}
 
-// ImportStmt represents import "go/pkg" [as alias] (Go stdlib bridge).
+// Namespace returns the name scripts call the module through: the alias
+// when one is given, otherwise the module name.
+func (u *UseStmt) Namespace() string {
+	if u.Alias != "" {
+		return u.Alias
+	}
+	return u.Module
+}
+
+// ImportStmt represents import "go/pkg" [as alias] [{name: GoFunc, ...}]
+// (Go stdlib bridge).
 type ImportStmt struct {
 	BaseStmt
-	Package string // Go package path (e.g. "strings", "path/filepath")
-	Alias   string // optional alias (e.g. "fp" for filepath)
+	Package string            // Go package path (e.g. "strings", "path/filepath")
+	Alias   string            // optional alias (e.g. "fp" for filepath)
+	Funcs   map[string]string // optional renames: Rugo name → Go function (e.g. "title_case" → "ToTitle")
 }
 
 func (i *ImportStmt) node() {}
 func (i *ImportStmt) stmt() {}
 
 // RequireStmt represents require "path" [as "alias" | with mod1, mod2, ...].
+// require_optional produces the same node with Optional set.
 type RequireStmt struct {
 	BaseStmt
-	Path  string
-	Alias string   // empty means use filename as namespace
-	With  []string // selective sub-module names (mutually exclusive with Alias)
+	Path     string
+	Alias    string   // empty means use filename as namespace
+	With     []string // selective sub-module names (mutually exclusive with Alias)
+	Optional bool     // require_optional: a missing file is skipped silently
 }
 
 func (r *RequireStmt) node() {}
@@ -145,6 +159,7 @@ type FuncDef struct {
 	Params    []Param
 	Body      []Statement
 	Namespace string // set during require resolution for namespaced functions
+	Memo      bool   // memo def: results are cached by argument values
 }
 
 func (f *FuncDef) node() {}
@@ -163,8 +178,10 @@ func (t *TestDef) stmt() {}
 // BenchDef represents bench "name" body end.
 type BenchDef struct {
 	BaseStmt
-	Name string
-	Body []Statement
+	Name       string
+	Body       []Statement
+	Iterations Expr // fixed iteration count (nil = auto-calibrate)
+	MinTime    Expr // minimum run time in seconds (nil = 1s)
 }
 
 func (b *BenchDef) node() {}
@@ -221,11 +238,13 @@ type CaseExpr struct {
 func (c *CaseExpr) node() {}
 func (c *CaseExpr) expr() {}
 
-// WhileStmt represents while cond body end.
+// WhileStmt represents while cond body [else body] end.
+// ElseBody runs once when the condition is false on the first check.
 type WhileStmt struct {
 	BaseStmt
 	Condition Expr
 	Body      []Statement
+	ElseBody  []Statement
 }
 
 func (w *WhileStmt) node() {}
@@ -279,6 +298,7 @@ type AssignStmt struct {
 	Target    string
 	Value     Expr
 	Namespace string // non-empty for top-level assignments from require'd files
+	Const     bool   // declared with const: never reassignable, whatever the case
 }
 
 func (a *AssignStmt) node() {}
@@ -357,6 +377,7 @@ func (s *SliceExpr) expr() {}
 type DotExpr struct {
 	Object Expr
 	Field  string
+	Safe   bool // a&.b: yields nil instead of failing when Object is nil
 }
 
 func (d *DotExpr) node() {}
@@ -431,11 +452,12 @@ type HashPair struct {
 	Value Expr
 }
 
-// TryExpr represents try expr or err handler end.
+// TryExpr represents try expr or err handler [ensure body] end.
 type TryExpr struct {
 	Expr    Expr        // expression to try
 	ErrVar  string      // error variable name
 	Handler []Statement // handler body; last expression is the result
+	Ensure  []Statement // cleanup body; always runs, nil when absent
 }
 
 func (t *TryExpr) node() {}
@@ -451,8 +473,10 @@ func (s *SpawnExpr) expr() {}
 
 // ParallelExpr represents parallel body end (fan-out concurrency).
 // Each statement in Body runs in its own goroutine; returns an array of results.
+// Limit, when set, caps how many branches run at once (parallel(limit: N)).
 type ParallelExpr struct {
-	Body []Statement
+	Body  []Statement
+	Limit Expr // nil when unbounded
 }
 
 func (p *ParallelExpr) node() {}
@@ -467,6 +491,7 @@ type LoweredTryExpr struct {
 	ErrVar     string      // error variable name
 	Handler    []Statement // handler body (last ExprStmt removed if ResultExpr is set)
 	ResultExpr Expr        // extracted last handler expression; nil if complex or empty
+	Ensure     []Statement // cleanup body; always runs, nil when absent
 }
 
 func (t *LoweredTryExpr) node() {}
@@ -493,6 +518,7 @@ type ParallelBranch struct {
 // Each body statement is pre-categorized as an expression or statement block.
 type LoweredParallelExpr struct {
 	Branches []ParallelBranch
+	Limit    Expr // max concurrent branches; nil when unbounded
 }
 
 func (p *LoweredParallelExpr) node() {}
@@ -1,9 +1,9 @@
 package ast
 
 import (
+	"errors"
 	"fmt"
 	"os"
-	"strings"
 
 	"github.com/rubiojr/rugo/parser"
 	"github.com/rubiojr/rugo/preprocess"
@@ -27,61 +27,24 @@ func (c *Compiler) ParseFile(filename string) (*Program, error) {
 func (c *Compiler) ParseSource(source, name string) (*Program, error) {
 	rawSource := source
 
-	cleaned, heredocLineMap, err := preprocess.ExpandHeredocs(source)
+	cleaned, lineMap, structInfos, err := preprocess.Run(source)
 	if err != nil {
 		return nil, fmt.Errorf("%s:%w", name, err)
 	}
 
-	cleaned, err = preprocess.StripComments(cleaned)
-	if err != nil {
-		return nil, fmt.Errorf("%s:%w", name, err)
-	}
-
-	var structLineMap []int
-	var structInfos []preprocess.StructInfo
-	cleaned, structLineMap, structInfos = preprocess.ExpandStructDefs(cleaned)
-
-	userFuncs := preprocess.ScanFuncDefs(cleaned)
-
-	var lineMap []int
-	cleaned, lineMap, err = preprocess.Preprocess(cleaned, userFuncs)
-	if err != nil {
-		return nil, fmt.Errorf("%s:%w", name, err)
-	}
-
-	// Compose line maps: preprocess → struct → heredoc → original source.
-	if structLineMap != nil && lineMap != nil {
-		for i, ppLine := range lineMap {
-			if ppLine > 0 && ppLine <= len(structLineMap) {
-				lineMap[i] = structLineMap[ppLine-1]
-			}
-		}
-	} else if structLineMap != nil {
-		lineMap = structLineMap
-	}
-
-	if heredocLineMap != nil && lineMap != nil {
-		for i, ppLine := range lineMap {
-			if ppLine > 0 && ppLine <= len(heredocLineMap) {
-				lineMap[i] = heredocLineMap[ppLine-1]
-			}
-		}
-	} else if heredocLineMap != nil {
-		lineMap = heredocLineMap
-	}
-
-	if !strings.HasSuffix(cleaned, "\n") {
-		cleaned += "\n"
-	}
-
 	p := &parser.Parser{}
 	flatAST, err := p.Parse(name, []byte(cleaned))
 	if err != nil {
-		return nil, firstParseError(err)
+		return nil, firstParseError(err, rawSource, cleaned, lineMap)
 	}
 
 	prog, err := WalkWithLineMap(p, flatAST, lineMap)
 	if err != nil {
+		var ue *UserError
+		if errors.As(err, &ue) && ue.Line > 0 {
+			line, col := preprocess.OriginalPosition(rawSource, cleaned, lineMap, ue.Line, ue.Col)
+			return nil, fmt.Errorf("%s:%d:%d: %s", name, line, col, ue.Msg)
+		}
 		return nil, fmt.Errorf("%s: internal error: %w", name, err)
 	}
 
@@ -91,10 +54,13 @@ func (c *Compiler) ParseSource(source, name string) (*Program, error) {
 	return prog, nil
 }
 
-// firstParseError extracts the first error from a parser error list.
-func firstParseError(err error) error {
+// firstParseError extracts the first error from a parser error list,
+// mapping its position from preprocessed back to original source.
+func firstParseError(err error, src, cleaned string, lineMap []int) error {
 	if el, ok := err.(scanner.ErrList); ok && len(el) > 0 {
-		return fmt.Errorf("%s", el[0])
+		e := el[0]
+		e.Pos.Line, e.Pos.Column = preprocess.OriginalPosition(src, cleaned, lineMap, e.Pos.Line, e.Pos.Column)
+		return fmt.Errorf("%s", e)
 	}
 	return err
 }
@@ -56,3 +56,26 @@ func mapSlice[T any](items []T, fn func(T) T) ([]T, bool) {
 	}
 	return out, true
 }
+
+// mapSliceFunc is mapSlice for element types that can't be compared with
+// ==, such as structs holding slices: fn reports whether it changed the
+// element.
+func mapSliceFunc[T any](items []T, fn func(T) (T, bool)) ([]T, bool) {
+	var out []T
+	modified := false
+	for i, item := range items {
+		newItem, changed := fn(item)
+		if changed && !modified {
+			out = make([]T, len(items))
+			copy(out[:i], items[:i])
+			modified = true
+		}
+		if modified {
+			out[i] = newItem
+		}
+	}
+	if !modified {
+		return items, false
+	}
+	return out, true
+}
@@ -4,8 +4,10 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/rubiojr/rugo/parser"
+	"github.com/rubiojr/rugo/preprocess"
 )
 
 // UserError represents a user-facing error (bad source code) rather than
@@ -13,6 +15,10 @@ import (
 // the message with "internal compiler error".
 type UserError struct {
 	Msg string
+	// Line and Col locate the error in the parsed (preprocessed) source,
+	// 1-based. They are zero when the error has no position; callers map
+	// them back to the original source through the line map.
+	Line, Col int
 }
 
 func (e *UserError) Error() string { return e.Msg }
@@ -77,26 +83,28 @@ func (w *walker) firstTokenLine(ast []int32) int {
 	return 0
 }
 
-// firstTokenRawLine returns the raw (pre-lineMap) parser line from the first
-// terminal token. Used for same-line detection where preprocessor-expanded
-// lines must not be confused with user-written same-line tokens.
-func (w *walker) firstTokenRawLine(ast []int32) int {
+// firstTokenRawPos returns the raw (pre-lineMap) parser line and column of
+// the first terminal token. Used for same-line detection where
+// preprocessor-expanded lines must not be confused with user-written
+// same-line tokens.
+func (w *walker) firstTokenRawPos(ast []int32) (int, int) {
 	for i := 0; i < len(ast); i++ {
 		if ast[i] >= 0 {
-			return w.p.Token(ast[i]).Position().Line
+			pos := w.p.Token(ast[i]).Position()
+			return pos.Line, pos.Column
 		}
 		if i+1 < len(ast) {
 			count := int(ast[i+1])
 			if count > 0 {
 				inner := ast[i+2 : i+2+count]
-				if line := w.firstTokenRawLine(inner); line > 0 {
-					return line
+				if line, col := w.firstTokenRawPos(inner); line > 0 {
+					return line, col
 				}
 			}
 			i += 1 + count
 		}
 	}
-	return 0
+	return 0, 0
 }
 
 // lastTokenLine returns the original source line from the last terminal token
@@ -158,10 +166,12 @@ func (w *walker) walkProgram() (*Program, error) {
 			// Skip ';' statement separators (injected by preprocessor)
 			if ch == parser.RugoTOK_003b {
 				_, children = w.readToken(children)
+				// An explicit separator lets the next statement share the line.
+				prevRawLine = 0
 				continue
 			}
 		}
-		rawLine := w.firstTokenRawLine(children)
+		rawLine, rawCol := w.firstTokenRawPos(children)
 		var stmt Statement
 		var err error
 		stmt, children, err = w.walkStatement(children)
@@ -169,9 +179,15 @@ func (w *walker) walkProgram() (*Program, error) {
 			return nil, err
 		}
 		if prevRawLine > 0 && rawLine == prevRawLine {
-			return nil, &UserError{Msg: fmt.Sprintf("line %d: syntax error: unexpected token on same line as previous statement", w.resolvedLine(stmt.StmtLine()))}
+			return nil, &UserError{Msg: "syntax error: unexpected token on same line as previous statement", Line: rawLine, Col: rawCol}
 		}
 		prevRawLine = rawLine
+		// import renames arrive as a trailing __import_funcs__(...) statement.
+		if n := len(prog.Statements); n > 0 {
+			if imp, ok := prog.Statements[n-1].(*ImportStmt); ok && liftImportFuncs(imp, stmt) {
+				continue
+			}
+		}
 		prog.Statements = append(prog.Statements, stmt)
 	}
 	return prog, nil
@@ -321,14 +337,23 @@ func (w *walker) walkStatement(ast []int32) (Statement, []int32, error) {
 }
 
 func (w *walker) walkUseStmt(ast []int32) (Statement, error) {
-	// UseStmt = "use" str_lit .
+	// UseStmt = "use" str_lit [ "as" ident ] .
 	_, ast = w.readToken(ast) // skip "use"
-	tok, _ := w.readToken(ast)
+	tok, ast := w.readToken(ast)
 	module, err := unquoteString(tok.src)
 	if err != nil {
 		return nil, err
 	}
-	return &UseStmt{Module: module}, nil
+	alias := ""
+	if len(ast) > 0 && ast[0] >= 0 {
+		nextTok := w.p.Token(ast[0])
+		if parser.Symbol(nextTok.Ch) == parser.RugoTOK_as {
+			_, ast = w.readToken(ast) // consume "as"
+			aliasTok, _ := w.readToken(ast)
+			alias = aliasTok.src
+		}
+	}
+	return &UseStmt{Module: module, Alias: alias}, nil
 }
 
 func (w *walker) walkImportStmt(ast []int32) (Statement, error) {
@@ -351,9 +376,35 @@ func (w *walker) walkImportStmt(ast []int32) (Statement, error) {
 	return &ImportStmt{Package: pkg, Alias: alias}, nil
 }
 
+// liftImportFuncs moves the renames of an __import_funcs__("name", "GoFunc",
+// ...) statement into imp.Funcs. It reports whether stmt was that marker.
+func liftImportFuncs(imp *ImportStmt, stmt Statement) bool {
+	es, ok := stmt.(*ExprStmt)
+	if !ok {
+		return false
+	}
+	call, ok := es.Expression.(*CallExpr)
+	if !ok {
+		return false
+	}
+	if id, ok := call.Func.(*IdentExpr); !ok || id.Name != preprocess.ImportFuncsBuiltin {
+		return false
+	}
+	imp.Funcs = make(map[string]string, len(call.Args)/2)
+	for i := 0; i+1 < len(call.Args); i += 2 {
+		name, _ := call.Args[i].(*StringLiteral)
+		goName, _ := call.Args[i+1].(*StringLiteral)
+		if name != nil && goName != nil {
+			imp.Funcs[name.Value] = goName.Value
+		}
+	}
+	return true
+}
+
 func (w *walker) walkRequireStmt(ast []int32) (Statement, error) {
-	// RequireStmt = "require" str_lit [ "as" ( str_lit | ident ) | "with" ident { ',' ident } ] .
-	_, ast = w.readToken(ast) // skip "require"
+	// RequireStmt = ( "require" | "require_optional" ) str_lit [ "as" ( str_lit | ident ) | "with" ident { ',' ident } ] .
+	kwTok, ast := w.readToken(ast)
+	optional := kwTok.ch == parser.RugoTOK_require_optional
 	tok, ast := w.readToken(ast)
 	path, err := unquoteString(tok.src)
 	if err != nil {
@@ -390,7 +441,7 @@ func (w *walker) walkRequireStmt(ast []int32) (Statement, error) {
 			}
 		}
 	}
-	return &RequireStmt{Path: path, Alias: alias, With: with}, nil
+	return &RequireStmt{Path: path, Alias: alias, With: with, Optional: optional}, nil
 }
 
 func (w *walker) walkEmbedStmt(ast []int32) (Statement, error) {
@@ -609,7 +660,30 @@ func (w *walker) walkBenchDef(ast []int32) (Statement, error) {
 		}
 	}
 	_ = ast // "end" token
-	return &BenchDef{Name: name, Body: body}, nil
+	bench := &BenchDef{Name: name, Body: body}
+
+	// bench "name", iterations: N arrives as a leading
+	// __bench_options__("iterations", N) statement.
+	if len(body) > 0 {
+		if es, ok := body[0].(*ExprStmt); ok {
+			if call, ok := es.Expression.(*CallExpr); ok {
+				if id, ok := call.Func.(*IdentExpr); ok && id.Name == preprocess.BenchOptionsBuiltin {
+					bench.Body = body[1:]
+					for i := 0; i+1 < len(call.Args); i += 2 {
+						opt, _ := call.Args[i].(*StringLiteral)
+						switch {
+						case opt == nil:
+						case opt.Value == "iterations":
+							bench.Iterations = call.Args[i+1]
+						case opt.Value == "min_time":
+							bench.MinTime = call.Args[i+1]
+						}
+					}
+				}
+			}
+		}
+	}
+	return bench, nil
 }
 
 func (w *walker) walkFuncDef(ast []int32) (Statement, error) {
@@ -650,7 +724,19 @@ func (w *walker) walkFuncDef(ast []int32) (Statement, error) {
 	// "end"
 	// _, _ = w.readToken(ast)
 
-	return &FuncDef{Name: nameTok.src, Params: params, Body: body}, nil
+	// memo def arrives as a leading __memo__() statement.
+	memo := false
+	if len(body) > 0 {
+		if es, ok := body[0].(*ExprStmt); ok {
+			if call, ok := es.Expression.(*CallExpr); ok && len(call.Args) == 0 {
+				if id, ok := call.Func.(*IdentExpr); ok && id.Name == preprocess.MemoBuiltin {
+					memo, body = true, body[1:]
+				}
+			}
+		}
+	}
+
+	return &FuncDef{Name: nameTok.src, Params: params, Body: body, Memo: memo}, nil
 }
 
 func (w *walker) walkParamList(ast []int32) ([]Param, error) {
@@ -728,16 +814,18 @@ func (w *walker) walkBody(ast []int32) ([]Statement, error) {
 			tok := w.p.Token(ast[0])
 			if parser.Symbol(tok.Ch) == parser.RugoTOK_003b {
 				_, ast = w.readToken(ast)
+				// An explicit separator lets the next statement share the line.
+				prevRawLine = 0
 				continue
 			}
 		}
-		rawLine := w.firstTokenRawLine(ast)
+		rawLine, rawCol := w.firstTokenRawPos(ast)
 		stmt, rest, err := w.walkStatement(ast)
 		if err != nil {
 			return nil, err
 		}
 		if prevRawLine > 0 && rawLine == prevRawLine {
-			return nil, &UserError{Msg: fmt.Sprintf("line %d: syntax error: unexpected token on same line as previous statement", w.resolvedLine(stmt.StmtLine()))}
+			return nil, &UserError{Msg: "syntax error: unexpected token on same line as previous statement", Line: rawLine, Col: rawCol}
 		}
 		prevRawLine = rawLine
 		stmts = append(stmts, stmt)
@@ -1019,7 +1107,29 @@ func (w *walker) walkWhileStmt(ast []int32) (Statement, error) {
 		}
 		_ = rest
 	}
-	return &WhileStmt{Condition: cond, Body: body}, nil
+	ws := &WhileStmt{Condition: cond, Body: body}
+	liftWhileElse(ws)
+	return ws, nil
+}
+
+// liftWhileElse moves the statements after a __while_else__() marker
+// (see preprocess.expandWhileElse) into the loop's ElseBody.
+func liftWhileElse(ws *WhileStmt) {
+	for i, stmt := range ws.Body {
+		es, ok := stmt.(*ExprStmt)
+		if !ok {
+			continue
+		}
+		call, ok := es.Expression.(*CallExpr)
+		if !ok {
+			continue
+		}
+		if id, ok := call.Func.(*IdentExpr); ok && id.Name == preprocess.WhileElseBuiltin {
+			ws.ElseBody = append([]Statement{}, ws.Body[i+1:]...)
+			ws.Body = ws.Body[:i]
+			return
+		}
+	}
 }
 
 func (w *walker) walkForStmt(ast []int32) (Statement, error) {
@@ -1086,12 +1196,19 @@ func (w *walker) walkAssignOrExpr(ast []int32) (Statement, error) {
 		}
 		// LHS must be an identifier or index expression for assignment
 		if ident, ok := lhs.(*IdentExpr); ok {
+			// const x = v arrives as __const__x = v.
+			if name, ok := strings.CutPrefix(ident.Name, preprocess.ConstPrefix); ok {
+				return &AssignStmt{Target: name, Value: rhs, Const: true}, nil
+			}
 			return &AssignStmt{Target: ident.Name, Value: rhs}, nil
 		}
 		if idx, ok := lhs.(*IndexExpr); ok {
 			return &IndexAssignStmt{Object: idx.Object, Index: idx.Index, Value: rhs}, nil
 		}
 		if dot, ok := lhs.(*DotExpr); ok {
+			if dot.Safe {
+				return nil, &UserError{Msg: "cannot assign through safe navigation — use x.y = value, not x&.y = value"}
+			}
 			return &DotAssignStmt{Object: dot.Object, Field: dot.Field, Value: rhs}, nil
 		}
 		return nil, &UserError{Msg: "cannot assign to non-variable — left side of '=' must be a variable, index expression (x[i]), or field access (x.y)"}
@@ -1169,7 +1286,7 @@ func (w *walker) walkAndExpr(ast []int32) (Expr, []int32, error) {
 }
 
 func (w *walker) walkCompExpr(ast []int32) (Expr, []int32, error) {
-	// CompExpr = AddExpr [ comp_op AddExpr ] .
+	// CompExpr = AddExpr [ ( comp_op | "in" ) AddExpr ] .
 	sym, children, rest := w.readNonTerminal(ast)
 	if sym != parser.RugoCompExpr {
 		return nil, rest, fmt.Errorf("expected CompExpr, got %v", sym)
@@ -1180,7 +1297,7 @@ func (w *walker) walkCompExpr(ast []int32) (Expr, []int32, error) {
 		return nil, rest, err
 	}
 	if len(remaining) > 0 {
-		opTok, remaining := w.readToken(remaining) // comp_op
+		opTok, remaining := w.readToken(remaining) // comp_op or "in"
 		right, _, err := w.walkAddExpr(remaining)
 		if err != nil {
 			return nil, rest, err
@@ -1348,6 +1465,10 @@ func (w *walker) walkSuffix(obj Expr, ast []int32) (Expr, error) {
 	case parser.RugoTOK_002e: // '.'
 		// Dot access: namespace.func
 		fieldTok, _ := w.readToken(rest)
+		// a&.b arrives as a.__safe__b.
+		if field, ok := strings.CutPrefix(fieldTok.src, preprocess.SafeNavPrefix); ok {
+			return &DotExpr{Object: obj, Field: field, Safe: true}, nil
+		}
 		return &DotExpr{Object: obj, Field: fieldTok.src}, nil
 	}
 	return obj, nil
@@ -1424,7 +1545,8 @@ func (w *walker) walkPrimary(ast []int32) (Expr, []int32, error) {
 	case parser.Rugostr_lit:
 		val, err := unquoteString(tok.src)
 		if err != nil {
-			return nil, rest, err
+			pos := w.p.Token(children[0]).Position()
+			return nil, rest, &UserError{Msg: fmt.Sprintf("%v: %s", err, tok.src), Line: pos.Line, Col: pos.Column}
 		}
 		return &StringLiteral{Value: val}, rest, nil
 	case parser.Rugoraw_str_lit:
@@ -1506,7 +1628,7 @@ func (w *walker) walkHashLit(ast []int32) (Expr, error) {
 }
 
 func (w *walker) walkTryExpr(ast []int32) (Expr, error) {
-	// TryExpr = "try" Expr "or" ident Body "end" .
+	// TryExpr = "try" Expr "or" ident Body [ "ensure" Body ] "end" .
 	_, ast = w.readToken(ast) // "try"
 
 	expr, ast, err := w.walkExpr(ast)
@@ -1518,20 +1640,36 @@ func (w *walker) walkTryExpr(ast []int32) (Expr, error) {
 
 	errTok, ast := w.readToken(ast) // ident (error variable)
 
-	sym, children, _ := w.readNonTerminal(ast)
-	var handler []Statement
-	if sym == parser.RugoBody {
-		handler, err = w.walkBody(children)
+	var handler, ensure []Statement
+	inEnsure := false
+	for len(ast) > 0 {
+		sym, children, rest := w.readNonTerminal(ast)
+		if sym != parser.RugoBody {
+			var tok token
+			tok, ast = w.readToken(ast)
+			if tok.ch == parser.RugoTOK_ensure {
+				inEnsure = true
+				ensure = []Statement{}
+			}
+			continue
+		}
+		body, err := w.walkBody(children)
 		if err != nil {
 			return nil, err
 		}
+		if inEnsure {
+			ensure = body
+		} else {
+			handler = body
+		}
+		ast = rest
 	}
-	// "end" is consumed by the parser
 
 	return &TryExpr{
 		Expr:    expr,
 		ErrVar:  errTok.src,
 		Handler: handler,
+		Ensure:  ensure,
 	}, nil
 }
 
@@ -1569,8 +1707,21 @@ func (w *walker) walkParallelExpr(ast []int32) (Expr, error) {
 		}
 	}
 
+	// parallel(limit: N) arrives as a leading __parallel_limit__(N) statement.
+	var limit Expr
+	if len(body) > 0 {
+		if es, ok := body[0].(*ExprStmt); ok {
+			if call, ok := es.Expression.(*CallExpr); ok && len(call.Args) == 1 {
+				if id, ok := call.Func.(*IdentExpr); ok && id.Name == preprocess.ParallelLimitBuiltin {
+					limit, body = call.Args[0], body[1:]
+				}
+			}
+		}
+	}
+
 	return &ParallelExpr{
-		Body: body,
+		Body:  body,
+		Limit: limit,
 	}, nil
 }
 
@@ -1611,12 +1762,18 @@ func (w *walker) walkFnExpr(ast []int32) (Expr, error) {
 }
 
 // unquoteString removes surrounding quotes and processes escape sequences.
+// An escaped \#{ stays as \#{ so string interpolation can tell it from a
+// real #{ (see preprocess.ProcessInterpolation); literal backslashes right
+// before either are doubled for the same reason.
 func unquoteString(s string) (string, error) {
 	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
 		s = s[1 : len(s)-1]
 	}
 	var sb strings.Builder
 	for i := 0; i < len(s); i++ {
+		if s[i] == '#' && i+1 < len(s) && s[i+1] == '{' {
+			doubleTrailingBackslashes(&sb)
+		}
 		if s[i] != '\\' || i+1 >= len(s) {
 			sb.WriteByte(s[i])
 			continue
@@ -1638,6 +1795,10 @@ func unquoteString(s string) (string, error) {
 		case next == '\\':
 			sb.WriteByte('\\')
 			i++
+		case next == '#' && i+2 < len(s) && s[i+2] == '{':
+			doubleTrailingBackslashes(&sb)
+			sb.WriteString(`\#`)
+			i++
 		case next == 'x' || next == 'X':
 			// Hex escape: \xHH
 			if i+3 < len(s) {
@@ -1664,6 +1825,20 @@ func unquoteString(s string) (string, error) {
 				continue
 			}
 			sb.WriteByte(s[i])
+		case next == 'u':
+			// Unicode escape: \uHHHH or \u{H...}
+			r, n, err := unicodeEscape(s[i:])
+			if err != nil {
+				return "", err
+			}
+			i += n - 1
+			if r == '#' && i+1 < len(s) && s[i+1] == '{' {
+				// An escaped # never starts an interpolation.
+				doubleTrailingBackslashes(&sb)
+				sb.WriteString(`\#`)
+				continue
+			}
+			sb.WriteRune(r)
 		default:
 			return "", &UserError{Msg: fmt.Sprintf("unsupported escape sequence \\%c in string literal", next)}
 		}
@@ -1671,6 +1846,49 @@ func unquoteString(s string) (string, error) {
 	return sb.String(), nil
 }
 
+// unicodeEscape decodes the \uHHHH or \u{H...} escape at the start of s
+// and returns the code point and the escape's length in bytes.
+func unicodeEscape(s string) (rune, int, error) {
+	var hex string
+	var n int
+	if len(s) > 2 && s[2] == '{' {
+		end := strings.IndexByte(s, '}')
+		if end < 0 {
+			return 0, 0, &UserError{Msg: "malformed unicode escape \\u{ in string literal — missing closing }"}
+		}
+		hex, n = s[3:end], end+1
+		if len(hex) == 0 || len(hex) > 6 {
+			return 0, 0, &UserError{Msg: fmt.Sprintf("malformed unicode escape %s in string literal — expected 1 to 6 hex digits", s[:n])}
+		}
+	} else {
+		n = 6
+		if len(s) < n {
+			n = len(s)
+		}
+		hex = s[2:n]
+		if len(hex) != 4 {
+			return 0, 0, &UserError{Msg: fmt.Sprintf("malformed unicode escape %s in string literal — expected \\uHHHH or \\u{HEX}", s[:n])}
+		}
+	}
+	val, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return 0, 0, &UserError{Msg: fmt.Sprintf("malformed unicode escape %s in string literal — %q is not hexadecimal", s[:n], hex)}
+	}
+	if !utf8.ValidRune(rune(val)) {
+		return 0, 0, &UserError{Msg: fmt.Sprintf("invalid unicode code point U+%04X in escape %s", val, s[:n])}
+	}
+	return rune(val), n, nil
+}
+
+// doubleTrailingBackslashes doubles the run of backslashes at the end of sb.
+func doubleTrailingBackslashes(sb *strings.Builder) {
+	cur := sb.String()
+	n := len(cur) - len(strings.TrimRight(cur, `\`))
+	if n > 0 {
+		sb.WriteString(strings.Repeat(`\`, n))
+	}
+}
+
 // unquoteRawString removes surrounding single quotes and only processes \\ and \'.
 // All other backslash sequences are kept literal (Ruby-style raw strings).
 func unquoteRawString(s string) string {
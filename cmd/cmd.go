@@ -3,7 +3,10 @@ package cmd
 import (
 	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"go/format"
 	"io"
 	"os"
 	"os/exec"
@@ -19,6 +22,7 @@ import (
 	rugodoc "github.com/rubiojr/rugo/doc"
 	"github.com/rubiojr/rugo/gobridge"
 	"github.com/rubiojr/rugo/modules"
+	"github.com/rubiojr/rugo/preprocess"
 	"github.com/rubiojr/rugo/remote"
 	"github.com/urfave/cli/v3"
 	"golang.org/x/term"
@@ -75,6 +79,14 @@ func Execute(version string) {
 						Name:  "show-warnings",
 						Usage: "Show bridge warnings about unbridgeable Go functions",
 					},
+					&cli.BoolFlag{
+						Name:  "strict",
+						Usage: "Reject unknown commands instead of running them as shell commands",
+					},
+					&cli.BoolFlag{
+						Name:  "warn-unused",
+						Usage: "Warn about local variables that are assigned but never used",
+					},
 				},
 				Action: buildAction,
 			},
@@ -82,7 +94,17 @@ func Execute(version string) {
 				Name:      "emit",
 				Usage:     "Output the generated Go source code",
 				ArgsUsage: "<file.rugo>",
-				Action:    emitAction,
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "map",
+						Usage: "Also write <file>.map.json mapping generated Go lines to Rugo lines",
+					},
+					&cli.BoolFlag{
+						Name:  "fmt",
+						Usage: "Format the generated Go source with gofmt before printing",
+					},
+				},
+				Action: emitAction,
 			},
 			{
 				Name:            "eval",
@@ -91,6 +113,11 @@ func Execute(version string) {
 				SkipFlagParsing: true,
 				Action:          evalAction,
 			},
+			{
+				Name:   "repl",
+				Usage:  "Start an interactive Rugo prompt",
+				Action: replAction,
+			},
 			{
 				Name:      "rats",
 				Usage:     "Run tests from _test.rugo files and Rugo files with inline rats blocks",
@@ -159,9 +186,42 @@ func Execute(version string) {
 						Aliases: []string{"a"},
 						Usage:   "List all available modules and bridge packages",
 					},
+					&cli.StringFlag{
+						Name:    "symbol",
+						Aliases: []string{"s"},
+						Usage:   "Show only this function or struct",
+					},
+					&cli.BoolFlag{
+						Name:  "markdown",
+						Usage: "Print Rugo file and directory docs as Markdown",
+					},
 				},
 				Action: docAction,
 			},
+			{
+				Name:      "deps",
+				Usage:     "Print the require/use/import dependency graph of a file",
+				ArgsUsage: "<file.rugo>",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "dot",
+						Usage: "Print the graph in Graphviz DOT format",
+					},
+				},
+				Action: depsAction,
+			},
+			{
+				Name:      "fmt",
+				Usage:     "Format Rugo source files in place",
+				ArgsUsage: "<file.rugo>...",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "check",
+						Usage: "List unformatted files and exit non-zero instead of rewriting them",
+					},
+				},
+				Action: fmtAction,
+			},
 			{
 				Name:  "mod",
 				Usage: "Manage remote module dependencies",
@@ -211,7 +271,7 @@ func Execute(version string) {
 	cmd.Commands = append(cmd.Commands, installedToolCommands()...)
 
 	if err := cmd.Run(context.Background(), os.Args); err != nil {
-		fmt.Fprintln(os.Stderr, formatError(err.Error()))
+		printError(err)
 		os.Exit(1)
 	}
 }
@@ -223,16 +283,36 @@ func runAction(ctx context.Context, cmd *cli.Command) error {
 	args := cmd.Args().Slice()
 	sandbox, args := parseSandboxFlags(args)
 	showWarnings, args := extractBoolFlag(args, "--show-warnings")
+	profile, args, err := parseProfileFlags(args)
+	if err != nil {
+		return err
+	}
+	strict, args := extractLeadingBoolFlag(args, "--strict")
+	dryShell, args := extractLeadingBoolFlag(args, "--dry-shell")
+	watch, args := extractLeadingBoolFlag(args, "--watch")
+	trace, args := extractLeadingBoolFlag(args, "--trace")
+	warnUnused, args := extractLeadingBoolFlag(args, "--warn-unused")
+	deps, args := extractLeadingBoolFlag(args, "--deps")
+	if dryShell {
+		// Read by the compiled program's shell helpers.
+		os.Setenv("RUGO_DRY_SHELL", "1")
+	}
 	if len(args) == 0 {
-		return fmt.Errorf("usage: rugo run [--sandbox flags...] <file.rugo> [args...]")
+		return fmt.Errorf("usage: rugo run [--strict] [--warn-unused] [--dry-shell] [--trace] [--watch] [--deps] [--sandbox flags...] [--profile file] [--memprofile file] <file.rugo> [args...]")
+	}
+	if deps {
+		return printDeps(args[0], false)
 	}
-	comp := &compiler.Compiler{Sandbox: sandbox, ShowWarnings: showWarnings}
+	comp := &compiler.Compiler{Sandbox: sandbox, ShowWarnings: showWarnings, Profile: profile, Strict: strict, WarnUnused: warnUnused, Trace: trace}
 	scriptArgs := args[1:]
 	// Strip leading "--" separator so `rugo run script -- args` passes
 	// only the actual args to the script (SkipFlagParsing keeps "--" literal).
 	if len(scriptArgs) > 0 && scriptArgs[0] == "--" {
 		scriptArgs = scriptArgs[1:]
 	}
+	if watch {
+		return comp.Watch(args[0], scriptArgs...)
+	}
 	return comp.Run(args[0], scriptArgs...)
 }
 
@@ -241,7 +321,7 @@ func buildAction(ctx context.Context, cmd *cli.Command) error {
 		return fmt.Errorf("usage: rugo build [-o output] [--frozen] [--sandbox flags...] <file.rugo>")
 	}
 	sandbox, _ := parseSandboxFlags(cmd.Args().Slice())
-	comp := &compiler.Compiler{Frozen: cmd.Bool("frozen"), ShowWarnings: cmd.Bool("show-warnings"), Sandbox: sandbox}
+	comp := &compiler.Compiler{Frozen: cmd.Bool("frozen"), ShowWarnings: cmd.Bool("show-warnings"), Sandbox: sandbox, Strict: cmd.Bool("strict"), WarnUnused: cmd.Bool("warn-unused")}
 	output := cmd.String("output")
 	// Also check if -o was passed after the filename (urfave quirk)
 	if output == "" {
@@ -254,6 +334,66 @@ func buildAction(ctx context.Context, cmd *cli.Command) error {
 	return comp.Build(cmd.Args().First(), output)
 }
 
+func depsAction(ctx context.Context, cmd *cli.Command) error {
+	if cmd.NArg() < 1 {
+		return fmt.Errorf("usage: rugo deps [--dot] <file.rugo>")
+	}
+	return printDeps(cmd.Args().First(), cmd.Bool("dot"))
+}
+
+// printDeps prints the dependency graph of file as a tree, or as DOT
+// when dot is set. Circular requires are still printed, then reported
+// as an error.
+func printDeps(file string, dot bool) error {
+	comp := &compiler.Compiler{}
+	graph, err := comp.Deps(file)
+	if err != nil {
+		return err
+	}
+	if dot {
+		graph.WriteDot(os.Stdout)
+	} else {
+		graph.WriteTree(os.Stdout)
+	}
+	if len(graph.Cycles) > 0 {
+		return fmt.Errorf("circular require detected: %s", strings.Join(graph.Cycles, "; "))
+	}
+	return nil
+}
+
+func fmtAction(ctx context.Context, cmd *cli.Command) error {
+	if cmd.NArg() < 1 {
+		return fmt.Errorf("usage: rugo fmt [--check] <file.rugo>...")
+	}
+	check := cmd.Bool("check")
+	unformatted := 0
+	for _, file := range cmd.Args().Slice() {
+		src, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		out, err := preprocess.Format(string(src))
+		if err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		if out == string(src) {
+			continue
+		}
+		if check {
+			fmt.Println(file)
+			unformatted++
+			continue
+		}
+		if err := os.WriteFile(file, []byte(out), 0644); err != nil {
+			return err
+		}
+	}
+	if unformatted > 0 {
+		return fmt.Errorf("%d file(s) not formatted", unformatted)
+	}
+	return nil
+}
+
 // parseSandboxFlags extracts --sandbox and related flags from args.
 // Returns the SandboxConfig (nil if --sandbox not present) and remaining args.
 // extractBoolFlag removes a boolean flag from args and returns whether it was present.
@@ -270,6 +410,53 @@ func extractBoolFlag(args []string, flag string) (bool, []string) {
 	return found, remaining
 }
 
+// extractLeadingBoolFlag removes a boolean flag from the args preceding the
+// script file (the first non-flag argument), leaving script arguments
+// untouched.
+func extractLeadingBoolFlag(args []string, flag string) (bool, []string) {
+	var remaining []string
+	found, inScript := false, false
+	for _, a := range args {
+		if a == flag && !inScript {
+			found = true
+			continue
+		}
+		if !strings.HasPrefix(a, "-") {
+			inScript = true
+		}
+		remaining = append(remaining, a)
+	}
+	return found, remaining
+}
+
+// parseProfileFlags extracts --profile and --memprofile (each followed by an
+// output path) from the args preceding the script file. Returns the
+// ProfileConfig (nil if neither flag is present) and remaining args.
+func parseProfileFlags(args []string) (*compiler.ProfileConfig, []string, error) {
+	var cfg compiler.ProfileConfig
+	var remaining []string
+	for i := 0; i < len(args); i++ {
+		flag := args[i]
+		if flag != "--profile" && flag != "--memprofile" || len(remaining) > 0 {
+			remaining = append(remaining, flag)
+			continue
+		}
+		if i+1 >= len(args) {
+			return nil, nil, fmt.Errorf("%s requires an output file", flag)
+		}
+		i++
+		if flag == "--profile" {
+			cfg.CPU = args[i]
+		} else {
+			cfg.Mem = args[i]
+		}
+	}
+	if cfg.CPU == "" && cfg.Mem == "" {
+		return nil, remaining, nil
+	}
+	return &cfg, remaining, nil
+}
+
 func parseSandboxFlags(args []string) (*compiler.SandboxConfig, []string) {
 	hasSandbox := false
 	var ro, rw, rox, rwx []string
@@ -338,11 +525,31 @@ func emitAction(ctx context.Context, cmd *cli.Command) error {
 	if cmd.NArg() < 1 {
 		return fmt.Errorf("usage: rugo emit <file.rugo>")
 	}
+	file := cmd.Args().First()
 	comp := &compiler.Compiler{}
-	src, err := comp.Emit(cmd.Args().First())
+	src, err := comp.Emit(file)
 	if err != nil {
 		return err
 	}
+	// Format before building the source map so its Go lines match the output.
+	if cmd.Bool("fmt") {
+		formatted, err := format.Source([]byte(src))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: could not format generated Go, printing it unformatted: %v\n", err)
+		} else {
+			src = string(formatted)
+		}
+	}
+	if cmd.Bool("map") {
+		data, err := json.MarshalIndent(compiler.BuildSourceMap(file, src), "", "  ")
+		if err != nil {
+			return fmt.Errorf("encoding source map: %w", err)
+		}
+		mapFile := strings.TrimSuffix(file, filepath.Ext(file)) + ".map.json"
+		if err := os.WriteFile(mapFile, append(data, '\n'), 0644); err != nil {
+			return fmt.Errorf("writing source map: %w", err)
+		}
+	}
 	fmt.Print(src)
 	return nil
 }
@@ -532,10 +739,11 @@ func docAction(ctx context.Context, cmd *cli.Command) error {
 	}
 
 	target := cmd.Args().First()
-	symbol := ""
-	if cmd.NArg() > 1 {
+	symbol := cmd.String("symbol")
+	if symbol == "" && cmd.NArg() > 1 {
 		symbol = cmd.Args().Get(1)
 	}
+	markdown := cmd.Bool("markdown")
 
 	// Parse use:/import: prefix for disambiguation
 	forceModule, forceBridge := false, false
@@ -547,6 +755,10 @@ func docAction(ctx context.Context, cmd *cli.Command) error {
 		target = strings.TrimPrefix(target, "import:")
 	}
 
+	if markdown && (forceModule || forceBridge) {
+		return fmt.Errorf("--markdown is only supported for Rugo files and directories")
+	}
+
 	// Forced module lookup (use:)
 	if forceModule {
 		m, ok := modules.Get(target)
@@ -576,21 +788,17 @@ func docAction(ctx context.Context, cmd *cli.Command) error {
 		if err != nil {
 			return fmt.Errorf("reading %s: %w", target, err)
 		}
-		if symbol != "" {
-			doc, sig, found := rugodoc.LookupSymbol(fd, symbol)
-			if !found {
-				return fmt.Errorf("%s: symbol %q not found", target, symbol)
-			}
-			docOutput(rugodoc.FormatSymbol(doc, sig))
-			return nil
-		}
-		docOutput(rugodoc.FormatFile(fd))
-		return nil
+		return docFileOutput(fd, target, symbol, markdown)
 	}
 
 	// Mode 2: local directory (e.g. ./gummy, gummy, .)
 	if info, err := os.Stat(target); err == nil && info.IsDir() {
-		return docLocalDir(target, symbol)
+		return docLocalDir(target, symbol, markdown)
+	}
+
+	// Mode 6 (remote module) is the only other mode with Rugo sources.
+	if markdown && !remote.IsRemoteRequire(target) {
+		return fmt.Errorf("--markdown is only supported for Rugo files and directories")
 	}
 
 	// Check for ambiguity: name exists as both module and bridge package
@@ -622,7 +830,7 @@ func docAction(ctx context.Context, cmd *cli.Command) error {
 
 	// Mode 6: remote module (e.g. github.com/user/repo)
 	if remote.IsRemoteRequire(target) {
-		return docRemote(target, symbol)
+		return docRemote(target, symbol, markdown)
 	}
 
 	return fmt.Errorf("unknown module or package: %s", target)
@@ -630,7 +838,7 @@ func docAction(ctx context.Context, cmd *cli.Command) error {
 
 // docRemote fetches a remote module and prints its documentation.
 // Recursively aggregates docs from all non-test Rugo files in the module directory.
-func docRemote(target, symbol string) error {
+func docRemote(target, symbol string, markdown bool) error {
 	r := &remote.Resolver{}
 
 	// Fetch repo and try to resolve entry point; fall back to Go module
@@ -659,24 +867,14 @@ func docRemote(target, symbol string) error {
 	if err != nil {
 		return fmt.Errorf("reading %s: %w", dir, err)
 	}
-
-	if symbol != "" {
-		doc, sig, found := rugodoc.LookupSymbol(fd, symbol)
-		if !found {
-			return fmt.Errorf("%s: symbol %q not found", target, symbol)
-		}
-		docOutput(rugodoc.FormatSymbol(doc, sig))
-		return nil
-	}
-
-	docOutput(rugodoc.FormatFile(fd))
-	return nil
+	fd.Path = target
+	return docFileOutput(fd, target, symbol, markdown)
 }
 
 // docLocalDir prints documentation for a local directory module.
 // It finds the entry point Rugo file and recursively aggregates docs
 // from all non-test Rugo files in the tree.
-func docLocalDir(dir, symbol string) error {
+func docLocalDir(dir, symbol string, markdown bool) error {
 	// Check if it's a Go module directory first
 	if gobridge.IsGoModuleDir(dir) {
 		result, err := gobridge.InspectSourcePackage(dir)
@@ -699,16 +897,28 @@ func docLocalDir(dir, symbol string) error {
 	if err != nil {
 		return fmt.Errorf("reading %s: %w", dir, err)
 	}
+	return docFileOutput(fd, dir, symbol, markdown)
+}
 
+// docFileOutput prints the docs extracted from Rugo sources, or just the
+// entry for symbol when set. Markdown output is never colorized.
+func docFileOutput(fd *rugodoc.FileDoc, target, symbol string, markdown bool) error {
 	if symbol != "" {
 		doc, sig, found := rugodoc.LookupSymbol(fd, symbol)
 		if !found {
-			return fmt.Errorf("%s: symbol %q not found", dir, symbol)
+			return fmt.Errorf("%s: symbol %q not found", target, symbol)
+		}
+		if markdown {
+			fmt.Printf("```ruby\n%s\n```\n\n%s\n", sig, doc)
+			return nil
 		}
 		docOutput(rugodoc.FormatSymbol(doc, sig))
 		return nil
 	}
-
+	if markdown {
+		fmt.Print(rugodoc.RenderMarkdown(fd))
+		return nil
+	}
 	docOutput(rugodoc.FormatFile(fd))
 	return nil
 }
@@ -985,7 +1195,7 @@ func testAction(ctx context.Context, cmd *cli.Command) error {
 		fmt.Fprintf(os.Stderr, "=== %s ===\n", files[0])
 		comp := &compiler.Compiler{TestMode: true}
 		if err := comp.Run(files[0]); err != nil {
-			fmt.Fprintln(os.Stderr, formatError(err.Error()))
+			printError(err)
 			os.Exit(1)
 		}
 		return nil
@@ -1160,6 +1370,19 @@ func fileHasRatsBlocks(path string) bool {
 	return false
 }
 
+// printError writes err to stderr. A compile that collected several
+// independent errors prints each one with its own error prefix.
+func printError(err error) {
+	var multi interface{ Unwrap() []error }
+	if errors.As(err, &multi) {
+		for _, e := range multi.Unwrap() {
+			fmt.Fprintln(os.Stderr, formatError(e.Error()))
+		}
+		return
+	}
+	fmt.Fprintln(os.Stderr, formatError(err.Error()))
+}
+
 // formatError colorizes an error message for terminal output.
 // Respects the NO_COLOR environment variable.
 func formatError(msg string) string {
@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/rubiojr/rugo/ast"
+	"github.com/rubiojr/rugo/compiler"
+	"github.com/rubiojr/rugo/preprocess"
+	"github.com/urfave/cli/v3"
+	"golang.org/x/term"
+)
+
+// replMarker separates the output of replayed history from the output of
+// the entry being evaluated.
+const replMarker = "\x1erugo-repl\x1e"
+
+// repl keeps the entries that evaluated successfully. Each new entry is
+// compiled and run together with this history, so definitions and
+// variables persist; earlier output is hidden behind replMarker.
+type repl struct {
+	history []string
+	out     io.Writer
+}
+
+func replAction(ctx context.Context, cmd *cli.Command) error {
+	interactive := term.IsTerminal(int(os.Stdin.Fd()))
+	if interactive {
+		fmt.Println("Rugo REPL — type exit or press Ctrl-D to quit")
+	}
+	r := &repl{out: os.Stdout}
+	scanner := bufio.NewScanner(os.Stdin)
+	var pending []string
+	for {
+		if interactive {
+			if len(pending) == 0 {
+				fmt.Print("rugo> ")
+			} else {
+				fmt.Print("...> ")
+			}
+		}
+		if !scanner.Scan() {
+			break
+		}
+		line := scanner.Text()
+		if len(pending) == 0 {
+			switch strings.TrimSpace(line) {
+			case "":
+				continue
+			case "exit", "quit":
+				return nil
+			}
+		}
+		pending = append(pending, line)
+		entry := strings.Join(pending, "\n")
+		if replIncomplete(entry) {
+			continue
+		}
+		pending = nil
+		r.eval(entry)
+	}
+	if interactive {
+		fmt.Println()
+	}
+	return scanner.Err()
+}
+
+// replIncomplete reports whether entry still has an open block (or an
+// unterminated heredoc) and the prompt should continue.
+func replIncomplete(entry string) bool {
+	cleaned, _, _, err := preprocess.Run(entry)
+	if err != nil {
+		return strings.Contains(err.Error(), "unterminated heredoc")
+	}
+	be := preprocess.CheckBlocks(cleaned)
+	return be != nil && be.Keyword != "end"
+}
+
+// eval runs entry on top of the history. A single expression prints its
+// value; anything else runs as statements.
+func (r *repl) eval(entry string) {
+	prefix := strings.Join(r.history, "\n")
+	if prefix != "" {
+		prefix += "\n"
+	}
+	prefix += fmt.Sprintf("print(%q)\n", replMarker)
+	offset := strings.Count(prefix, "\n")
+
+	src := prefix + entry + "\n"
+	if replIsExpr(src, offset) {
+		src = prefix + "__repl_value__ = " + entry + "\n" +
+			"if type_of(__repl_value__) == \"String\"\n" +
+			"  puts(\"=> \\\"\" + __repl_value__ + \"\\\"\")\n" +
+			"elsif __repl_value__ != nil\n" +
+			"  puts(\"=> #{__repl_value__}\")\n" +
+			"end\n"
+	}
+	result, err := (&compiler.Compiler{}).RunStringCapture(src)
+	if err != nil {
+		fmt.Fprintln(r.out, replErrorLines(err.Error(), offset))
+		return
+	}
+	output := result.Output
+	if i := strings.Index(output, replMarker); i >= 0 {
+		output = output[i+len(replMarker):]
+	}
+	if output != "" {
+		fmt.Fprintln(r.out, replErrorLines(output, offset))
+	}
+	if result.ExitCode == 0 {
+		r.history = append(r.history, entry)
+	}
+}
+
+// replIsExpr reports whether the lines of src after offset hold a single
+// one-line expression statement that is not a shell command. The history
+// is parsed along with it so known variables are not taken for commands.
+func replIsExpr(src string, offset int) bool {
+	prog, err := (&compiler.Compiler{}).ParseSource(src, compiler.StringSourceName)
+	if err != nil {
+		return false
+	}
+	var entry []ast.Statement
+	for _, s := range prog.Statements {
+		if s.StmtLine() > offset {
+			entry = append(entry, s)
+		}
+	}
+	if len(entry) != 1 || entry[0].StmtEndLine() > entry[0].StmtLine() {
+		return false
+	}
+	es, ok := entry[0].(*ast.ExprStmt)
+	if !ok {
+		return false
+	}
+	if call, ok := es.Expression.(*ast.CallExpr); ok {
+		if fn, ok := call.Func.(*ast.IdentExpr); ok && strings.HasPrefix(fn.Name, "__") {
+			return false
+		}
+	}
+	return true
+}
+
+var replLineRe = regexp.MustCompile(regexp.QuoteMeta(compiler.StringSourceName) + `:(\d+)`)
+
+// replErrorLines rewrites <string>:N locations, which count lines of the
+// whole replayed buffer, to lines of the current entry.
+func replErrorLines(msg string, offset int) string {
+	return replLineRe.ReplaceAllStringFunc(msg, func(m string) string {
+		n, _ := strconv.Atoi(m[len(compiler.StringSourceName)+1:])
+		if n > offset {
+			n -= offset
+		}
+		return fmt.Sprintf("%s:%d", compiler.StringSourceName, n)
+	})
+}
@@ -35,6 +35,40 @@ func TestParseSourceError(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestParseSourceErrorsCarryColumns(t *testing.T) {
+	c := &Compiler{}
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{"x = 1\nputs(x ))\n", "bad.rugo:2:9: unexpected \")\""},
+		{"x = 1\nputs 1, x x\n", "bad.rugo:2:11: unexpected \"x\""},
+		{"x = 1\nputs(1) puts(2)\n", "bad.rugo:2:9: syntax error: unexpected token on same line"},
+		{"x = 1\nunless x == \"a\\q\"\nend\n", "bad.rugo:2:13: unsupported escape sequence \\q"},
+	}
+	for _, tt := range tests {
+		_, err := c.ParseSource(tt.src, "bad.rugo")
+		require.Error(t, err, tt.src)
+		assert.Contains(t, err.Error(), tt.want, tt.src)
+	}
+}
+
+func TestCompileString(t *testing.T) {
+	c := &Compiler{}
+	goSrc, err := c.CompileString("x = 1\nputs(x + 1)\n")
+	require.NoError(t, err)
+	assert.Contains(t, goSrc, "package main")
+	assert.Contains(t, goSrc, "//line "+StringSourceName+":2")
+}
+
+func TestCompileStringErrorsNameTheSource(t *testing.T) {
+	c := &Compiler{}
+	_, err := c.CompileString("puts(1)\nputs(y)\n")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), StringSourceName+":2")
+	assert.Contains(t, err.Error(), "undefined variable 'y'")
+}
+
 func TestParseFile(t *testing.T) {
 	c := &Compiler{}
 	prog, err := c.ParseFile("../examples/hello.rugo")
@@ -124,6 +158,39 @@ func TestStmtLineAfterHeredoc(t *testing.T) {
 	assert.Equal(t, "def foo()", strings.TrimSpace(lines[fn.StmtLine()-1]))
 }
 
+func TestHeredocInStructMethod(t *testing.T) {
+	c := &Compiler{}
+	src := "struct Page\n  title\nend\n\ndef Page.render()\n  return <<~HTML\n    <h1>#{self.title}</h1>\n      <p>body</p>\n    HTML\nend\n\nputs(render(Page(\"Hi\")))\n"
+	prog, err := c.ParseSource(src, "test.rugo")
+	require.NoError(t, err)
+
+	var fn *ast.FuncDef
+	for _, s := range prog.Statements {
+		if f, ok := s.(*ast.FuncDef); ok && f.Name == "render" {
+			fn = f
+		}
+	}
+	require.NotNil(t, fn)
+	assert.Equal(t, 5, fn.StmtLine())
+	assert.Equal(t, 10, fn.StmtEndLine())
+	require.Len(t, fn.Body, 1)
+	ret := fn.Body[0].(*ast.ReturnStmt)
+	assert.Equal(t, 6, ret.StmtLine(), "return should point at the heredoc opener")
+
+	// Common indent is relative to the heredoc body, not the method.
+	interp, ok := ret.Value.(*ast.StringLiteral)
+	require.True(t, ok, "expected an interpolated string, got %T", ret.Value)
+	assert.Equal(t, "<h1>#{self.title}</h1>\n  <p>body</p>", interp.Value)
+}
+
+func TestParseErrorLineAfterHeredoc(t *testing.T) {
+	c := &Compiler{}
+	src := "struct Page\n  title\nend\n\ndef Page.render()\n  return <<~HTML\n    <h1>#{self.title}</h1>\n    HTML\nend\n\ny = (1 +\n"
+	_, err := c.ParseSource(src, "test.rugo")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "test.rugo:11:")
+}
+
 func TestInferExported(t *testing.T) {
 	c := &Compiler{}
 	src := "def add(a, b)\n  return a + b\nend\nx = add(1, 2)\n"
@@ -388,3 +455,38 @@ func TestStructInfoMultiple(t *testing.T) {
 	assert.Equal(t, "Color", prog.Structs[1].Name)
 	assert.Equal(t, []string{"r", "g", "b"}, prog.Structs[1].Fields)
 }
+
+// Both parse entry points share preprocess.Run, so preprocessor features
+// and line maps must come out identical.
+func TestParseSourceMatchesASTParser(t *testing.T) {
+	tests := []struct {
+		name  string
+		src   string
+		lines []int
+	}{
+		{"heredoc", "x = <<~TXT\n  a\n  b\nTXT\nputs(x)\n", []int{1, 5}},
+		{"struct", "struct Dog\n  name\nend\nd = Dog(\"rex\")\nputs(d.name)\n", []int{1, 1, 4, 5}},
+		{"do end", "def run(f)\n  f()\nend\nrun do\n  puts(1)\nend\nputs(2)\n", []int{1, 4, 7}},
+		{"pipe", "echo hi | tr a-z A-Z\nputs(1)\n", []int{1, 2}},
+		{"try sugar", "x = try conv.to_i(\"x\") or 0\nputs(x)\n", []int{1, 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			compProg, err := (&Compiler{}).ParseSource(tt.src, "test.rugo")
+			require.NoError(t, err)
+			astProg, err := (&ast.Compiler{}).ParseSource(tt.src, "test.rugo")
+			require.NoError(t, err)
+
+			var compLines, astLines []int
+			for _, s := range compProg.Statements {
+				compLines = append(compLines, s.StmtLine())
+			}
+			for _, s := range astProg.Statements {
+				astLines = append(astLines, s.StmtLine())
+			}
+			assert.Equal(t, tt.lines, compLines)
+			assert.Equal(t, compLines, astLines)
+			assert.Equal(t, compProg.Structs, astProg.Structs)
+		})
+	}
+}
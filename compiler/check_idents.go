@@ -11,17 +11,27 @@ import (
 
 // builtinFuncs are always-available function names.
 var builtinFuncs = map[string]bool{
-	"puts":           true,
-	"print":          true,
-	"len":            true,
-	"append":         true,
-	"raise":          true,
-	"exit":           true,
-	"type_of":        true,
-	"range":          true,
-	"__shell__":      true,
-	"__capture__":    true,
-	"__pipe_shell__": true,
+	"puts":             true,
+	"print":            true,
+	"len":              true,
+	"append":           true,
+	"raise":            true,
+	"exit":             true,
+	"die":              true,
+	"rethrow":          true,
+	"assert":           true,
+	"type_of":          true,
+	"range":            true,
+	"parallel_map":     true,
+	"__shell__":        true,
+	"__capture__":      true,
+	"__pipe_shell__":   true,
+	"__defined__":      true,
+	"__range__":        true,
+	"__array_concat__": true,
+	"__hash_merge__":   true,
+	"__with__":         true,
+	"__kw_default__":   true,
 }
 
 // identCheck implements ast.Check and reports undefined identifier references.
@@ -53,7 +63,7 @@ func (ic *identCheck) Check(prog *ast.Program) error {
 	// First pass: collect top-level names (functions, variables, modules, namespaces).
 	var funcs []*ast.FuncDef
 	namespaces := make(map[string]bool)
-	imports := make(map[string]bool)        // Rugo stdlib modules
+	imports := make(map[string]string)      // Rugo stdlib modules: namespace → module
 	goImports := make(map[string]string)    // Go bridge: path → alias
 	nsVarNames := make(map[string]bool)     // "ns.var" qualified names
 	funcDefs := make(map[string]bool)       // function names (including namespaced)
@@ -72,8 +82,8 @@ func (ic *identCheck) Check(prog *ast.Program) error {
 			}
 			funcs = append(funcs, st)
 		case *ast.UseStmt:
-			imports[st.Module] = true
-			global[st.Module] = true
+			imports[st.Namespace()] = st.Module
+			global[st.Namespace()] = true
 		case *ast.ImportStmt:
 			ns := goBridgeNamespace(st)
 			goImports[st.Package] = st.Alias
@@ -202,6 +212,15 @@ func (ic *identCheck) Check(prog *ast.Program) error {
 			if src := st.StmtSource(); src != "" {
 				w.sourceFile = src
 			}
+			// Options are evaluated once at the top level, before the runner.
+			for _, opt := range []ast.Expr{st.Iterations, st.MinTime} {
+				if opt == nil {
+					continue
+				}
+				if err := w.checkExpr(opt, st.StmtLine(), topScope); err != nil {
+					return err
+				}
+			}
 			for _, bs := range st.Body {
 				if err := w.checkStmt(bs, scope); err != nil {
 					return err
@@ -219,7 +238,7 @@ type identWalker struct {
 	sourceFile     string
 	global         map[string]bool
 	namespaces     map[string]bool
-	imports        map[string]bool
+	imports        map[string]string
 	goImports      map[string]string
 	nsVarNames     map[string]bool
 	funcDefs       map[string]bool
@@ -372,6 +391,12 @@ func (w *identWalker) checkStmt(s ast.Statement, localScope map[string]bool) err
 				return err
 			}
 		}
+		elseScope := childScope(localScope)
+		for _, bs := range st.ElseBody {
+			if err := w.checkStmt(bs, elseScope); err != nil {
+				return err
+			}
+		}
 	case *ast.ForStmt:
 		if err := w.checkExpr(st.Collection, st.StmtLine(), localScope); err != nil {
 			return err
@@ -508,6 +533,14 @@ func (w *identWalker) checkExpr(e ast.Expr, line int, localScope map[string]bool
 				return err
 			}
 		}
+		// The ensure body runs on both paths, so the error variable is
+		// not in scope there.
+		ensureScope := childScope(localScope)
+		for _, bs := range ex.Ensure {
+			if err := w.checkStmt(bs, ensureScope); err != nil {
+				return err
+			}
+		}
 	case *ast.LoweredSpawnExpr:
 		innerScope := childScope(localScope)
 		for _, bs := range ex.Body {
@@ -521,6 +554,11 @@ func (w *identWalker) checkExpr(e ast.Expr, line int, localScope map[string]bool
 			}
 		}
 	case *ast.LoweredParallelExpr:
+		if ex.Limit != nil {
+			if err := w.checkExpr(ex.Limit, line, localScope); err != nil {
+				return err
+			}
+		}
 		for _, br := range ex.Branches {
 			if br.Expr != nil {
 				if err := w.checkExpr(br.Expr, line, localScope); err != nil {
@@ -601,9 +639,9 @@ func (w *identWalker) checkNamespacedCall(nsName, field string, line int, localS
 	}
 
 	// Rugo stdlib module call
-	if w.imports[nsName] {
-		if _, ok := modules.LookupFunc(nsName, field); !ok {
-			return fmt.Errorf("%s:%d: unknown function %s.%s in module %q", w.sourceFile, line, nsName, field, nsName)
+	if mod, ok := w.imports[nsName]; ok {
+		if _, ok := modules.LookupFunc(mod, field); !ok {
+			return fmt.Errorf("%s:%d: unknown function %s.%s in module %q", w.sourceFile, line, nsName, field, mod)
 		}
 		return nil
 	}
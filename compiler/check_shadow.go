@@ -0,0 +1,150 @@
+package compiler
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/rubiojr/rugo/ast"
+)
+
+// namespaceShadowCheck implements ast.Check and warns when a variable,
+// parameter or loop variable takes the name of a module namespace
+// (use, import or require). Local variables shadow namespaces, so after
+// `http = 1` every http.get() call silently becomes a method call on 1.
+type namespaceShadowCheck struct {
+	sourceFile string
+	w          io.Writer
+	strict     bool
+}
+
+// NamespaceShadowCheck returns a Check that writes namespace-shadowing
+// warnings to w. In strict mode the first shadowing fails the build instead.
+func NamespaceShadowCheck(sourceFile string, w io.Writer, strict bool) ast.Check {
+	return &namespaceShadowCheck{sourceFile: sourceFile, w: w, strict: strict}
+}
+
+func (sc *namespaceShadowCheck) Name() string { return "namespace-shadow" }
+
+func (sc *namespaceShadowCheck) Check(prog *ast.Program) error {
+	// Namespace name → how it was brought in, for the message.
+	namespaces := make(map[string]string)
+	for _, s := range prog.Statements {
+		switch st := s.(type) {
+		case *ast.UseStmt:
+			origin := fmt.Sprintf("use %q", st.Module)
+			if st.Alias != "" {
+				origin += " as " + st.Alias
+			}
+			namespaces[st.Namespace()] = origin
+		case *ast.ImportStmt:
+			namespaces[goBridgeNamespace(st)] = fmt.Sprintf("import %q", st.Package)
+		case *ast.RequireStmt:
+			if len(st.With) > 0 {
+				for _, name := range st.With {
+					namespaces[name] = fmt.Sprintf("require %q", st.Path)
+				}
+			} else {
+				ns := st.Alias
+				if ns == "" {
+					ns = requireNamespace(st.Path)
+				}
+				if ns != "" {
+					namespaces[ns] = fmt.Sprintf("require %q", st.Path)
+				}
+			}
+		}
+	}
+	if len(namespaces) == 0 {
+		return nil
+	}
+
+	var firstErr error
+	warned := make(map[string]bool)
+	report := func(kind, name, sourceFile string, line int) {
+		origin, ok := namespaces[name]
+		if !ok || firstErr != nil {
+			return
+		}
+		key := fmt.Sprintf("%s:%d:%s", sourceFile, line, name)
+		if warned[key] {
+			return
+		}
+		warned[key] = true
+		msg := fmt.Sprintf("%s:%d: %s `%s` shadows the namespace from %s — %s.func() calls in its scope go to the %s", sourceFile, line, kind, name, origin, name, kind)
+		if sc.strict {
+			firstErr = fmt.Errorf("%s", msg)
+			return
+		}
+		fmt.Fprintf(sc.w, "warning: %s\n", msg)
+	}
+
+	seenFns := make(map[*ast.FnExpr]bool)
+	var visit func(s ast.Statement, sourceFile string)
+	checkFns := func(s ast.Statement, sourceFile string) func(ast.Expr) bool {
+		return func(e ast.Expr) bool {
+			fn, ok := e.(*ast.FnExpr)
+			if !ok || seenFns[fn] {
+				return false
+			}
+			seenFns[fn] = true
+			for _, p := range fn.Params {
+				report("parameter", p.Name, sourceFile, s.StmtLine())
+			}
+			for _, child := range fn.Body {
+				visit(child, sourceFile)
+			}
+			return false
+		}
+	}
+	visit = func(s ast.Statement, sourceFile string) {
+		walkStmtRecursive(s, func(s ast.Statement) bool {
+			switch st := s.(type) {
+			case *ast.AssignStmt:
+				if st.Namespace == "" {
+					report("variable", st.Target, sourceFile, st.StmtLine())
+				}
+			case *ast.ForStmt:
+				report("loop variable", st.Var, sourceFile, st.StmtLine())
+				if st.IndexVar != "" {
+					report("loop variable", st.IndexVar, sourceFile, st.StmtLine())
+				}
+			case *ast.FuncDef:
+				for _, p := range st.Params {
+					report("parameter", p.Name, sourceFile, st.StmtLine())
+				}
+			}
+			if exprs, ok := headerExprs(s); ok {
+				for _, e := range exprs {
+					walkExpr(e, checkFns(s, sourceFile))
+				}
+			} else {
+				walkStmtExprs(s, checkFns(s, sourceFile))
+			}
+			return true
+		})
+	}
+
+	for _, s := range prog.Statements {
+		// Required files have their own namespaces; only the main file's
+		// names can shadow the namespaces it brought in.
+		switch st := s.(type) {
+		case *ast.FuncDef:
+			if st.Namespace != "" {
+				continue
+			}
+		case *ast.AssignStmt:
+			if st.Namespace != "" {
+				continue
+			}
+		}
+		sourceFile := sc.sourceFile
+		if src := s.StmtSource(); src != "" {
+			sourceFile = src
+		}
+		visit(s, sourceFile)
+		if firstErr != nil {
+			return firstErr
+		}
+	}
+	return nil
+}
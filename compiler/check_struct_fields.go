@@ -0,0 +1,139 @@
+package compiler
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/rubiojr/rugo/ast"
+)
+
+// structFieldCheck implements ast.Check and warns when a struct method
+// reads self.<name> where <name> is neither a declared field, a method of
+// the struct, nor a field some method of the struct assigns. Such reads
+// return nil at runtime, which usually means a typo in the field name.
+type structFieldCheck struct {
+	sourceFile string
+	w          io.Writer
+}
+
+// StructFieldCheck returns a Check that writes unknown-field warnings to w.
+// It never fails the build.
+func StructFieldCheck(sourceFile string, w io.Writer) ast.Check {
+	return &structFieldCheck{sourceFile: sourceFile, w: w}
+}
+
+func (sc *structFieldCheck) Name() string { return "struct-field" }
+
+func (sc *structFieldCheck) Check(prog *ast.Program) error {
+	// Map each method's function name to its struct and known members.
+	owner := make(map[string]map[string]bool)
+	structName := make(map[string]string)
+	for _, si := range prog.Structs {
+		known := map[string]bool{"__type__": true}
+		for _, f := range si.Fields {
+			known[f] = true
+		}
+		for _, m := range si.Methods {
+			known[m] = true
+			owner[m] = known
+			structName[m] = si.Name
+		}
+	}
+	if len(owner) == 0 {
+		return nil
+	}
+
+	var methods []*ast.FuncDef
+	for _, s := range prog.Statements {
+		fd, ok := s.(*ast.FuncDef)
+		if !ok || fd.Namespace != "" || owner[fd.Name] == nil {
+			continue
+		}
+		if len(fd.Params) == 0 || fd.Params[0].Name != "self" {
+			continue
+		}
+		methods = append(methods, fd)
+		// Fields assigned in any method (self.x = ...) count as declared.
+		known := owner[fd.Name]
+		for _, child := range fd.Body {
+			walkStmtRecursive(child, func(s ast.Statement) bool {
+				if da, ok := s.(*ast.DotAssignStmt); ok && isSelf(da.Object) {
+					known[da.Field] = true
+				}
+				return true
+			})
+		}
+	}
+
+	for _, fd := range methods {
+		known := owner[fd.Name]
+		warned := make(map[string]bool)
+		for _, child := range fd.Body {
+			walkStmtRecursive(child, func(s ast.Statement) bool {
+				calls := make(map[*ast.DotExpr]bool)
+				check := func(e ast.Expr) bool {
+					switch ex := e.(type) {
+					case *ast.CallExpr:
+						// self.helper() may call a function, not read a field.
+						if dot, ok := ex.Func.(*ast.DotExpr); ok {
+							calls[dot] = true
+						}
+					case *ast.DotExpr:
+						if isSelf(ex.Object) && !calls[ex] && !known[ex.Field] && !warned[ex.Field] {
+							warned[ex.Field] = true
+							fmt.Fprintf(sc.w, "warning: %s:%d: `self.%s` is not a field of struct %s\n", sc.sourceFile, s.StmtLine(), ex.Field, structName[fd.Name])
+						}
+					}
+					return false
+				}
+				if exprs, ok := headerExprs(s); ok {
+					for _, e := range exprs {
+						walkExpr(e, check)
+					}
+				} else {
+					walkStmtExprs(s, check)
+				}
+				return true
+			})
+		}
+	}
+	return nil
+}
+
+// headerExprs returns the expressions in the header of a block statement
+// (conditions, loop collections, case values), leaving out the nested
+// bodies that walkStmtRecursive visits on their own.
+func headerExprs(s ast.Statement) ([]ast.Expr, bool) {
+	switch st := s.(type) {
+	case *ast.FuncDef, *ast.TestDef, *ast.BenchDef:
+		return nil, true
+	case *ast.IfStmt:
+		exprs := []ast.Expr{st.Condition}
+		for _, ec := range st.ElsifClauses {
+			exprs = append(exprs, ec.Condition)
+		}
+		return exprs, true
+	case *ast.WhileStmt:
+		return []ast.Expr{st.Condition}, true
+	case *ast.ForStmt:
+		return []ast.Expr{st.Collection}, true
+	case *ast.CaseStmt:
+		exprs := []ast.Expr{st.Subject}
+		for _, oc := range st.OfClauses {
+			exprs = append(exprs, oc.Values...)
+			if oc.ArrowExpr != nil {
+				exprs = append(exprs, oc.ArrowExpr)
+			}
+		}
+		for _, ec := range st.ElsifClauses {
+			exprs = append(exprs, ec.Condition)
+		}
+		return exprs, true
+	}
+	return nil, false
+}
+
+func isSelf(e ast.Expr) bool {
+	id, ok := e.(*ast.IdentExpr)
+	return ok && id.Name == "self"
+}
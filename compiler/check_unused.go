@@ -0,0 +1,143 @@
+package compiler
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/rubiojr/rugo/ast"
+)
+
+// unusedVarCheck implements ast.Check and warns about local variables that
+// are assigned but never read. Codegen silences Go's own unused-variable
+// error with `_ = x`, so without this check such dead assignments go unseen.
+type unusedVarCheck struct {
+	sourceFile string
+	w          io.Writer
+}
+
+// UnusedVarCheck returns a Check that writes unused-variable warnings to w.
+// It never fails the build.
+func UnusedVarCheck(sourceFile string, w io.Writer) ast.Check {
+	return &unusedVarCheck{sourceFile: sourceFile, w: w}
+}
+
+func (uc *unusedVarCheck) Name() string { return "unused-var" }
+
+func (uc *unusedVarCheck) Check(prog *ast.Program) error {
+	// Top-level variables read by a function are promoted to package level
+	// (handlerVars), so those reads count as uses. Bench options are
+	// evaluated at the top level, so reads there count too.
+	funcRefs := make(map[string]bool)
+	var top []ast.Statement
+	for _, s := range prog.Statements {
+		switch st := s.(type) {
+		case *ast.FuncDef:
+			if st.Namespace == "" {
+				for _, child := range st.Body {
+					walkStmtExprs(child, readIdents(funcRefs))
+				}
+			}
+		case *ast.BenchDef:
+			for _, opt := range []ast.Expr{st.Iterations, st.MinTime} {
+				if opt != nil {
+					walkExpr(opt, readIdents(funcRefs))
+				}
+			}
+		case *ast.TestDef:
+		default:
+			top = append(top, s)
+		}
+	}
+
+	uc.checkBody(top, nil, funcRefs, uc.sourceFile)
+	for _, s := range prog.Statements {
+		switch st := s.(type) {
+		case *ast.FuncDef:
+			params := make(map[string]bool, len(st.Params))
+			for _, p := range st.Params {
+				params[p.Name] = true
+			}
+			uc.checkBody(st.Body, params, nil, uc.sourceOf(st))
+		case *ast.TestDef:
+			uc.checkBody(st.Body, nil, nil, uc.sourceOf(st))
+		case *ast.BenchDef:
+			uc.checkBody(st.Body, nil, nil, uc.sourceOf(st))
+		}
+	}
+	return nil
+}
+
+// checkBody reports variables assigned in body that are never read there.
+// Reads inside a variable's own assignment (x = x + 1) don't count.
+// skip holds names that are not locals of body (parameters); extra holds
+// names read from elsewhere.
+func (uc *unusedVarCheck) checkBody(body []ast.Statement, skip, extra map[string]bool, sourceFile string) {
+	type assignment struct {
+		name     string
+		line     int
+		constant bool
+	}
+	var assigned []assignment
+	seen := make(map[string]bool)
+	used := make(map[string]bool)
+	topLevel := skip == nil && extra != nil
+
+	for _, child := range body {
+		walkStmtRecursive(child, func(s ast.Statement) bool {
+			if a, ok := s.(*ast.AssignStmt); ok {
+				refs := make(map[string]bool)
+				walkExpr(a.Value, readIdents(refs))
+				delete(refs, a.Target)
+				for name := range refs {
+					used[name] = true
+				}
+				if a.Namespace == "" && !seen[a.Target] && !skip[a.Target] {
+					seen[a.Target] = true
+					assigned = append(assigned, assignment{a.Target, a.StmtLine(), isConstAssign(a)})
+				}
+				return true
+			}
+			if exprs, ok := headerExprs(s); ok {
+				for _, e := range exprs {
+					walkExpr(e, readIdents(used))
+				}
+			} else {
+				walkStmtExprs(s, readIdents(used))
+			}
+			return true
+		})
+	}
+
+	for _, a := range assigned {
+		if used[a.name] || extra[a.name] || strings.HasPrefix(a.name, "_") {
+			continue
+		}
+		// Top-level constants are meant to be read by requiring files.
+		if topLevel && a.constant {
+			continue
+		}
+		fmt.Fprintf(uc.w, "warning: %s:%d: variable `%s` is assigned but never used\n", sourceFile, a.line, a.name)
+	}
+}
+
+// readIdents returns a walkExpr callback that records every identifier
+// read, including those inside string interpolation.
+func readIdents(names map[string]bool) func(ast.Expr) bool {
+	return func(e ast.Expr) bool {
+		switch e.(type) {
+		case *ast.IdentExpr, *ast.StringLiteral:
+			collectIdentsFromExpr(e, names)
+		}
+		return false
+	}
+}
+
+// sourceOf returns the file a statement came from (required files keep
+// their own path).
+func (uc *unusedVarCheck) sourceOf(s ast.Statement) string {
+	if src := s.StmtSource(); src != "" {
+		return src
+	}
+	return uc.sourceFile
+}
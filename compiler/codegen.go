@@ -19,6 +19,12 @@ var runtimeCorePost string
 //go:embed templates/runtime_spawn.go.tmpl
 var runtimeSpawn string
 
+//go:embed templates/runtime_parallel.go.tmpl
+var runtimeParallelMap string
+
+//go:embed templates/runtime_memo.go.tmpl
+var runtimeMemo string
+
 // funcArity stores the arity range for a user-defined function.
 type funcArity struct {
 	Min         int  // number of required params (no default)
@@ -33,12 +39,15 @@ type codeGen struct {
 	constScopes     []map[string]int // track constant bindings: name → line of first assignment
 	inFunc          bool
 	imports         map[string]bool      // Rugo stdlib modules imported (via use)
+	useNS           map[string]string    // use namespaces: module name or alias → module
 	goImports       map[string]string    // Go bridge packages: path → alias
 	namespaces      map[string]bool      // known require namespaces
 	nsVarNames      map[string]bool      // namespaced var names: "ns.name" → true
 	sourceFile      string               // original source filename for //line directives
 	hasSpawn        bool                 // whether spawn is used
 	hasParallel     bool                 // whether parallel is used
+	hasParallelMap  bool                 // whether parallel_map is called
+	hasMemo         bool                 // whether a memo def is present
 	hasBench        bool                 // whether bench blocks are present
 	usesTaskMethods bool                 // whether .value/.done/.wait appear
 	funcDefs        map[string]funcArity // user function name → arity info
@@ -51,9 +60,15 @@ type codeGen struct {
 	lambdaScopeBase []int                // scope index at each lambda entry (stack)
 	lambdaOuterFunc []*ast.FuncDef       // enclosing function at each lambda entry (stack)
 	sandbox         *SandboxConfig       // Landlock sandbox config (nil = no sandbox)
+	profile         *ProfileConfig       // pprof profiling config (nil = no profiling)
+	trace           bool                 // emit a rugo_trace call before each statement
 	caseCounter     int                  // counter for unique case temp variable names
+	withCounter     int                  // counter for unique with cleanup variable names
+	whileCounter    int                  // counter for unique while-else guard variable names
+	withClosers     []withCloser         // open with blocks at current function scope (reset by def/fn)
 	loopCtlDepth    int                  // loop nesting depth at current function scope (reset by def/fn)
 	inTryHandler    bool                 // true when building try handler body
+	inRescue        bool                 // true in a try handler body, where rethrow is allowed
 	loopNeedsCtl    bool                 // set when next/break is emitted inside a try handler in a loop
 	embedFiles      map[string]string    // staged name → absolute source path (populated during codegen)
 	disableEmbed    bool                 // reject embed statements (set by eval.run)
@@ -66,9 +81,10 @@ type generateResult struct {
 }
 
 // generate produces Go source code from a ast.Program AST.
-func generate(prog *ast.Program, sourceFile string, testMode bool, sandbox *SandboxConfig, disableEmbed bool) (*generateResult, error) {
+func generate(prog *ast.Program, sourceFile string, testMode bool, sandbox *SandboxConfig, profile *ProfileConfig, disableEmbed, trace bool) (*generateResult, error) {
 	// Run AST transform chain before type inference and codegen.
 	prog = ast.Chain(
+		ast.ConstantFolding(),
 		ast.ConcurrencyLowering(),
 		ast.ImplicitReturnLowering(),
 	).Transform(prog)
@@ -77,19 +93,22 @@ func generate(prog *ast.Program, sourceFile string, testMode bool, sandbox *Sand
 	ti := Infer(prog)
 
 	g := &codeGen{
-		declared:    make(map[string]bool),
-		scopes:      []map[string]bool{make(map[string]bool)},
-		constScopes: []map[string]int{make(map[string]int)},
-		imports:     make(map[string]bool),
-		goImports:   make(map[string]string),
-		namespaces:  make(map[string]bool),
-		nsVarNames:  make(map[string]bool),
-		handlerVars: make(map[string]bool),
-		sourceFile:  sourceFile,
-		funcDefs:    make(map[string]funcArity),
-		testMode:    testMode,
-		typeInfo:    ti,
+		declared:     make(map[string]bool),
+		scopes:       []map[string]bool{make(map[string]bool)},
+		constScopes:  []map[string]int{make(map[string]int)},
+		imports:      make(map[string]bool),
+		useNS:        make(map[string]string),
+		goImports:    make(map[string]string),
+		namespaces:   make(map[string]bool),
+		nsVarNames:   make(map[string]bool),
+		handlerVars:  make(map[string]bool),
+		sourceFile:   sourceFile,
+		funcDefs:     make(map[string]funcArity),
+		testMode:     testMode,
+		typeInfo:     ti,
 		sandbox:      sandbox,
+		profile:      profile,
+		trace:        trace,
 		embedFiles:   make(map[string]string),
 		disableEmbed: disableEmbed,
 	}
@@ -150,6 +169,7 @@ func (g *codeGen) generate(prog *ast.Program) (string, error) {
 			continue
 		case *ast.UseStmt:
 			g.imports[st.Module] = true
+			g.useNS[st.Namespace()] = st.Module
 			continue
 		case *ast.ImportStmt:
 			g.goImports[st.Package] = st.Alias
@@ -255,10 +275,14 @@ func (g *codeGen) generate(prog *ast.Program) (string, error) {
 	// Detect spawn/parallel/bench usage to gate runtime emission and imports
 	g.hasSpawn = astUsesSpawn(prog)
 	g.hasParallel = astUsesParallel(prog)
+	g.hasParallelMap = astCallsBuiltin(prog, "parallel_map")
 	g.hasBench = len(benches) > 0
 	g.usesTaskMethods = astUsesTaskMethods(prog)
 	needsSpawnRuntime := g.hasSpawn || g.usesTaskMethods
-	needsSyncImport := needsSpawnRuntime || g.hasParallel
+	for _, f := range funcs {
+		g.hasMemo = g.hasMemo || f.Memo
+	}
+	needsSyncImport := needsSpawnRuntime || g.hasParallel || g.hasParallelMap || g.hasMemo
 	needsTimeImport := needsSpawnRuntime || g.hasBench
 
 	// --- Build GoFile ---
@@ -289,7 +313,7 @@ func (g *codeGen) generate(prog *ast.Program) (string, error) {
 		suppressors = append(suppressors, "var _ = time.Now")
 	}
 	if g.sandbox != nil {
-		suppressors = append(suppressors, "var _ = landlock.V5", "var _ = llsyscall.AccessFSExecute", "var _ = runtime.GOOS")
+		suppressors = append(suppressors, "var _ = landlock.V5", "var _ = llsyscall.AccessFSExecute", "var _ = runtime.GOOS", "var _ = filepath.Rel")
 	}
 	for _, pkg := range sortedGoBridgeImports(g.goImports) {
 		bp := gobridge.GetPackage(pkg)
@@ -377,24 +401,34 @@ func (g *codeGen) generate(prog *ast.Program) (string, error) {
 		file.Decls = append(file.Decls, GoBlankLine{})
 	}
 
-	// User-defined functions
+	// User-defined functions. An error in one function does not stop the
+	// rest from being generated, so independent mistakes surface together.
+	var errs []error
 	for _, f := range funcs {
+		depth := len(g.scopes)
 		decl, err := g.buildFunc(f)
 		if err != nil {
-			return "", err
+			errs = append(errs, err)
+			g.unwindScopes(depth)
+			continue
 		}
 		file.Decls = append(file.Decls, decl)
 	}
 
 	// Dispatch maps
-	dispatchHandlers := collectDispatchHandlers(prog.Statements, g.imports)
+	dispatchHandlers := collectDispatchHandlers(prog.Statements, g.useNS)
 	file.Decls = append(file.Decls, g.buildDispatchMaps(funcs, dispatchHandlers)...)
+	file.Decls = append(file.Decls, buildComparators(funcs)...)
+	file.Decls = append(file.Decls, buildExitHooks(funcs)...)
 
 	// Test harness
 	if len(tests) > 0 {
 		harnessDecls, herr := g.buildTestHarness(tests, topStmts, setupFunc, teardownFunc, setupFileFunc, teardownFileFunc)
 		if herr != nil {
-			return "", herr
+			errs = append(errs, herr)
+		}
+		if len(errs) > 0 {
+			return "", joinCompileErrors(errs)
 		}
 		file.Decls = append(file.Decls, harnessDecls...)
 		return PrintGoFile(file), nil
@@ -404,7 +438,10 @@ func (g *codeGen) generate(prog *ast.Program) (string, error) {
 	if len(benches) > 0 {
 		harnessDecls, herr := g.buildBenchHarness(benches, topStmts)
 		if herr != nil {
-			return "", herr
+			errs = append(errs, herr)
+		}
+		if len(errs) > 0 {
+			return "", joinCompileErrors(errs)
 		}
 		file.Decls = append(file.Decls, harnessDecls...)
 		return PrintGoFile(file), nil
@@ -416,6 +453,9 @@ func (g *codeGen) generate(prog *ast.Program) (string, error) {
 	if g.sandbox != nil {
 		mainBody = append(mainBody, g.buildSandboxApply()...)
 	}
+	if g.profile != nil {
+		mainBody = append(mainBody, g.buildProfileStart()...)
+	}
 	mainBody = append(mainBody, embedInitStmts(embeds)...)
 	g.pushScope()
 	for _, e := range embeds {
@@ -423,7 +463,10 @@ func (g *codeGen) generate(prog *ast.Program) (string, error) {
 	}
 	mainStmts, merr := g.buildStmts(topStmts)
 	if merr != nil {
-		return "", merr
+		errs = append(errs, merr)
+	}
+	if len(errs) > 0 {
+		return "", joinCompileErrors(errs)
 	}
 	mainBody = append(mainBody, mainStmts...)
 	g.popScope()
@@ -568,9 +611,11 @@ func (g *codeGen) goTyped(e ast.Expr) bool {
 		switch ex.Op {
 		case "+":
 			return bothInts || bothStrings || bothNumeric
-		case "-", "*", "/":
+		case "*":
+			return bothInts || bothNumeric || (leftType == TypeString && rightType == TypeInt && bothGoTyped)
+		case "-", "/":
 			return bothInts || bothNumeric
-		case "%":
+		case "%", "&", "|", "^":
 			return bothInts
 		case "==", "!=":
 			return sameTyped && bothGoTyped
@@ -3,10 +3,12 @@ package compiler
 import (
 	"fmt"
 	"github.com/rubiojr/rugo/ast"
+	"strconv"
 	"strings"
 
 	"github.com/rubiojr/rugo/gobridge"
 	"github.com/rubiojr/rugo/modules"
+	"github.com/rubiojr/rugo/preprocess"
 )
 
 // buildStmt converts an AST statement into Go output AST nodes.
@@ -24,6 +26,9 @@ func (g *codeGen) buildStmt(s ast.Statement) ([]GoStmt, error) {
 	var stmts []GoStmt
 	if line := s.StmtLine(); line > 0 && g.sourceFile != "" {
 		stmts = append(stmts, GoLineDirective{File: g.sourceFile, Line: line})
+		if g.trace {
+			stmts = append(stmts, GoExprStmt{Expr: GoRawExpr{Code: fmt.Sprintf("rugo_trace(%q, %d)", g.sourceFile, line)}})
+		}
 	}
 
 	node, err := g.buildStmtInner(s)
@@ -68,23 +73,25 @@ func (g *codeGen) buildStmtInner(s ast.Statement) ([]GoStmt, error) {
 	case *ast.ForStmt:
 		return g.buildFor(st)
 	case *ast.BreakStmt:
+		closers := g.closeLoopWiths()
 		if g.inTryHandler && g.loopCtlDepth > 0 {
 			g.loopNeedsCtl = true
-			return []GoStmt{
+			return append(closers,
 				GoAssignStmt{Target: "__rugo_loop_ctl", Op: "=", Value: GoRawExpr{Code: "2"}},
 				GoReturnStmt{},
-			}, nil
+			), nil
 		}
-		return []GoStmt{GoBreakStmt{}}, nil
+		return append(closers, GoBreakStmt{}), nil
 	case *ast.NextStmt:
+		closers := g.closeLoopWiths()
 		if g.inTryHandler && g.loopCtlDepth > 0 {
 			g.loopNeedsCtl = true
-			return []GoStmt{
+			return append(closers,
 				GoAssignStmt{Target: "__rugo_loop_ctl", Op: "=", Value: GoRawExpr{Code: "1"}},
 				GoReturnStmt{},
-			}, nil
+			), nil
 		}
-		return []GoStmt{GoContinueStmt{}}, nil
+		return append(closers, GoContinueStmt{}), nil
 	case *ast.ReturnStmt:
 		return g.buildReturn(st)
 	case *ast.ImplicitReturnStmt:
@@ -111,7 +118,7 @@ func (g *codeGen) buildStmtInner(s ast.Statement) ([]GoStmt, error) {
 // --- Leaf statement builders ---
 
 func (g *codeGen) buildAssign(a *ast.AssignStmt) ([]GoStmt, error) {
-	// Uppercase names are constants — reject reassignment
+	// Uppercase names and const declarations are constants — reject reassignment
 	if origLine, ok := g.constantLine(a.Target); ok {
 		return nil, fmt.Errorf("cannot reassign constant %s (first assigned at line %d)", a.Target, origLine)
 	}
@@ -152,13 +159,13 @@ func (g *codeGen) buildAssign(a *ast.AssignStmt) ([]GoStmt, error) {
 		op = "="
 		if g.handlerVars[a.Target] && !g.isDeclared(a.Target) {
 			g.declareVar(a.Target)
-			if len(a.Target) > 0 && a.Target[0] >= 'A' && a.Target[0] <= 'Z' {
+			if isConstAssign(a) {
 				g.declareConst(a.Target, a.SourceLine)
 			}
 		}
 	} else {
 		g.declareVar(a.Target)
-		if len(a.Target) > 0 && a.Target[0] >= 'A' && a.Target[0] <= 'Z' {
+		if isConstAssign(a) {
 			g.declareConst(a.Target, a.SourceLine)
 		}
 	}
@@ -167,6 +174,12 @@ func (g *codeGen) buildAssign(a *ast.AssignStmt) ([]GoStmt, error) {
 	return stmts, nil
 }
 
+// isConstAssign reports whether a declares a constant: an uppercase name
+// (Ruby convention) or an explicit const.
+func isConstAssign(a *ast.AssignStmt) bool {
+	return a.Const || (len(a.Target) > 0 && a.Target[0] >= 'A' && a.Target[0] <= 'Z')
+}
+
 func (g *codeGen) buildIndexAssign(ia *ast.IndexAssignStmt) ([]GoStmt, error) {
 	obj, err := g.buildExpr(ia.Object)
 	if err != nil {
@@ -394,6 +407,10 @@ func (g *codeGen) buildCase(cs *ast.CaseStmt) ([]GoStmt, error) {
 }
 
 func (g *codeGen) buildIf(i *ast.IfStmt) ([]GoStmt, error) {
+	if call, ok := withCall(i.Condition); ok {
+		return g.buildWith(i, call)
+	}
+
 	// Pre-declare variables (Ruby-like scoping)
 	var preDecls []GoStmt
 	var allBranches []ast.Statement
@@ -467,6 +484,84 @@ func (g *codeGen) buildIf(i *ast.IfStmt) ([]GoStmt, error) {
 	return result, nil
 }
 
+// withCloser is an open with block: the Go variable holding its cleanup
+// function and the loop depth it was opened at.
+type withCloser struct {
+	name      string
+	loopDepth int
+}
+
+// withCall reports whether cond is the __with__ call the preprocessor
+// emits for a `with` block.
+func withCall(cond ast.Expr) (*ast.CallExpr, bool) {
+	call, ok := cond.(*ast.CallExpr)
+	if !ok {
+		return nil, false
+	}
+	ident, ok := call.Func.(*ast.IdentExpr)
+	return call, ok && ident.Name == preprocess.WithBuiltin
+}
+
+// buildWith lowers a `with` block. The resource is already bound by the
+// preceding assignment; the block validates it, defers its cleanup so an
+// error or return still releases it, and releases it again on the normal
+// path so it is closed as soon as the block ends. The cleanup function is
+// idempotent, so only the first call runs.
+func (g *codeGen) buildWith(i *ast.IfStmt, call *ast.CallExpr) ([]GoStmt, error) {
+	var preDecls []GoStmt
+	for _, name := range collectAssignTargets(i.Body) {
+		if !g.isDeclared(name) {
+			varType := g.varType(name)
+			if varType.IsTyped() {
+				preDecls = append(preDecls, GoVarStmt{Name: name, Type: varType.GoType()})
+			} else {
+				preDecls = append(preDecls, GoVarStmt{Name: name, Type: "interface{}"})
+			}
+			g.declareVar(name)
+		}
+	}
+
+	guard, err := g.buildExpr(call)
+	if err != nil {
+		return nil, err
+	}
+	g.withCounter++
+	closer := fmt.Sprintf("__with_%d", g.withCounter)
+
+	g.withClosers = append(g.withClosers, withCloser{name: closer, loopDepth: g.loopCtlDepth})
+	body, err := g.buildStmts(i.Body)
+	g.withClosers = g.withClosers[:len(g.withClosers)-1]
+	if err != nil {
+		return nil, err
+	}
+
+	var block []GoStmt
+	if line := i.StmtLine(); line > 0 && g.sourceFile != "" {
+		block = append(block, GoLineDirective{File: g.sourceFile, Line: line})
+	}
+	block = append(block,
+		GoAssignStmt{Target: closer, Op: ":=", Value: guard},
+		GoRawStmt{Code: "defer " + closer + "()"},
+	)
+	block = append(block, body...)
+	block = append(block, GoExprStmt{Expr: GoCallExpr{Func: closer}})
+	return append(preDecls, GoBlockStmt{Body: block}), nil
+}
+
+// closeLoopWiths returns cleanup calls for the with blocks opened inside
+// the innermost loop, which a break or next is about to leave.
+func (g *codeGen) closeLoopWiths() []GoStmt {
+	var stmts []GoStmt
+	for j := len(g.withClosers) - 1; j >= 0; j-- {
+		w := g.withClosers[j]
+		if g.loopCtlDepth == 0 || w.loopDepth != g.loopCtlDepth {
+			break
+		}
+		stmts = append(stmts, GoExprStmt{Expr: GoCallExpr{Func: w.name}})
+	}
+	return stmts
+}
+
 // buildLoopBody builds loop body statements with loop-control tracking.
 // When next/break appears inside a try handler, it emits a __rugo_loop_ctl
 // closure variable and per-statement checks so that the loop control
@@ -523,17 +618,39 @@ func (g *codeGen) buildWhile(w *ast.WhileStmt) ([]GoStmt, error) {
 	g.popScope()
 
 	p := &goPrinter{}
-	return []GoStmt{GoForStmt{
-		Cond: p.exprStr(cond),
-		Body: body,
-	}}, nil
+	if len(w.ElseBody) == 0 {
+		return []GoStmt{GoForStmt{
+			Cond: p.exprStr(cond),
+			Body: body,
+		}}, nil
+	}
+
+	// while/else: the else body runs only when the loop body never ran.
+	g.whileCounter++
+	entered := fmt.Sprintf("__while_entered_%d", g.whileCounter)
+	g.pushScope()
+	elseBody, err := g.buildStmts(w.ElseBody)
+	if err != nil {
+		return nil, err
+	}
+	g.popScope()
+
+	body = append([]GoStmt{GoAssignStmt{Target: entered, Op: "=", Value: GoRawExpr{Code: "true"}}}, body...)
+	return []GoStmt{
+		GoAssignStmt{Target: entered, Op: ":=", Value: GoRawExpr{Code: "false"}},
+		GoForStmt{Cond: p.exprStr(cond), Body: body},
+		GoIfStmt{Cond: GoRawExpr{Code: "!" + entered}, Body: elseBody},
+	}, nil
 }
 
 func (g *codeGen) buildFor(f *ast.ForStmt) ([]GoStmt, error) {
 	// Try optimized integer range loop
 	startExpr, endExpr := g.rangeExprs(f.Collection)
 	if startExpr != "" {
-		return g.buildForRange(f, startExpr, endExpr)
+		return g.buildForRange(f, startExpr, "rugo_range_i < "+endExpr, "1")
+	}
+	if from, to, inclusive, ok := rangeLiteral(f.Collection); ok {
+		return g.buildForRangeLiteral(f, from, to, inclusive)
 	}
 
 	coll, err := g.buildExpr(f.Collection)
@@ -600,7 +717,80 @@ func (g *codeGen) buildFor(f *ast.ForStmt) ([]GoStmt, error) {
 	}}, nil
 }
 
-func (g *codeGen) buildForRange(f *ast.ForStmt, startExpr, endExpr string) ([]GoStmt, error) {
+// rangeLiteral reports whether coll is a range literal (a..b or a...b),
+// which the preprocessor rewrites to a __range__ call.
+func rangeLiteral(coll ast.Expr) (from, to ast.Expr, inclusive, ok bool) {
+	call, isCall := coll.(*ast.CallExpr)
+	if !isCall || len(call.Args) != 3 {
+		return nil, nil, false, false
+	}
+	ident, isIdent := call.Func.(*ast.IdentExpr)
+	incl, isBool := call.Args[2].(*ast.BoolLiteral)
+	if !isIdent || ident.Name != preprocess.RangeBuiltin || !isBool {
+		return nil, nil, false, false
+	}
+	return call.Args[0], call.Args[1], incl.Value, true
+}
+
+// buildForRangeLiteral lowers `for i in a..b` to a counting loop. Literal
+// bounds produce a plain ascending or descending loop; otherwise the
+// direction is picked at runtime by rugo_range_bounds.
+func (g *codeGen) buildForRangeLiteral(f *ast.ForStmt, from, to ast.Expr, inclusive bool) ([]GoStmt, error) {
+	lo, loOK := intLiteralValue(from)
+	hi, hiOK := intLiteralValue(to)
+	if loOK && hiOK {
+		step, cmp := 1, "<"
+		if lo > hi {
+			step, cmp = -1, ">"
+		}
+		if inclusive {
+			hi += step
+		}
+		return g.buildForRange(f, strconv.Itoa(lo), fmt.Sprintf("rugo_range_i %s %d", cmp, hi), strconv.Itoa(step))
+	}
+
+	fromExpr, err := g.buildExpr(from)
+	if err != nil {
+		return nil, err
+	}
+	toExpr, err := g.buildExpr(to)
+	if err != nil {
+		return nil, err
+	}
+	p := &goPrinter{}
+	bounds := fmt.Sprintf("rugo_range_start, rugo_range_end, rugo_range_step := rugo_range_bounds(%s, %s, %t)",
+		g.boxed(p.exprStr(fromExpr), g.exprType(from)), g.boxed(p.exprStr(toExpr), g.exprType(to)), inclusive)
+	loop, err := g.buildForRange(f, "rugo_range_start", "rugo_range_i != rugo_range_end", "rugo_range_step")
+	if err != nil {
+		return nil, err
+	}
+	return []GoStmt{GoBlockStmt{Body: append([]GoStmt{GoRawStmt{Code: bounds}}, loop...)}}, nil
+}
+
+// intLiteralValue returns the value of an integer literal, including a
+// negated one.
+func intLiteralValue(e ast.Expr) (int, bool) {
+	neg := false
+	if u, ok := e.(*ast.UnaryExpr); ok && u.Op == "-" {
+		neg, e = true, u.Operand
+	}
+	lit, ok := e.(*ast.IntLiteral)
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.Atoi(lit.Value)
+	if err != nil {
+		return 0, false
+	}
+	if neg {
+		n = -n
+	}
+	return n, true
+}
+
+// buildForRange emits a counting loop over rugo_range_i, starting at
+// startExpr and advancing by step while cond holds.
+func (g *codeGen) buildForRange(f *ast.ForStmt, startExpr, cond, step string) ([]GoStmt, error) {
 	iterVar := f.Var
 	idxVar := f.IndexVar
 
@@ -633,8 +823,8 @@ func (g *codeGen) buildForRange(f *ast.ForStmt, startExpr, endExpr string) ([]Go
 
 		forStmt = GoForStmt{
 			Init: fmt.Sprintf("rugo_range_i, rugo_range_idx := %s, 0", startExpr),
-			Cond: fmt.Sprintf("rugo_range_i < %s", endExpr),
-			Post: "rugo_range_i, rugo_range_idx = rugo_range_i+1, rugo_range_idx+1",
+			Cond: cond,
+			Post: fmt.Sprintf("rugo_range_i, rugo_range_idx = rugo_range_i%s, rugo_range_idx+1", signed(step)),
 			Body: append(preamble, body...),
 		}
 	} else {
@@ -651,8 +841,8 @@ func (g *codeGen) buildForRange(f *ast.ForStmt, startExpr, endExpr string) ([]Go
 
 		forStmt = GoForStmt{
 			Init: fmt.Sprintf("rugo_range_i := %s", startExpr),
-			Cond: fmt.Sprintf("rugo_range_i < %s", endExpr),
-			Post: "rugo_range_i++",
+			Cond: cond,
+			Post: rangePost(step),
 			Body: append(preamble, body...),
 		}
 	}
@@ -660,6 +850,25 @@ func (g *codeGen) buildForRange(f *ast.ForStmt, startExpr, endExpr string) ([]Go
 	return []GoStmt{forStmt}, nil
 }
 
+// signed prefixes a non-negative step literal or name with "+".
+func signed(step string) string {
+	if strings.HasPrefix(step, "-") {
+		return step
+	}
+	return "+" + step
+}
+
+// rangePost returns the post statement advancing rugo_range_i by step.
+func rangePost(step string) string {
+	switch step {
+	case "1":
+		return "rugo_range_i++"
+	case "-1":
+		return "rugo_range_i--"
+	}
+	return "rugo_range_i += " + step
+}
+
 // --- Function builder ---
 
 // buildFunc converts a Rugo FuncDef into a GoFuncDecl.
@@ -738,7 +947,7 @@ func (g *codeGen) buildFunc(f *ast.FuncDef) (GoFuncDecl, error) {
 				}
 				pr := &goPrinter{}
 				body = append(body, GoVarStmt{Name: p.Name, Type: "interface{}"})
-				body = append(body, GoRawStmt{Code: fmt.Sprintf("if len(_args) > %d { %s = _args[%d] } else { %s = %s }", i, p.Name, i, p.Name, pr.exprStr(defaultExpr))})
+				body = append(body, GoRawStmt{Code: fmt.Sprintf("if rugo_arg_given(_args, %d) { %s = _args[%d] } else { %s = %s }", i, p.Name, i, p.Name, pr.exprStr(defaultExpr))})
 			}
 			body = append(body, GoExprStmt{Expr: GoRawExpr{Code: fmt.Sprintf("_ = %s", p.Name)}})
 		}
@@ -751,8 +960,11 @@ func (g *codeGen) buildFunc(f *ast.FuncDef) (GoFuncDecl, error) {
 	g.currentFunc = f
 	g.inFunc = true
 	savedLoopCtl := g.loopCtlDepth
+	savedWiths := g.withClosers
 	g.loopCtlDepth = 0
+	g.withClosers = nil
 	bodyStmts, err := g.buildStmts(f.Body)
+	g.withClosers = savedWiths
 	if err != nil {
 		g.loopCtlDepth = savedLoopCtl
 		g.inFunc = false
@@ -761,13 +973,17 @@ func (g *codeGen) buildFunc(f *ast.FuncDef) (GoFuncDecl, error) {
 		return GoFuncDecl{}, err
 	}
 	g.loopCtlDepth = savedLoopCtl
-	body = append(body, bodyStmts...)
 
 	if !bodyAlwaysReturns(f.Body) {
 		// Not all code paths produce a value — the function may fall through
 		// without returning. The return type was kept as interface{} above,
 		// so the fallback is nil.
-		body = append(body, GoReturnStmt{Value: GoRawExpr{Code: "nil"}})
+		bodyStmts = append(bodyStmts, GoReturnStmt{Value: GoRawExpr{Code: "nil"}})
+	}
+	if f.Memo {
+		body = append(body, memoBody(goName, f.Params, retType, bodyStmts)...)
+	} else {
+		body = append(body, bodyStmts...)
 	}
 	g.inFunc = false
 	g.currentFunc = nil
@@ -781,6 +997,30 @@ func (g *codeGen) buildFunc(f *ast.FuncDef) (GoFuncDecl, error) {
 	}, nil
 }
 
+// memoBody wraps the statements of a memo function so results are looked
+// up in, and stored to, the runtime cache keyed by the argument values. The
+// original body runs in a closure so its returns still produce the value.
+func memoBody(goName string, params []ast.Param, retType string, stmts []GoStmt) []GoStmt {
+	keyArgs := []string{fmt.Sprintf("%q", goName)}
+	for _, p := range params {
+		keyArgs = append(keyArgs, p.Name)
+	}
+	hit := "rugo_memo_v"
+	if retType != "interface{}" {
+		hit += ".(" + retType + ")"
+	}
+	return []GoStmt{
+		GoRawStmt{Code: fmt.Sprintf("rugo_memo_k := rugo_memo_key(%s)", strings.Join(keyArgs, ", "))},
+		GoIfStmt{
+			Cond: GoRawExpr{Code: "rugo_memo_v, ok := rugo_memo_get(rugo_memo_k); ok"},
+			Body: []GoStmt{GoReturnStmt{Value: GoRawExpr{Code: hit}}},
+		},
+		GoAssignStmt{Target: "rugo_memo_v", Op: ":=", Value: GoIIFEExpr{ReturnType: retType, Body: stmts}},
+		GoExprStmt{Expr: GoRawExpr{Code: "rugo_memo_put(rugo_memo_k, rugo_memo_v)"}},
+		GoReturnStmt{Value: GoRawExpr{Code: "rugo_memo_v"}},
+	}
+}
+
 // buildPanicHandler returns the defer/recover block used in main functions.
 func (g *codeGen) buildPanicHandler() GoDeferStmt {
 	return GoDeferStmt{Body: []GoStmt{
@@ -789,7 +1029,7 @@ func (g *codeGen) buildPanicHandler() GoDeferStmt {
 			Body: []GoStmt{
 				GoIfStmt{
 					Cond: GoRawExpr{Code: "shellErr, ok := e.(rugoShellError); ok"},
-					Body: []GoStmt{GoRawStmt{Code: "os.Exit(shellErr.code)"}},
+					Body: []GoStmt{GoRawStmt{Code: "rugo_run_at_exit()"}, GoRawStmt{Code: "os.Exit(shellErr.code)"}},
 				},
 				GoRawStmt{Code: "rugo_panic_handler(e)"},
 			},
@@ -800,7 +1040,7 @@ func (g *codeGen) buildPanicHandler() GoDeferStmt {
 // buildImports constructs the GoImport list for a Rugo program.
 func (g *codeGen) buildImports(needsSync, needsTime bool) []GoImport {
 	var imports []GoImport
-	base := []string{"fmt", "math", "os", "os/exec", "reflect", "runtime/debug", "sort", "strconv", "strings", "unicode/utf8"}
+	base := []string{"encoding/json", "fmt", "math", "os", "os/exec", "reflect", "runtime/debug", "sort", "strconv", "strings", "unicode/utf8"}
 	for _, p := range base {
 		imports = append(imports, GoImport{Path: p})
 	}
@@ -890,9 +1130,24 @@ func (g *codeGen) buildImports(needsSync, needsTime bool) []GoImport {
 			imports = append(imports, GoImport{Path: "runtime"})
 			emitted["runtime"] = true
 		}
+		if !emitted["path/filepath"] {
+			imports = append(imports, GoImport{Path: "path/filepath"})
+			emitted["path/filepath"] = true
+		}
 		imports = append(imports, GoImport{Path: "github.com/landlock-lsm/go-landlock/landlock"})
 		imports = append(imports, GoImport{Path: "github.com/landlock-lsm/go-landlock/landlock/syscall", Alias: "llsyscall"})
 	}
 
+	// Profiling imports
+	if g.profile != nil {
+		if !emitted["runtime"] {
+			imports = append(imports, GoImport{Path: "runtime"})
+			emitted["runtime"] = true
+		}
+		if !emitted["runtime/pprof"] {
+			imports = append(imports, GoImport{Path: "runtime/pprof"})
+		}
+	}
+
 	return imports
 }
@@ -169,6 +169,9 @@ func (g *codeGen) buildBinaryExpr(e *ast.BinaryExpr) (GoExpr, error) {
 		if bothNumeric {
 			return typedFloatBinOp("*"), nil
 		}
+		if leftType == TypeString && rightType == TypeInt && bothGoTyped {
+			return GoCallExpr{Func: "rugo_repeat_string", Args: []GoExpr{left, right}}, nil
+		}
 		return runtimeCall("rugo_mul"), nil
 	case "/":
 		if bothInts {
@@ -183,6 +186,18 @@ func (g *codeGen) buildBinaryExpr(e *ast.BinaryExpr) (GoExpr, error) {
 			return typedBinOp("%"), nil
 		}
 		return runtimeCall("rugo_mod"), nil
+	case "&", "|", "^":
+		if bothInts {
+			return typedBinOp(e.Op), nil
+		}
+		return runtimeCall(map[string]string{"&": "rugo_band", "|": "rugo_bor", "^": "rugo_bxor"}[e.Op]), nil
+	case "<<", ">>":
+		// Shifts always go through the runtime so a negative count raises
+		// a Rugo error instead of a Go runtime panic.
+		if e.Op == "<<" {
+			return runtimeCall("rugo_shl"), nil
+		}
+		return runtimeCall("rugo_shr"), nil
 	case "==":
 		if sameTyped && bothGoTyped {
 			return typedBinOp("=="), nil
@@ -213,6 +228,11 @@ func (g *codeGen) buildBinaryExpr(e *ast.BinaryExpr) (GoExpr, error) {
 			return typedBinOp(">="), nil
 		}
 		return runtimeCall("rugo_ge"), nil
+	case "<=>":
+		return runtimeCall("rugo_spaceship"), nil
+	case "in":
+		// Membership: the collection comes first in rugo_contains.
+		return GoCallExpr{Func: "rugo_contains", Args: []GoExpr{boxedRight, boxedLeft}}, nil
 	case "&&":
 		if leftType == TypeBool && rightType == TypeBool && bothGoTyped {
 			return typedBinOp("&&"), nil
@@ -313,9 +333,11 @@ func (g *codeGen) buildArrayLiteral(e *ast.ArrayLiteral) (GoExpr, error) {
 	return GoCastExpr{Type: "interface{}", Value: GoSliceLit{Type: "[]interface{}", Elements: elems}}, nil
 }
 
+// buildHashLiteral emits rugo_hash_lit(k1, v1, k2, v2, ...), which keeps
+// the keys in the order the literal lists them.
 func (g *codeGen) buildHashLiteral(e *ast.HashLiteral) (GoExpr, error) {
-	pairs := make([]GoMapPair, len(e.Pairs))
-	for i, p := range e.Pairs {
+	args := make([]GoExpr, 0, 2*len(e.Pairs))
+	for _, p := range e.Pairs {
 		key, err := g.buildExpr(p.Key)
 		if err != nil {
 			return nil, err
@@ -324,13 +346,14 @@ func (g *codeGen) buildHashLiteral(e *ast.HashLiteral) (GoExpr, error) {
 		if err != nil {
 			return nil, err
 		}
-		pairs[i] = GoMapPair{Key: key, Value: val}
+		args = append(args, key, val)
 	}
-	return GoCastExpr{Type: "interface{}", Value: GoMapLit{KeyType: "interface{}", ValType: "interface{}", Pairs: pairs}}, nil
+	return GoCastExpr{Type: "interface{}", Value: GoCallExpr{Func: "rugo_hash_lit", Args: args}}, nil
 }
 
 func (g *codeGen) buildStringLiteral(value string, typed bool) (GoExpr, error) {
-	if preprocess.HasInterpolation(value) {
+	// Escaped \#{ has no interpolation but still needs the escape removed.
+	if strings.Contains(value, "#{") {
 		format, exprStrs, err := preprocess.ProcessInterpolation(value)
 		if err != nil {
 			return nil, err
@@ -412,12 +435,15 @@ func (g *codeGen) buildLoweredTryExpr(e *ast.LoweredTryExpr) (GoExpr, error) {
 
 	savedInTryHandler := g.inTryHandler
 	g.inTryHandler = true
+	savedInRescue := g.inRescue
+	g.inRescue = true
 
 	var handlerBody []GoStmt
 	if e.ResultExpr != nil {
 		stmts, berr := g.buildStmts(e.Handler)
 		if berr != nil {
 			g.inTryHandler = savedInTryHandler
+			g.inRescue = savedInRescue
 			g.popScope()
 			return nil, berr
 		}
@@ -425,6 +451,7 @@ func (g *codeGen) buildLoweredTryExpr(e *ast.LoweredTryExpr) (GoExpr, error) {
 		val, verr := g.buildExpr(e.ResultExpr)
 		if verr != nil {
 			g.inTryHandler = savedInTryHandler
+			g.inRescue = savedInRescue
 			g.popScope()
 			return nil, verr
 		}
@@ -433,29 +460,52 @@ func (g *codeGen) buildLoweredTryExpr(e *ast.LoweredTryExpr) (GoExpr, error) {
 		stmts, berr := g.buildStmts(e.Handler)
 		if berr != nil {
 			g.inTryHandler = savedInTryHandler
+			g.inRescue = savedInRescue
 			g.popScope()
 			return nil, berr
 		}
 		handlerBody = append(handlerBody, stmts...)
 	}
 
-	g.inTryHandler = savedInTryHandler
+	g.inRescue = savedInRescue
 	g.popScope()
 
+	// The ensure body is scoped apart from the handler so the error
+	// variable is not visible in it.
+	var ensureBody []GoStmt
+	if e.Ensure != nil {
+		g.pushScope()
+		stmts, berr := g.buildStmts(e.Ensure)
+		g.popScope()
+		if berr != nil {
+			g.inTryHandler = savedInTryHandler
+			return nil, berr
+		}
+		ensureBody = stmts
+	}
+	g.inTryHandler = savedInTryHandler
+
+	var body []GoStmt
+	if e.Ensure != nil {
+		// Deferred first so it runs last, after the handler has recovered.
+		body = append(body, GoDeferStmt{Body: ensureBody})
+	}
+	body = append(body, GoDeferStmt{Body: []GoStmt{
+		// The recovered value keeps its own name so the error variable
+		// (which may be called e) doesn't hide it from rethrow.
+		GoIfStmt{Cond: GoRawExpr{Code: "rugo_recovered := recover(); rugo_recovered != nil"}, Body: append(
+			[]GoStmt{
+				GoAssignStmt{Target: e.ErrVar, Op: ":=", Value: GoRawExpr{Code: "fmt.Sprint(rugo_recovered)"}},
+				GoExprStmt{Expr: GoRawExpr{Code: fmt.Sprintf("_ = %s", e.ErrVar)}},
+			},
+			handlerBody...,
+		)},
+	}})
+
 	return GoIIFEExpr{
 		ReturnType: "(r interface{})",
-		Body: []GoStmt{
-			GoDeferStmt{Body: []GoStmt{
-				GoIfStmt{Cond: GoRawExpr{Code: "e := recover(); e != nil"}, Body: append(
-					[]GoStmt{
-						GoAssignStmt{Target: e.ErrVar, Op: ":=", Value: GoRawExpr{Code: "fmt.Sprint(e)"}},
-						GoExprStmt{Expr: GoRawExpr{Code: fmt.Sprintf("_ = %s", e.ErrVar)}},
-					},
-					handlerBody...,
-				)},
-			}},
-		},
-		Result: triedExpr,
+		Body:       body,
+		Result:     triedExpr,
 	}, nil
 }
 
@@ -530,6 +580,25 @@ func (g *codeGen) buildLoweredParallelExpr(e *ast.LoweredParallelExpr) (GoExpr,
 		}
 	}
 
+	// parallel(limit: N) bounds the running branches with a semaphore:
+	// the parent acquires a slot before each go statement and the
+	// goroutine releases it when done, so results keep their input order.
+	var semSetup []GoStmt
+	if e.Limit != nil {
+		limit, err := g.buildExpr(e.Limit)
+		if err != nil {
+			return nil, err
+		}
+		s := (&goPrinter{}).exprStr(limit)
+		semSetup = []GoStmt{
+			GoRawStmt{Code: fmt.Sprintf("_limit := rugo_to_int(%s)", g.boxed(s, g.exprType(e.Limit)))},
+			GoIfStmt{Cond: GoRawExpr{Code: "_limit < 1"}, Body: []GoStmt{
+				GoRawStmt{Code: `panic(fmt.Sprintf("parallel: limit must be at least 1, got %d", _limit))`},
+			}},
+			GoRawStmt{Code: "_sem := make(chan struct{}, _limit)"},
+		}
+	}
+
 	var goroutines []GoStmt
 	for _, bc := range branches {
 		goroutineBody := []GoStmt{
@@ -540,17 +609,21 @@ func (g *codeGen) buildLoweredParallelExpr(e *ast.LoweredParallelExpr) (GoExpr,
 				}},
 			}},
 		}
+		if e.Limit != nil {
+			goroutineBody = append(goroutineBody, GoRawStmt{Code: "defer func() { <-_sem }()"})
+			goroutines = append(goroutines, GoRawStmt{Code: "_sem <- struct{}{}"})
+		}
 		goroutineBody = append(goroutineBody, bc.stmts...)
 		goroutines = append(goroutines, GoGoStmt{Body: goroutineBody})
 	}
 
-	body := []GoStmt{
+	body := append(semSetup, []GoStmt{
 		GoRawStmt{Code: fmt.Sprintf("_results := make([]interface{}, %d)", n)},
 		GoRawStmt{Code: "var _wg sync.WaitGroup"},
 		GoRawStmt{Code: "var _parErr string"},
 		GoRawStmt{Code: "var _parOnce sync.Once"},
 		GoRawStmt{Code: fmt.Sprintf("_wg.Add(%d)", n)},
-	}
+	}...)
 	body = append(body, goroutines...)
 	body = append(body,
 		GoRawStmt{Code: "_wg.Wait()"},
@@ -581,7 +654,7 @@ func (g *codeGen) buildCondExpr(e ast.Expr) (GoExpr, error) {
 // compileInterpolatedExpr parses a rugo expression string and returns the
 // generated Go code along with the inferred type of the expression.
 func (g *codeGen) compileInterpolatedExpr(exprStr string) (string, RugoType, error) {
-	src := exprStr + "\n"
+	src := preprocess.ExpandSafeNav(exprStr) + "\n"
 	p := &parser.Parser{}
 	flatAST, err := p.Parse("<interpolation>", []byte(src))
 	if err != nil {
@@ -672,15 +745,25 @@ func (g *codeGen) buildDotExpr(e *ast.DotExpr) (GoExpr, error) {
 	if e.Field == "__type__" {
 		return nil, fmt.Errorf("cannot access .__type__ directly — use type_of() instead")
 	}
+	// a&.b yields nil for a nil receiver. Namespaces are never nil, so
+	// there &. is a plain dot.
+	if e.Safe && !g.isNamespaceRef(e.Object) {
+		obj, err := g.buildExpr(e.Object)
+		if err != nil {
+			return nil, err
+		}
+		g.usesTaskMethods = g.usesTaskMethods || taskMethodNames[e.Field]
+		return GoCallExpr{Func: "rugo_dot_get_safe", Args: []GoExpr{obj, GoStringLit{Value: e.Field}}}, nil
+	}
 	// Rugo stdlib or namespace access without call
 	if ns, ok := e.Object.(*ast.IdentExpr); ok {
 		nsName := ns.Name
 		// Local variables shadow namespaces for dot access
 		if !g.isDeclared(nsName) {
-			if g.imports[nsName] {
-				if goFunc, ok := modules.LookupFunc(nsName, e.Field); ok {
+			if mod, ok := g.useNS[nsName]; ok {
+				if goFunc, ok := modules.LookupFunc(mod, e.Field); ok {
 					// Check arity: 0 required args → auto-call, N args → compile error
-					if fd, ok := modules.LookupFuncDef(nsName, e.Field); ok && len(fd.Args) > 0 {
+					if fd, ok := modules.LookupFuncDef(mod, e.Field); ok && len(fd.Args) > 0 {
 						return nil, fmt.Errorf("function %s.%s must be called with arguments", nsName, e.Field)
 					}
 					return GoCallExpr{Func: goFunc, Args: nil}, nil
@@ -724,6 +807,22 @@ func (g *codeGen) buildDotExpr(e *ast.DotExpr) (GoExpr, error) {
 	return GoCallExpr{Func: "rugo_dot_get", Args: []GoExpr{obj, GoStringLit{Value: e.Field}}}, nil
 }
 
+// isNamespaceRef reports whether e names a module, Go bridge package or
+// require namespace rather than a value.
+func (g *codeGen) isNamespaceRef(e ast.Expr) bool {
+	ident, ok := e.(*ast.IdentExpr)
+	if !ok || g.isDeclared(ident.Name) {
+		return false
+	}
+	if _, ok := gobridge.PackageForNS(ident.Name, g.goImports); ok {
+		return true
+	}
+	if _, ok := g.useNS[ident.Name]; ok {
+		return true
+	}
+	return g.namespaces[ident.Name]
+}
+
 func (g *codeGen) buildCallExpr(e *ast.CallExpr) (GoExpr, error) {
 	pr := &goPrinter{}
 	goArgs := make([]GoExpr, len(e.Args))
@@ -737,16 +836,39 @@ func (g *codeGen) buildCallExpr(e *ast.CallExpr) (GoExpr, error) {
 
 	// Check for namespaced function calls: ns.func(args)
 	if dot, ok := e.Func.(*ast.DotExpr); ok {
+		// a&.m(args) yields nil for a nil receiver without evaluating args.
+		if dot.Safe && !g.isNamespaceRef(dot.Object) {
+			obj, err := g.buildExpr(dot.Object)
+			if err != nil {
+				return nil, err
+			}
+			argStrs := make([]string, len(goArgs))
+			for i, a := range goArgs {
+				argStrs[i] = pr.exprStr(a)
+			}
+			return GoRawExpr{Code: fmt.Sprintf("func() interface{} { _recv := interface{}(%s); if _recv == nil { return nil }; return rugo_dot_call(_recv, %q, %s) }()",
+				pr.exprStr(obj), dot.Field, strings.Join(argStrs, ", "))}, nil
+		}
 		if ns, ok := dot.Object.(*ast.IdentExpr); ok {
 			nsName := ns.Name
 			// Local variables shadow namespaces for dot calls
 			if !g.isDeclared(nsName) {
 				// Rugo stdlib module call
-				if g.imports[nsName] {
-					if goFunc, ok := modules.LookupFunc(nsName, dot.Field); ok {
-						return GoCallExpr{Func: goFunc, Args: goArgs}, nil
+				if mod, ok := g.useNS[nsName]; ok {
+					if goFunc, ok := modules.LookupFunc(mod, dot.Field); ok {
+						if fd, ok := modules.LookupFuncDef(mod, dot.Field); ok {
+							min, max := fd.Arity()
+							if len(e.Args) < min || (max >= 0 && len(e.Args) > max) {
+								return nil, arityCountError(nsName+"."+dot.Field, len(e.Args), funcArity{Min: min, Max: max})
+							}
+						}
+						call := GoCallExpr{Func: goFunc, Args: goArgs}
+						if t := g.exprType(e); t.IsTyped() {
+							return GoTypeAssert{Value: call, Type: t.GoType()}, nil
+						}
+						return call, nil
 					}
-					return nil, fmt.Errorf("unknown function %s.%s in module %q", nsName, dot.Field, nsName)
+					return nil, fmt.Errorf("unknown function %s.%s in module %q", nsName, dot.Field, mod)
 				}
 				// Go bridge call — render args to strings for generateGoBridgeCall
 				if pkg, ok := gobridge.PackageForNS(nsName, g.goImports); ok {
@@ -826,12 +948,72 @@ func (g *codeGen) buildCallExpr(e *ast.CallExpr) (GoExpr, error) {
 		case "raise":
 			return GoCallExpr{Func: "rugo_raise", Args: boxed}, nil
 		case "exit":
+			if len(e.Args) > 2 {
+				return nil, fmt.Errorf("exit expects 0 to 2 arguments, got %d", len(e.Args))
+			}
 			return GoCallExpr{Func: "rugo_exit", Args: boxed}, nil
+		case "rethrow":
+			if !g.inRescue {
+				return nil, fmt.Errorf("rethrow can only be used inside a try handler")
+			}
+			if len(e.Args) != 0 {
+				return nil, fmt.Errorf("rethrow takes no arguments, got %d", len(e.Args))
+			}
+			return GoCallExpr{Func: "rugo_rethrow", Args: []GoExpr{GoIdentExpr{Name: "rugo_recovered"}}}, nil
+		case "die":
+			if len(e.Args) != 1 {
+				return nil, fmt.Errorf("die expects 1 argument, got %d", len(e.Args))
+			}
+			return GoCallExpr{Func: "rugo_die", Args: boxed}, nil
+		case "__range__":
+			// a..b / a...b range literal outside a for loop: materialize it.
+			incl, ok := e.Args[2].(*ast.BoolLiteral)
+			if len(e.Args) != 3 || !ok {
+				return nil, fmt.Errorf("malformed range literal")
+			}
+			return GoCallExpr{Func: "rugo_range_literal", Args: []GoExpr{boxed[0], boxed[1], GoBoolLit{Value: incl.Value}}}, nil
+		case "assert":
+			if len(e.Args) < 1 || len(e.Args) > 2 {
+				return nil, fmt.Errorf("assert expects 1 or 2 arguments, got %d", len(e.Args))
+			}
+			return GoCallExpr{Func: "rugo_assert", Args: boxed}, nil
+		case "__array_concat__":
+			// [...xs, ys] array literal with spreads.
+			return GoCallExpr{Func: "rugo_array_concat", Args: boxed}, nil
+		case "__hash_merge__":
+			// {...base, k => v} hash literal with spreads.
+			return GoCallExpr{Func: "rugo_hash_merge", Args: boxed}, nil
+		case "__defined__":
+			if len(e.Args) != 1 {
+				return nil, fmt.Errorf("defined? expects 1 argument, got %d", len(e.Args))
+			}
+			// foldDefined has already replaced a string literal name with its answer.
+			folded, ok := e.Args[0].(*ast.BoolLiteral)
+			if !ok {
+				return nil, fmt.Errorf("defined? expects a string literal name")
+			}
+			lit := GoBoolLit{Value: folded.Value}
+			if g.exprIsTyped(e) {
+				return lit, nil
+			}
+			return GoCastExpr{Type: "interface{}", Value: lit}, nil
+		case kwDefaultBuiltin:
+			return GoRawExpr{Code: "interface{}(rugo_default_arg{})"}, nil
+		case "__with__":
+			if len(e.Args) != 1 {
+				return nil, fmt.Errorf("with expects 1 resource, got %d", len(e.Args))
+			}
+			return GoCallExpr{Func: "rugo_with_guard", Args: boxed}, nil
 		case "type_of":
 			if len(e.Args) != 1 {
 				return nil, fmt.Errorf("type_of expects 1 argument, got %d", len(e.Args))
 			}
 			return GoCallExpr{Func: "rugo_type_of", Args: boxed}, nil
+		case "parallel_map":
+			if len(e.Args) < 2 || len(e.Args) > 3 {
+				return nil, fmt.Errorf("parallel_map expects 2 or 3 arguments, got %d", len(e.Args))
+			}
+			return GoCallExpr{Func: "rugo_parallel_map", Args: boxed}, nil
 		case "range":
 			if len(e.Args) < 1 || len(e.Args) > 2 {
 				return nil, fmt.Errorf("range expects 1 or 2 arguments, got %d", len(e.Args))
@@ -902,11 +1084,14 @@ func (g *codeGen) buildFnExpr(e *ast.FnExpr) (GoExpr, error) {
 	savedFunc := g.currentFunc
 	savedInFunc := g.inFunc
 	savedLoopCtl := g.loopCtlDepth
+	savedWiths := g.withClosers
 	g.inFunc = true
 	g.loopCtlDepth = 0
+	g.withClosers = nil
 
 	restoreLambda := func() {
 		g.loopCtlDepth = savedLoopCtl
+		g.withClosers = savedWiths
 		g.inFunc = savedInFunc
 		g.currentFunc = savedFunc
 		g.lambdaScopeBase = g.lambdaScopeBase[:len(g.lambdaScopeBase)-1]
@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/rubiojr/rugo/modules"
+	"github.com/rubiojr/rugo/preprocess"
 )
 
 func (g *codeGen) buildTestHarness(tests []*ast.TestDef, topStmts []ast.Statement, setup, teardown, setupFile, teardownFile *ast.FuncDef) ([]GoDecl, error) {
@@ -154,7 +155,24 @@ func (g *codeGen) buildBenchHarness(benches []*ast.BenchDef, topStmts []ast.Stat
 	runnerCall.WriteString("rugo_bench_runner([]rugoBenchCase{\n")
 	for i, b := range benches {
 		escapedName := goEscapeString(b.Name)
-		fmt.Fprintf(&runnerCall, "\t{Name: \"%s\", Func: rugo_bench_%d},\n", escapedName, i)
+		opts := ""
+		if b.Iterations != nil {
+			s, err := g.benchOption(b.Iterations)
+			if err != nil {
+				g.popScope()
+				return nil, err
+			}
+			opts += fmt.Sprintf(", Iterations: rugo_bench_iterations(\"%s\", %s)", escapedName, s)
+		}
+		if b.MinTime != nil {
+			s, err := g.benchOption(b.MinTime)
+			if err != nil {
+				g.popScope()
+				return nil, err
+			}
+			opts += fmt.Sprintf(", MinTime: rugo_bench_min_time(\"%s\", %s)", escapedName, s)
+		}
+		fmt.Fprintf(&runnerCall, "\t{Name: \"%s\", Func: rugo_bench_%d%s},\n", escapedName, i, opts)
 	}
 	runnerCall.WriteString("})")
 	mainBody = append(mainBody, GoRawStmt{Code: runnerCall.String()})
@@ -169,6 +187,15 @@ func (g *codeGen) buildBenchHarness(benches []*ast.BenchDef, topStmts []ast.Stat
 	return decls, nil
 }
 
+// benchOption renders a bench option value as a boxed Go expression.
+func (g *codeGen) benchOption(e ast.Expr) (string, error) {
+	expr, err := g.buildExpr(e)
+	if err != nil {
+		return "", err
+	}
+	return g.boxed((&goPrinter{}).exprStr(expr), g.exprType(e)), nil
+}
+
 func (g *codeGen) buildDispatchMaps(funcs []*ast.FuncDef, handlers map[string]bool) []GoDecl {
 	var decls []GoDecl
 	for _, name := range importedModuleNames(g.imports) {
@@ -212,16 +239,58 @@ func (g *codeGen) buildDispatchMaps(funcs []*ast.FuncDef, handlers map[string]bo
 	return decls
 }
 
+// buildComparators registers each struct's <=> method (rewritten to
+// __cmp_<Type> by the preprocessor) so rugo_compare can order its values.
+func buildComparators(funcs []*ast.FuncDef) []GoDecl {
+	var sb strings.Builder
+	for _, f := range funcs {
+		typeName, ok := strings.CutPrefix(f.Name, preprocess.ComparatorPrefix)
+		if !ok || len(f.Params) != 2 {
+			continue
+		}
+		goName := fmt.Sprintf("rugofn_%s", f.Name)
+		if f.Namespace != "" {
+			goName = fmt.Sprintf("rugons_%s_%s", f.Namespace, f.Name)
+		}
+		fmt.Fprintf(&sb, "\trugo_comparators[%q] = func(a, b interface{}) interface{} { return %s(a, b) }\n", typeName, goName)
+	}
+	if sb.Len() == 0 {
+		return nil
+	}
+	return []GoDecl{GoRawDecl{Code: "func init() {\n" + sb.String() + "}\n"}, GoBlankLine{}}
+}
+
+// buildExitHooks registers each struct's __exit__ method (rewritten to
+// __exit_<Type> by the preprocessor) so with blocks can release its values.
+func buildExitHooks(funcs []*ast.FuncDef) []GoDecl {
+	var sb strings.Builder
+	for _, f := range funcs {
+		typeName, ok := strings.CutPrefix(f.Name, preprocess.ExitPrefix)
+		if !ok || len(f.Params) != 1 {
+			continue
+		}
+		goName := fmt.Sprintf("rugofn_%s", f.Name)
+		if f.Namespace != "" {
+			goName = fmt.Sprintf("rugons_%s_%s", f.Namespace, f.Name)
+		}
+		fmt.Fprintf(&sb, "\trugo_exit_hooks[%q] = func(v interface{}) interface{} { return %s(v) }\n", typeName, goName)
+	}
+	if sb.Len() == 0 {
+		return nil
+	}
+	return []GoDecl{GoRawDecl{Code: "func init() {\n" + sb.String() + "}\n"}, GoBlankLine{}}
+}
+
 // collectDispatchHandlers scans top-level statements for module method calls
 // that register handler functions (e.g. web.get("/", "handler"), cli.cmd("greet", "fn"))
 // and returns the set of handler function names referenced.
-func collectDispatchHandlers(stmts []ast.Statement, imports map[string]bool) map[string]bool {
+func collectDispatchHandlers(stmts []ast.Statement, useNS map[string]string) map[string]bool {
 	handlers := make(map[string]bool)
-	// Collect module names that have dispatch entries
+	// Collect the namespaces of modules that have dispatch entries
 	dispatchModules := make(map[string]bool)
-	for name := range imports {
+	for ns, name := range useNS {
 		if m, ok := modules.Get(name); ok && m.DispatchEntry != "" {
-			dispatchModules[m.Name] = true
+			dispatchModules[ns] = true
 		}
 	}
 	if len(dispatchModules) == 0 {
@@ -257,6 +326,9 @@ func collectDispatchHandlersFromStmt(s ast.Statement, dispatchModules map[string
 		for _, b := range st.Body {
 			collectDispatchHandlersFromStmt(b, dispatchModules, handlers)
 		}
+		for _, b := range st.ElseBody {
+			collectDispatchHandlersFromStmt(b, dispatchModules, handlers)
+		}
 	case *ast.FuncDef:
 		for _, b := range st.Body {
 			collectDispatchHandlersFromStmt(b, dispatchModules, handlers)
@@ -1,6 +1,7 @@
 package compiler
 
 import (
+	"errors"
 	"fmt"
 	"sort"
 	"strings"
@@ -25,10 +26,22 @@ func (g *codeGen) buildRuntimeCode() string {
 		sb.WriteString(runtimeSpawn)
 	}
 
+	if g.hasParallelMap {
+		sb.WriteString(runtimeParallelMap)
+	}
+
+	if g.hasMemo {
+		sb.WriteString(runtimeMemo)
+	}
+
 	if g.sandbox != nil {
 		sb.WriteString(g.sandboxRuntimeCode())
 	}
 
+	if g.profile != nil {
+		sb.WriteString(profileRuntimeCode())
+	}
+
 	if len(g.goImports) > 0 {
 		sb.WriteString(g.goBridgeRuntimeCode())
 	}
@@ -81,6 +94,57 @@ func rugo_sandbox_is_dir(path string) bool {
 	fi, err := os.Stat(path)
 	return err == nil && fi.IsDir()
 }
+
+// rugoSandboxRule is one filesystem rule of the sandbox directive. The
+// rules are enforced in-process by the module file helpers as well as by
+// Landlock, so violations raise even where Landlock is unavailable.
+type rugoSandboxRule struct {
+	path  string
+	write bool
+}
+
+var rugo_sandbox_rules []rugoSandboxRule
+
+func rugo_sandbox_allow(path string, write bool) {
+	if path == "" {
+		return
+	}
+	rugo_sandbox_rules = append(rugo_sandbox_rules, rugoSandboxRule{path: rugo_sandbox_resolve(path), write: write})
+}
+
+// rugo_sandbox_resolve returns the absolute, symlink-free form of path.
+// Paths that do not exist yet resolve through their parent directory.
+func rugo_sandbox_resolve(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return filepath.Clean(path)
+	}
+	if real, err := filepath.EvalSymlinks(abs); err == nil {
+		return real
+	}
+	if dir, err := filepath.EvalSymlinks(filepath.Dir(abs)); err == nil {
+		return filepath.Join(dir, filepath.Base(abs))
+	}
+	return abs
+}
+
+func rugo_sandbox_check_rules(op, path string, write bool) {
+	target := rugo_sandbox_resolve(path)
+	for _, r := range rugo_sandbox_rules {
+		if write && !r.write {
+			continue
+		}
+		rel, err := filepath.Rel(r.path, target)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return
+		}
+	}
+	access := "read"
+	if write {
+		access = "write"
+	}
+	panic(fmt.Sprintf("%s: sandbox denies %s access to %s", op, access, path))
+}
 `
 }
 
@@ -107,6 +171,21 @@ func (g *codeGen) buildSandboxApply() []GoStmt {
 		}
 	}
 
+	// In-process path rules, enforced on every platform
+	stmts = append(stmts, GoRawStmt{Code: "rugo_sandbox_check = rugo_sandbox_check_rules"})
+	for _, p := range cfg.RO {
+		stmts = append(stmts, GoRawStmt{Code: fmt.Sprintf("rugo_sandbox_allow(os.ExpandEnv(%q), false)", p)})
+	}
+	for _, p := range cfg.ROX {
+		stmts = append(stmts, GoRawStmt{Code: fmt.Sprintf("rugo_sandbox_allow(os.ExpandEnv(%q), false)", p)})
+	}
+	for _, p := range cfg.RW {
+		stmts = append(stmts, GoRawStmt{Code: fmt.Sprintf("rugo_sandbox_allow(os.ExpandEnv(%q), true)", p)})
+	}
+	for _, p := range cfg.RWX {
+		stmts = append(stmts, GoRawStmt{Code: fmt.Sprintf("rugo_sandbox_allow(os.ExpandEnv(%q), true)", p)})
+	}
+
 	// Platform check + Landlock setup
 	hasFS := len(cfg.RO) > 0 || len(cfg.RW) > 0 || len(cfg.ROX) > 0 || len(cfg.RWX) > 0
 	hasNet := len(cfg.Connect) > 0 || len(cfg.Bind) > 0
@@ -415,6 +494,7 @@ func (g *codeGen) goBridgeRuntimeCode() string {
 }
 
 // arityCountError produces a human-friendly argument count error for range arity.
+// A negative Max means any number of arguments beyond Min is accepted.
 func arityCountError(name string, got int, arity funcArity) error {
 	gotDesc := fmt.Sprintf("%d were", got)
 	if got == 0 {
@@ -422,6 +502,13 @@ func arityCountError(name string, got int, arity funcArity) error {
 	} else if got == 1 {
 		gotDesc = "1 was"
 	}
+	if arity.Max < 0 {
+		argWord := "arguments"
+		if arity.Min == 1 {
+			argWord = "argument"
+		}
+		return fmt.Errorf("%s() takes at least %d %s but %s given", name, arity.Min, argWord, gotDesc)
+	}
 	if arity.Min == arity.Max {
 		argWord := "arguments"
 		if arity.Max == 1 {
@@ -432,6 +519,16 @@ func arityCountError(name string, got int, arity funcArity) error {
 	return fmt.Errorf("%s() takes %d to %d arguments but %s given", name, arity.Min, arity.Max, gotDesc)
 }
 
+// joinCompileErrors combines independent codegen errors into one error that
+// reports each of them on its own line. The result unwraps to the individual
+// errors so callers can print them separately.
+func joinCompileErrors(errs []error) error {
+	if len(errs) == 1 {
+		return errs[0]
+	}
+	return errors.Join(errs...)
+}
+
 // argCountError produces a human-friendly argument count mismatch error.
 func argCountError(name string, got, expected int) error {
 	return arityCountError(name, got, funcArity{Min: expected, Max: expected})
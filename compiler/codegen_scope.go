@@ -72,6 +72,9 @@ func collectIdentsFromStmt(s ast.Statement, names map[string]bool) {
 		for _, b := range st.Body {
 			collectIdentsFromStmt(b, names)
 		}
+		for _, b := range st.ElseBody {
+			collectIdentsFromStmt(b, names)
+		}
 	case *ast.ForStmt:
 		collectIdentsFromExpr(st.Collection, names)
 		for _, b := range st.Body {
@@ -136,6 +139,9 @@ func collectIdentsFromExpr(e ast.Expr, names map[string]bool) {
 		if ex.ResultExpr != nil {
 			collectIdentsFromExpr(ex.ResultExpr, names)
 		}
+		for _, b := range ex.Ensure {
+			collectIdentsFromStmt(b, names)
+		}
 	case *ast.LoweredSpawnExpr:
 		for _, s := range ex.Body {
 			collectIdentsFromStmt(s, names)
@@ -144,6 +150,9 @@ func collectIdentsFromExpr(e ast.Expr, names map[string]bool) {
 			collectIdentsFromExpr(ex.ResultExpr, names)
 		}
 	case *ast.LoweredParallelExpr:
+		if ex.Limit != nil {
+			collectIdentsFromExpr(ex.Limit, names)
+		}
 		for _, br := range ex.Branches {
 			if br.Expr != nil {
 				collectIdentsFromExpr(br.Expr, names)
@@ -213,6 +222,15 @@ func (g *codeGen) popScope() {
 	g.constScopes = g.constScopes[:len(g.constScopes)-1]
 }
 
+// unwindScopes drops the scopes a failed function build left open and
+// resets the per-function state, so the next function starts clean.
+func (g *codeGen) unwindScopes(depth int) {
+	g.scopes = g.scopes[:depth]
+	g.constScopes = g.constScopes[:depth]
+	g.inFunc = false
+	g.currentFunc = nil
+}
+
 func (g *codeGen) declareVar(name string) {
 	g.scopes[len(g.scopes)-1][name] = true
 }
@@ -313,6 +331,19 @@ func astUsesTaskMethods(prog *ast.Program) bool {
 
 var taskMethodNames = map[string]bool{"value": true, "done": true, "wait": true}
 
+// astCallsBuiltin checks if the AST contains a direct call to the named
+// builtin function.
+func astCallsBuiltin(prog *ast.Program, name string) bool {
+	return WalkExprs(prog, func(e ast.Expr) bool {
+		call, ok := e.(*ast.CallExpr)
+		if !ok {
+			return false
+		}
+		ident, ok := call.Func.(*ast.IdentExpr)
+		return ok && ident.Name == name
+	})
+}
+
 // astUsesParallel checks if any LoweredParallelExpr exists in the AST.
 func astUsesParallel(prog *ast.Program) bool {
 	return WalkExprs(prog, func(e ast.Expr) bool {
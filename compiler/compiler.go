@@ -51,6 +51,18 @@ type Compiler struct {
 	// Sandbox, when non-nil, overrides any sandbox directive in the script.
 	// Populated by CLI flags (--sandbox --ro, --rw, etc.).
 	Sandbox *SandboxConfig
+	// Profile, when non-nil, compiles pprof CPU/heap profiling into the
+	// program. Populated by CLI flags (--profile, --memprofile).
+	Profile *ProfileConfig
+	// Trace compiles a rugo_trace call before every statement, printing
+	// "TRACE file:line" to stderr as it runs. Set by `rugo run --trace`.
+	Trace bool
+	// Strict turns the implicit shell fallback into a compile error (see
+	// preprocess.Options.Strict). Also enabled by a non-empty RUGO_STRICT.
+	Strict bool
+	// WarnUnused reports local variables that are assigned but never read.
+	// Always on in Strict mode.
+	WarnUnused bool
 	// Resolver overrides the default remote resolver. When set, the compiler
 	// uses this resolver instead of creating one. Used by mod tidy to share
 	// a single resolver across multiple compilations.
@@ -60,13 +72,19 @@ type Compiler struct {
 	// loaded tracks already-loaded files and the namespace they were loaded under.
 	loaded map[string]string // abs path → namespace
 	// imports tracks which Rugo stdlib modules have been imported via use.
-	imports map[string]bool
+	imports map[string]string // namespace (module name or alias) → module
 	// goImports tracks Go stdlib bridge packages imported via import.
 	goImports map[string]string // package path → alias (empty = default)
 	// nsFuncs tracks namespace+function pairs to detect duplicates.
 	nsFuncs map[string]string // "ns.func" → source file
 	// requireStack tracks files currently being resolved (for cycle detection).
 	requireStack []string
+	// baseURL is the URL of the file being resolved when it was required
+	// by URL; its relative requires resolve against it, not the cache dir.
+	baseURL string
+	// deps, when non-nil, records the dependency graph as requires are
+	// resolved. Set by Deps.
+	deps *DepGraph
 	// goModuleRequires tracks Go modules discovered via require for go.mod generation.
 	// Maps Go module path → local cache directory.
 	goModuleRequires map[string]string
@@ -93,41 +111,22 @@ type CompileResult struct {
 	EmbedFiles map[string]string
 }
 
+// StringSourceName is the synthetic filename used for source compiled by
+// CompileString and RunString. It appears in errors and //line directives.
+const StringSourceName = "<string>"
+
 // Compile reads a Rugo source file, resolves requires, and produces Go source.
 func (c *Compiler) Compile(filename string) (*CompileResult, error) {
 	WarnDeprecatedExt(filename)
 
-	if c.loaded == nil {
-		c.loaded = make(map[string]string)
-	}
-	if c.imports == nil {
-		c.imports = make(map[string]bool)
-	}
-	if c.goImports == nil {
-		c.goImports = make(map[string]string)
-	}
-	if c.nsFuncs == nil {
-		c.nsFuncs = make(map[string]string)
-	}
-	if c.goModuleRequires == nil {
-		c.goModuleRequires = make(map[string]string)
-	}
+	c.initState()
 	absPath, err := filepath.Abs(filename)
 	if err != nil {
 		return nil, fmt.Errorf("resolving path %s: %w", filename, err)
 	}
 	c.BaseDir = filepath.Dir(absPath)
-
-	// Initialize remote resolver.
-	if c.resolver == nil {
-		if c.Resolver != nil {
-			c.resolver = c.Resolver
-		} else {
-			c.resolver = &remote.Resolver{ModuleDir: c.ModuleDir, Frozen: c.Frozen, ReadOnly: c.TestMode}
-			if err := c.resolver.InitLockFromDir(c.BaseDir); err != nil {
-				return nil, err
-			}
-		}
+	if err := c.initResolver(); err != nil {
+		return nil, err
 	}
 
 	// In test mode, auto-require Rugo files from helpers/ dir next to the test file.
@@ -151,22 +150,118 @@ func (c *Compiler) Compile(filename string) (*CompileResult, error) {
 	c.loaded[absPath] = ""
 	c.requireStack = []string{absPath}
 
+	return c.compileProgram(prog, filename)
+}
+
+// CompileString compiles Rugo source held in memory and returns the
+// generated Go source. Requires resolve against BaseDir, or the working
+// directory when BaseDir is empty. Errors and //line directives name the
+// source StringSourceName.
+func (c *Compiler) CompileString(src string) (string, error) {
+	result, err := c.compileString(src)
+	if err != nil {
+		return "", err
+	}
+	return result.GoSource, nil
+}
+
+func (c *Compiler) compileString(src string) (*CompileResult, error) {
+	c.initState()
+	if c.BaseDir == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return nil, fmt.Errorf("resolving working directory: %w", err)
+		}
+		c.BaseDir = wd
+	}
+	if err := c.initResolver(); err != nil {
+		return nil, err
+	}
+
+	prog, err := c.parseSource(src, StringSourceName)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateSandboxPlacement(prog, StringSourceName); err != nil {
+		return nil, err
+	}
+	c.requireStack = nil
+
+	return c.compileProgram(prog, StringSourceName)
+}
+
+// initState allocates the per-compilation bookkeeping maps.
+func (c *Compiler) initState() {
+	// A failed compile can leave a URL require's base behind.
+	c.baseURL = ""
+	if c.loaded == nil {
+		c.loaded = make(map[string]string)
+	}
+	if c.imports == nil {
+		c.imports = make(map[string]string)
+	}
+	if c.goImports == nil {
+		c.goImports = make(map[string]string)
+	}
+	if c.nsFuncs == nil {
+		c.nsFuncs = make(map[string]string)
+	}
+	if c.goModuleRequires == nil {
+		c.goModuleRequires = make(map[string]string)
+	}
+}
+
+// initResolver sets up the remote module resolver for BaseDir.
+func (c *Compiler) initResolver() error {
+	if c.resolver != nil {
+		return nil
+	}
+	if c.Resolver != nil {
+		c.resolver = c.Resolver
+		return nil
+	}
+	c.resolver = &remote.Resolver{ModuleDir: c.ModuleDir, Frozen: c.Frozen, ReadOnly: c.TestMode}
+	return c.resolver.InitLockFromDir(c.BaseDir)
+}
+
+// compileProgram runs the shared back half of the pipeline on a parsed
+// main program: require resolution, semantic checks and code generation.
+func (c *Compiler) compileProgram(prog *ast.Program, filename string) (*CompileResult, error) {
 	// Resolve requires recursively
 	resolved, err := c.resolveRequires(prog)
 	if err != nil {
 		return nil, err
 	}
 
+	// Rewrite calls to Go functions imported under a new name.
+	if err := resolveImportFuncs(resolved, filename); err != nil {
+		return nil, err
+	}
+
+	// Fold defined?("name") now that every required function is known.
+	foldDefined(resolved)
+
+	// Bind keyword arguments to parameter positions.
+	if err := bindKeywordArgs(resolved, filename); err != nil {
+		return nil, err
+	}
+
 	// Run semantic checks before code generation
+	strict := c.Strict || os.Getenv("RUGO_STRICT") != ""
 	checks := ast.CheckChain{
 		UndefinedIdentCheck(filename),
+		StructFieldCheck(filename, os.Stderr),
+		NamespaceShadowCheck(filename, os.Stderr, strict),
+	}
+	if c.WarnUnused || strict {
+		checks = append(checks, UnusedVarCheck(filename, os.Stderr))
 	}
 	if err := checks.Run(resolved); err != nil {
 		return nil, err
 	}
 
 	// Generate Go source
-	genResult, err := generate(resolved, filename, c.TestMode, c.Sandbox, c.DisableEmbed)
+	genResult, err := generate(resolved, filename, c.TestMode, c.Sandbox, c.Profile, c.DisableEmbed, c.Trace)
 	if err != nil {
 		return nil, err
 	}
@@ -278,6 +373,12 @@ func (c *Compiler) Run(filename string, extraArgs ...string) error {
 		return err
 	}
 
+	if c.Profile != nil {
+		if err := writeProfileSymbols(c.Profile, result.Program, result.SourceFile); err != nil {
+			return err
+		}
+	}
+
 	tmpDir, binFile, err := buildBinary(result)
 	if err != nil {
 		return err
@@ -298,6 +399,28 @@ func (c *Compiler) Run(filename string, extraArgs ...string) error {
 	return nil
 }
 
+// RunString compiles and runs Rugo source held in memory, passing args to
+// the program. Unlike Run it never exits the calling process: a program
+// that exits non-zero is reported as an *exec.ExitError.
+func (c *Compiler) RunString(src string, args ...string) error {
+	result, err := c.compileString(src)
+	if err != nil {
+		return err
+	}
+
+	tmpDir, binFile, err := buildBinary(result)
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	cmd := exec.Command(binFile, args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Stdin = os.Stdin
+	return cmd.Run()
+}
+
 // CapturedOutput holds the result of RunCapture.
 type CapturedOutput struct {
 	Output   string
@@ -313,13 +436,28 @@ func (c *Compiler) RunCapture(filename string, extraArgs ...string) (*CapturedOu
 		return nil, err
 	}
 
+	return runCaptured(result, extraArgs)
+}
+
+// RunStringCapture is RunCapture for Rugo source held in memory (see
+// CompileString).
+func (c *Compiler) RunStringCapture(src string, args ...string) (*CapturedOutput, error) {
+	result, err := c.compileString(src)
+	if err != nil {
+		return nil, err
+	}
+	return runCaptured(result, args)
+}
+
+// runCaptured builds result and runs it with combined output captured.
+func runCaptured(result *CompileResult, args []string) (*CapturedOutput, error) {
 	tmpDir, binFile, err := buildBinary(result)
 	if err != nil {
 		return nil, err
 	}
 	defer os.RemoveAll(tmpDir)
 
-	cmd := exec.Command(binFile, extraArgs...)
+	cmd := exec.Command(binFile, args...)
 	cmd.Env = append(os.Environ(), "NO_COLOR=1")
 	out, err := cmd.CombinedOutput()
 	output := strings.TrimRight(string(out), "\n")
@@ -478,77 +616,35 @@ func (c *Compiler) parseSource(source, displayName string) (*ast.Program, error)
 	// Preserve raw source before preprocessing destroys comments.
 	rawSource := source
 
-	// Expand heredocs before comment stripping (bodies may contain #).
-	cleaned, heredocLineMap, err := preprocess.ExpandHeredocs(source)
-	if err != nil {
-		return nil, fmt.Errorf("%s:%w", displayName, err)
-	}
-
-	// Strip comments
-	cleaned, err = preprocess.StripComments(cleaned)
-	if err != nil {
-		return nil, fmt.Errorf("%s:%w", displayName, err)
-	}
-
-	// Expand struct definitions and method definitions before other preprocessing
-	var structLineMap []int
-	var structInfos []preprocess.StructInfo
-	cleaned, structLineMap, structInfos = preprocess.ExpandStructDefs(cleaned)
-
-	// Scan for user-defined function names (quick pass for def lines)
-	userFuncs := preprocess.ScanFuncDefs(cleaned)
-
-	// preprocess: paren-free calls + shell fallback
-	var lineMap []int
-	cleaned, lineMap, err = preprocess.Preprocess(cleaned, userFuncs)
+	strict := c.Strict || os.Getenv("RUGO_STRICT") != ""
+	cleaned, lineMap, structInfos, err := preprocess.RunWithOptions(source, preprocess.Options{Strict: strict})
 	if err != nil {
 		return nil, fmt.Errorf("%s:%w", displayName, err)
 	}
 
-	// Compose all line maps: preprocess → struct → heredoc → original source.
-	// Each map translates from its output lines to its input lines (1-indexed).
-	if structLineMap != nil && lineMap != nil {
-		for i, ppLine := range lineMap {
-			if ppLine > 0 && ppLine <= len(structLineMap) {
-				lineMap[i] = structLineMap[ppLine-1]
-			}
-		}
-	} else if structLineMap != nil {
-		lineMap = structLineMap
-	}
-
-	if heredocLineMap != nil && lineMap != nil {
-		for i, ppLine := range lineMap {
-			if ppLine > 0 && ppLine <= len(heredocLineMap) {
-				lineMap[i] = heredocLineMap[ppLine-1]
-			}
-		}
-	} else if heredocLineMap != nil {
-		lineMap = heredocLineMap
-	}
-
-	// Ensure source ends with newline
-	if !strings.HasSuffix(cleaned, "\n") {
-		cleaned += "\n"
-	}
-
 	// Validate: no non-ASCII characters outside strings. The parser's
 	// generated scanner panics on multi-byte UTF-8 in code positions.
-	if err := validateSourceChars(cleaned, displayName, lineMap); err != nil {
+	sm := sourceMap{src: source, cleaned: cleaned, lineMap: lineMap}
+	if err := validateSourceChars(cleaned, displayName, sm); err != nil {
 		return nil, err
 	}
 
 	p := &parser.Parser{}
 	flatAST, err := p.Parse(displayName, []byte(cleaned))
 	if err != nil {
-		return nil, firstParseError(err)
+		// A missing `end` makes the parser fail at EOF, far from the
+		// cause, so name the unbalanced block instead.
+		if be := unbalancedBlock(err, cleaned); be != nil {
+			return nil, blockError(be, displayName, sm)
+		}
+		return nil, firstParseError(err, sm)
 	}
 
 	prog, err := ast.WalkWithLineMap(p, flatAST, lineMap)
 	if err != nil {
 		var ue *ast.UserError
 		if errors.As(err, &ue) {
-			return nil, fmt.Errorf("%s: %s", displayName, ue.Msg)
+			return nil, walkError(ue, displayName, sm)
 		}
 		return nil, fmt.Errorf("%s: internal compiler error: %w (please report this bug)", displayName, err)
 	}
@@ -563,6 +659,7 @@ func (c *Compiler) parseSource(source, displayName string) (*ast.Program, error)
 // It introspects the Go source, registers it as a bridge package, and returns
 // an ImportStmt so codegen treats it like any other Go bridge package.
 func (c *Compiler) resolveGoModuleRequire(req *ast.RequireStmt, dir string, sourceFile string) ([]ast.Statement, error) {
+	c.recordLeaf(DepGoModule, req.Path)
 	result, err := gobridge.InspectSourcePackage(dir)
 	if err != nil {
 		return nil, fmt.Errorf("%s:%d: Go module require %q: %w", sourceFile, req.StmtLine(), req.Path, err)
@@ -583,7 +680,7 @@ func (c *Compiler) resolveGoModuleRequire(req *ast.RequireStmt, dir string, sour
 	ns = strings.ReplaceAll(ns, "-", "_")
 
 	// Check for namespace conflicts
-	if c.imports[ns] {
+	if _, ok := c.imports[ns]; ok {
 		return nil, fmt.Errorf("%s:%d: require namespace %q conflicts with use'd stdlib module", sourceFile, req.StmtLine(), ns)
 	}
 	for pkg, alias := range c.goImports {
@@ -689,18 +786,23 @@ func (c *Compiler) resolveRequires(prog *ast.Program) (*ast.Program, error) {
 				}
 				return nil, fmt.Errorf("%s:%d: unknown module %q (available: %s)", prog.SourceFile, s.StmtLine(), use.Module, strings.Join(modules.Names(), ", "))
 			}
+			useNS := use.Namespace()
 			// Check for namespace conflicts with Go bridge imports
 			for pkg, alias := range c.goImports {
 				ns := alias
 				if ns == "" {
 					ns = gobridge.DefaultNS(pkg)
 				}
-				if ns == use.Module {
-					return nil, fmt.Errorf("%s:%d: use namespace %q conflicts with an imported Go bridge package; add an alias to the import: import %q as <alias>", prog.SourceFile, s.StmtLine(), use.Module, pkg)
+				if ns == useNS {
+					return nil, fmt.Errorf("%s:%d: use namespace %q conflicts with an imported Go bridge package; add an alias to the import: import %q as <alias>", prog.SourceFile, s.StmtLine(), useNS, pkg)
 				}
 			}
-			if !c.imports[use.Module] {
-				c.imports[use.Module] = true
+			if mod, ok := c.imports[useNS]; ok && mod != use.Module {
+				return nil, fmt.Errorf("%s:%d: use namespace %q is already taken by module %q; pick another alias: use %q as <alias>", prog.SourceFile, s.StmtLine(), useNS, mod, use.Module)
+			}
+			c.recordLeaf(DepModule, use.Module)
+			if _, ok := c.imports[useNS]; !ok {
+				c.imports[useNS] = use.Module
 				resolved = append(resolved, s)
 			}
 			continue
@@ -726,9 +828,10 @@ func (c *Compiler) resolveRequires(prog *ast.Program) (*ast.Program, error) {
 				gobridge.Register(pkg)
 			}
 			// Check for namespace conflicts with Rugo modules
-			if c.imports[ns] {
+			if _, ok := c.imports[ns]; ok {
 				return nil, fmt.Errorf("%s:%d: import namespace %q conflicts with a use'd Rugo module; add an alias: import %q as <alias>", prog.SourceFile, s.StmtLine(), ns, imp.Package)
 			}
+			c.recordLeaf(DepImport, imp.Package)
 			if _, exists := c.goImports[imp.Package]; !exists {
 				c.goImports[imp.Package] = imp.Alias
 				resolved = append(resolved, s)
@@ -754,7 +857,20 @@ func (c *Compiler) resolveRequires(prog *ast.Program) (*ast.Program, error) {
 			}
 		}
 
+		// Inside a file required by URL, relative requires are fetched
+		// from next to it rather than looked up in the cache directory.
+		if c.baseURL != "" && !remote.IsURLRequire(req.Path) && !remote.IsRemoteRequire(req.Path) {
+			u, err := remote.ResolveURLRequire(c.baseURL, req.Path)
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: %w", prog.SourceFile, s.StmtLine(), err)
+			}
+			req.Path = u
+		}
+
 		// Handle "with" clause: load specific sub-modules from a directory
+		if len(req.With) > 0 && remote.IsURLRequire(req.Path) {
+			return nil, fmt.Errorf("%s:%d: require with 'with' is not supported for URLs — require each file by its URL", prog.SourceFile, req.StmtLine())
+		}
 		if len(req.With) > 0 {
 			var baseDir string
 			if remote.IsRemoteRequire(req.Path) {
@@ -770,6 +886,9 @@ func (c *Compiler) resolveRequires(prog *ast.Program) (*ast.Program, error) {
 					localDir = filepath.Join(c.BaseDir, localDir)
 				}
 				info, err := os.Stat(localDir)
+				if err != nil && req.Optional && errors.Is(err, os.ErrNotExist) {
+					continue // require_optional: missing directory is skipped
+				}
 				if err != nil || !info.IsDir() {
 					return nil, fmt.Errorf("%s:%d: require with 'with' requires a directory, but %q is not a directory", prog.SourceFile, req.StmtLine(), req.Path)
 				}
@@ -811,6 +930,9 @@ func (c *Compiler) resolveRequires(prog *ast.Program) (*ast.Program, error) {
 					return nil, fmt.Errorf("resolving require path %s/%s: %w", req.Path, modName, err)
 				}
 
+				if c.recordRequire(absPath, displayPath(absPath)) {
+					continue
+				}
 				if _, alreadyLoaded := c.loaded[absPath]; alreadyLoaded {
 					continue
 				}
@@ -824,20 +946,24 @@ func (c *Compiler) resolveRequires(prog *ast.Program) (*ast.Program, error) {
 					return nil, fmt.Errorf("in require %q (with %s): %w", req.Path, modName, err)
 				}
 
-				oldBase := c.BaseDir
+				reqSourceFile := reqProg.SourceFile
+
+				oldBase, oldURL := c.BaseDir, c.baseURL
 				c.BaseDir = filepath.Dir(absPath)
+				c.baseURL = ""
 				c.requireStack = append(c.requireStack, absPath)
 				reqProg, err = c.resolveRequires(reqProg)
 				c.requireStack = c.requireStack[:len(c.requireStack)-1]
-				c.BaseDir = oldBase
+				c.BaseDir, c.baseURL = oldBase, oldURL
 				if err != nil {
 					return nil, err
 				}
+				StructFieldCheck(reqSourceFile, os.Stderr).Check(reqProg)
 
 				ns := modName
 				modSourceFile := reqProg.SourceFile
 
-				if c.imports[ns] {
+				if _, ok := c.imports[ns]; ok {
 					return nil, fmt.Errorf("%s:%d: require namespace %q (from with) conflicts with use'd stdlib module", prog.SourceFile, req.StmtLine(), ns)
 				}
 				for pkg, alias := range c.goImports {
@@ -853,7 +979,7 @@ func (c *Compiler) resolveRequires(prog *ast.Program) (*ast.Program, error) {
 				for _, rs := range reqProg.Statements {
 					switch st := rs.(type) {
 					case *ast.UseStmt:
-						c.imports[st.Module] = true
+						c.imports[st.Namespace()] = st.Module
 						resolved = append(resolved, st)
 					case *ast.ImportStmt:
 						if _, exists := c.goImports[st.Package]; !exists {
@@ -895,7 +1021,17 @@ func (c *Compiler) resolveRequires(prog *ast.Program) (*ast.Program, error) {
 
 		var absPath string
 
-		if remote.IsRemoteRequire(req.Path) {
+		if remote.IsURLRequire(req.Path) {
+			// URL require: download a single file into the cache
+			cached, err := remote.FetchURL(req.Path)
+			if err != nil {
+				if req.Optional && errors.Is(err, os.ErrNotExist) {
+					continue // require_optional: a missing URL is skipped
+				}
+				return nil, fmt.Errorf("%s:%d: %w", prog.SourceFile, s.StmtLine(), err)
+			}
+			absPath = cached
+		} else if remote.IsRemoteRequire(req.Path) {
 			// Remote require: fetch from git and resolve entry point
 			entryPoint, cacheDir, err := c.resolver.ResolveModuleOrDir(req.Path)
 			if err != nil {
@@ -955,7 +1091,9 @@ func (c *Compiler) resolveRequires(prog *ast.Program) (*ast.Program, error) {
 		// Determine namespace early (needed for dedup check)
 		ns := req.Alias
 		if ns == "" {
-			if remote.IsRemoteRequire(req.Path) {
+			if remote.IsURLRequire(req.Path) {
+				ns = remote.URLNamespace(req.Path)
+			} else if remote.IsRemoteRequire(req.Path) {
 				ns, _ = remote.DefaultNamespace(req.Path)
 			} else {
 				base := filepath.Base(req.Path)
@@ -963,6 +1101,14 @@ func (c *Compiler) resolveRequires(prog *ast.Program) (*ast.Program, error) {
 			}
 		}
 
+		name := displayPath(absPath)
+		if remote.IsRemoteRequire(req.Path) || remote.IsURLRequire(req.Path) {
+			name = req.Path
+		}
+		if c.recordRequire(absPath, name) {
+			continue
+		}
+
 		if prevNS, alreadyLoaded := c.loaded[absPath]; alreadyLoaded {
 			if ns == prevNS {
 				continue // Already loaded with same namespace
@@ -982,6 +1128,11 @@ func (c *Compiler) resolveRequires(prog *ast.Program) (*ast.Program, error) {
 		reqProg, err := c.parseFile(absPath, displayPath(absPath))
 		if err != nil {
 			if errors.Is(err, os.ErrNotExist) {
+				if req.Optional {
+					// require_optional: a missing file leaves its functions undefined.
+					delete(c.loaded, absPath)
+					continue
+				}
 				return nil, fmt.Errorf("cannot find required file %q (looked for %s)", req.Path, displayPath(absPath))
 			}
 			return nil, fmt.Errorf("in require %q: %w", req.Path, err)
@@ -990,18 +1141,25 @@ func (c *Compiler) resolveRequires(prog *ast.Program) (*ast.Program, error) {
 		reqSourceFile := reqProg.SourceFile // save before resolveRequires replaces reqProg
 
 		// Recursively resolve requires in the required file
-		oldBase := c.BaseDir
+		oldBase, oldURL := c.BaseDir, c.baseURL
 		c.BaseDir = filepath.Dir(absPath)
+		c.baseURL = ""
+		if remote.IsURLRequire(req.Path) {
+			c.baseURL = req.Path
+		}
 		c.requireStack = append(c.requireStack, absPath)
 		reqProg, err = c.resolveRequires(reqProg)
 		c.requireStack = c.requireStack[:len(c.requireStack)-1]
-		c.BaseDir = oldBase
+		c.BaseDir, c.baseURL = oldBase, oldURL
 		if err != nil {
 			return nil, err
 		}
+		// The file's own functions are not namespaced yet, so struct
+		// methods can still be matched to their struct.
+		StructFieldCheck(reqSourceFile, os.Stderr).Check(reqProg)
 
 		// Reject require namespace that conflicts with a use'd Rugo module
-		if c.imports[ns] {
+		if _, ok := c.imports[ns]; ok {
 			return nil, fmt.Errorf("%s:%d: require namespace %q conflicts with use'd stdlib module", prog.SourceFile, req.StmtLine(), ns)
 		}
 		// Reject require namespace that conflicts with an import'd Go bridge
@@ -1023,7 +1181,7 @@ func (c *Compiler) resolveRequires(prog *ast.Program) (*ast.Program, error) {
 			case *ast.SandboxStmt:
 				return nil, fmt.Errorf("%s:%d: sandbox directive not allowed in required files — it must be in the main entry file", reqSourceFile, st.SourceLine)
 			case *ast.UseStmt:
-				c.imports[st.Module] = true
+				c.imports[st.Namespace()] = st.Module
 				resolved = append(resolved, st)
 			case *ast.ImportStmt:
 				if _, exists := c.goImports[st.Package]; !exists {
@@ -1064,7 +1222,7 @@ func (c *Compiler) resolveRequires(prog *ast.Program) (*ast.Program, error) {
 		}
 	}
 
-	return &ast.Program{Statements: resolved}, nil
+	return &ast.Program{Statements: resolved, Structs: prog.Structs}, nil
 }
 
 // validateSandboxPlacement checks sandbox placement rules on the original
@@ -1104,10 +1262,12 @@ func displayPath(absPath string) string {
 }
 
 // firstParseError extracts only the first error from a parser error list
-// and reformats it for human readability.
-func firstParseError(err error) error {
+// and reformats it for human readability. The error position is mapped
+// from preprocessed to original source through sm.
+func firstParseError(err error, sm sourceMap) error {
 	if el, ok := err.(scanner.ErrList); ok && len(el) > 0 {
 		e := el[0]
+		e.Pos.Line, e.Pos.Column = sm.position(e.Pos.Line, e.Pos.Column)
 		msg := formatParseError(e)
 
 		snippetLine := e.Pos.Line
@@ -1186,13 +1346,81 @@ func sourceSnippet(filename string, line, col int) string {
 	return sb.String()
 }
 
+// unbalancedBlock returns the block imbalance in src that explains the
+// parse error err, or nil when the error has another cause. An unclosed
+// block shows up as an error at EOF and a stray `end` as an error on that
+// same `end`.
+func unbalancedBlock(err error, src string) *preprocess.BlockError {
+	el, ok := err.(scanner.ErrList)
+	if !ok || len(el) == 0 {
+		return nil
+	}
+	be := preprocess.CheckBlocks(src)
+	if be == nil {
+		return nil
+	}
+	msg := el[0].Err.Error()
+	if be.Keyword == "end" {
+		if el[0].Pos.Line == be.Line && el[0].Pos.Column == be.Col {
+			return be
+		}
+		return nil
+	}
+	if strings.Contains(msg, "[EOF]") {
+		return be
+	}
+	return nil
+}
+
+// sourceMap translates positions in preprocessed source back to the
+// original source it was produced from.
+type sourceMap struct {
+	src, cleaned string
+	lineMap      []int
+}
+
+// position maps a 1-based line and column of the preprocessed source to
+// the original source. See preprocess.OriginalColumn for how columns on
+// rewritten lines are recovered.
+func (m sourceMap) position(line, col int) (int, int) {
+	return preprocess.OriginalPosition(m.src, m.cleaned, m.lineMap, line, col)
+}
+
+// blockError formats an unbalanced block reported by preprocess.CheckBlocks
+// at its original source position, with a snippet pointing at the keyword.
+func blockError(be *preprocess.BlockError, displayName string, sm sourceMap) error {
+	orig := *be
+	orig.Line, orig.Col = sm.position(be.Line, be.Col)
+	msg := fmt.Sprintf("%s:%d:%d: %s", displayName, orig.Line, orig.Col, orig.Error())
+	if snippet := sourceSnippet(displayName, orig.Line, orig.Col); snippet != "" {
+		msg += "\n" + snippet
+	}
+	return fmt.Errorf("%s", msg)
+}
+
+// walkError formats a user error reported by the AST walker as
+// `file:line:col: message` at its original source position, with a
+// snippet pointing at the offending token. Errors without a position
+// are reported against the file alone.
+func walkError(ue *ast.UserError, displayName string, sm sourceMap) error {
+	if ue.Line <= 0 {
+		return fmt.Errorf("%s: %s", displayName, ue.Msg)
+	}
+	line, col := sm.position(ue.Line, ue.Col)
+	msg := fmt.Sprintf("%s:%d:%d: %s", displayName, line, col, ue.Msg)
+	if snippet := sourceSnippet(displayName, line, col); snippet != "" {
+		msg += "\n" + snippet
+	}
+	return fmt.Errorf("%s", msg)
+}
+
 // validateSourceChars checks that the preprocessed source contains no
 // non-ASCII characters outside string literals. The parser's generated
 // scanner cannot handle multi-byte UTF-8 characters in code positions
 // (identifiers, operators, etc.) and panics with a raw Go bounds error.
 // This pre-validation catches the problem early and produces a friendly
 // error with file, line, and column information.
-func validateSourceChars(src, displayName string, lineMap []int) error {
+func validateSourceChars(src, displayName string, sm sourceMap) error {
 	inDouble := false
 	inSingle := false
 	escaped := false
@@ -1227,13 +1455,10 @@ func validateSourceChars(src, displayName string, lineMap []int) error {
 		}
 		if !inDouble && !inSingle && ch >= 0x80 {
 			r, _ := utf8.DecodeRuneInString(src[i:])
-			origLine := line
-			if lineMap != nil && line > 0 && line <= len(lineMap) {
-				origLine = lineMap[line-1]
-			}
+			origLine, origCol := sm.position(line, col)
 			msg := fmt.Sprintf("%s:%d:%d: invalid character %q (U+%04X) — non-ASCII characters are only allowed inside strings",
-				displayName, origLine, col, r, r)
-			if snippet := sourceSnippet(displayName, origLine, col); snippet != "" {
+				displayName, origLine, origCol, r, r)
+			if snippet := sourceSnippet(displayName, origLine, origCol); snippet != "" {
 				msg += snippet
 			}
 			return fmt.Errorf("%s", msg)
@@ -1275,10 +1500,6 @@ func formatParseError(e scanner.ErrWithPosition) string {
 
 		// Special case: EOF with expected "end" — unclosed block
 		if strings.Contains(beforeExpected, "[EOF]") && isEndExpectedSet(expectedPart) {
-			blockType := detectUnclosedBlock(e.Pos.Filename)
-			if blockType != "" {
-				return prefix + "unexpected end of file — unclosed " + blockType
-			}
 			return prefix + "unexpected end of file — expected \"end\" (unclosed block)"
 		}
 
@@ -1339,6 +1560,18 @@ func parseTokenDescription(s string) string {
 		if tokenVal == "__shell__" || tokenVal == "__capture__" || tokenVal == "__pipe_shell__" {
 			return "expression"
 		}
+		if tokenVal == "__defined__" {
+			return "\"defined?\""
+		}
+		if tokenVal == "__with__" {
+			return "\"with\""
+		}
+		if tokenVal == "__range__" {
+			return "range"
+		}
+		if tokenVal == "__array_concat__" || tokenVal == "__hash_merge__" {
+			return "\"...\""
+		}
 		if tokenVal != "" {
 			return "\"" + tokenVal + "\""
 		}
@@ -1376,10 +1609,10 @@ func parseTokenDescription(s string) string {
 // recognises as keywords (and therefore cannot be used as identifiers).
 var parserKeywords = map[string]bool{
 	"as": true, "bench": true, "break": true, "def": true, "else": true,
-	"elsif": true, "end": true, "false": true, "fn": true, "for": true,
+	"elsif": true, "end": true, "ensure": true, "false": true, "fn": true, "for": true,
 	"if": true, "import": true, "in": true, "next": true, "nil": true,
 	"or": true, "parallel": true, "rats": true, "require": true,
-	"return": true, "sandbox": true, "spawn": true, "true": true,
+	"require_optional": true, "return": true, "sandbox": true, "spawn": true, "true": true,
 	"try": true, "use": true, "while": true, "with": true, "do": true,
 }
 
@@ -1599,44 +1832,6 @@ func findBlockMissingName(filename string, errorLine int) string {
 	return ""
 }
 
-// detectUnclosedBlock reads the source file and returns a description of
-// the last unmatched block-opening keyword (e.g., `"def" block`).
-func detectUnclosedBlock(filename string) string {
-	data, err := os.ReadFile(filename)
-	if err != nil {
-		return ""
-	}
-	lines := strings.Split(string(data), "\n")
-	type blockInfo struct {
-		keyword string
-		line    int
-	}
-	var stack []blockInfo
-	blockOpeners := map[string]bool{
-		"def": true, "if": true, "while": true, "for": true,
-		"rats": true, "bench": true, "fn": true,
-		"spawn": true, "parallel": true, "try": true,
-	}
-	for i, line := range lines {
-		trimmed := strings.TrimSpace(line)
-		// Get first word
-		word := trimmed
-		if idx := strings.IndexAny(trimmed, " \t("); idx >= 0 {
-			word = trimmed[:idx]
-		}
-		if blockOpeners[word] {
-			stack = append(stack, blockInfo{keyword: word, line: i + 1})
-		} else if word == "end" && len(stack) > 0 {
-			stack = stack[:len(stack)-1]
-		}
-	}
-	if len(stack) > 0 {
-		b := stack[len(stack)-1]
-		return fmt.Sprintf("\"%s\" block (opened at line %d)", b.keyword, b.line)
-	}
-	return ""
-}
-
 // translateBuildError post-processes `go build` stderr to translate
 // Go compiler errors into Rugo-friendly messages.
 func translateBuildError(stderr, sourceFile string) error {
@@ -1737,6 +1932,9 @@ func validateTopLevelOnly(stmts []ast.Statement, sourceFile string) error {
 			if err := rejectNestedImports(st.Body, sourceFile); err != nil {
 				return err
 			}
+			if err := rejectNestedImports(st.ElseBody, sourceFile); err != nil {
+				return err
+			}
 		case *ast.ForStmt:
 			if err := rejectNestedImports(st.Body, sourceFile); err != nil {
 				return err
@@ -1786,6 +1984,9 @@ func rejectNestedImports(stmts []ast.Statement, sourceFile string) error {
 			if err := rejectNestedImports(st.Body, sourceFile); err != nil {
 				return err
 			}
+			if err := rejectNestedImports(st.ElseBody, sourceFile); err != nil {
+				return err
+			}
 		case *ast.ForStmt:
 			if err := rejectNestedImports(st.Body, sourceFile); err != nil {
 				return err
@@ -1,7 +1,10 @@
 package compiler
 
 import (
+	"errors"
 	"github.com/rubiojr/rugo/preprocess"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"strings"
@@ -21,7 +24,7 @@ import (
 func compileToGo(t *testing.T, src string) string {
 	t.Helper()
 	prog := parseAndWalk(t, src)
-	goSrc, err := generate(prog, "test.rugo", false, nil, false)
+	goSrc, err := generate(prog, "test.rugo", false, nil, nil, false, false)
 	if err != nil {
 		t.Fatalf("Generate error: %v", err)
 	}
@@ -150,6 +153,9 @@ func TestHasInterpolation(t *testing.T) {
 		{"hello #{name}", true},
 		{"#{a} and #{b}", true},
 		{"no interpolation #", false},
+		{`\#{name}`, false},
+		{`\#{a} and #{b}`, true},
+		{`\\#{name}`, true},
 	}
 	for _, tt := range tests {
 		if preprocess.HasInterpolation(tt.input) != tt.expect {
@@ -171,6 +177,28 @@ func TestProcessInterpolation(t *testing.T) {
 	}
 }
 
+func TestProcessInterpolationEscapes(t *testing.T) {
+	tests := []struct {
+		input  string
+		format string
+		exprs  []string
+	}{
+		{`\#{x} at start`, "#{x} at start", nil},
+		{`in \#{the} middle`, "in #{the} middle", nil},
+		{`\#{x}#{x}`, "#{x}%v", []string{"x"}},
+		{`#{x}\#{x}`, "%v#{x}", []string{"x"}},
+		{`\\#{x}`, `\%v`, []string{"x"}},
+		{`\\\#{x}`, `\#{x}`, nil},
+		{`a \ b`, `a \ b`, nil},
+	}
+	for _, tt := range tests {
+		format, exprs, err := preprocess.ProcessInterpolation(tt.input)
+		require.NoError(t, err, tt.input)
+		assert.Equal(t, tt.format, format, tt.input)
+		assert.Equal(t, tt.exprs, exprs, tt.input)
+	}
+}
+
 // --- Code Generation Tests ---
 
 func TestGenHelloWorld(t *testing.T) {
@@ -223,6 +251,54 @@ func TestGenReturn(t *testing.T) {
 	}
 }
 
+func TestGenTypedModuleReturn(t *testing.T) {
+	// str.capitalize declares a string return, so the def stays typed.
+	src := compileToGo(t, "use \"str\"\ndef shout(s)\nreturn str.capitalize(s) + \"!\"\nend\nshout(\"hi\")")
+	if !strings.Contains(src, "func rugofn_shout(s string) string {") {
+		t.Errorf("expected typed function:\n%s", src)
+	}
+	if !strings.Contains(src, "rugo_str_capitalize(s).(string)") {
+		t.Errorf("expected string assertion on module call:\n%s", src)
+	}
+}
+
+func TestGenTypedTrimAffixReturn(t *testing.T) {
+	for _, fn := range []string{"trim_prefix", "trim_suffix"} {
+		src := compileToGo(t, "use \"str\"\ndef strip(s)\nreturn str."+fn+"(s, \"x\")\nend\nstrip(\"xax\")")
+		assert.Contains(t, src, "func rugofn_strip(s string) string {", fn)
+		assert.Contains(t, src, "rugo_str_"+fn+"(s, \"x\").(string)", fn)
+	}
+}
+
+func TestGenLambdaReturn(t *testing.T) {
+	// A return inside fn belongs to the lambda: the enclosing def keeps its
+	// inferred int return type and the string return stays in the closure.
+	src := compileToGo(t, "def pick(x)\nf = fn(y)\nreturn \"early\"\nend\nf(x)\nreturn 1\nend\npick(2)")
+	if !strings.Contains(src, "func rugofn_pick(x int) int {") {
+		t.Errorf("expected typed outer function:\n%s", src)
+	}
+	if !strings.Contains(src, `return "early"`) {
+		t.Errorf("expected lambda return inside closure:\n%s", src)
+	}
+}
+
+func TestGenSandboxPathRules(t *testing.T) {
+	src := compileToGo(t, "sandbox ro: \"/etc\", rw: [\"/tmp\"], rwx: \"$HOME/data\"\nputs(1)")
+	for _, want := range []string{
+		"rugo_sandbox_check = rugo_sandbox_check_rules",
+		`rugo_sandbox_allow(os.ExpandEnv("/etc"), false)`,
+		`rugo_sandbox_allow(os.ExpandEnv("/tmp"), true)`,
+		`rugo_sandbox_allow(os.ExpandEnv("$HOME/data"), true)`,
+	} {
+		if !strings.Contains(src, want) {
+			t.Errorf("expected %q in output:\n%s", want, src)
+		}
+	}
+	if plain := compileToGo(t, "puts(1)"); strings.Contains(plain, "rugo_sandbox_check_rules") {
+		t.Error("path rules should only be installed by a sandbox directive")
+	}
+}
+
 func TestGenArithmetic(t *testing.T) {
 	src := compileToGo(t, "x = 1 + 2")
 	// With type inference, typed int arithmetic uses native ops
@@ -239,6 +315,182 @@ func TestGenComparison(t *testing.T) {
 	}
 }
 
+func TestGenSpaceship(t *testing.T) {
+	src := compileToGo(t, "x = 1 <=> 2")
+	if !strings.Contains(src, "rugo_spaceship(") {
+		t.Errorf("expected rugo_spaceship call:\n%s", src)
+	}
+}
+
+func TestGenBitwise(t *testing.T) {
+	src := compileToGo(t, "a = 12\nb = a & 10\nc = a | b\nd = a ^ 1")
+	for _, want := range []string{"(a & 10)", "(a | b)", "(a ^ 1)"} {
+		if !strings.Contains(src, want) {
+			t.Errorf("expected typed %q:\n%s", want, src)
+		}
+	}
+	src = compileToGo(t, "def f(x)\nreturn x & 1\nend\ny = 1 << 3\nz = y >> 1")
+	for _, want := range []string{"rugo_band(", "rugo_shl(", "rugo_shr("} {
+		if !strings.Contains(src, want) {
+			t.Errorf("expected %s call:\n%s", want, src)
+		}
+	}
+}
+
+func TestGenStringFormat(t *testing.T) {
+	src := compileToGo(t, "n = 5\ns = \"%d items\" % n\nm = n % 2")
+	if !strings.Contains(src, "rugo_mod(") || !strings.Contains(src, "func rugo_format(") {
+		t.Errorf("expected string %% to go through rugo_mod/rugo_format:\n%s", src)
+	}
+	if !strings.Contains(src, "(n % 2)") {
+		t.Errorf("expected typed integer modulo:\n%s", src)
+	}
+}
+
+func TestStructFieldCheck(t *testing.T) {
+	src := `struct Dog
+  name
+end
+def Dog.bark()
+  self.sound = "woof"
+  if self.nmae == nil
+    return self.sound + self.nmae
+  end
+  return self.name + self.__type__
+end
+`
+	prog, err := (&Compiler{}).ParseSource(src, "dog.rugo")
+	require.NoError(t, err)
+	var out strings.Builder
+	require.NoError(t, StructFieldCheck("dog.rugo", &out).Check(prog))
+	assert.Equal(t, "warning: dog.rugo:6: `self.nmae` is not a field of struct Dog\n", out.String())
+}
+
+func TestUnusedVarCheck(t *testing.T) {
+	src := `LIMIT = 3
+count = 0
+shared = 1
+def read_shared()
+  return shared
+end
+def work(a)
+  tmp = a * 2
+  seen = 0
+  seen = seen + 1
+  _ignored = 1
+  name = "x"
+  r = try a.upcase() or "none"
+  puts "#{name} #{r}"
+end
+`
+	prog, err := (&Compiler{}).ParseSource(src, "w.rugo")
+	require.NoError(t, err)
+	var out strings.Builder
+	require.NoError(t, UnusedVarCheck("w.rugo", &out).Check(prog))
+	assert.Equal(t, "warning: w.rugo:2: variable `count` is assigned but never used\n"+
+		"warning: w.rugo:8: variable `tmp` is assigned but never used\n"+
+		"warning: w.rugo:9: variable `seen` is assigned but never used\n", out.String())
+}
+
+func TestNamespaceShadowCheck(t *testing.T) {
+	src := `use "json"
+import "path/filepath" as fp
+def encode(json)
+  return json
+end
+for fp in [1]
+  puts fp
+end
+apply = fn(json) json end
+data = json.encode({})
+json = 1
+`
+	prog, err := (&Compiler{}).ParseSource(src, "s.rugo")
+	require.NoError(t, err)
+	var out strings.Builder
+	require.NoError(t, NamespaceShadowCheck("s.rugo", &out, false).Check(prog))
+	assert.Equal(t, "warning: s.rugo:3: parameter `json` shadows the namespace from use \"json\" — json.func() calls in its scope go to the parameter\n"+
+		"warning: s.rugo:6: loop variable `fp` shadows the namespace from import \"path/filepath\" — fp.func() calls in its scope go to the loop variable\n"+
+		"warning: s.rugo:9: parameter `json` shadows the namespace from use \"json\" — json.func() calls in its scope go to the parameter\n"+
+		"warning: s.rugo:11: variable `json` shadows the namespace from use \"json\" — json.func() calls in its scope go to the variable\n", out.String())
+
+	out.Reset()
+	err = NamespaceShadowCheck("s.rugo", &out, true).Check(prog)
+	assert.EqualError(t, err, "s.rugo:3: parameter `json` shadows the namespace from use \"json\" — json.func() calls in its scope go to the parameter")
+	assert.Empty(t, out.String())
+}
+
+func TestGenStructComparator(t *testing.T) {
+	// def Point.<=>(other) as rewritten by ExpandStructDefs.
+	src := compileToGo(t, "def __cmp_Point(self, other)\nreturn self.x <=> other.x\nend")
+	if !strings.Contains(src, `rugo_comparators["Point"] = func(a, b interface{}) interface{} { return rugofn___cmp_Point(a, b) }`) {
+		t.Errorf("expected Point comparator registration:\n%s", src)
+	}
+}
+
+func TestGenParallelMap(t *testing.T) {
+	src := compileToGo(t, "x = parallel_map([1, 2], fn(n) n * 2 end)")
+	if !strings.Contains(src, "rugo_parallel_map(") {
+		t.Errorf("expected rugo_parallel_map call:\n%s", src)
+	}
+	if !strings.Contains(src, "func rugo_parallel_map(") || !strings.Contains(src, `"sync"`) {
+		t.Errorf("expected parallel map runtime and sync import:\n%s", src)
+	}
+	if src := compileToGo(t, "x = 1"); strings.Contains(src, "func rugo_parallel_map(") {
+		t.Errorf("parallel map runtime emitted without a parallel_map call")
+	}
+}
+
+func TestGenParallelMapArity(t *testing.T) {
+	prog := parseAndWalk(t, "x = parallel_map([1])")
+	_, err := generate(prog, "test.rugo", false, nil, nil, false, false)
+	if err == nil || !strings.Contains(err.Error(), "parallel_map expects 2 or 3 arguments, got 1") {
+		t.Errorf("expected arity error, got %v", err)
+	}
+}
+
+func TestGenModuleArity(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{`use "str"` + "\n" + `x = str.split("a,b")`, "str.split() takes 2 arguments but 1 was given"},
+		{`use "str"` + "\n" + `x = str.upper("a", "b")`, "str.upper() takes 1 argument but 2 were given"},
+		{`use "str"` + "\n" + `x = str.pad_left("a", 3, "-", "x")`, "str.pad_left() takes 2 to 3 arguments but 4 were given"},
+		{`use "str"` + "\n" + `x = str.format()`, "str.format() takes at least 1 argument but none were given"},
+	}
+	for _, tt := range tests {
+		prog := parseAndWalk(t, tt.src)
+		_, err := generate(prog, "test.rugo", false, nil, nil, false, false)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: expected %q, got %v", tt.src, tt.want, err)
+		}
+	}
+	compileToGo(t, `use "str"`+"\n"+`x = str.pad_left("a", 3, "-")`)
+	compileToGo(t, `use "str"`+"\n"+`x = str.format("{} {}", 1, 2)`)
+}
+
+func TestGenReportsErrorsFromEachFunction(t *testing.T) {
+	src := "def add(a, b)\nreturn a + b\nend\ndef one()\nreturn add(1)\nend\ndef two()\nreturn add(1, 2, 3)\nend\nx = add(5)\n"
+	prog := parseAndWalk(t, src)
+	_, err := generate(prog, "test.rugo", false, nil, nil, false, false)
+	if err == nil {
+		t.Fatal("expected errors")
+	}
+	var multi interface{ Unwrap() []error }
+	if !errors.As(err, &multi) || len(multi.Unwrap()) != 3 {
+		t.Fatalf("expected 3 joined errors, got %v", err)
+	}
+	for _, want := range []string{
+		"add() takes 2 arguments but 1 was given",
+		"add() takes 2 arguments but 3 were given",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in:\n%v", want, err)
+		}
+	}
+}
+
 func TestGenArray(t *testing.T) {
 	src := compileToGo(t, "x = [1, 2, 3]")
 	if !strings.Contains(src, "[]interface{}{") {
@@ -248,8 +500,8 @@ func TestGenArray(t *testing.T) {
 
 func TestGenHash(t *testing.T) {
 	src := compileToGo(t, `x = {"a" => 1}`)
-	if !strings.Contains(src, "map[interface{}]interface{}{") {
-		t.Errorf("expected map literal:\n%s", src)
+	if !strings.Contains(src, `rugo_hash_lit("a", 1)`) {
+		t.Errorf("expected hash literal:\n%s", src)
 	}
 }
 
@@ -395,6 +647,137 @@ func TestCompilerDuplicateRequire(t *testing.T) {
 	}
 }
 
+func TestCompilerRequireOptional(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	helperFile := filepath.Join(tmpDir, "plugin.rugo")
+	os.WriteFile(helperFile, []byte("def run()\nreturn 1\nend\n"), 0644)
+
+	mainFile := filepath.Join(tmpDir, "main.rugo")
+	os.WriteFile(mainFile, []byte("require_optional \"plugin\"\nif defined?(\"plugin.run\")\nputs(plugin.run())\nend\n"), 0644)
+
+	c := &Compiler{}
+	result, err := c.Compile(mainFile)
+	if err != nil {
+		t.Fatalf("Compile error: %v", err)
+	}
+	if !strings.Contains(result.GoSource, "rugons_plugin_run()") {
+		t.Errorf("expected guarded plugin call in output:\n%s", result.GoSource)
+	}
+}
+
+func TestCompilerRequireOptionalMissing(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	mainFile := filepath.Join(tmpDir, "main.rugo")
+	os.WriteFile(mainFile, []byte("require_optional \"plugin\"\nif defined?(\"plugin.run\")\nputs(plugin.run())\nelse\nputs(\"no plugin\")\nend\n"), 0644)
+
+	c := &Compiler{}
+	result, err := c.Compile(mainFile)
+	if err != nil {
+		t.Fatalf("Compile error: %v", err)
+	}
+	if strings.Contains(result.GoSource, "rugons_plugin_run") {
+		t.Errorf("guarded call to missing plugin should be dropped:\n%s", result.GoSource)
+	}
+}
+
+func TestCompilerRequireURL(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/str-helpers.rugo" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("def shout(s)\nreturn s + \"!\"\nend\n"))
+	}))
+	defer srv.Close()
+	// URL requires are https-only; trust the test server's certificate.
+	origTransport := http.DefaultTransport
+	http.DefaultTransport = srv.Client().Transport
+	defer func() { http.DefaultTransport = origTransport }()
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	tmpDir := t.TempDir()
+	mainFile := filepath.Join(tmpDir, "main.rugo")
+	src := "require \"" + srv.URL + "/str-helpers.rugo\"\nputs(str_helpers.shout(\"hi\"))\n"
+	os.WriteFile(mainFile, []byte(src), 0644)
+
+	c := &Compiler{}
+	result, err := c.Compile(mainFile)
+	if err != nil {
+		t.Fatalf("Compile error: %v", err)
+	}
+	if !strings.Contains(result.GoSource, "rugons_str_helpers_shout(") {
+		t.Errorf("expected URL require namespaced by file name:\n%s", result.GoSource)
+	}
+
+	os.WriteFile(mainFile, []byte("require_optional \""+srv.URL+"/missing.rugo\"\nputs(\"ok\")\n"), 0644)
+	if _, err := c.Compile(mainFile); err != nil {
+		t.Errorf("require_optional of a missing URL should be skipped: %v", err)
+	}
+
+	os.WriteFile(mainFile, []byte("require \""+srv.URL+"/missing.rugo\"\n"), 0644)
+	_, err = c.Compile(mainFile)
+	if err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("expected 404 error, got %v", err)
+	}
+}
+
+func TestCompilerRequireURLRelative(t *testing.T) {
+	files := map[string]string{
+		"/lib/main.rugo":    "require \"helpers\"\ndef greet(s)\nreturn helpers.shout(s)\nend\n",
+		"/lib/helpers.rugo": "def shout(s)\nreturn s + \"!\"\nend\n",
+		"/lib/escape.rugo":  "require \"/etc/lib\"\n",
+	}
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(body))
+	}))
+	defer srv.Close()
+	origTransport := http.DefaultTransport
+	http.DefaultTransport = srv.Client().Transport
+	defer func() { http.DefaultTransport = origTransport }()
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	tmpDir := t.TempDir()
+	// A local helpers.rugo next to main.rugo must not be picked up.
+	os.WriteFile(filepath.Join(tmpDir, "helpers.rugo"), []byte("def shout(s)\nreturn s\nend\n"), 0644)
+	mainFile := filepath.Join(tmpDir, "main.rugo")
+	os.WriteFile(mainFile, []byte("require \""+srv.URL+"/lib/main.rugo\"\nputs(main.greet(\"hi\"))\n"), 0644)
+
+	c := &Compiler{}
+	result, err := c.Compile(mainFile)
+	if err != nil {
+		t.Fatalf("Compile error: %v", err)
+	}
+	if !strings.Contains(result.GoSource, "rugons_helpers_shout(") || !strings.Contains(result.GoSource, `"!"`) {
+		t.Errorf("expected helpers fetched from next to the required URL:\n%s", result.GoSource)
+	}
+
+	os.WriteFile(mainFile, []byte("require \""+srv.URL+"/lib/escape.rugo\"\n"), 0644)
+	_, err = c.Compile(mainFile)
+	if err == nil || !strings.Contains(err.Error(), "can only require relative paths") {
+		t.Errorf("expected absolute require in a URL file to be rejected, got %v", err)
+	}
+}
+
+func TestCompilerRequireMissingStillFails(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	mainFile := filepath.Join(tmpDir, "main.rugo")
+	os.WriteFile(mainFile, []byte("require \"plugin\"\n"), 0644)
+
+	c := &Compiler{}
+	_, err := c.Compile(mainFile)
+	if err == nil || !strings.Contains(err.Error(), "cannot find required file") {
+		t.Fatalf("expected missing require error, got %v", err)
+	}
+}
+
 func TestCompilerComments(t *testing.T) {
 	c := &Compiler{}
 	tmpDir := t.TempDir()
@@ -701,6 +1084,25 @@ func TestPreprocessShellWithPipes(t *testing.T) {
 	}
 }
 
+func TestPreprocessBitwiseOrIsNotPipe(t *testing.T) {
+	for _, tt := range []struct{ input, expect string }{
+		{"a = 1\nb = 2\nx = a | b", "x = a | b"},
+		{"a = 1\nx = a | 4", "x = a | 4"},
+		{"a = 1\nx = a | (1 + 2)", "x = a | (1 + 2)"},
+		{"a = 1\nputs a | 2", "puts(a | 2)"},
+		{"x = len(\"hi\") | puts", "x = puts(len(\"hi\"))"},
+		{`"hi" | tr a-z A-Z | puts`, `puts(__pipe_shell__("tr a-z A-Z", "hi"))`},
+	} {
+		result, _, err := preprocess.Preprocess(tt.input, nil)
+		if err != nil {
+			t.Fatalf("preprocess.Preprocess(%q): %v", tt.input, err)
+		}
+		if !strings.Contains(result, tt.expect) {
+			t.Errorf("preprocess.Preprocess(%q) = %q, want %q", tt.input, result, tt.expect)
+		}
+	}
+}
+
 func TestPreprocessPreservesIndent(t *testing.T) {
 	input := `  ls -la`
 	result, _, _ := preprocess.Preprocess(input, nil)
@@ -802,7 +1204,7 @@ func TestPreprocessShellBeforeDefFuncAfter(t *testing.T) {
 func TestGenDotCall(t *testing.T) {
 	// Unknown ns.func() should compile to rugo_dot_call (runtime dispatch)
 	prog := parseAndWalk(t, `ns.func(1, 2)`)
-	_, err := generate(prog, "test.rugo", false, nil, false)
+	_, err := generate(prog, "test.rugo", false, nil, nil, false, false)
 	if err != nil {
 		t.Errorf("unexpected error for dot call: %v", err)
 	}
@@ -1004,11 +1406,108 @@ func TestGenTryExpr(t *testing.T) {
 	if !strings.Contains(src, "recover()") {
 		t.Errorf("try should use recover():\n%s", src)
 	}
-	if !strings.Contains(src, "fmt.Sprint(e)") {
+	if !strings.Contains(src, "fmt.Sprint(rugo_recovered)") {
 		t.Error("try should convert panic value to string")
 	}
 }
 
+func TestGenTryRethrow(t *testing.T) {
+	src := compileToGo(t, "x = try raise(\"boom\") or e\nrethrow()\nend")
+	if !strings.Contains(src, "rugo_rethrow(rugo_recovered)") {
+		t.Errorf("rethrow should re-panic with the recovered value:\n%s", src)
+	}
+	prog := parseAndWalk(t, "rethrow()\n")
+	_, err := generate(prog, "test.rugo", false, nil, nil, false, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "rethrow can only be used inside a try handler")
+}
+
+func TestGenWhileElse(t *testing.T) {
+	src := compileToGo(t, "i = 0\nwhile i < 3\n  i = i + 1\n__while_else__()\n  puts(\"none\")\nend\n")
+	assert.Contains(t, src, "__while_entered_1 := false")
+	assert.Contains(t, src, "__while_entered_1 = true")
+	assert.Contains(t, src, "if !__while_entered_1 {")
+	assert.NotContains(t, src, "__while_else__")
+
+	if src := compileToGo(t, "i = 0\nwhile i < 3\n  i = i + 1\nend\n"); strings.Contains(src, "__while_entered") {
+		t.Errorf("while without else should not track entry:\n%s", src)
+	}
+}
+
+func TestGenConstantFolding(t *testing.T) {
+	src := compileToGo(t, "secs = 2 * 60 * 60\nratio = 7 / 2.0\nputs(secs, ratio)\n")
+	assert.Contains(t, src, "secs := 7200")
+	assert.Contains(t, src, "ratio := rugo_float(3.5)")
+}
+
+func TestGenRepeat(t *testing.T) {
+	src := compileToGo(t, "sep = \"=\" * 40\nputs(sep)\n")
+	assert.Contains(t, src, `sep := rugo_repeat_string("=", 40)`)
+
+	src = compileToGo(t, "a = [0] * 5\nputs(a)\n")
+	assert.Contains(t, src, "rugo_mul(")
+}
+
+func TestGenTryExprEnsure(t *testing.T) {
+	src := compileToGo(t, "x = try 1 or err\n  0\nensure\n  puts(\"done\")\nend\n")
+	iife := src[strings.Index(src, "x := func() (r interface{})"):]
+	ensure := strings.Index(iife, `rugo_puts(interface{}("done"))`)
+	recover := strings.Index(iife, "recover()")
+	if ensure < 0 || recover < 0 || ensure > recover {
+		t.Errorf("ensure defer should be registered before the recover defer:\n%s", iife)
+	}
+}
+
+func TestGenParallelLimit(t *testing.T) {
+	src := compileToGo(t, "x = parallel __parallel_limit__(2)\n  1\n  2\n  3\nend\n")
+	assert.Contains(t, src, "_sem := make(chan struct{}, _limit)")
+	assert.Equal(t, 3, strings.Count(src, "_sem <- struct{}{}"), "one acquire per branch")
+	assert.Equal(t, 3, strings.Count(src, "defer func() { <-_sem }()"), "one release per branch")
+	assert.NotContains(t, src, "__parallel_limit__")
+
+	if src := compileToGo(t, "x = parallel\n  1\nend\n"); strings.Contains(src, "_sem") {
+		t.Errorf("unbounded parallel should not use a semaphore:\n%s", src)
+	}
+}
+
+func TestGenTrace(t *testing.T) {
+	prog := parseAndWalk(t, "def f(x)\n  x\nend\nputs(f(1))\n")
+	res, err := generate(prog, "test.rugo", false, nil, nil, false, true)
+	require.NoError(t, err)
+	assert.Contains(t, res.GoSource, `rugo_trace("test.rugo", 2)`)
+	assert.Contains(t, res.GoSource, `rugo_trace("test.rugo", 4)`)
+
+	assert.NotContains(t, compileToGo(t, "puts(1)\n"), `rugo_trace("`)
+}
+
+func TestGenMemoFunc(t *testing.T) {
+	src := compileToGo(t, "def add(a, b) __memo__()\n  return a + b\nend\nputs(add(1, 2))\n")
+	assert.Contains(t, src, `rugo_memo_k := rugo_memo_key("rugofn_add", a, b)`)
+	assert.Contains(t, src, "rugo_memo_put(rugo_memo_k, rugo_memo_v)")
+	assert.Contains(t, src, "func rugo_memo_get(")
+	assert.Contains(t, src, `"sync"`)
+	assert.NotContains(t, src, "__memo__")
+
+	src = compileToGo(t, "def add(a, b)\n  return a + b\nend\nputs(add(1, 2))\n")
+	assert.NotContains(t, src, "rugo_memo_")
+}
+
+func TestGenForRangeLiteral(t *testing.T) {
+	src := compileToGo(t, "for i in __range__(0, 3, true)\nputs(i)\nend\n")
+	assert.Contains(t, src, "for rugo_range_i := 0; rugo_range_i < 4; rugo_range_i++ {")
+	assert.NotContains(t, src, "rugo_range_literal(interface{}")
+
+	src = compileToGo(t, "for i in __range__(3, 0, false)\nputs(i)\nend\n")
+	assert.Contains(t, src, "for rugo_range_i := 3; rugo_range_i > 0; rugo_range_i-- {")
+
+	src = compileToGo(t, "n = 5\nfor i in __range__(n, 0, true)\nputs(i)\nend\n")
+	assert.Contains(t, src, "rugo_range_bounds(")
+	assert.Contains(t, src, "rugo_range_i != rugo_range_end")
+
+	src = compileToGo(t, "x = __range__(1, 3, true)\n")
+	assert.Contains(t, src, "rugo_range_literal(interface{}(1), interface{}(3), true)")
+}
+
 func TestGenTryExprSilent(t *testing.T) {
 	// Level 1: try EXPR (preprocessor expands to block form)
 	src := compileToGo(t, `use "os"`+"\n"+`x = try os.exec("ls") or _err`+"\n"+`nil`+"\n"+`end`)
@@ -1365,6 +1864,28 @@ func TestRequireAliasConflictsWithImport(t *testing.T) {
 	assert.Contains(t, err.Error(), "str", "error should mention the conflicting name")
 }
 
+// A use alias frees the module name, so a require namespace may take it.
+func TestUseAliasFreesModuleName(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	os.WriteFile(filepath.Join(tmpDir, "helpers.rugo"), []byte("def upper(s)\nreturn \"CUSTOM\"\nend\n"), 0644)
+
+	mainFile := filepath.Join(tmpDir, "main.rugo")
+	os.WriteFile(mainFile, []byte("use \"str\" as s\nrequire \"helpers\" as \"str\"\nputs(str.upper(\"a\"), s.upper(\"b\"))\n"), 0644)
+
+	c := &Compiler{}
+	result, err := c.Compile(mainFile)
+	require.NoError(t, err)
+	assert.Contains(t, result.GoSource, "rugons_str_upper(")
+	assert.Contains(t, result.GoSource, "rugo_str_upper(")
+}
+
+func TestUseAliasConflicts(t *testing.T) {
+	_, err := (&Compiler{}).CompileString("use \"str\" as s\nuse \"json\" as s\n")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `use namespace "s" is already taken by module "str"`)
+}
+
 // Bug 6013a24: Imports inside required files should be propagated.
 func TestRequiredFileImports(t *testing.T) {
 	tmpDir := t.TempDir()
@@ -1471,6 +1992,11 @@ func TestExpandHashColonSyntax(t *testing.T) {
 		{"tab after colon", "{foo:\t1}", "{\"foo\" =>\t1}"},
 		{"nested hash", `{a: {b: 1}}`, `{"a" => {"b" => 1}}`},
 		{"empty hash unchanged", `{}`, `{}`},
+		{"keyword argument", `f(name: "Ada")`, `f(__kw__("name", "Ada"))`},
+		{"keyword after positional", `f(1, a: g(2), b: 3)`, `f(1, __kw__("a", g(2)), __kw__("b", 3))`},
+		{"hash argument", `f({a: 1})`, `f({"a" => 1})`},
+		{"hash as keyword value", `f(opts: {a: 1})`, `f(__kw__("opts", {"a" => 1}))`},
+		{"paren-free keyword", `f "x", b: 3`, `f "x", __kw__("b", 3)`},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -1485,6 +2011,38 @@ func TestExpandHashColonSyntax(t *testing.T) {
 	}
 }
 
+func TestBindKeywordArgs(t *testing.T) {
+	src := "def f(a, b = 1, c = 2)\nreturn a\nend\nf(c: 3, a: 0)\n"
+	prog, err := (&Compiler{}).ParseSource(src, "test.rugo")
+	require.NoError(t, err)
+	require.NoError(t, bindKeywordArgs(prog, "test.rugo"))
+	goSrc, err := generate(prog, "test.rugo", false, nil, nil, false, false)
+	require.NoError(t, err)
+	assert.Contains(t, goSrc.GoSource, "interface{}(rugo_default_arg{})")
+
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"unknown keyword", "f(a: 1, d: 2)", "test.rugo:4: `f` has no parameter named `d`"},
+		{"duplicate", "f(1, a: 2)", "argument `a` given more than once in call to `f`"},
+		{"missing", "f(b: 2)", "missing argument `a` in call to `f`"},
+		{"positional after keyword", "f(b: 2, 1)", "positional argument after keyword arguments in call to `f`"},
+		{"builtin", "len(a: 1)", "keyword arguments are only supported for functions defined with def, not `len`"},
+		{"outside call", "x = a: 1", "keyword argument `a:` must be an argument of a function call"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prog, err := (&Compiler{}).ParseSource("def f(a, b = 1, c = 2)\nreturn a\nend\n"+tt.src+"\n", "test.rugo")
+			require.NoError(t, err)
+			err = bindKeywordArgs(prog, "test.rugo")
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
+
 func TestExpandHashColonSyntaxRejectsIntegerKeys(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -1693,9 +2251,178 @@ func TestPreprocessPostfixIf(t *testing.T) {
 	}
 }
 
+// --- unless: preprocessor sugar ---
+
+func TestPreprocessUnless(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		expect string
+	}{
+		{
+			"block unless",
+			"unless done\n  puts(\"working\")\nend",
+			"if !(done)\n  puts(\"working\")\nend",
+		},
+		{
+			"block unless wraps condition in parens",
+			"unless a && b\n  puts(\"x\")\nend",
+			"if !(a && b)",
+		},
+		{
+			"postfix unless",
+			`puts("hello") unless quiet`,
+			"if !(quiet)\n  puts(\"hello\")\nend",
+		},
+		{
+			"postfix unless with comparison",
+			`x = 0 unless x > 10`,
+			"if !(x > 10)\n  x = 0\nend",
+		},
+		{
+			"does not affect unless inside string",
+			`puts("go unless told")`,
+			`puts("go unless told")`,
+		},
+		{
+			"else in nested if is allowed",
+			"unless a\n  if b\n    puts(1)\n  else\n    puts(2)\n  end\nend",
+			"if !(a)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allFuncs := preprocess.ScanFuncDefs(tt.input)
+			result, _, err := preprocess.Preprocess(tt.input, allFuncs)
+			require.NoError(t, err)
+			assert.Contains(t, strings.TrimSpace(result), tt.expect,
+				"preprocessed output for %q", tt.input)
+		})
+	}
+}
+
+func TestPreprocessUnlessRejectsElse(t *testing.T) {
+	for _, kw := range []string{"else", "elsif x"} {
+		src := "unless a\n  puts(1)\n" + kw + "\n  puts(2)\nend"
+		_, _, err := preprocess.Preprocess(src, preprocess.ScanFuncDefs(src))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "line 3: `unless` does not support")
+		assert.Contains(t, err.Error(), "use `if` instead")
+	}
+}
+
+// --- with: resource blocks ---
+
+func TestPreprocessWith(t *testing.T) {
+	src := "with open_db(\"app.db\") as db\n  puts(db.name)\nend"
+	result, _, err := preprocess.Preprocess(src, preprocess.ScanFuncDefs(src))
+	require.NoError(t, err)
+	assert.Contains(t, result, `db = open_db("app.db"); if __with__(db)`)
+	// The binding is a known variable inside the body, not a shell command.
+	assert.Contains(t, result, "puts(db.name)")
+	assert.NotContains(t, result, "__shell__")
+}
+
+func TestPreprocessWithErrors(t *testing.T) {
+	for src, want := range map[string]string{
+		"with res\n  puts(1)\nend":                       "line 1: `with` requires a resource and a name",
+		"with res as 1x\n  puts(1)\nend":                 "line 1: `with` expects a variable name after `as`",
+		"with res as r\n  puts(1)\nelse\n  puts(2)\nend": "line 3: `with` does not support `else`",
+	} {
+		_, _, err := preprocess.Preprocess(src, preprocess.ScanFuncDefs(src))
+		require.Error(t, err, src)
+		assert.Contains(t, err.Error(), want, src)
+	}
+}
+
+func TestGenWith(t *testing.T) {
+	// compileToGo skips the preprocessor, so feed it the lowered form.
+	src := compileToGo(t, "r = {}\nfor i in [1, 2]\nf = r; if __with__(f)\nnext\nend\nend")
+	for _, want := range []string{
+		"__with_1 := rugo_with_guard(",
+		"defer __with_1()",
+	} {
+		if !strings.Contains(src, want) {
+			t.Errorf("expected %q in output:\n%s", want, src)
+		}
+	}
+	// next leaves the with block, so it must release the resource first.
+	if !strings.Contains(src, "__with_1()\n\t\t\tcontinue") {
+		t.Errorf("expected cleanup before continue:\n%s", src)
+	}
+}
+
+func TestPreprocessStrict(t *testing.T) {
+	strict := preprocess.Options{Strict: true}
+	for _, src := range []string{
+		"echo hello\n",
+		"x = 1\nls -la\n",
+		"out = uname -a\n",
+		"whoami\n",
+	} {
+		_, _, _, err := preprocess.RunWithOptions(src, strict)
+		require.Error(t, err, src)
+		assert.Contains(t, err.Error(), "is not a known function or variable", src)
+	}
+
+	_, _, _, err := preprocess.RunWithOptions("x = 1\nout = uname -a\n", strict)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "line 2: `uname`")
+
+	for _, src := range []string{
+		"x = `echo hi`\n",
+		"apt-get install foo\n",
+		"./run.sh --fast\n",
+		"def greet(n)\nputs n\nend\ngreet \"a\"\n",
+		"x = 1\nx\n",
+	} {
+		_, _, _, err := preprocess.RunWithOptions(src, strict)
+		assert.NoError(t, err, src)
+	}
+
+	_, _, _, err = preprocess.Run("echo hello\n")
+	assert.NoError(t, err, "shell fallback stays on by default")
+}
+
+func TestCompilerStrictEnv(t *testing.T) {
+	mainFile := filepath.Join(t.TempDir(), "main.rugo")
+	os.WriteFile(mainFile, []byte("echo hello\n"), 0644)
+
+	_, err := (&Compiler{Strict: true}).Compile(mainFile)
+	require.Error(t, err)
+
+	t.Setenv("RUGO_STRICT", "1")
+	_, err = (&Compiler{}).Compile(mainFile)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "strict mode disables the shell fallback")
+}
+
 // Bug 0d702bf: parser keywords used as identifiers should produce a
 // "reserved keyword" error instead of a cryptic "unexpected ... — expected
 // an identifier" message.
+func TestUnbalancedBlockErrorMessage(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{"unclosed if", "def greet(name)\n  if name\n    puts(name)\nend\n", `main.rugo:2:3: "if" at line 2 is never closed`},
+		{"unclosed at eof", "x = 1\nwhile x\n  puts(x)\n", `main.rugo:2:1: "while" at line 2 is never closed`},
+		{"stray end", "def a()\nend\nend\n", `main.rugo:3:1: unexpected "end" — no matching block to close`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			mainFile := filepath.Join(tmpDir, "main.rugo")
+			os.WriteFile(mainFile, []byte(tt.source), 0644)
+
+			_, err := (&Compiler{}).Compile(mainFile)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
+
 func TestReservedKeywordErrorMessage(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -1732,3 +2459,22 @@ func TestReservedKeywordErrorMessage(t *testing.T) {
 		})
 	}
 }
+
+func TestGenBenchOptions(t *testing.T) {
+	src := compileToGo(t, "use \"bench\"\nbench \"a\"; __bench_options__(\"iterations\", 10)\n  x = 1\nend\nbench \"b\"; __bench_options__(\"min_time\", 0.5)\n  y = 2\nend\n")
+	assert.Contains(t, src, `{Name: "a", Func: rugo_bench_0, Iterations: rugo_bench_iterations("a", interface{}(10))},`)
+	assert.Contains(t, src, `{Name: "b", Func: rugo_bench_1, MinTime: rugo_bench_min_time("b", interface{}(rugo_float(0.5)))},`)
+	assert.NotContains(t, src, "__bench_options__")
+}
+
+func TestGenSafeNav(t *testing.T) {
+	src := compileToGo(t, "u = nil\nputs(u.__safe__profile.__safe__email)\nputs(u.__safe__greet(1))\n")
+	assert.Contains(t, src, `rugo_dot_get_safe(rugo_dot_get_safe(u, "profile"), "email")`)
+	assert.Contains(t, src, `_recv := interface{}(u); if _recv == nil { return nil }; return rugo_dot_call(_recv, "greet", 1)`)
+	assert.NotContains(t, src, "__safe__")
+}
+
+func TestGenIn(t *testing.T) {
+	src := compileToGo(t, "xs = [1, 2]\nputs(1 in xs)\n")
+	assert.Contains(t, src, `rugo_contains(xs, interface{}(1))`)
+}
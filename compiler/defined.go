@@ -0,0 +1,159 @@
+package compiler
+
+import (
+	"strings"
+
+	"github.com/rubiojr/rugo/ast"
+	"github.com/rubiojr/rugo/modules"
+)
+
+// defined?("name") is resolved at compile time. Once requires are resolved
+// the full set of functions, top-level variables and use'd modules is known,
+// so every call folds to a boolean literal. If statements whose condition
+// folds to a constant keep only the branch that can run, which lets scripts
+// guard calls into a require_optional file that was not found without
+// tripping undefined-identifier checks.
+
+// foldDefined replaces the argument of each defined?("name") call with a
+// boolean literal and prunes if branches that became unreachable.
+func foldDefined(prog *ast.Program) {
+	known := definedNames(prog)
+	WalkExprs(prog, func(e ast.Expr) bool {
+		call, ok := e.(*ast.CallExpr)
+		if !ok || !isDefinedCall(call) || len(call.Args) != 1 {
+			return false
+		}
+		lit, ok := call.Args[0].(*ast.StringLiteral)
+		if !ok || strings.Contains(lit.Value, "#{") {
+			return false
+		}
+		call.Args[0] = &ast.BoolLiteral{Value: known[lit.Value]}
+		return false
+	})
+	prog.Statements = pruneDefinedBranches(prog.Statements)
+}
+
+// definedNames collects the names defined? reports as defined: builtins,
+// functions, top-level variables (namespaced when they come from a require),
+// renamed Go imports and the functions of use'd stdlib modules.
+func definedNames(prog *ast.Program) map[string]bool {
+	known := make(map[string]bool)
+	for name := range builtinFuncs {
+		if !strings.HasPrefix(name, "__") {
+			known[name] = true
+		}
+	}
+	for _, s := range prog.Statements {
+		switch st := s.(type) {
+		case *ast.FuncDef:
+			known[qualifiedName(st.Namespace, st.Name)] = true
+		case *ast.AssignStmt:
+			known[qualifiedName(st.Namespace, st.Target)] = true
+		case *ast.ImportStmt:
+			for name := range st.Funcs {
+				known[name] = true
+			}
+		case *ast.UseStmt:
+			if m, ok := modules.Get(st.Module); ok {
+				for _, f := range m.Funcs {
+					known[st.Namespace()+"."+f.Name] = true
+				}
+			}
+		}
+	}
+	return known
+}
+
+func qualifiedName(ns, name string) string {
+	if ns == "" {
+		return name
+	}
+	return ns + "." + name
+}
+
+func isDefinedCall(call *ast.CallExpr) bool {
+	ident, ok := call.Func.(*ast.IdentExpr)
+	return ok && ident.Name == "__defined__"
+}
+
+// definedConst reports the value of cond when it is a folded defined? call,
+// optionally negated with !.
+func definedConst(cond ast.Expr) (value, ok bool) {
+	negate := false
+	if u, isUnary := cond.(*ast.UnaryExpr); isUnary && u.Op == "!" {
+		negate = true
+		cond = u.Operand
+	}
+	call, isCall := cond.(*ast.CallExpr)
+	if !isCall || !isDefinedCall(call) || len(call.Args) != 1 {
+		return false, false
+	}
+	lit, isBool := call.Args[0].(*ast.BoolLiteral)
+	if !isBool {
+		return false, false
+	}
+	return lit.Value != negate, true
+}
+
+func pruneDefinedBranches(stmts []ast.Statement) []ast.Statement {
+	var out []ast.Statement
+	for _, s := range stmts {
+		switch st := s.(type) {
+		case *ast.IfStmt:
+			out = append(out, pruneDefinedIf(st)...)
+			continue
+		case *ast.FuncDef:
+			st.Body = pruneDefinedBranches(st.Body)
+		case *ast.TestDef:
+			st.Body = pruneDefinedBranches(st.Body)
+		case *ast.BenchDef:
+			st.Body = pruneDefinedBranches(st.Body)
+		case *ast.WhileStmt:
+			st.Body = pruneDefinedBranches(st.Body)
+			st.ElseBody = pruneDefinedBranches(st.ElseBody)
+		case *ast.ForStmt:
+			st.Body = pruneDefinedBranches(st.Body)
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+// pruneDefinedIf returns the statements that replace an if statement:
+// the taken branch when its condition is constant, otherwise the if itself
+// with constant-false elsif clauses dropped.
+func pruneDefinedIf(st *ast.IfStmt) []ast.Statement {
+	if value, ok := definedConst(st.Condition); ok {
+		if value {
+			return pruneDefinedBranches(st.Body)
+		}
+		if len(st.ElsifClauses) == 0 {
+			return pruneDefinedBranches(st.ElseBody)
+		}
+		next := &ast.IfStmt{
+			BaseStmt:     st.BaseStmt,
+			Condition:    st.ElsifClauses[0].Condition,
+			Body:         st.ElsifClauses[0].Body,
+			ElsifClauses: st.ElsifClauses[1:],
+			ElseBody:     st.ElseBody,
+		}
+		return pruneDefinedIf(next)
+	}
+	st.Body = pruneDefinedBranches(st.Body)
+	var elsifs []ast.ElsifClause
+	for _, clause := range st.ElsifClauses {
+		if value, ok := definedConst(clause.Condition); ok {
+			if !value {
+				continue
+			}
+			// Always taken: it becomes the else branch.
+			st.ElseBody = clause.Body
+			break
+		}
+		clause.Body = pruneDefinedBranches(clause.Body)
+		elsifs = append(elsifs, clause)
+	}
+	st.ElsifClauses = elsifs
+	st.ElseBody = pruneDefinedBranches(st.ElseBody)
+	return []ast.Statement{st}
+}
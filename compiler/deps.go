@@ -0,0 +1,227 @@
+package compiler
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// DepKind classifies a node in a dependency graph.
+type DepKind int
+
+const (
+	// DepFile is a Rugo source file: the main file or a required one.
+	DepFile DepKind = iota
+	// DepModule is a Rugo stdlib module loaded with use.
+	DepModule
+	// DepImport is a Go package bridged with import.
+	DepImport
+	// DepGoModule is a Go module loaded with require.
+	DepGoModule
+)
+
+// DepNode is a file or module in a DepGraph. Modules are always leaves.
+type DepNode struct {
+	Kind DepKind
+	// Name is the display path for files, the module name for use,
+	// and the package or module path for import and Go module requires.
+	Name string
+	// Deps lists the node's direct dependencies in source order.
+	Deps []*DepNode
+	// cycles marks the entries of Deps that close a require cycle.
+	cycles map[*DepNode]bool
+}
+
+// Label returns the node as printed by WriteTree and WriteDot.
+func (n *DepNode) Label() string {
+	switch n.Kind {
+	case DepModule:
+		return "use " + n.Name
+	case DepImport:
+		return "import " + n.Name
+	case DepGoModule:
+		return "require " + n.Name + " (go)"
+	}
+	return n.Name
+}
+
+// DepGraph is the require/use/import graph of a Rugo program, built by
+// Compiler.Deps.
+type DepGraph struct {
+	Root *DepNode
+	// Cycles lists each circular require found, as "a -> b -> a".
+	Cycles []string
+
+	nodes map[string]*DepNode
+}
+
+func newDepGraph(absPath string) *DepGraph {
+	g := &DepGraph{nodes: make(map[string]*DepNode)}
+	g.Root = g.node(DepFile, absPath, displayPath(absPath))
+	return g
+}
+
+// node returns the node for key, creating it on first use.
+func (g *DepGraph) node(kind DepKind, key, name string) *DepNode {
+	id := fmt.Sprintf("%d:%s", kind, key)
+	if n, ok := g.nodes[id]; ok {
+		return n
+	}
+	n := &DepNode{Kind: kind, Name: name}
+	g.nodes[id] = n
+	return n
+}
+
+// link records that from depends on to, once.
+func (g *DepGraph) link(from, to *DepNode) {
+	for _, d := range from.Deps {
+		if d == to {
+			return
+		}
+	}
+	from.Deps = append(from.Deps, to)
+}
+
+// Deps parses filename and resolves its requires the same way Compile
+// does, without generating code, and returns the resulting dependency
+// graph. Unlike Compile, a circular require does not stop the walk: it
+// is recorded in the graph's Cycles and the rest of the graph is still
+// resolved.
+func (c *Compiler) Deps(filename string) (*DepGraph, error) {
+	c.initState()
+	absPath, err := filepath.Abs(filename)
+	if err != nil {
+		return nil, fmt.Errorf("resolving path %s: %w", filename, err)
+	}
+	c.BaseDir = filepath.Dir(absPath)
+	if err := c.initResolver(); err != nil {
+		return nil, err
+	}
+
+	prog, err := c.parseFile(absPath, displayPath(absPath))
+	if err != nil {
+		return nil, err
+	}
+
+	c.loaded[absPath] = ""
+	c.requireStack = []string{absPath}
+	c.deps = newDepGraph(absPath)
+	defer func() { c.deps = nil }()
+
+	if _, err := c.resolveRequires(prog); err != nil {
+		return nil, err
+	}
+	return c.deps, nil
+}
+
+// depParent returns the graph node of the file being resolved.
+func (c *Compiler) depParent() *DepNode {
+	top := c.requireStack[len(c.requireStack)-1]
+	return c.deps.node(DepFile, top, displayPath(top))
+}
+
+// recordLeaf adds a module dependency of the file being resolved.
+// It is a no-op outside Deps.
+func (c *Compiler) recordLeaf(kind DepKind, name string) {
+	if c.deps == nil {
+		return
+	}
+	c.deps.link(c.depParent(), c.deps.node(kind, name, name))
+}
+
+// recordRequire adds a require of absPath by the file being resolved and
+// reports whether the walk should skip it: because it was already
+// resolved, or because requiring it closes a cycle. It is a no-op
+// returning false outside Deps, and for files that do not exist, so
+// the caller reports those as it always does.
+func (c *Compiler) recordRequire(absPath, name string) bool {
+	if c.deps == nil || !fileExists(absPath) {
+		return false
+	}
+	parent := c.depParent()
+	child := c.deps.node(DepFile, absPath, name)
+	c.deps.link(parent, child)
+	if chain := c.requireChain(absPath); chain != "" {
+		if parent.cycles == nil {
+			parent.cycles = make(map[*DepNode]bool)
+		}
+		parent.cycles[child] = true
+		c.deps.Cycles = append(c.deps.Cycles, chain)
+		return true
+	}
+	_, loaded := c.loaded[absPath]
+	return loaded
+}
+
+// WriteTree prints the graph as an indented tree rooted at the main
+// file. A file required from several places is expanded the first time
+// and marked "(see above)" after that; a require closing a cycle is
+// marked "(cycle)".
+func (g *DepGraph) WriteTree(w io.Writer) {
+	fmt.Fprintln(w, g.Root.Label())
+	seen := map[*DepNode]bool{g.Root: true}
+	g.writeTree(w, g.Root, "", seen)
+}
+
+func (g *DepGraph) writeTree(w io.Writer, n *DepNode, indent string, seen map[*DepNode]bool) {
+	for i, d := range n.Deps {
+		branch, next := "├── ", "│   "
+		if i == len(n.Deps)-1 {
+			branch, next = "└── ", "    "
+		}
+		label := d.Label()
+		expand := false
+		switch {
+		case n.cycles[d]:
+			label += " (cycle)"
+		case d.Kind == DepFile && seen[d]:
+			if len(d.Deps) > 0 {
+				label += " (see above)"
+			}
+		default:
+			seen[d] = true
+			expand = true
+		}
+		fmt.Fprintln(w, indent+branch+label)
+		if expand {
+			g.writeTree(w, d, indent+next, seen)
+		}
+	}
+}
+
+// WriteDot prints the graph in Graphviz DOT format. Modules are drawn
+// as boxes and edges closing a cycle in red.
+func (g *DepGraph) WriteDot(w io.Writer) {
+	fmt.Fprintln(w, "digraph deps {")
+	seen := map[*DepNode]bool{}
+	var visit func(n *DepNode)
+	visit = func(n *DepNode) {
+		if seen[n] {
+			return
+		}
+		seen[n] = true
+		if n.Kind == DepFile {
+			fmt.Fprintf(w, "  %s;\n", dotQuote(n.Label()))
+		} else {
+			fmt.Fprintf(w, "  %s [shape=box];\n", dotQuote(n.Label()))
+		}
+		for _, d := range n.Deps {
+			attrs := ""
+			if n.cycles[d] {
+				attrs = " [color=red]"
+			}
+			fmt.Fprintf(w, "  %s -> %s%s;\n", dotQuote(n.Label()), dotQuote(d.Label()), attrs)
+		}
+		for _, d := range n.Deps {
+			visit(d)
+		}
+	}
+	visit(g.Root)
+	fmt.Fprintln(w, "}")
+}
+
+// dotQuote returns s as a DOT quoted identifier.
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
@@ -0,0 +1,107 @@
+package compiler
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeDepsFiles writes files (relative path → source) under a temp dir
+// and changes into it so display paths are relative.
+func writeDepsFiles(t *testing.T, files map[string]string) {
+	t.Helper()
+	dir := t.TempDir()
+	for name, src := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(src), 0o644))
+	}
+	t.Chdir(dir)
+}
+
+func TestDepsTree(t *testing.T) {
+	writeDepsFiles(t, map[string]string{
+		"main.rugo":  "use \"json\"\nimport \"strings\"\nrequire \"lib/a\"\nrequire \"lib/b\"\n",
+		"lib/a.rugo": "use \"os\"\nrequire \"b\"\ndef x()\n  return 1\nend\n",
+		"lib/b.rugo": "import \"strings\"\ndef y()\n  return 2\nend\n",
+	})
+
+	graph, err := (&Compiler{}).Deps("main.rugo")
+	require.NoError(t, err)
+	assert.Empty(t, graph.Cycles)
+
+	var out bytes.Buffer
+	graph.WriteTree(&out)
+	assert.Equal(t, `main.rugo
+├── use json
+├── import strings
+├── lib/a.rugo
+│   ├── use os
+│   └── lib/b.rugo
+│       └── import strings
+└── lib/b.rugo (see above)
+`, out.String())
+}
+
+func TestDepsDot(t *testing.T) {
+	writeDepsFiles(t, map[string]string{
+		"main.rugo": "use \"json\"\nrequire \"util\"\n",
+		"util.rugo": "def f()\n  return 1\nend\n",
+	})
+
+	graph, err := (&Compiler{}).Deps("main.rugo")
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	graph.WriteDot(&out)
+	assert.Equal(t, `digraph deps {
+  "main.rugo";
+  "main.rugo" -> "use json";
+  "main.rugo" -> "util.rugo";
+  "use json" [shape=box];
+  "util.rugo";
+}
+`, out.String())
+}
+
+func TestDepsCycle(t *testing.T) {
+	writeDepsFiles(t, map[string]string{
+		"main.rugo": "require \"a\"\n",
+		"a.rugo":    "require \"b\"\n",
+		"b.rugo":    "require \"a\"\nuse \"os\"\n",
+	})
+
+	graph, err := (&Compiler{}).Deps("main.rugo")
+	require.NoError(t, err, "a cycle is reported in the graph, not as an error")
+	assert.Equal(t, []string{"a.rugo → b.rugo → a.rugo"}, graph.Cycles)
+
+	var out bytes.Buffer
+	graph.WriteTree(&out)
+	assert.Equal(t, `main.rugo
+└── a.rugo
+    └── b.rugo
+        ├── a.rugo (cycle)
+        └── use os
+`, out.String())
+}
+
+func TestDepsMissingRequire(t *testing.T) {
+	writeDepsFiles(t, map[string]string{
+		"main.rugo": "require \"nope\"\n",
+		"opt.rugo":  "require_optional \"gone\"\nuse \"os\"\n",
+	})
+
+	_, err := (&Compiler{}).Deps("main.rugo")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `cannot find required file "nope"`)
+
+	graph, err := (&Compiler{}).Deps("opt.rugo")
+	require.NoError(t, err)
+	var out bytes.Buffer
+	graph.WriteTree(&out)
+	assert.Equal(t, "opt.rugo\n└── use os\n", out.String(), "a missing optional require is left out")
+}
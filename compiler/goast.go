@@ -166,6 +166,13 @@ type GoDeferStmt struct {
 
 func (GoDeferStmt) goStmt() {}
 
+// GoBlockStmt represents: { body }
+type GoBlockStmt struct {
+	Body []GoStmt
+}
+
+func (GoBlockStmt) goStmt() {}
+
 // GoGoStmt represents: go func() { body }()
 type GoGoStmt struct {
 	Body []GoStmt
@@ -145,6 +145,14 @@ func (p *goPrinter) printStmt(s GoStmt) {
 		}
 		p.indent--
 		p.line("}()")
+	case GoBlockStmt:
+		p.line("{")
+		p.indent++
+		for _, s := range st.Body {
+			p.printStmt(s)
+		}
+		p.indent--
+		p.line("}")
 	case GoGoStmt:
 		p.line("go func() {")
 		p.indent++
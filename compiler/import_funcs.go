@@ -0,0 +1,77 @@
+package compiler
+
+import (
+	"fmt"
+
+	"github.com/rubiojr/rugo/ast"
+	"github.com/rubiojr/rugo/gobridge"
+)
+
+// An import can bring single Go functions in under a plain Rugo name:
+//
+//	import "strings" as {title_case: ToTitle}
+//
+// Once requires are resolved, every call to a renamed function is rewritten
+// into a regular bridge call on the import's namespace (strings.to_title),
+// so codegen resolves it like any other Go bridge call.
+
+// importedFunc is the bridge call a renamed function stands for.
+type importedFunc struct {
+	ns    string // namespace of the import (alias or default)
+	field string // snake_case Rugo name of the Go function
+}
+
+// resolveImportFuncs rewrites calls to renamed Go functions into bridge
+// calls. Renames that shadow a def or builtin, or name a function the
+// package does not have, are errors.
+func resolveImportFuncs(prog *ast.Program, sourceFile string) error {
+	renames := make(map[string]importedFunc)
+	for _, s := range prog.Statements {
+		imp, ok := s.(*ast.ImportStmt)
+		if !ok || len(imp.Funcs) == 0 {
+			continue
+		}
+		for name, goName := range imp.Funcs {
+			field := gobridge.ToSnakeCase(goName)
+			if gobridge.IsPackage(imp.Package) {
+				if _, found := gobridge.Lookup(imp.Package, field); !found {
+					return fmt.Errorf("%s:%d: import %q has no function %s", sourceFile, imp.StmtLine(), imp.Package, goName)
+				}
+			}
+			if builtinFuncs[name] {
+				return fmt.Errorf("%s:%d: cannot import %s as %s: %s is a builtin", sourceFile, imp.StmtLine(), goName, name, name)
+			}
+			if prev, dup := renames[name]; dup && prev != (importedFunc{goBridgeNamespace(imp), field}) {
+				return fmt.Errorf("%s:%d: %s is already imported from another package", sourceFile, imp.StmtLine(), name)
+			}
+			renames[name] = importedFunc{ns: goBridgeNamespace(imp), field: field}
+		}
+	}
+	if len(renames) == 0 {
+		return nil
+	}
+
+	for _, s := range prog.Statements {
+		if fd, ok := s.(*ast.FuncDef); ok && fd.Namespace == "" {
+			if _, clash := renames[fd.Name]; clash {
+				return fmt.Errorf("%s:%d: def %s conflicts with the imported Go function of the same name", sourceFile, fd.StmtLine(), fd.Name)
+			}
+		}
+	}
+
+	WalkExprs(prog, func(e ast.Expr) bool {
+		call, ok := e.(*ast.CallExpr)
+		if !ok {
+			return false
+		}
+		id, ok := call.Func.(*ast.IdentExpr)
+		if !ok {
+			return false
+		}
+		if f, ok := renames[id.Name]; ok {
+			call.Func = &ast.DotExpr{Object: &ast.IdentExpr{Name: f.ns}, Field: f.field}
+		}
+		return false
+	})
+	return nil
+}
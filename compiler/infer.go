@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/rubiojr/rugo/ast"
+	"github.com/rubiojr/rugo/modules"
 )
 
 // Infer runs type inference on a parsed program, returning type annotations
@@ -14,6 +15,7 @@ func Infer(prog *ast.Program) *TypeInfo {
 		ExprTypes: make(map[ast.Expr]RugoType),
 		FuncTypes: make(map[string]*FuncTypeInfo),
 		VarTypes:  make(map[string]map[string]RugoType),
+		uses:      make(map[string]string),
 	}
 
 	// Collect all function definitions (skip duplicates — codegen validates them).
@@ -33,6 +35,9 @@ func Infer(prog *ast.Program) *TypeInfo {
 				HasDefaults: ast.HasDefaults(st.Params),
 			}
 		default:
+			if u, ok := st.(*ast.UseStmt); ok {
+				ti.uses[u.Namespace()] = u.Module
+			}
 			topStmts = append(topStmts, s)
 		}
 	}
@@ -90,6 +95,14 @@ func (s *typeScope) get(name string) RugoType {
 	return TypeDynamic
 }
 
+// has reports whether name is a variable in this scope or any parent.
+func (s *typeScope) has(name string) bool {
+	if _, ok := s.vars[name]; ok {
+		return true
+	}
+	return s.parent != nil && s.parent.has(name)
+}
+
 func (s *typeScope) set(name string, t RugoType) {
 	if existing, ok := s.vars[name]; ok {
 		// Variable reassigned — unify types.
@@ -294,6 +307,9 @@ func inferStmt(ti *TypeInfo, scope *typeScope, s ast.Statement) {
 				inferStmt(ti, scope, s)
 			}
 		}
+		for _, s := range st.ElseBody {
+			inferStmt(ti, scope, s)
+		}
 
 	case *ast.ForStmt:
 		inferExpr(ti, scope, st.Collection)
@@ -343,6 +359,12 @@ func inferStmt(ti *TypeInfo, scope *typeScope, s ast.Statement) {
 		inferExpr(ti, scope, st.Value)
 
 	case *ast.BenchDef:
+		if st.Iterations != nil {
+			inferExpr(ti, scope, st.Iterations)
+		}
+		if st.MinTime != nil {
+			inferExpr(ti, scope, st.MinTime)
+		}
 		blockScope := newTypeScope(scope)
 		for _, s := range st.Body {
 			inferStmt(ti, blockScope, s)
@@ -403,6 +425,9 @@ func collectReturns(ti *TypeInfo, scope *typeScope, s ast.Statement, out *[]Rugo
 		for _, s := range st.Body {
 			collectReturns(ti, scope, s, out)
 		}
+		for _, s := range st.ElseBody {
+			collectReturns(ti, scope, s, out)
+		}
 	case *ast.ForStmt:
 		for _, s := range st.Body {
 			collectReturns(ti, scope, s, out)
@@ -481,6 +506,9 @@ func inferExprInner(ti *TypeInfo, scope *typeScope, e ast.Expr) RugoType {
 		if ex.ResultExpr != nil {
 			inferExpr(ti, scope, ex.ResultExpr)
 		}
+		for _, s := range ex.Ensure {
+			inferStmt(ti, scope, s)
+		}
 		return TypeDynamic
 
 	case *ast.LoweredSpawnExpr:
@@ -493,6 +521,9 @@ func inferExprInner(ti *TypeInfo, scope *typeScope, e ast.Expr) RugoType {
 		return TypeDynamic
 
 	case *ast.LoweredParallelExpr:
+		if ex.Limit != nil {
+			inferExpr(ti, scope, ex.Limit)
+		}
 		for _, br := range ex.Branches {
 			if br.Expr != nil {
 				inferExpr(ti, scope, br.Expr)
@@ -590,6 +621,10 @@ func inferBinaryOp(op string, left, right RugoType) RugoType {
 		if left == TypeInt && right == TypeInt {
 			return TypeInt
 		}
+		// string * int repeats the string.
+		if op == "*" && left == TypeString && right == TypeInt {
+			return TypeString
+		}
 		if left.IsNumeric() && right.IsNumeric() {
 			return TypeFloat
 		}
@@ -610,7 +645,7 @@ func inferBinaryOp(op string, left, right RugoType) RugoType {
 		}
 		return TypeDynamic
 
-	case "%":
+	case "%", "&", "|", "^":
 		if left == TypeInt && right == TypeInt {
 			return TypeInt
 		}
@@ -701,6 +736,8 @@ func inferCall(ti *TypeInfo, scope *typeScope, e *ast.CallExpr) RugoType {
 			return TypeInt
 		case "append":
 			return TypeArray
+		case "__defined__":
+			return TypeBool
 		}
 
 		// User-defined function — propagate argument types.
@@ -733,6 +770,12 @@ func inferCall(ti *TypeInfo, scope *typeScope, e *ast.CallExpr) RugoType {
 				}
 				return fti.ReturnType
 			}
+			// Stdlib module functions with a declared return type.
+			if mod, ok := ti.uses[ns.Name]; ok && !scope.has(ns.Name) {
+				if fd, ok := modules.LookupFuncDef(mod, dot.Field); ok {
+					return goTypeToRugo(fd.Returns)
+				}
+			}
 		}
 	}
 
@@ -755,7 +798,7 @@ func inferForVarType(ti *TypeInfo, scope *typeScope, coll ast.Expr) RugoType {
 	}
 	// for i in range(...)
 	if call, ok := coll.(*ast.CallExpr); ok {
-		if fn, ok := call.Func.(*ast.IdentExpr); ok && fn.Name == "range" {
+		if fn, ok := call.Func.(*ast.IdentExpr); ok && (fn.Name == "range" || fn.Name == "__range__") {
 			return TypeInt
 		}
 	}
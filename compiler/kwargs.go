@@ -0,0 +1,201 @@
+package compiler
+
+import (
+	"fmt"
+
+	"github.com/rubiojr/rugo/ast"
+	"github.com/rubiojr/rugo/preprocess"
+)
+
+// Keyword arguments are bound at compile time. The preprocessor rewrites
+// `f(a, name: v)` to `f(a, __kw__("name", v))`; once requires are resolved
+// every def is known, so each keyword moves to its parameter's position.
+// A defaulted parameter that is skipped over is passed as __kw_default__,
+// which tells the callee to use its default.
+
+// kwDefaultBuiltin is the placeholder argument for a skipped parameter.
+const kwDefaultBuiltin = "__kw_default__"
+
+// bindKeywordArgs rewrites every call with keyword arguments into a
+// positional call. Errors name the function and the offending keyword.
+func bindKeywordArgs(prog *ast.Program, sourceFile string) error {
+	funcs := make(map[string]*ast.FuncDef)
+	for _, s := range prog.Statements {
+		if fd, ok := s.(*ast.FuncDef); ok {
+			funcs[qualifiedName(fd.Namespace, fd.Name)] = fd
+		}
+	}
+
+	var firstErr error
+	for _, s := range prog.Statements {
+		ns := ""
+		file := sourceFile
+		if fd, ok := s.(*ast.FuncDef); ok {
+			ns = fd.Namespace
+			if fd.SourceFile != "" {
+				file = fd.SourceFile
+			}
+		}
+		walkStmtRecursive(s, func(st ast.Statement) bool {
+			if firstErr != nil {
+				return false
+			}
+			bind := func(e ast.Expr) bool {
+				call, ok := e.(*ast.CallExpr)
+				if !ok {
+					return false
+				}
+				// Bound calls drop their markers before walkExpr visits
+				// the arguments, so a marker seen here is misplaced.
+				if name, ok := kwArgName(call); ok {
+					firstErr = fmt.Errorf("%s:%d: keyword argument `%s:` must be an argument of a function call", file, st.StmtLine(), name)
+					return true
+				}
+				if !hasKwArgs(call) {
+					return false
+				}
+				if err := bindCall(call, funcs, ns); err != nil {
+					firstErr = fmt.Errorf("%s:%d: %w", file, st.StmtLine(), err)
+					return true
+				}
+				return false
+			}
+			if exprs, ok := headerExprs(st); ok {
+				for _, e := range exprs {
+					walkExpr(e, bind)
+				}
+			} else {
+				walkStmtExprs(st, bind)
+			}
+			return firstErr == nil
+		})
+		if firstErr != nil {
+			return firstErr
+		}
+	}
+	return nil
+}
+
+// bindCall reorders the arguments of call to match the parameters of the
+// def it calls. ns is the namespace of the calling function, where a bare
+// name resolves to a sibling function first.
+func bindCall(call *ast.CallExpr, funcs map[string]*ast.FuncDef, ns string) error {
+	name := calleeName(call.Func)
+	fd := funcs[name]
+	if fd == nil && ns != "" {
+		if _, bare := call.Func.(*ast.IdentExpr); bare {
+			fd = funcs[ns+"."+name]
+		}
+	}
+	if fd == nil {
+		if name == "" {
+			name = "this call"
+		}
+		return fmt.Errorf("keyword arguments are only supported for functions defined with def, not `%s`", name)
+	}
+
+	args := make([]ast.Expr, len(fd.Params))
+	set := make([]bool, len(fd.Params))
+	n := 0 // positional arguments seen
+	for _, a := range call.Args {
+		kw, isKw := kwArgName(a)
+		if !isKw {
+			if hasKwArgsBefore(call.Args, a) {
+				return fmt.Errorf("positional argument after keyword arguments in call to `%s`", name)
+			}
+			if n >= len(fd.Params) {
+				return arityCountError(name, len(call.Args), funcArity{Min: ast.MinArity(fd.Params), Max: len(fd.Params), HasDefaults: ast.HasDefaults(fd.Params)})
+			}
+			args[n], set[n] = a, true
+			n++
+			continue
+		}
+		idx := paramIndex(fd.Params, kw)
+		if idx < 0 {
+			return fmt.Errorf("`%s` has no parameter named `%s`", name, kw)
+		}
+		if set[idx] {
+			return fmt.Errorf("argument `%s` given more than once in call to `%s`", kw, name)
+		}
+		args[idx], set[idx] = a.(*ast.CallExpr).Args[1], true
+	}
+
+	last := -1
+	for i, p := range fd.Params {
+		if set[i] {
+			last = i
+			continue
+		}
+		if p.Default == nil {
+			return fmt.Errorf("missing argument `%s` in call to `%s`", p.Name, name)
+		}
+	}
+	for i := 0; i < last; i++ {
+		if !set[i] {
+			args[i] = &ast.CallExpr{Func: &ast.IdentExpr{Name: kwDefaultBuiltin}}
+		}
+	}
+	call.Args = args[:last+1]
+	return nil
+}
+
+// kwArgName reports whether e is a __kw__("name", value) marker and
+// returns the keyword.
+func kwArgName(e ast.Expr) (string, bool) {
+	call, ok := e.(*ast.CallExpr)
+	if !ok || len(call.Args) != 2 {
+		return "", false
+	}
+	if ident, ok := call.Func.(*ast.IdentExpr); !ok || ident.Name != preprocess.KwArgBuiltin {
+		return "", false
+	}
+	lit, ok := call.Args[0].(*ast.StringLiteral)
+	if !ok {
+		return "", false
+	}
+	return lit.Value, true
+}
+
+func hasKwArgs(call *ast.CallExpr) bool {
+	for _, a := range call.Args {
+		if _, ok := kwArgName(a); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// hasKwArgsBefore reports whether a keyword argument precedes arg.
+func hasKwArgsBefore(args []ast.Expr, arg ast.Expr) bool {
+	for _, a := range args {
+		if a == arg {
+			return false
+		}
+		if _, ok := kwArgName(a); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func paramIndex(params []ast.Param, name string) int {
+	for i, p := range params {
+		if p.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// calleeName returns "f" for f(...) and "ns.f" for ns.f(...).
+func calleeName(fn ast.Expr) string {
+	switch f := fn.(type) {
+	case *ast.IdentExpr:
+		return f.Name
+	case *ast.DotExpr:
+		if obj, ok := f.Object.(*ast.IdentExpr); ok {
+			return obj.Name + "." + f.Field
+		}
+	}
+	return ""
+}
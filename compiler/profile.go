@@ -0,0 +1,128 @@
+package compiler
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/rubiojr/rugo/ast"
+)
+
+// ProfileConfig enables pprof profiling in the compiled program.
+// Populated by `rugo run --profile <file> --memprofile <file>`.
+type ProfileConfig struct {
+	CPU string // CPU profile output path ("" = disabled)
+	Mem string // heap profile output path ("" = disabled)
+}
+
+// ProfileSymbol maps a generated Go function name, as it appears in pprof
+// output, back to the Rugo function it was compiled from.
+type ProfileSymbol struct {
+	GoName string `json:"go_name"`
+	Name   string `json:"name"`
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+}
+
+// profileRuntimeCode returns the helpers that start profiling at the top of
+// main and write the profiles when the program exits.
+func profileRuntimeCode() string {
+	return `
+var rugo_profile_cpu *os.File
+
+func rugo_profile_start(cpuPath, memPath string) {
+	if cpuPath != "" {
+		f, err := os.Create(cpuPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: --profile: %v\n", err)
+			os.Exit(1)
+		}
+		if err := pprof.StartCPUProfile(f); err != nil {
+			fmt.Fprintf(os.Stderr, "error: --profile: %v\n", err)
+			os.Exit(1)
+		}
+		rugo_profile_cpu = f
+	}
+	rugo_at_exit = append(rugo_at_exit, func() { rugo_profile_stop(memPath) })
+}
+
+func rugo_profile_stop(memPath string) {
+	if rugo_profile_cpu != nil {
+		pprof.StopCPUProfile()
+		rugo_profile_cpu.Close()
+		rugo_profile_cpu = nil
+	}
+	if memPath != "" {
+		f, err := os.Create(memPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: --memprofile: %v\n", err)
+			return
+		}
+		runtime.GC()
+		if err := pprof.WriteHeapProfile(f); err != nil {
+			fmt.Fprintf(os.Stderr, "error: --memprofile: %v\n", err)
+		}
+		f.Close()
+	}
+}
+`
+}
+
+// buildProfileStart returns the GoStmt nodes that start profiling inside
+// main(). The deferred flush runs before the panic handler, so profiles are
+// written on normal return and on uncaught errors alike; exit and die flush
+// through rugo_at_exit.
+func (g *codeGen) buildProfileStart() []GoStmt {
+	return []GoStmt{
+		GoRawStmt{Code: fmt.Sprintf("rugo_profile_start(%q, %q)", g.profile.CPU, g.profile.Mem)},
+		GoDeferStmt{Body: []GoStmt{GoRawStmt{Code: "rugo_run_at_exit()"}}},
+	}
+}
+
+// ProfileSymbols lists the Go function names of every Rugo function in prog.
+// Generated names are mangled (rugofn_name, rugons_ns_name); the symbols
+// let profiles be read in terms of the original source.
+func ProfileSymbols(prog *ast.Program, sourceFile string) []ProfileSymbol {
+	syms := []ProfileSymbol{}
+	for _, s := range prog.Statements {
+		f, ok := s.(*ast.FuncDef)
+		if !ok {
+			continue
+		}
+		sym := ProfileSymbol{
+			GoName: "main.rugofn_" + f.Name,
+			Name:   f.Name,
+			File:   f.SourceFile,
+			Line:   f.SourceLine,
+		}
+		if f.Namespace != "" {
+			sym.GoName = fmt.Sprintf("main.rugons_%s_%s", f.Namespace, f.Name)
+			sym.Name = f.Namespace + "." + f.Name
+		}
+		if sym.File == "" {
+			sym.File = sourceFile
+		}
+		syms = append(syms, sym)
+	}
+	sort.Slice(syms, func(i, j int) bool { return syms[i].GoName < syms[j].GoName })
+	return syms
+}
+
+// writeProfileSymbols writes the symbol map for prog next to each enabled
+// profile as <profile>.symbols.json.
+func writeProfileSymbols(cfg *ProfileConfig, prog *ast.Program, sourceFile string) error {
+	data, err := json.MarshalIndent(ProfileSymbols(prog, sourceFile), "", "  ")
+	if err != nil {
+		return err
+	}
+	for _, path := range []string{cfg.CPU, cfg.Mem} {
+		if path == "" {
+			continue
+		}
+		if err := os.WriteFile(path+".symbols.json", append(data, '\n'), 0644); err != nil {
+			return fmt.Errorf("writing profile symbols: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package compiler
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rubiojr/rugo/ast"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenProfile(t *testing.T) {
+	prog := parseAndWalk(t, "def work()\n  return 1\nend\nputs(work())\n")
+	res, err := generate(prog, "test.rugo", false, nil, &ProfileConfig{CPU: "cpu.prof", Mem: "mem.prof"}, false, false)
+	require.NoError(t, err)
+	assert.Contains(t, res.GoSource, `"runtime/pprof"`)
+	assert.Contains(t, res.GoSource, `rugo_profile_start("cpu.prof", "mem.prof")`)
+	assert.Contains(t, res.GoSource, "defer func() {\n\t\trugo_run_at_exit()")
+	assert.Contains(t, res.GoSource, "pprof.StartCPUProfile")
+}
+
+func TestGenNoProfile(t *testing.T) {
+	goSrc := compileToGo(t, "puts(1)\n")
+	assert.NotContains(t, goSrc, "runtime/pprof")
+	assert.NotContains(t, goSrc, "rugo_profile_start")
+}
+
+func TestProfileSymbols(t *testing.T) {
+	prog := parseAndWalk(t, "def fib(n)\n  return n\nend\n\ndef add(a, b)\n  return a + b\nend\n")
+	prog.Statements[1].(*ast.FuncDef).Namespace = "math"
+	syms := ProfileSymbols(prog, "app.rugo")
+	assert.Equal(t, []ProfileSymbol{
+		{GoName: "main.rugofn_fib", Name: "fib", File: "app.rugo", Line: 1},
+		{GoName: "main.rugons_math_add", Name: "math.add", File: "app.rugo", Line: 5},
+	}, syms)
+}
+
+func TestWriteProfileSymbols(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &ProfileConfig{CPU: filepath.Join(dir, "cpu.prof")}
+	prog := parseAndWalk(t, "def fib(n)\n  return n\nend\n")
+	require.NoError(t, writeProfileSymbols(cfg, prog, "app.rugo"))
+
+	data, err := os.ReadFile(cfg.CPU + ".symbols.json")
+	require.NoError(t, err)
+	var syms []ProfileSymbol
+	require.NoError(t, json.Unmarshal(data, &syms))
+	assert.Equal(t, "main.rugofn_fib", syms[0].GoName)
+	assert.NoFileExists(t, filepath.Join(dir, "mem.prof.symbols.json"))
+}
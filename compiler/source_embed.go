@@ -5,6 +5,6 @@ import "embed"
 // Sources embeds all non-test Go source files and templates needed to
 // reconstruct the compiler package in an external module cache.
 //
-//go:embed bincache.go check_idents.go compiler.go codegen.go codegen_build.go codegen_embed.go codegen_expr.go codegen_func.go codegen_runtime.go codegen_scope.go codegen_stmt.go ext.go goast.go goprint.go infer.go types.go visitor.go
-//go:embed templates/runtime_core_pre.go.tmpl templates/runtime_core_post.go.tmpl templates/runtime_spawn.go.tmpl
+//go:embed bincache.go check_idents.go check_shadow.go check_struct_fields.go check_unused.go compiler.go codegen.go codegen_build.go codegen_embed.go codegen_expr.go codegen_func.go codegen_runtime.go codegen_scope.go codegen_stmt.go defined.go deps.go ext.go goast.go goprint.go import_funcs.go infer.go kwargs.go profile.go sourcemap.go types.go visitor.go watch.go
+//go:embed templates/runtime_core_pre.go.tmpl templates/runtime_core_post.go.tmpl templates/runtime_spawn.go.tmpl templates/runtime_parallel.go.tmpl templates/runtime_memo.go.tmpl
 var Sources embed.FS
@@ -0,0 +1,48 @@
+package compiler
+
+import (
+	"strconv"
+	"strings"
+)
+
+// SourceMapping maps one line of generated Go to the Rugo line that produced it.
+type SourceMapping struct {
+	GoLine  int    `json:"go_line"`
+	SrcFile string `json:"file"`
+	SrcLine int    `json:"line"`
+}
+
+// SourceMap holds the line mappings for a generated Go file, as written
+// by `rugo emit --map`.
+type SourceMap struct {
+	Source   string          `json:"source"`
+	Mappings []SourceMapping `json:"mappings"`
+}
+
+// BuildSourceMap derives a source map from generated Go source by following
+// its //line directives. Every Go line after a directive is attributed to
+// that directive's Rugo file:line until the next directive. Go lines before
+// the first directive (runtime helpers, imports) are not mapped.
+func BuildSourceMap(source, goSrc string) *SourceMap {
+	sm := &SourceMap{Source: source, Mappings: []SourceMapping{}}
+	file, line := "", 0
+	for i, ln := range strings.Split(goSrc, "\n") {
+		if rest, ok := strings.CutPrefix(ln, "//line "); ok {
+			colon := strings.LastIndex(rest, ":")
+			if colon < 0 {
+				continue
+			}
+			n, err := strconv.Atoi(rest[colon+1:])
+			if err != nil {
+				continue
+			}
+			file, line = rest[:colon], n
+			continue
+		}
+		if file == "" || strings.TrimSpace(ln) == "" {
+			continue
+		}
+		sm.Mappings = append(sm.Mappings, SourceMapping{GoLine: i + 1, SrcFile: file, SrcLine: line})
+	}
+	return sm
+}
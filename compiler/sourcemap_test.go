@@ -0,0 +1,24 @@
+package compiler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildSourceMap(t *testing.T) {
+	goSrc := "package main\n\nfunc main() {\n//line app.rugo:3\n\tx := 1\n\n\t_ = x\n//line lib/util.rugo:7\n\tfoo()\n}\n"
+	sm := BuildSourceMap("app.rugo", goSrc)
+	assert.Equal(t, "app.rugo", sm.Source)
+	assert.Equal(t, []SourceMapping{
+		{GoLine: 5, SrcFile: "app.rugo", SrcLine: 3},
+		{GoLine: 7, SrcFile: "app.rugo", SrcLine: 3},
+		{GoLine: 9, SrcFile: "lib/util.rugo", SrcLine: 7},
+		{GoLine: 10, SrcFile: "lib/util.rugo", SrcLine: 7},
+	}, sm.Mappings)
+}
+
+func TestBuildSourceMapNoDirectives(t *testing.T) {
+	sm := BuildSourceMap("app.rugo", "package main\n\nfunc main() {}\n")
+	assert.Empty(t, sm.Mappings)
+}
@@ -21,7 +21,7 @@ const (
 	TypeNil
 	// TypeArray is []interface{} (element types not tracked).
 	TypeArray
-	// TypeHash is map[interface{}]interface{}.
+	// TypeHash is a Rugo hash (an insertion-ordered *rugoHash at runtime).
 	TypeHash
 	// TypeDynamic means the type is explicitly unresolvable (mixed types,
 	// external calls, etc.). Falls back to interface{} in codegen.
@@ -85,6 +85,23 @@ func (t RugoType) GoType() string {
 	}
 }
 
+// goTypeToRugo maps a Go type name back to its primitive RugoType.
+// Anything else is TypeDynamic.
+func goTypeToRugo(goType string) RugoType {
+	switch goType {
+	case "int":
+		return TypeInt
+	case "float64":
+		return TypeFloat
+	case "string":
+		return TypeString
+	case "bool":
+		return TypeBool
+	default:
+		return TypeDynamic
+	}
+}
+
 // unifyTypes merges two types. If they agree, returns that type.
 // If either is unknown, returns the other. If they conflict, returns dynamic.
 func unifyTypes(a, b RugoType) RugoType {
@@ -116,6 +133,8 @@ type TypeInfo struct {
 	// VarTypes maps (scope, variable name) to their final inferred type.
 	// Scope is the function name (or "" for top-level).
 	VarTypes map[string]map[string]RugoType
+
+	uses map[string]string // `use` namespaces: module name or alias → module
 }
 
 // FuncTypeInfo holds the inferred signature for a function.
@@ -69,6 +69,9 @@ func walkStmtRecursive(s ast.Statement, fn func(ast.Statement) bool) {
 		for _, child := range st.Body {
 			walkStmtRecursive(child, fn)
 		}
+		for _, child := range st.ElseBody {
+			walkStmtRecursive(child, fn)
+		}
 	case *ast.ForStmt:
 		for _, child := range st.Body {
 			walkStmtRecursive(child, fn)
@@ -155,6 +158,11 @@ func walkStmtExprs(s ast.Statement, fn func(ast.Expr) bool) bool {
 				return true
 			}
 		}
+		for _, s := range st.ElseBody {
+			if walkStmtExprs(s, fn) {
+				return true
+			}
+		}
 	case *ast.ForStmt:
 		if walkExpr(st.Collection, fn) {
 			return true
@@ -177,6 +185,11 @@ func walkStmtExprs(s ast.Statement, fn func(ast.Expr) bool) bool {
 			}
 		}
 	case *ast.BenchDef:
+		for _, opt := range []ast.Expr{st.Iterations, st.MinTime} {
+			if opt != nil && walkExpr(opt, fn) {
+				return true
+			}
+		}
 		for _, s := range st.Body {
 			if walkStmtExprs(s, fn) {
 				return true
@@ -227,6 +240,43 @@ func walkExpr(e ast.Expr, fn func(ast.Expr) bool) bool {
 		return walkExpr(ex.Operand, fn)
 	case *ast.IndexExpr:
 		return walkExpr(ex.Object, fn) || walkExpr(ex.Index, fn)
+	case *ast.SliceExpr:
+		return walkExpr(ex.Object, fn) || walkExpr(ex.Start, fn) || walkExpr(ex.Length, fn)
+	case *ast.FnExpr:
+		for _, s := range ex.Body {
+			if walkStmtExprs(s, fn) {
+				return true
+			}
+		}
+	case *ast.TryExpr:
+		if walkExpr(ex.Expr, fn) {
+			return true
+		}
+		for _, s := range ex.Handler {
+			if walkStmtExprs(s, fn) {
+				return true
+			}
+		}
+		for _, s := range ex.Ensure {
+			if walkStmtExprs(s, fn) {
+				return true
+			}
+		}
+	case *ast.SpawnExpr:
+		for _, s := range ex.Body {
+			if walkStmtExprs(s, fn) {
+				return true
+			}
+		}
+	case *ast.ParallelExpr:
+		if ex.Limit != nil && walkExpr(ex.Limit, fn) {
+			return true
+		}
+		for _, s := range ex.Body {
+			if walkStmtExprs(s, fn) {
+				return true
+			}
+		}
 	case *ast.DotExpr:
 		return walkExpr(ex.Object, fn)
 	case *ast.ArrayLiteral:
@@ -255,6 +305,11 @@ func walkExpr(e ast.Expr, fn func(ast.Expr) bool) bool {
 				return true
 			}
 		}
+		for _, s := range ex.Ensure {
+			if walkStmtExprs(s, fn) {
+				return true
+			}
+		}
 	case *ast.LoweredSpawnExpr:
 		for _, s := range ex.Body {
 			if walkStmtExprs(s, fn) {
@@ -267,6 +322,9 @@ func walkExpr(e ast.Expr, fn func(ast.Expr) bool) bool {
 			}
 		}
 	case *ast.LoweredParallelExpr:
+		if ex.Limit != nil && walkExpr(ex.Limit, fn) {
+			return true
+		}
 		for _, br := range ex.Branches {
 			if br.Expr != nil {
 				if walkExpr(br.Expr, fn) {
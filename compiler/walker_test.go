@@ -324,6 +324,35 @@ func TestWalkNestedExpr(t *testing.T) {
 	}
 }
 
+func TestWalkBitwisePrecedence(t *testing.T) {
+	// Go precedence: & and shifts are multiplicative, | and ^ additive, and
+	// all of them bind tighter than comparisons.
+	tests := []struct {
+		src, outer, inner string
+		innerRight        bool
+	}{
+		{`x = a & b == 0`, "==", "&", false},
+		{`x = 1 + 2 << 1`, "+", "<<", true},
+		{`x = a | b & c`, "|", "&", true},
+		{`x = a ^ b >> 2`, "^", ">>", true},
+	}
+	for _, tt := range tests {
+		prog := parseAndWalk(t, tt.src)
+		outer, ok := prog.Statements[0].(*ast.AssignStmt).Value.(*ast.BinaryExpr)
+		if !ok || outer.Op != tt.outer {
+			t.Fatalf("%s: expected outer %q, got %#v", tt.src, tt.outer, outer)
+		}
+		side := outer.Left
+		if tt.innerRight {
+			side = outer.Right
+		}
+		inner, ok := side.(*ast.BinaryExpr)
+		if !ok || inner.Op != tt.inner {
+			t.Errorf("%s: expected inner %q, got %#v", tt.src, tt.inner, side)
+		}
+	}
+}
+
 func TestWalkMultipleStatements(t *testing.T) {
 	prog := parseAndWalk(t, "x = 1\ny = 2\nputs(x + y)")
 	if len(prog.Statements) != 3 {
@@ -441,6 +470,20 @@ func TestWalkUseStmt(t *testing.T) {
 	}
 }
 
+func TestWalkUseStmtAlias(t *testing.T) {
+	prog := parseAndWalk(t, `use "http" as web`)
+	use, ok := prog.Statements[0].(*ast.UseStmt)
+	if !ok {
+		t.Fatalf("expected ast.UseStmt, got %T", prog.Statements[0])
+	}
+	if use.Module != "http" || use.Alias != "web" {
+		t.Errorf("module, alias = %q, %q, want %q, %q", use.Module, use.Alias, "http", "web")
+	}
+	if use.Namespace() != "web" {
+		t.Errorf("namespace = %q, want %q", use.Namespace(), "web")
+	}
+}
+
 func TestWalkTryExpr(t *testing.T) {
 	prog := parseAndWalk(t, `use "os"`+"\n"+`x = try os.exec("ls") or err`+"\n"+`"fallback"`+"\n"+`end`)
 	found := false
@@ -0,0 +1,151 @@
+package compiler
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Watch polling intervals. A change is acted on once the watched files
+// have been quiet for watchDebounce, so an editor's burst of writes on
+// save triggers a single re-run.
+const (
+	watchPoll     = 250 * time.Millisecond
+	watchDebounce = 200 * time.Millisecond
+)
+
+// Watch compiles and runs filename like Run, then re-runs it each time the
+// file or one of the files it requires changes. Compile errors and non-zero
+// exits are reported and watching continues; a program still running when
+// a change arrives is killed first. Watch runs until interrupted.
+func (c *Compiler) Watch(filename string, extraArgs ...string) error {
+	var watched []string
+	for {
+		fmt.Fprint(os.Stderr, "\033[H\033[2J")
+		fmt.Fprintf(os.Stderr, "[%s] running %s\n", time.Now().Format("15:04:05"), filename)
+
+		run := c.watchCopy()
+		files := []string{filename}
+		var proc *exec.Cmd
+		var done chan error
+		var tmpDir string
+		result, err := run.Compile(filename)
+		for f := range run.loaded {
+			files = append(files, f)
+		}
+		if err == nil && c.Profile != nil {
+			err = writeProfileSymbols(c.Profile, result.Program, result.SourceFile)
+		}
+		if err == nil {
+			var binFile string
+			tmpDir, binFile, err = buildBinary(result)
+			if err == nil {
+				proc = exec.Command(binFile, extraArgs...)
+				proc.Stdout = os.Stdout
+				proc.Stderr = os.Stderr
+				proc.Stdin = os.Stdin
+				if err = proc.Start(); err == nil {
+					done = make(chan error, 1)
+					go func() { done <- proc.Wait() }()
+				}
+			}
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			// A failed compile may stop before reaching the requires, so
+			// keep watching the files from the last run too.
+			files = append(files, watched...)
+		}
+		watched = uniqueFiles(files)
+		fmt.Fprintf(os.Stderr, "[watching %d file(s) for changes]\n", len(watched))
+
+		if exited := waitForChange(watched, done); done != nil && !exited {
+			proc.Process.Kill()
+			<-done
+		}
+		if tmpDir != "" {
+			os.RemoveAll(tmpDir)
+		}
+	}
+}
+
+// watchCopy returns a compiler with c's settings and none of the state a
+// previous compilation left behind.
+func (c *Compiler) watchCopy() *Compiler {
+	return &Compiler{
+		TestMode:     c.TestMode,
+		ModuleDir:    c.ModuleDir,
+		Frozen:       c.Frozen,
+		ShowWarnings: c.ShowWarnings,
+		Sandbox:      c.Sandbox,
+		Profile:      c.Profile,
+		Strict:       c.Strict,
+		WarnUnused:   c.WarnUnused,
+		Resolver:     c.Resolver,
+	}
+}
+
+// waitForChange blocks until one of files changes and then settles. When
+// done is non-nil it receives the running program's exit, which is reported
+// without ending the wait. Reports whether the program exited.
+func waitForChange(files []string, done chan error) (exited bool) {
+	last := statFiles(files)
+	for {
+		select {
+		case err := <-done:
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "[%v]\n", err)
+			}
+			exited, done = true, nil
+		case <-time.After(watchPoll):
+		}
+		cur := statFiles(files)
+		if cur == last {
+			continue
+		}
+		// Debounce: wait until the files stop changing.
+		for {
+			time.Sleep(watchDebounce)
+			next := statFiles(files)
+			if next == cur {
+				return exited
+			}
+			cur = next
+		}
+	}
+}
+
+// statFiles returns a fingerprint of the size and modification time of
+// each file. A missing file contributes an empty entry, so deleting and
+// recreating it (as some editors do on save) also counts as a change.
+func statFiles(files []string) string {
+	var fp string
+	for _, f := range files {
+		if info, err := os.Stat(f); err == nil {
+			fp += fmt.Sprintf("%s:%d:%d\n", f, info.Size(), info.ModTime().UnixNano())
+		} else {
+			fp += f + "\n"
+		}
+	}
+	return fp
+}
+
+// uniqueFiles returns the absolute paths of files, sorted and deduplicated.
+func uniqueFiles(files []string) []string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, f := range files {
+		if abs, err := filepath.Abs(f); err == nil {
+			f = abs
+		}
+		if !seen[f] {
+			seen[f] = true
+			out = append(out, f)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
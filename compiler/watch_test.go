@@ -0,0 +1,40 @@
+package compiler
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWaitForChange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "main.rugo")
+	require.NoError(t, os.WriteFile(path, []byte("puts 1\n"), 0o644))
+
+	done := make(chan error, 1)
+	done <- nil // the program has already exited
+	go func() {
+		time.Sleep(2 * watchPoll)
+		os.WriteFile(path, []byte("puts 22\n"), 0o644)
+	}()
+	start := time.Now()
+	exited := waitForChange([]string{path}, done)
+	assert.True(t, exited)
+	assert.GreaterOrEqual(t, time.Since(start), 2*watchPoll+watchDebounce)
+}
+
+func TestStatFilesMissing(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lib.rugo")
+	missing := statFiles([]string{path})
+	require.NoError(t, os.WriteFile(path, []byte("x = 1\n"), 0o644))
+	assert.NotEqual(t, missing, statFiles([]string{path}), "creating a file is a change")
+}
+
+func TestUniqueFiles(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.rugo")
+	assert.Equal(t, []string{a}, uniqueFiles([]string{a, a}))
+}
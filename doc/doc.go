@@ -33,11 +33,12 @@ type FuncDoc struct {
 
 // StructDoc describes a documented struct.
 type StructDoc struct {
-	Name   string
-	Fields []string
-	Doc    string
-	Line   int    // 1-based line number of the struct keyword
-	Source string // relative path of the source file (set by recursive extraction)
+	Name    string
+	Fields  []string
+	Doc     string
+	Line    int       // 1-based line number of the struct keyword
+	Source  string    // relative path of the source file (set by recursive extraction)
+	Methods []FuncDoc // methods defined with def Name.method, e.g. "Dog.bark"
 }
 
 // ExtractFile reads a Rugo file and extracts all documentation.
@@ -95,7 +96,8 @@ func ExtractDir(dir, entryFile string) (*FileDoc, error) {
 }
 
 // ExtractDirRecursive walks a directory tree and aggregates documentation
-// from all non-test Rugo files. Test files (*_test.rugo) are excluded.
+// from all non-test Rugo files. Test files (*_test.rugo) are excluded, as
+// are paths matching a .rugodocignore file at the root of dir.
 // The entry file's doc becomes the top-level doc.
 func ExtractDirRecursive(dir, entryFile string) (*FileDoc, error) {
 	result := &FileDoc{Path: dir}
@@ -118,10 +120,18 @@ func ExtractDirRecursive(dir, entryFile string) (*FileDoc, error) {
 		entryAbs, _ = filepath.Abs(entryFile)
 	}
 
+	ignore := loadIgnore(dir)
+
 	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return nil
 		}
+		if rel, _ := filepath.Rel(dir, path); rel != "." && ignored(ignore, rel, info.IsDir()) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
 		if info.IsDir() {
 			return nil
 		}
@@ -161,6 +171,9 @@ func tagSource(fd *FileDoc, source string) {
 	}
 	for i := range fd.Structs {
 		fd.Structs[i].Source = source
+		for j := range fd.Structs[i].Methods {
+			fd.Structs[i].Methods[j].Source = source
+		}
 	}
 }
 
@@ -199,6 +212,21 @@ func Extract(src, path string) *FileDoc {
 		structConstructors["new"] = true
 	}
 
+	// Extract struct docs from preprocessor metadata. Methods are grouped
+	// under their struct rather than listed as loose functions.
+	methodOwner := make(map[string]int)
+	for i, si := range prog.Structs {
+		fd.Structs = append(fd.Structs, StructDoc{
+			Name:   si.Name,
+			Fields: si.Fields,
+			Doc:    extractDocComment(lines, si.Line),
+			Line:   si.Line,
+		})
+		for _, m := range si.Methods {
+			methodOwner[si.Name+"."+m] = i
+		}
+	}
+
 	// Extract function docs from AST
 	for _, s := range prog.Statements {
 		fn, ok := s.(*ast.FuncDef)
@@ -219,22 +247,17 @@ func Extract(src, path string) *FileDoc {
 		if strings.HasPrefix(funcName, "_") {
 			continue
 		}
-		fd.Funcs = append(fd.Funcs, FuncDoc{
+		f := FuncDoc{
 			Name:   name,
 			Params: params,
 			Doc:    extractDocComment(lines, defLine),
 			Line:   defLine,
-		})
-	}
-
-	// Extract struct docs from preprocessor metadata
-	for _, si := range prog.Structs {
-		fd.Structs = append(fd.Structs, StructDoc{
-			Name:   si.Name,
-			Fields: si.Fields,
-			Doc:    extractDocComment(lines, si.Line),
-			Line:   si.Line,
-		})
+		}
+		if i, ok := methodOwner[name]; ok {
+			fd.Structs[i].Methods = append(fd.Structs[i].Methods, f)
+			continue
+		}
+		fd.Funcs = append(fd.Funcs, f)
 	}
 
 	return fd
@@ -277,6 +300,9 @@ func extractFuncsFromLines(fd *FileDoc, lines []string) {
 						break
 					}
 					if ft != "" && !strings.HasPrefix(ft, "#") {
+						if field, _, ok := strings.Cut(ft, "="); ok && !strings.ContainsAny(field, "!<>") {
+							ft = strings.TrimSpace(field)
+						}
 						fields = append(fields, ft)
 					}
 				}
@@ -409,21 +435,50 @@ func isRugoFile(name string) bool {
 func LookupSymbol(fd *FileDoc, name string) (doc string, signature string, found bool) {
 	for _, f := range fd.Funcs {
 		if f.Name == name {
-			sig := "def " + f.Name
-			if len(f.Params) > 0 {
-				sig += "(" + strings.Join(f.Params, ", ") + ")"
-			}
-			return f.Doc, sig, true
+			return f.Doc, funcSignature(f), true
 		}
 	}
 	for _, s := range fd.Structs {
 		if s.Name == name {
-			sig := "struct " + s.Name
-			if len(s.Fields) > 0 {
-				sig += " { " + strings.Join(s.Fields, ", ") + " }"
+			return s.Doc, structSignature(s), true
+		}
+		for _, m := range s.Methods {
+			if m.Name == name {
+				return m.Doc, funcSignature(m), true
 			}
-			return s.Doc, sig, true
 		}
 	}
 	return "", "", false
 }
+
+// documented reports whether a struct or any of its methods has a doc
+// comment, i.e. whether it belongs in a listing.
+func documented(s StructDoc) bool {
+	if s.Doc != "" {
+		return true
+	}
+	for _, m := range s.Methods {
+		if m.Doc != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// funcSignature returns a function's signature, e.g. "def add(a, b)".
+func funcSignature(f FuncDoc) string {
+	sig := "def " + f.Name
+	if len(f.Params) > 0 {
+		sig += "(" + strings.Join(f.Params, ", ") + ")"
+	}
+	return sig
+}
+
+// structSignature returns a struct's signature, e.g. "struct Dog { name }".
+func structSignature(s StructDoc) string {
+	sig := "struct " + s.Name
+	if len(s.Fields) > 0 {
+		sig += " { " + strings.Join(s.Fields, ", ") + " }"
+	}
+	return sig
+}
@@ -2,6 +2,8 @@ package doc
 
 import (
 	"os"
+	"path/filepath"
+	"sort"
 	"strings"
 	"testing"
 
@@ -123,6 +125,47 @@ end
 	}
 }
 
+func TestExtract_StructMethodsGrouped(t *testing.T) {
+	src := `struct Dog
+  name
+end
+
+# Makes the dog bark.
+def Dog.bark()
+  return self.name
+end
+
+def Dog._wag()
+end
+
+# Not a method.
+def helper()
+end
+`
+	fd := Extract(src, "test.rugo")
+	if len(fd.Funcs) != 1 || fd.Funcs[0].Name != "helper" {
+		t.Fatalf("funcs = %+v, want only helper", fd.Funcs)
+	}
+	if len(fd.Structs) != 1 {
+		t.Fatalf("expected 1 struct, got %d", len(fd.Structs))
+	}
+	methods := fd.Structs[0].Methods
+	if len(methods) != 1 || methods[0].Name != "Dog.bark" || methods[0].Doc != "Makes the dog bark." {
+		t.Fatalf("methods = %+v", methods)
+	}
+
+	doc, sig, found := LookupSymbol(fd, "Dog.bark")
+	if !found || doc != "Makes the dog bark." || sig != "def Dog.bark" {
+		t.Errorf("LookupSymbol(Dog.bark) = %q, %q, %v", doc, sig, found)
+	}
+	if got := FormatFile(fd); !strings.Contains(got, "struct Dog { name }\n\n  def Dog.bark\n      Makes the dog bark.\n") {
+		t.Errorf("FormatFile should list the method under its struct:\n%s", got)
+	}
+	if got := RenderMarkdown(fd); !strings.Contains(got, "### Dog\n\n```ruby\nstruct Dog { name }\n```\n\n#### Dog.bark\n\n") {
+		t.Errorf("RenderMarkdown should nest the method under its struct:\n%s", got)
+	}
+}
+
 func TestExtract_HeredocSkipsComments(t *testing.T) {
 	src := `msg = <<HEREDOC
 # This is NOT a comment
@@ -216,6 +259,39 @@ end
 	}
 }
 
+func TestRenderMarkdown(t *testing.T) {
+	src := `# Geometry helpers.
+
+# Scales a point.
+def scale(p, k)
+end
+
+# Adds two points.
+def add(a, b)
+end
+
+def undocumented()
+end
+
+# A Point.
+struct Point
+  x
+  y
+end
+`
+	fd := Extract(src, "lib/geo.rugo")
+	fd.Funcs[0].Source = "lib/geo.rugo"
+	want := "# geo\n\nGeometry helpers.\n\n" +
+		"## Functions\n\n" +
+		"### add\n\n```ruby\ndef add(a, b)\n```\n\nAdds two points.\n\n" +
+		"### scale\n\n```ruby\ndef scale(p, k)\n```\n\nScales a point.\n\nDefined in `lib/geo.rugo`.\n\n" +
+		"## Structs\n\n" +
+		"### Point\n\n```ruby\nstruct Point { x, y }\n```\n\nA Point.\n"
+	if got := RenderMarkdown(fd); got != want {
+		t.Errorf("RenderMarkdown =\n%s\nwant:\n%s", got, want)
+	}
+}
+
 func TestExtract_FileDocBeforeCode(t *testing.T) {
 	src := `# Module documentation.
 # More details.
@@ -408,3 +484,51 @@ func TestStructNameFromWrapper(t *testing.T) {
 		}
 	}
 }
+
+func TestExtractDirRecursiveIgnore(t *testing.T) {
+	dir := t.TempDir()
+	for _, sub := range []string{"vendor/x", "examples", "lib/internal", "lib/deep/gen"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile(t, dir, "main.rugo", "# Top-level doc.\n")
+	writeFile(t, dir, "api.rugo", "def api()\nend\n")
+	writeFile(t, dir, "vendor/x/dep.rugo", "def dep()\nend\n")
+	writeFile(t, dir, "examples/demo.rugo", "def demo()\nend\n")
+	writeFile(t, dir, "lib/internal/helper.rugo", "def helper()\nend\n")
+	writeFile(t, dir, "lib/deep/gen/out.rugo", "def generated()\nend\n")
+	writeFile(t, dir, "lib/scratch_tmp.rugo", "def scratch()\nend\n")
+	writeFile(t, dir, "lib/util.rugo", "def util()\nend\n")
+	writeFile(t, dir, IgnoreFile, "# not part of the public API\nvendor/\n/examples\nlib/internal\n**/gen/**\n*_tmp.rugo\n")
+
+	fd, err := ExtractDirRecursive(dir, filepath.Join(dir, "main.rugo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, f := range fd.Funcs {
+		names = append(names, f.Name)
+	}
+	sort.Strings(names)
+	if strings.Join(names, ",") != "api,util" {
+		t.Errorf("funcs = %v, want [api util]", names)
+	}
+}
+
+func TestExtractDirRecursiveNoIgnoreFile(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "vendor"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, dir, "main.rugo", "def main_fn()\nend\n")
+	writeFile(t, dir, "vendor/dep.rugo", "def dep()\nend\n")
+
+	fd, err := ExtractDirRecursive(dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fd.Funcs) != 2 {
+		t.Errorf("expected 2 funcs, got %d", len(fd.Funcs))
+	}
+}
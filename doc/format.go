@@ -30,7 +30,7 @@ func FormatFile(fd *FileDoc) string {
 	}
 	if !hasSource {
 		for _, s := range fd.Structs {
-			if s.Source != "" && s.Doc != "" {
+			if s.Source != "" && documented(s) {
 				hasSource = true
 				break
 			}
@@ -40,7 +40,7 @@ func FormatFile(fd *FileDoc) string {
 	if !hasSource {
 		// Single-file mode: flat list
 		for _, s := range fd.Structs {
-			if s.Doc == "" {
+			if !documented(s) {
 				continue
 			}
 			formatStruct(&sb, s)
@@ -59,7 +59,7 @@ func FormatFile(fd *FileDoc) string {
 		for _, src := range sources {
 			sb.WriteString(src + ":\n\n")
 			for _, s := range fd.Structs {
-				if s.Source != src || s.Doc == "" {
+				if s.Source != src || !documented(s) {
 					continue
 				}
 				sb.WriteString("  ")
@@ -86,7 +86,7 @@ func sourceOrder(fd *FileDoc) []string {
 	seen := make(map[string]bool)
 	var order []string
 	for _, s := range fd.Structs {
-		if s.Source != "" && s.Doc != "" && !seen[s.Source] {
+		if s.Source != "" && documented(s) && !seen[s.Source] {
 			seen[s.Source] = true
 			order = append(order, s.Source)
 		}
@@ -250,6 +250,17 @@ func formatStruct(sb *strings.Builder, s StructDoc) {
 		sb.WriteString(strings.ReplaceAll(s.Doc, "\n", "\n    "))
 		sb.WriteString("\n")
 	}
+	// Documented methods are listed under their struct, indented.
+	for _, m := range s.Methods {
+		if m.Doc == "" {
+			continue
+		}
+		var mb strings.Builder
+		formatFunc(&mb, m)
+		sb.WriteString("\n  ")
+		sb.WriteString(strings.ReplaceAll(strings.TrimRight(mb.String(), "\n"), "\n", "\n  "))
+		sb.WriteString("\n")
+	}
 }
 
 func formatFunc(sb *strings.Builder, f FuncDoc) {
@@ -0,0 +1,93 @@
+package doc
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// IgnoreFile is the name of the optional file at the root of a documented
+// directory that lists gitignore-style patterns to exclude from the docs.
+const IgnoreFile = ".rugodocignore"
+
+// ignorePattern is one line of a .rugodocignore file.
+type ignorePattern struct {
+	segs     []string // slash-separated glob segments; "**" spans directories
+	anchored bool     // contains a slash: matched against the path from the root
+	dirOnly  bool     // trailing slash: matches directories only
+}
+
+// loadIgnore reads dir/.rugodocignore. A missing file yields no patterns.
+// Blank lines and # comments are skipped.
+func loadIgnore(dir string) []ignorePattern {
+	data, err := os.ReadFile(filepath.Join(dir, IgnoreFile))
+	if err != nil {
+		return nil
+	}
+	var patterns []ignorePattern
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		var p ignorePattern
+		if strings.HasSuffix(line, "/") {
+			p.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		if strings.Contains(line, "/") {
+			p.anchored = true
+			line = strings.TrimPrefix(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+		p.segs = strings.Split(line, "/")
+		patterns = append(patterns, p)
+	}
+	return patterns
+}
+
+// ignored reports whether rel (a path relative to the documented root)
+// matches any pattern. Patterns without a slash match a file or directory
+// name at any depth; anchored patterns match the whole relative path.
+func ignored(patterns []ignorePattern, rel string, isDir bool) bool {
+	segs := strings.Split(filepath.ToSlash(rel), "/")
+	for _, p := range patterns {
+		if p.dirOnly && !isDir {
+			continue
+		}
+		if p.anchored {
+			if matchSegments(p.segs, segs) {
+				return true
+			}
+			continue
+		}
+		if ok, _ := path.Match(p.segs[0], segs[len(segs)-1]); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// matchSegments matches glob segments against path segments. A "**"
+// segment matches zero or more path segments.
+func matchSegments(pattern, parts []string) bool {
+	if len(pattern) == 0 {
+		return len(parts) == 0
+	}
+	if pattern[0] == "**" {
+		for skip := 0; skip <= len(parts); skip++ {
+			if matchSegments(pattern[1:], parts[skip:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(parts) == 0 {
+		return false
+	}
+	ok, _ := path.Match(pattern[0], parts[0])
+	return ok && matchSegments(pattern[1:], parts[1:])
+}
@@ -0,0 +1,67 @@
+package doc
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// RenderMarkdown formats a FileDoc as a Markdown document: the file doc as
+// an intro, then "Functions" and "Structs" sections with one heading per
+// documented item, sorted by name. Struct methods get a sub-heading under
+// their struct. Items from recursive extraction note the file they come from.
+func RenderMarkdown(fd *FileDoc) string {
+	var sb strings.Builder
+
+	title := strings.TrimSuffix(filepath.Base(fd.Path), filepath.Ext(fd.Path))
+	sb.WriteString("# " + title + "\n\n")
+	if fd.Doc != "" {
+		sb.WriteString(fd.Doc + "\n\n")
+	}
+
+	var funcs []FuncDoc
+	for _, f := range fd.Funcs {
+		if f.Doc != "" {
+			funcs = append(funcs, f)
+		}
+	}
+	sort.SliceStable(funcs, func(i, j int) bool { return funcs[i].Name < funcs[j].Name })
+	if len(funcs) > 0 {
+		sb.WriteString("## Functions\n\n")
+		for _, f := range funcs {
+			writeMarkdownItem(&sb, "###", f.Name, funcSignature(f), f.Doc, f.Source)
+		}
+	}
+
+	var structs []StructDoc
+	for _, s := range fd.Structs {
+		if documented(s) {
+			structs = append(structs, s)
+		}
+	}
+	sort.SliceStable(structs, func(i, j int) bool { return structs[i].Name < structs[j].Name })
+	if len(structs) > 0 {
+		sb.WriteString("## Structs\n\n")
+		for _, s := range structs {
+			writeMarkdownItem(&sb, "###", s.Name, structSignature(s), s.Doc, s.Source)
+			for _, m := range s.Methods {
+				if m.Doc != "" {
+					writeMarkdownItem(&sb, "####", m.Name, funcSignature(m), m.Doc, "")
+				}
+			}
+		}
+	}
+
+	return strings.TrimRight(sb.String(), "\n") + "\n"
+}
+
+func writeMarkdownItem(sb *strings.Builder, heading, name, signature, docStr, source string) {
+	sb.WriteString(heading + " " + name + "\n\n")
+	sb.WriteString("```ruby\n" + signature + "\n```\n\n")
+	if docStr != "" {
+		sb.WriteString(docStr + "\n\n")
+	}
+	if source != "" {
+		sb.WriteString("Defined in `" + source + "`.\n\n")
+	}
+}
@@ -838,8 +838,9 @@ func FuncAdapterConv(argExpr string, ft *GoFuncType) string {
 		strings.Join(params, ", "), retType, argExpr, retExpr)
 }
 
-// StructDecompCode generates the Go hash literal for struct decomposition.
-// varName is the Go variable holding the struct (e.g., "_v").
+// StructDecompCode generates the Go hash literal for struct decomposition,
+// keeping the struct's field order. varName is the Go variable holding the
+// struct (e.g., "_v").
 func StructDecompCode(varName string, sr *GoStructReturn) string {
 	var entries []string
 	for _, f := range sr.Fields {
@@ -851,9 +852,9 @@ func StructDecompCode(varName string, sr *GoStructReturn) string {
 		} else {
 			valExpr = fmt.Sprintf("%s.%s", varName, f.GoField)
 		}
-		entries = append(entries, fmt.Sprintf("\t\t%q: %s,", f.RugoKey, TypeWrapReturn(valExpr, f.Type)))
+		entries = append(entries, fmt.Sprintf("\t\t%q, %s,", f.RugoKey, TypeWrapReturn(valExpr, f.Type)))
 	}
-	return "map[interface{}]interface{}{\n" + strings.Join(entries, "\n") + "\n\t}"
+	return "rugo_hash_lit(\n" + strings.Join(entries, "\n") + "\n\t)"
 }
 
 // TypeWrapReturn returns the Go expression to wrap a Go return value to interface{}.
@@ -15,6 +15,7 @@ import (
 	_ "github.com/rubiojr/rugo/modules/hex"
 	_ "github.com/rubiojr/rugo/modules/http"
 	_ "github.com/rubiojr/rugo/modules/json"
+	_ "github.com/rubiojr/rugo/modules/log"
 	_ "github.com/rubiojr/rugo/modules/math"
 	_ "github.com/rubiojr/rugo/modules/os"
 	_ "github.com/rubiojr/rugo/modules/queue"
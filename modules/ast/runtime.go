@@ -30,11 +30,11 @@ func (*AST) ParseSource(source, name string) interface{} {
 }
 
 func (*AST) SourceLines(prog, stmt interface{}) interface{} {
-	progHash, ok := prog.(map[interface{}]interface{})
+	progHash, ok := rugo_hash_map(prog)
 	if !ok {
 		panic("ast.source_lines: first argument must be a program hash")
 	}
-	stmtHash, ok := stmt.(map[interface{}]interface{})
+	stmtHash, ok := rugo_hash_map(stmt)
 	if !ok {
 		panic("ast.source_lines: second argument must be a statement hash")
 	}
@@ -118,6 +118,12 @@ func convertStmt(s ast.Statement) map[interface{}]interface{} {
 		m["type"] = "bench"
 		m["name"] = st.Name
 		m["body"] = convertBody(st.Body)
+		if st.Iterations != nil {
+			m["iterations"] = convertExpr(st.Iterations)
+		}
+		if st.MinTime != nil {
+			m["min_time"] = convertExpr(st.MinTime)
+		}
 
 	case *ast.IfStmt:
 		m["type"] = "if"
@@ -134,6 +140,7 @@ func convertStmt(s ast.Statement) map[interface{}]interface{} {
 	case *ast.WhileStmt:
 		m["type"] = "while"
 		m["body"] = convertBody(st.Body)
+		m["else_body"] = convertBody(st.ElseBody)
 
 	case *ast.ForStmt:
 		m["type"] = "for"
@@ -170,6 +177,9 @@ func convertStmt(s ast.Statement) map[interface{}]interface{} {
 	case *ast.UseStmt:
 		m["type"] = "use"
 		m["module"] = st.Module
+		if st.Alias != "" {
+			m["alias"] = st.Alias
+		}
 
 	case *ast.ImportStmt:
 		m["type"] = "import"
@@ -177,6 +187,13 @@ func convertStmt(s ast.Statement) map[interface{}]interface{} {
 		if st.Alias != "" {
 			m["alias"] = st.Alias
 		}
+		if len(st.Funcs) > 0 {
+			funcs := make(map[interface{}]interface{}, len(st.Funcs))
+			for name, goName := range st.Funcs {
+				funcs[name] = goName
+			}
+			m["funcs"] = funcs
+		}
 
 	case *ast.RequireStmt:
 		m["type"] = "require"
@@ -191,6 +208,9 @@ func convertStmt(s ast.Statement) map[interface{}]interface{} {
 			}
 			m["with"] = with
 		}
+		if st.Optional {
+			m["optional"] = true
+		}
 
 	default:
 		m["type"] = "unknown"
@@ -232,6 +252,9 @@ func convertExpr(e ast.Expr) map[interface{}]interface{} {
 		m["type"] = "dot"
 		m["object"] = convertExpr(ex.Object)
 		m["field"] = ex.Field
+		if ex.Safe {
+			m["safe"] = true
+		}
 
 	case *ast.StringLiteral:
 		m["type"] = "string"
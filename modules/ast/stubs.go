@@ -0,0 +1,8 @@
+package astmod
+
+// Runtime helper stubs for standalone compilation and testing.
+
+func rugo_hash_map(v interface{}) (map[interface{}]interface{}, bool) {
+	m, ok := v.(map[interface{}]interface{})
+	return m, ok
+}
@@ -11,10 +11,11 @@ var runtime string
 
 func init() {
 	modules.Register(&modules.Module{
-		Name:    "bench",
-		Type:    "Bench",
-		Doc:     "Benchmarking framework for measuring code performance.",
-		Funcs:   []modules.FuncDef{},
-		Runtime: modules.CleanRuntime(runtime),
+		Name:      "bench",
+		Type:      "Bench",
+		Doc:       "Benchmarking framework for measuring code performance.",
+		Funcs:     []modules.FuncDef{},
+		GoImports: []string{"runtime"},
+		Runtime:   modules.CleanRuntime(runtime),
 	})
 }
@@ -5,6 +5,7 @@ package benchmod
 import (
 	"fmt"
 	"os"
+	"runtime"
 	"time"
 )
 
@@ -12,13 +13,45 @@ import (
 
 type Bench struct{}
 
-// rugoBenchCase describes a single benchmark for the runner.
+// rugoBenchCase describes a single benchmark for the runner. Iterations
+// fixes the run count; otherwise the runner calibrates until a run takes
+// at least MinTime seconds (1s when zero).
 type rugoBenchCase struct {
-	Name string
-	Func func()
+	Name       string
+	Func       func()
+	Iterations int
+	MinTime    float64
 }
 
-// rugo_bench_runner executes benchmarks with auto-calibration and reports timing.
+// rugo_bench_iterations validates the iterations option of a bench block.
+func rugo_bench_iterations(name string, v interface{}) int {
+	n := rugo_to_int(v)
+	if n < 1 {
+		panic(fmt.Sprintf("bench %q: iterations must be at least 1, got %d", name, n))
+	}
+	return n
+}
+
+// rugo_bench_min_time validates the min_time option of a bench block.
+func rugo_bench_min_time(name string, v interface{}) float64 {
+	secs := rugo_to_float(v)
+	if secs <= 0 {
+		panic(fmt.Sprintf("bench %q: min_time must be positive, got %g", name, secs))
+	}
+	return secs
+}
+
+// rugoBenchResult is the measurement of one benchmark.
+type rugoBenchResult struct {
+	Name        string
+	N           int
+	NsPerOp     float64
+	AllocsPerOp uint64
+}
+
+// rugo_bench_runner executes benchmarks with auto-calibration and reports
+// timing, allocations and, for more than one benchmark, how each compares
+// to the fastest.
 func rugo_bench_runner(benches []rugoBenchCase) {
 	if len(benches) == 0 {
 		fmt.Fprintln(os.Stderr, "no benchmarks to run")
@@ -37,40 +70,86 @@ func rugo_bench_runner(benches []rugoBenchCase) {
 		colorReset = ""
 	}
 
+	var results []rugoBenchResult
 	for _, b := range benches {
 		// Warm up: run once to avoid cold-start effects
 		b.Func()
 
-		// Calibrate: find N such that total time is >= 1 second
-		n := 1
-		var elapsed time.Duration
-		for {
-			start := time.Now()
-			for range n {
-				b.Func()
-			}
-			elapsed = time.Since(start)
-			if elapsed >= time.Second {
-				break
-			}
-			// Scale up: aim for 1s based on current rate
-			if elapsed > 0 {
-				next := int(float64(n) * float64(time.Second) / float64(elapsed))
-				if next <= n {
-					next = n * 2
-				}
-				n = next
-			} else {
-				n *= 10
-			}
+		var r rugoBenchResult
+		if b.Iterations > 0 {
+			r = rugoBenchRun(b, b.Iterations)
+		} else {
+			r = rugoBenchCalibrate(b)
+		}
+		results = append(results, r)
+
+		fmt.Fprintf(os.Stderr, "  %s%-40s%s %s%10s/op%s %6d allocs/op %s(%d runs)%s\n",
+			colorName, r.Name, colorReset,
+			colorTime, formatDuration(r.NsPerOp), colorReset,
+			r.AllocsPerOp,
+			colorRuns, r.N, colorReset)
+	}
+
+	if len(results) < 2 {
+		return
+	}
+	fastest := results[0].NsPerOp
+	for _, r := range results[1:] {
+		if r.NsPerOp < fastest {
+			fastest = r.NsPerOp
+		}
+	}
+	fmt.Fprintln(os.Stderr, "\n  relative to fastest:")
+	for _, r := range results {
+		rel := "fastest"
+		if r.NsPerOp > fastest && fastest > 0 {
+			rel = fmt.Sprintf("%.2fx slower", r.NsPerOp/fastest)
+		}
+		fmt.Fprintf(os.Stderr, "  %s%-40s%s %s%s%s\n", colorName, r.Name, colorReset, colorTime, rel, colorReset)
+	}
+}
+
+// rugoBenchCalibrate grows the run count until one run lasts at least the
+// benchmark's minimum time, like Go's testing.B: each step predicts the
+// count needed from the last rate, with 20% headroom, growing at most
+// 100x at a time.
+func rugoBenchCalibrate(b rugoBenchCase) rugoBenchResult {
+	target := time.Second
+	if b.MinTime > 0 {
+		target = time.Duration(b.MinTime * float64(time.Second))
+	}
+	n := 1
+	for {
+		r := rugoBenchRun(b, n)
+		elapsed := time.Duration(r.NsPerOp * float64(n))
+		if elapsed >= target || n >= 1e9 {
+			return r
+		}
+		next := n * 100
+		if elapsed > 0 {
+			next = int(float64(n) * float64(target) / float64(elapsed) * 1.2)
 		}
+		next = min(max(next, n+1), n*100, 1e9)
+		n = next
+	}
+}
 
-		nsPerOp := float64(elapsed.Nanoseconds()) / float64(n)
-		timeStr := formatDuration(nsPerOp)
-		fmt.Fprintf(os.Stderr, "  %s%-40s%s %s%10s/op%s %s(%d runs)%s\n",
-			colorName, b.Name, colorReset,
-			colorTime, timeStr, colorReset,
-			colorRuns, n, colorReset)
+// rugoBenchRun times n calls of the benchmark and counts their allocations.
+func rugoBenchRun(b rugoBenchCase, n int) rugoBenchResult {
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	for range n {
+		b.Func()
+	}
+	elapsed := time.Since(start)
+	runtime.ReadMemStats(&after)
+	return rugoBenchResult{
+		Name:        b.Name,
+		N:           n,
+		NsPerOp:     float64(elapsed.Nanoseconds()) / float64(n),
+		AllocsPerOp: (after.Mallocs - before.Mallocs) / uint64(n),
 	}
 }
 
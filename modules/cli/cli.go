@@ -19,7 +19,7 @@ func init() {
 			{Name: "name", Args: []modules.ArgType{modules.String}, Doc: "Set the application name."},
 			{Name: "version", Args: []modules.ArgType{modules.String}, Doc: "Set the application version string."},
 			{Name: "about", Args: []modules.ArgType{modules.String}, Doc: "Set the application description."},
-			{Name: "cmd", Args: []modules.ArgType{modules.String, modules.String}, Doc: "Register a subcommand with a name and description."},
+			{Name: "cmd", Args: []modules.ArgType{modules.String, modules.String}, Variadic: true, MaxArgs: 3, Doc: "Register a subcommand with a name, description, and optional options hash (\"aliases\", \"group\")."},
 			{Name: "flag", Args: []modules.ArgType{modules.String, modules.String, modules.String, modules.String, modules.String}, Doc: "Add a flag with command, name, short, description, and default value."},
 			{Name: "bool_flag", Args: []modules.ArgType{modules.String, modules.String, modules.String, modules.String}, Doc: "Add a boolean flag with command, name, short, and description."},
 			{Name: "run", Args: nil, Doc: "Parse arguments and dispatch to the matched subcommand handler."},
@@ -34,6 +34,7 @@ func init() {
 		DispatchTransform: func(s string) string {
 			return strings.NewReplacer(":", "_", "-", "_", " ", "_").Replace(s)
 		},
-		Runtime: modules.CleanRuntime(runtime),
+		GoImports: []string{"path/filepath"},
+		Runtime:   modules.CleanRuntime(runtime),
 	})
 }
@@ -1,6 +1,8 @@
 package climod
 
 import (
+	"io"
+	"os"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -279,3 +281,108 @@ func TestColonSubcommandParsing(t *testing.T) {
 
 	assert.Equal(t, "db:migrate", c.matched)
 }
+
+func TestCmdOptions(t *testing.T) {
+	c := newCLI()
+	c.Cmd("remove", "Remove a file", map[interface{}]interface{}{
+		"aliases": []interface{}{"rm", "del"},
+		"group":   "File",
+	})
+
+	require.Len(t, c.commands, 1)
+	assert.Equal(t, []string{"rm", "del"}, c.commands[0].Aliases)
+	assert.Equal(t, "File", c.commands[0].Group)
+
+	assert.Panics(t, func() { c.Cmd("x", "X", "File") })
+	assert.Panics(t, func() { c.Cmd("x", "X", map[interface{}]interface{}{"aliases": "rm"}) })
+	assert.Panics(t, func() { c.Cmd("x", "X", map[interface{}]interface{}{"alias": []interface{}{"rm"}}) })
+}
+
+func TestMatchCommandAlias(t *testing.T) {
+	c := newCLI()
+	c.Cmd("remove", "Remove a file", map[interface{}]interface{}{"aliases": []interface{}{"rm"}})
+	c.Cmd("db migrate", "Run migrations", map[interface{}]interface{}{"aliases": []interface{}{"dbm"}})
+
+	match, consumed := c.matchCommand([]string{"rm", "a.txt"})
+	assert.Equal(t, "remove", match, "alias should resolve to the canonical name")
+	assert.Equal(t, 1, consumed)
+
+	match, consumed = c.matchCommand([]string{"dbm"})
+	assert.Equal(t, "db migrate", match)
+	assert.Equal(t, 1, consumed)
+}
+
+func TestPrintHelpGroups(t *testing.T) {
+	c := newCLI()
+	c.Name("app")
+	c.Cmd("remove", "Remove a file", map[interface{}]interface{}{"aliases": []interface{}{"rm"}, "group": "File"})
+	c.Cmd("version", "Show version")
+	c.Cmd("copy", "Copy a file", map[interface{}]interface{}{"group": "File"})
+
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	stderr := os.Stderr
+	os.Stderr = w
+	c.printHelp()
+	os.Stderr = stderr
+	w.Close()
+	out, err := io.ReadAll(r)
+	require.NoError(t, err)
+
+	assert.Contains(t, string(out), "Commands:\n  version      Show version\n\nFile:\n  remove (rm)  Remove a file\n  copy         Copy a file\n")
+}
+
+func completionCLI() *CLI {
+	c := newCLI()
+	c.Name("app")
+	c.Version("1.0.0")
+	c.Cmd("remove", "Remove a file", map[interface{}]interface{}{"aliases": []interface{}{"rm"}})
+	c.Cmd("db migrate", "Run migrations")
+	c.Flag("remove", "force", "f", "Don't ask", "")
+	c.BoolFlag("", "verbose", "v", "Verbose output")
+	return c
+}
+
+func TestCompletionWords(t *testing.T) {
+	keys, words := completionCLI().completionWords()
+
+	assert.Equal(t, []string{"", "remove", "rm", "db", "db migrate"}, keys)
+	assert.Equal(t, []string{"remove", "rm", "db", "--verbose", "-v", "--help", "-h", "--version", "-V"}, words[""])
+	assert.Equal(t, []string{"--force", "-f", "--verbose", "-v", "--help", "-h"}, words["rm"])
+	assert.Equal(t, []string{"migrate"}, words["db"])
+	assert.Equal(t, []string{"--verbose", "-v", "--help", "-h"}, words["db migrate"])
+}
+
+func TestCompletionScript(t *testing.T) {
+	c := completionCLI()
+
+	bash, err := c.completionScript("bash")
+	require.NoError(t, err)
+	assert.Contains(t, bash, "'db') opts='migrate'; break ;;")
+	assert.Contains(t, bash, "complete -F _app_completion app\n")
+
+	zsh, err := c.completionScript("zsh")
+	require.NoError(t, err)
+	assert.Contains(t, zsh, "bashcompinit")
+	assert.Contains(t, zsh, "complete -F _app_completion app\n")
+
+	fish, err := c.completionScript("fish")
+	require.NoError(t, err)
+	assert.Contains(t, fish, "complete -c app -n 'not __fish_seen_subcommand_from remove rm db' -a 'remove' -d 'Remove a file'\n")
+	assert.Contains(t, fish, "complete -c app -n '__fish_seen_subcommand_from db' -a 'migrate' -d 'Run migrations'\n")
+	assert.Contains(t, fish, "complete -c app -n '__fish_seen_subcommand_from remove rm' -l 'force' -s 'f' -r -d 'Don'\\''t ask'\n")
+	assert.Contains(t, fish, "complete -c app -l 'verbose' -s 'v' -d 'Verbose output'\n")
+
+	_, err = c.completionScript("")
+	assert.EqualError(t, err, "missing shell (usage: app completion bash|zsh|fish)")
+	_, err = c.completionScript("tcsh")
+	assert.EqualError(t, err, `unsupported shell "tcsh" (expected bash, zsh or fish)`)
+}
+
+func TestCompletionYieldsToUserCommand(t *testing.T) {
+	c := newCLI()
+	c.Cmd("completion", "Custom completion")
+	c.parseArgs([]string{"completion", "bash"})
+
+	assert.Equal(t, "completion", c.matched)
+}
@@ -3,14 +3,17 @@ package climod
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 )
 
 // --- cli module ---
 
 type cliCommand struct {
-	Name string
-	Desc string
+	Name    string
+	Desc    string
+	Aliases []string
+	Group   string
 }
 
 type cliFlag struct {
@@ -49,11 +52,50 @@ func (c *CLI) About(desc string) interface{} {
 	return nil
 }
 
-func (c *CLI) Cmd(name, desc string) interface{} {
-	c.commands = append(c.commands, cliCommand{Name: name, Desc: desc})
+func (c *CLI) Cmd(name, desc string, opts ...interface{}) interface{} {
+	cmd := cliCommand{Name: name, Desc: desc}
+	if len(opts) > 0 {
+		h, ok := rugo_hash_map(opts[0])
+		if !ok {
+			panic(fmt.Sprintf("cli.cmd: options for %q must be a hash", name))
+		}
+		for k, v := range h {
+			switch k {
+			case "aliases":
+				list, ok := v.([]interface{})
+				if !ok {
+					panic(fmt.Sprintf("cli.cmd: aliases for %q must be an array of strings", name))
+				}
+				for _, a := range list {
+					alias, ok := a.(string)
+					if !ok {
+						panic(fmt.Sprintf("cli.cmd: aliases for %q must be an array of strings", name))
+					}
+					cmd.Aliases = append(cmd.Aliases, alias)
+				}
+			case "group":
+				group, ok := v.(string)
+				if !ok {
+					panic(fmt.Sprintf("cli.cmd: group for %q must be a string", name))
+				}
+				cmd.Group = group
+			default:
+				panic(fmt.Sprintf("cli.cmd: unknown option %v for %q (expected \"aliases\" or \"group\")", k, name))
+			}
+		}
+	}
+	c.commands = append(c.commands, cmd)
 	return nil
 }
 
+// label is the command name as shown in help, followed by its aliases.
+func (cmd cliCommand) label() string {
+	if len(cmd.Aliases) == 0 {
+		return cmd.Name
+	}
+	return cmd.Name + " (" + strings.Join(cmd.Aliases, ", ") + ")"
+}
+
 func (c *CLI) Flag(cmd, long, short, desc, defVal string) interface{} {
 	c.flags = append(c.flags, cliFlag{
 		Cmd: cmd, Long: long, Short: short, Desc: desc, Default: defVal,
@@ -155,6 +197,9 @@ func (c *CLI) parseArgs(args []string) {
 			fmt.Printf("%s %s\n", c.appName, c.appVersion)
 			os.Exit(0)
 		}
+		if arg == "completion" && !c.hasCommand("completion") {
+			c.printCompletion(args[i+1:])
+		}
 		if arg == "--" {
 			i++
 		} else if strings.HasPrefix(arg, "-") {
@@ -236,26 +281,29 @@ func (c *CLI) parseArgs(args []string) {
 	}
 }
 
-// matchCommand tries to match the longest multi-word command from the given args.
-// Returns the matched command name and the number of args consumed.
+// matchCommand tries to match the longest multi-word command or alias from
+// the given args. Returns the matched command's canonical name, so an alias
+// dispatches to the same handler, and the number of args consumed.
 func (c *CLI) matchCommand(args []string) (string, int) {
 	bestMatch := ""
 	bestLen := 0
 	for _, cmd := range c.commands {
-		parts := strings.Split(cmd.Name, " ")
-		if len(parts) > len(args) {
-			continue
-		}
-		match := true
-		for j, p := range parts {
-			if args[j] != p {
-				match = false
-				break
+		for _, name := range append([]string{cmd.Name}, cmd.Aliases...) {
+			parts := strings.Split(name, " ")
+			if len(parts) > len(args) {
+				continue
+			}
+			match := true
+			for j, p := range parts {
+				if args[j] != p {
+					match = false
+					break
+				}
+			}
+			if match && len(parts) > bestLen {
+				bestMatch = cmd.Name
+				bestLen = len(parts)
 			}
-		}
-		if match && len(parts) > bestLen {
-			bestMatch = cmd.Name
-			bestLen = len(parts)
 		}
 	}
 	return bestMatch, bestLen
@@ -296,16 +344,40 @@ func (c *CLI) printHelp() {
 	}
 
 	if len(c.commands) > 0 {
-		fmt.Fprintln(os.Stderr, "\nCommands:")
-		// Group by prefix for subcommands
 		maxLen := 0
 		for _, cmd := range c.commands {
-			if len(cmd.Name) > maxLen {
-				maxLen = len(cmd.Name)
+			if n := len(cmd.label()); n > maxLen {
+				maxLen = n
 			}
 		}
+		// Ungrouped commands first, then each group in the order it
+		// was first used.
+		groups := []string{""}
+		seen := map[string]bool{"": true}
 		for _, cmd := range c.commands {
-			fmt.Fprintf(os.Stderr, "  %-*s  %s\n", maxLen, cmd.Name, cmd.Desc)
+			if !seen[cmd.Group] {
+				seen[cmd.Group] = true
+				groups = append(groups, cmd.Group)
+			}
+		}
+		for _, group := range groups {
+			var cmds []cliCommand
+			for _, cmd := range c.commands {
+				if cmd.Group == group {
+					cmds = append(cmds, cmd)
+				}
+			}
+			if len(cmds) == 0 {
+				continue
+			}
+			if group == "" {
+				fmt.Fprintln(os.Stderr, "\nCommands:")
+			} else {
+				fmt.Fprintf(os.Stderr, "\n%s:\n", group)
+			}
+			for _, cmd := range cmds {
+				fmt.Fprintf(os.Stderr, "  %-*s  %s\n", maxLen, cmd.label(), cmd.Desc)
+			}
 		}
 	}
 
@@ -326,9 +398,11 @@ func (c *CLI) printHelp() {
 func (c *CLI) printCommandHelp(cmdName string) {
 	// Find command description
 	var desc string
+	var aliases []string
 	for _, cmd := range c.commands {
 		if cmd.Name == cmdName {
 			desc = cmd.Desc
+			aliases = cmd.Aliases
 			break
 		}
 	}
@@ -341,6 +415,9 @@ func (c *CLI) printCommandHelp(cmdName string) {
 		fmt.Fprintf(os.Stderr, " — %s", desc)
 	}
 	fmt.Fprintln(os.Stderr)
+	if len(aliases) > 0 {
+		fmt.Fprintf(os.Stderr, "\nAliases: %s\n", strings.Join(aliases, ", "))
+	}
 
 	cmdFlags := c.flagsForCmd(cmdName)
 	globalFlags := c.flagsForCmd("")
@@ -385,3 +462,249 @@ func (c *CLI) printFlags(flags []cliFlag) {
 		}
 	}
 }
+
+// hasCommand reports whether any registered command or alias starts
+// with the word name.
+func (c *CLI) hasCommand(name string) bool {
+	for _, cmd := range c.commands {
+		for _, n := range append([]string{cmd.Name}, cmd.Aliases...) {
+			if strings.Split(n, " ")[0] == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// printCompletion handles the hidden `completion <shell>` command: it
+// writes a completion script for the registered commands and flags to
+// stdout and exits.
+func (c *CLI) printCompletion(args []string) {
+	shell := ""
+	if len(args) > 0 {
+		shell = args[0]
+	}
+	script, err := c.completionScript(shell)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Print(script)
+	os.Exit(0)
+}
+
+// completionProgram is the command name completions are registered for:
+// the app name, or the executable's name when none is set.
+func (c *CLI) completionProgram() string {
+	if c.appName != "" {
+		return c.appName
+	}
+	return filepath.Base(os.Args[0])
+}
+
+func (c *CLI) completionScript(shell string) (string, error) {
+	switch shell {
+	case "bash":
+		return c.bashCompletion(false), nil
+	case "zsh":
+		return c.bashCompletion(true), nil
+	case "fish":
+		return c.fishCompletion(), nil
+	case "":
+		return "", fmt.Errorf("missing shell (usage: %s completion bash|zsh|fish)", c.completionProgram())
+	default:
+		return "", fmt.Errorf("unsupported shell %q (expected bash, zsh or fish)", shell)
+	}
+}
+
+// completionWords maps each command-word prefix ("" for the top level,
+// "db" for a partly typed "db migrate") to the words that can follow it:
+// the next command word, or the command's flags once it is complete.
+// Keys are returned in registration order.
+func (c *CLI) completionWords() ([]string, map[string][]string) {
+	var keys []string
+	words := make(map[string][]string)
+	add := func(key string, ws ...string) {
+		if _, ok := words[key]; !ok {
+			keys = append(keys, key)
+		}
+		for _, w := range ws {
+			dup := false
+			for _, have := range words[key] {
+				if have == w {
+					dup = true
+					break
+				}
+			}
+			if !dup {
+				words[key] = append(words[key], w)
+			}
+		}
+	}
+
+	global := flagWords(c.flagsForCmd(""))
+	top := []string{}
+	for _, cmd := range c.commands {
+		for _, name := range append([]string{cmd.Name}, cmd.Aliases...) {
+			top = append(top, strings.Split(name, " ")[0])
+		}
+	}
+	top = append(top, global...)
+	top = append(top, "--help", "-h")
+	if c.appVersion != "" {
+		top = append(top, "--version", "-V")
+	}
+	add("", top...)
+
+	for _, cmd := range c.commands {
+		for _, name := range append([]string{cmd.Name}, cmd.Aliases...) {
+			parts := strings.Split(name, " ")
+			for k := 1; k < len(parts); k++ {
+				add(strings.Join(parts[:k], " "), parts[k])
+			}
+			flags := append(flagWords(c.flagsForCmd(cmd.Name)), global...)
+			add(name, append(flags, "--help", "-h")...)
+		}
+	}
+	return keys, words
+}
+
+func flagWords(flags []cliFlag) []string {
+	var out []string
+	for _, f := range flags {
+		out = append(out, "--"+f.Long)
+		if f.Short != "" {
+			out = append(out, "-"+f.Short)
+		}
+	}
+	return out
+}
+
+// bashCompletion renders a bash completion function. The words typed so
+// far (flags skipped) are matched against the longest known command
+// prefix, so flags still complete after positional arguments. zsh runs
+// the same function through bashcompinit.
+func (c *CLI) bashCompletion(zsh bool) string {
+	prog := c.completionProgram()
+	fn := "_" + shellIdent(prog) + "_completion"
+	keys, words := c.completionWords()
+
+	var sb strings.Builder
+	if zsh {
+		fmt.Fprintf(&sb, "#compdef %s\n", prog)
+		fmt.Fprintf(&sb, "# zsh completion for %s. Generated by: %s completion zsh\n\n", prog, prog)
+		sb.WriteString("autoload -U +X bashcompinit && bashcompinit\n\n")
+	} else {
+		fmt.Fprintf(&sb, "# bash completion for %s. Generated by: %s completion bash\n\n", prog, prog)
+	}
+	fmt.Fprintf(&sb, "%s() {\n", fn)
+	sb.WriteString("    local cur=\"${COMP_WORDS[COMP_CWORD]}\" opts=\"\" key i\n")
+	sb.WriteString("    local words=()\n")
+	sb.WriteString("    for ((i = 1; i < COMP_CWORD; i++)); do\n")
+	sb.WriteString("        [[ ${COMP_WORDS[i]} == -* ]] || words+=(\"${COMP_WORDS[i]}\")\n")
+	sb.WriteString("    done\n")
+	sb.WriteString("    for ((i = ${#words[@]}; i >= 0; i--)); do\n")
+	sb.WriteString("        key=\"${words[*]:0:i}\"\n")
+	sb.WriteString("        case \"$key\" in\n")
+	for _, key := range keys {
+		fmt.Fprintf(&sb, "            %s) opts=%s; break ;;\n", shellQuote(key), shellQuote(strings.Join(words[key], " ")))
+	}
+	sb.WriteString("        esac\n")
+	sb.WriteString("    done\n")
+	sb.WriteString("    COMPREPLY=($(compgen -W \"$opts\" -- \"$cur\"))\n")
+	sb.WriteString("}\n\n")
+	fmt.Fprintf(&sb, "complete -F %s %s\n", fn, prog)
+	return sb.String()
+}
+
+// fishCompletion renders `complete` commands for fish, with command and
+// flag descriptions.
+func (c *CLI) fishCompletion() string {
+	prog := c.completionProgram()
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# fish completion for %s. Generated by: %s completion fish\n\n", prog, prog)
+	fmt.Fprintf(&sb, "complete -c %s -f\n", prog)
+
+	// First command words are offered until a subcommand has been typed;
+	// later words of multi-word commands once the earlier ones are there.
+	var firsts []string
+	for _, cmd := range c.commands {
+		for _, name := range append([]string{cmd.Name}, cmd.Aliases...) {
+			firsts = append(firsts, strings.Split(name, " ")[0])
+		}
+	}
+	for _, cmd := range c.commands {
+		for _, name := range append([]string{cmd.Name}, cmd.Aliases...) {
+			parts := strings.Split(name, " ")
+			if len(parts) == 1 {
+				fmt.Fprintf(&sb, "complete -c %s -n %s -a %s -d %s\n", prog,
+					shellQuote("not __fish_seen_subcommand_from "+strings.Join(firsts, " ")),
+					shellQuote(parts[0]), shellQuote(cmd.Desc))
+				continue
+			}
+			fmt.Fprintf(&sb, "complete -c %s -n %s -a %s\n", prog,
+				shellQuote("not __fish_seen_subcommand_from "+strings.Join(firsts, " ")),
+				shellQuote(parts[0]))
+			for k := 1; k < len(parts); k++ {
+				desc := ""
+				if k == len(parts)-1 {
+					desc = " -d " + shellQuote(cmd.Desc)
+				}
+				fmt.Fprintf(&sb, "complete -c %s -n %s -a %s%s\n", prog,
+					shellQuote("__fish_seen_subcommand_from "+parts[k-1]), shellQuote(parts[k]), desc)
+			}
+		}
+	}
+
+	for _, f := range c.flags {
+		cond := ""
+		if f.Cmd != "" {
+			cmd := c.commandByName(f.Cmd)
+			var lasts []string
+			for _, name := range append([]string{cmd.Name}, cmd.Aliases...) {
+				parts := strings.Split(name, " ")
+				lasts = append(lasts, parts[len(parts)-1])
+			}
+			cond = " -n " + shellQuote("__fish_seen_subcommand_from "+strings.Join(lasts, " "))
+		}
+		short := ""
+		if f.Short != "" {
+			short = " -s " + shellQuote(f.Short)
+		}
+		value := ""
+		if !f.IsBool {
+			value = " -r"
+		}
+		fmt.Fprintf(&sb, "complete -c %s%s -l %s%s%s -d %s\n", prog, cond, shellQuote(f.Long), short, value, shellQuote(f.Desc))
+	}
+	fmt.Fprintf(&sb, "complete -c %s -s h -l help -d 'Show help'\n", prog)
+	if c.appVersion != "" {
+		fmt.Fprintf(&sb, "complete -c %s -n %s -s V -l version -d 'Show version'\n", prog,
+			shellQuote("not __fish_seen_subcommand_from "+strings.Join(firsts, " ")))
+	}
+	return sb.String()
+}
+
+func (c *CLI) commandByName(name string) cliCommand {
+	for _, cmd := range c.commands {
+		if cmd.Name == name {
+			return cmd
+		}
+	}
+	return cliCommand{Name: name}
+}
+
+// shellQuote single-quotes s for bash, zsh and fish.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "'\\''") + "'"
+}
+
+// shellIdent turns a program name into a valid shell function name.
+func shellIdent(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, s)
+}
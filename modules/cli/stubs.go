@@ -65,3 +65,8 @@ func rugo_to_bool(v interface{}) bool {
 // the compiler populates this with user-defined handler functions. Here it
 // starts empty so the module compiles standalone.
 var rugo_cli_dispatch = map[string]func(interface{}) interface{}{}
+
+func rugo_hash_map(v interface{}) (map[interface{}]interface{}, bool) {
+	m, ok := v.(map[interface{}]interface{})
+	return m, ok
+}
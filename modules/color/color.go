@@ -11,6 +11,7 @@ var runtime string
 
 func init() {
 	s := modules.String
+	n := modules.Int
 	modules.Register(&modules.Module{
 		Name: "color",
 		Type: "Color",
@@ -38,7 +39,15 @@ func init() {
 			{Name: "bold", Args: []modules.ArgType{s}, Doc: "Wrap text in bold style."},
 			{Name: "dim", Args: []modules.ArgType{s}, Doc: "Wrap text in dim style."},
 			{Name: "underline", Args: []modules.ArgType{s}, Doc: "Wrap text in underline style."},
+			// 256-color palette and truecolor
+			{Name: "fg256", Args: []modules.ArgType{s, n}, Doc: "Wrap text in foreground color n (0-255) of the 256-color palette."},
+			{Name: "bg256", Args: []modules.ArgType{s, n}, Doc: "Wrap text in background color n (0-255) of the 256-color palette."},
+			{Name: "rgb", Args: []modules.ArgType{s, n, n, n}, Doc: "Wrap text in a 24-bit truecolor foreground (components 0-255)."},
+			{Name: "bg_rgb", Args: []modules.ArgType{s, n, n, n}, Doc: "Wrap text in a 24-bit truecolor background (components 0-255)."},
+			// Utilities
+			{Name: "strip", Args: []modules.ArgType{s}, Doc: "Remove all ANSI escape sequences from text. Works regardless of NO_COLOR."},
 		},
-		Runtime: modules.CleanRuntime(runtime),
+		GoImports: []string{"regexp"},
+		Runtime:   modules.CleanRuntime(runtime),
 	})
 }
@@ -14,7 +14,7 @@ func TestModuleRegistration(t *testing.T) {
 	m, ok := modules.Get("color")
 	require.True(t, ok)
 	assert.Equal(t, "Color", m.Type)
-	assert.Len(t, m.Funcs, 19)
+	assert.Len(t, m.Funcs, 24)
 }
 
 func TestForegroundColors(t *testing.T) {
@@ -54,6 +54,27 @@ func TestStyles(t *testing.T) {
 	assert.Equal(t, "\033[4mhello\033[0m", c.Underline("hello"))
 }
 
+func TestExtendedColors(t *testing.T) {
+	os.Unsetenv("NO_COLOR")
+	c := &Color{}
+
+	assert.Equal(t, "\033[38;5;208mhello\033[0m", c.Fg256("hello", 208))
+	assert.Equal(t, "\033[48;5;0mhello\033[0m", c.Bg256("hello", 0))
+	assert.Equal(t, "\033[38;2;255;127;80mhello\033[0m", c.Rgb("hello", 255, 127, 80))
+	assert.Equal(t, "\033[48;2;1;2;3mhello\033[0m", c.BgRgb("hello", 1, 2, 3))
+	assert.PanicsWithValue(t, "color.fg256: color must be between 0 and 255, got 256", func() { c.Fg256("x", 256) })
+	assert.PanicsWithValue(t, "color.bg_rgb: blue must be between 0 and 255, got -1", func() { c.BgRgb("x", 0, 0, -1) })
+}
+
+func TestStrip(t *testing.T) {
+	os.Unsetenv("NO_COLOR")
+	c := &Color{}
+
+	colored := c.Bold(c.Rgb("err", 1, 2, 3).(string)).(string)
+	assert.Equal(t, "err", c.Strip(colored))
+	assert.Equal(t, "plain text", c.Strip("\033[2K\033[1;31mplain\033[0m text"))
+}
+
 func TestNoColor(t *testing.T) {
 	os.Setenv("NO_COLOR", "1")
 	defer os.Unsetenv("NO_COLOR")
@@ -62,6 +83,8 @@ func TestNoColor(t *testing.T) {
 	assert.Equal(t, "hello", c.Red("hello"))
 	assert.Equal(t, "hello", c.BgBlue("hello"))
 	assert.Equal(t, "hello", c.Bold("hello"))
+	assert.Equal(t, "hello", c.Fg256("hello", 1))
+	assert.Equal(t, "hello", c.Rgb("hello", 1, 2, 3))
 }
 
 func TestComposable(t *testing.T) {
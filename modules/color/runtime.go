@@ -1,6 +1,10 @@
 package colormod
 
-import "os"
+import (
+	"fmt"
+	"os"
+	"regexp"
+)
 
 // --- color module ---
 
@@ -40,3 +44,39 @@ func (*Color) BgGray(s string) interface{}    { return colorize("100", s) }
 func (*Color) Bold(s string) interface{}      { return colorize("1", s) }
 func (*Color) Dim(s string) interface{}       { return colorize("2", s) }
 func (*Color) Underline(s string) interface{} { return colorize("4", s) }
+
+// 256-color palette and truecolor
+
+func (*Color) Fg256(s string, n int) interface{} {
+	return colorize("38;5;"+colorLevel("fg256", "color", n), s)
+}
+
+func (*Color) Bg256(s string, n int) interface{} {
+	return colorize("48;5;"+colorLevel("bg256", "color", n), s)
+}
+
+func (*Color) Rgb(s string, r, g, b int) interface{} {
+	return colorize("38;2;"+colorRGB("rgb", r, g, b), s)
+}
+
+func (*Color) BgRgb(s string, r, g, b int) interface{} {
+	return colorize("48;2;"+colorRGB("bg_rgb", r, g, b), s)
+}
+
+// colorLevel checks that n fits in a byte, as every 256-color index and
+// RGB component must.
+func colorLevel(fn, what string, n int) string {
+	if n < 0 || n > 255 {
+		panic(fmt.Sprintf("color.%s: %s must be between 0 and 255, got %d", fn, what, n))
+	}
+	return fmt.Sprint(n)
+}
+
+func colorRGB(fn string, r, g, b int) string {
+	return colorLevel(fn, "red", r) + ";" + colorLevel(fn, "green", g) + ";" + colorLevel(fn, "blue", b)
+}
+
+// colorANSIRe matches ANSI CSI escape sequences such as colors and styles.
+var colorANSIRe = regexp.MustCompile("\x1b\\[[0-9;?]*[ -/]*[@-~]")
+
+func (*Color) Strip(s string) interface{} { return colorANSIRe.ReplaceAllString(s, "") }
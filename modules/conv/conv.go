@@ -15,13 +15,15 @@ func init() {
 		Type: "Conv",
 		Doc:  "Type conversion between strings, integers, and floats.",
 		Funcs: []modules.FuncDef{
-			{Name: "to_i", Args: []modules.ArgType{modules.Any}, Doc: "Convert a value to an integer."},
+			{Name: "to_i", Args: []modules.ArgType{modules.Any}, Variadic: true, MaxArgs: 2, Doc: "Convert a value to an integer. Optional second arg is the base for strings (0 detects 0x/0b/0o prefixes)."},
 			{Name: "to_f", Args: []modules.ArgType{modules.Any}, Doc: "Convert a value to a float."},
 			{Name: "to_s", Args: []modules.ArgType{modules.Any}, Doc: "Convert a value to a string."},
-			{Name: "to_bool", Args: []modules.ArgType{modules.Any}, Doc: "Convert a value to a boolean."},
+			{Name: "to_bool", Args: []modules.ArgType{modules.Any}, Doc: "Convert a value to a boolean. Strings must be true/false, yes/no, on/off or 1/0 (case-insensitive)."},
+			{Name: "to_json", Args: []modules.ArgType{modules.Any}, Doc: "Encode a value as a compact JSON string (same as json.encode)."},
+			{Name: "from_json", Args: []modules.ArgType{modules.String}, Doc: "Parse a JSON string. Whole numbers become integers (same as json.parse)."},
 			{Name: "parse_int", Args: []modules.ArgType{modules.String, modules.Int}, Doc: "Parse a string as an integer with a given base (e.g. 16 for hex)."},
 		},
-		GoImports: []string{"strconv"},
+		GoImports: []string{"encoding/json", "math", "strconv", "strings"},
 		Runtime:   modules.CleanRuntime(runtime),
 	})
 }
@@ -1,8 +1,11 @@
 package convmod
 
 import (
+	"encoding/json"
 	"fmt"
+	"math"
 	"strconv"
+	"strings"
 )
 
 // --- conv module ---
@@ -31,7 +34,10 @@ func rugoTypeName(val interface{}) string {
 	}
 }
 
-func (*Conv) ToI(val interface{}) interface{} {
+func (*Conv) ToI(val interface{}, opts ...interface{}) interface{} {
+	if len(opts) > 0 {
+		return toIBase(val, opts[0])
+	}
 	switch v := val.(type) {
 	case int:
 		return v
@@ -53,6 +59,27 @@ func (*Conv) ToI(val interface{}) interface{} {
 	}
 }
 
+// toIBase parses a string in the given base. Base 0 infers it from a
+// 0x, 0b or 0o prefix, defaulting to 10.
+func toIBase(val, baseArg interface{}) interface{} {
+	base, ok := baseArg.(int)
+	if !ok {
+		panic(fmt.Sprintf("conv.to_i: base must be an integer, got %s", rugoTypeName(baseArg)))
+	}
+	s, ok := val.(string)
+	if !ok {
+		panic(fmt.Sprintf("conv.to_i: a base needs a string to parse, got %s", rugoTypeName(val)))
+	}
+	if base != 0 && (base < 2 || base > 36) {
+		panic(fmt.Sprintf("conv.to_i: invalid base %d (must be 0 or 2 to 36)", base))
+	}
+	n, err := strconv.ParseInt(s, base, 64)
+	if err != nil {
+		panic(fmt.Sprintf("conv.to_i: cannot convert %q to integer with base %d", s, base))
+	}
+	return int(n)
+}
+
 func (*Conv) ToF(val interface{}) interface{} {
 	switch v := val.(type) {
 	case float64:
@@ -83,10 +110,13 @@ func (*Conv) ToBool(val interface{}) interface{} {
 	case float64:
 		return v != 0.0
 	case string:
-		if v == "" || v == "false" {
+		switch strings.ToLower(strings.TrimSpace(v)) {
+		case "true", "yes", "on", "1":
+			return true
+		case "false", "no", "off", "0", "":
 			return false
 		}
-		return true
+		panic(fmt.Sprintf("conv.to_bool: cannot convert %q to boolean (expected true/false, yes/no, on/off or 1/0)", v))
 	case nil:
 		return false
 	default:
@@ -101,3 +131,48 @@ func (*Conv) ParseInt(s string, base int) interface{} {
 	}
 	return int(n)
 }
+
+func (*Conv) ToJson(val interface{}) interface{} {
+	out, err := rugo_to_json(val, "")
+	if err != nil {
+		panic(fmt.Sprintf("conv.to_json: %v", err))
+	}
+	return out
+}
+
+func (*Conv) FromJson(s string) interface{} {
+	var raw interface{}
+	if err := json.Unmarshal([]byte(s), &raw); err != nil {
+		if se, ok := err.(*json.SyntaxError); ok {
+			panic(fmt.Sprintf("conv.from_json: invalid JSON at position %d", se.Offset))
+		}
+		panic(fmt.Sprintf("conv.from_json: invalid JSON: %v", err))
+	}
+	return convFromJSONValue(raw)
+}
+
+// convFromJSONValue converts json.Unmarshal output to Rugo values with the
+// same rules as json.parse: objects become hashes and whole numbers that
+// fit an int become int, so `conv.from_json("5") == 5` holds.
+func convFromJSONValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		m := make(map[interface{}]interface{}, len(val))
+		for k, child := range val {
+			m[k] = convFromJSONValue(child)
+		}
+		return m
+	case []interface{}:
+		for i, child := range val {
+			val[i] = convFromJSONValue(child)
+		}
+		return val
+	case float64:
+		if val == math.Trunc(val) && math.Abs(val) < 1<<63 {
+			return int(val)
+		}
+		return val
+	default:
+		return v
+	}
+}
@@ -1,7 +1,15 @@
 package convmod
 
-import "fmt"
+import (
+	"encoding/json"
+	"fmt"
+)
 
 // Runtime helper stubs for standalone compilation and testing.
 
 func rugo_to_string(v interface{}) string { return fmt.Sprintf("%v", v) }
+
+func rugo_to_json(v interface{}, indent string) (string, error) {
+	b, err := json.Marshal(v)
+	return string(b), err
+}
@@ -15,13 +15,13 @@ func init() {
 		Type: "HTTP",
 		Doc:  "HTTP client for making web requests.",
 		Funcs: []modules.FuncDef{
-			{Name: "get", Args: []modules.ArgType{modules.String}, Variadic: true, Doc: "Send an HTTP GET request to a URL."},
-			{Name: "post", Args: []modules.ArgType{modules.String, modules.String}, Variadic: true, Doc: "Send an HTTP POST request with a body."},
-			{Name: "put", Args: []modules.ArgType{modules.String, modules.String}, Variadic: true, Doc: "Send an HTTP PUT request with a body."},
-			{Name: "patch", Args: []modules.ArgType{modules.String, modules.String}, Variadic: true, Doc: "Send an HTTP PATCH request with a body."},
+			{Name: "get", Args: []modules.ArgType{modules.String}, Variadic: true, Doc: "Send an HTTP GET request to a URL. Optional options hash: {\"headers\" => {...}, \"timeout\" => secs}."},
+			{Name: "post", Args: []modules.ArgType{modules.String, modules.Any}, Variadic: true, Doc: "Send an HTTP POST request with a body. Hash and array bodies are sent as JSON."},
+			{Name: "put", Args: []modules.ArgType{modules.String, modules.Any}, Variadic: true, Doc: "Send an HTTP PUT request with a body. Hash and array bodies are sent as JSON."},
+			{Name: "patch", Args: []modules.ArgType{modules.String, modules.Any}, Variadic: true, Doc: "Send an HTTP PATCH request with a body. Hash and array bodies are sent as JSON."},
 			{Name: "delete", Args: []modules.ArgType{modules.String}, Variadic: true, Doc: "Send an HTTP DELETE request to a URL."},
 		},
-		GoImports: []string{"errors", "io", "net", "net/http", "net/url"},
+		GoImports: []string{"errors", "io", "net", "net/http", "net/url", "time"},
 		Runtime:   modules.CleanRuntime(runtime),
 	})
 }
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 )
 
 // --- http module ---
@@ -29,8 +30,17 @@ func httpErr(funcName string, err error) string {
 	return fmt.Sprintf("%s failed: %v", funcName, err)
 }
 
+// requestOptions holds the per-request settings parsed from the optional
+// trailing hash argument.
+type requestOptions struct {
+	headers map[interface{}]interface{}
+	timeout time.Duration
+}
+
 // doRequest builds and executes an HTTP request, returning a Rugo response hash.
-func doRequest(method, rawURL string, body string, headers map[interface{}]interface{}) map[interface{}]interface{} {
+func doRequest(method, rawURL string, body string, opts requestOptions) map[interface{}]interface{} {
+	funcName := "http." + strings.ToLower(method)
+
 	var bodyReader io.Reader
 	if body != "" {
 		bodyReader = strings.NewReader(body)
@@ -38,7 +48,7 @@ func doRequest(method, rawURL string, body string, headers map[interface{}]inter
 
 	req, err := http.NewRequest(method, rawURL, bodyReader)
 	if err != nil {
-		panic(httpErr("http."+strings.ToLower(method), err))
+		panic(httpErr(funcName, err))
 	}
 
 	// Set default Content-Type for methods that carry a body
@@ -46,19 +56,28 @@ func doRequest(method, rawURL string, body string, headers map[interface{}]inter
 		req.Header.Set("Content-Type", "application/json")
 	}
 
-	for k, v := range headers {
+	for k, v := range opts.headers {
 		req.Header.Set(rugo_to_string(k), rugo_to_string(v))
 	}
 
-	resp, err := http.DefaultClient.Do(req)
+	client := http.DefaultClient
+	if opts.timeout > 0 {
+		client = &http.Client{Timeout: opts.timeout}
+	}
+
+	resp, err := client.Do(req)
 	if err != nil {
-		panic(httpErr("http."+strings.ToLower(method), err))
+		var netErr net.Error
+		if opts.timeout > 0 && errors.As(err, &netErr) && netErr.Timeout() {
+			panic(fmt.Sprintf("%s failed: request timed out after %v", funcName, opts.timeout))
+		}
+		panic(httpErr(funcName, err))
 	}
 	defer resp.Body.Close()
 
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		panic(fmt.Sprintf("http.%s: failed to read response body: %v", strings.ToLower(method), err))
+		panic(fmt.Sprintf("%s: failed to read response body: %v", funcName, err))
 	}
 
 	respHeaders := make(map[interface{}]interface{})
@@ -73,37 +92,92 @@ func doRequest(method, rawURL string, body string, headers map[interface{}]inter
 	}
 }
 
-// extractHeaders extracts an optional headers hash from variadic extra args.
-func extractHeaders(extra []interface{}) map[interface{}]interface{} {
-	for _, arg := range extra {
-		if h, ok := arg.(map[interface{}]interface{}); ok {
-			return h
+// requestBody converts a body argument to the string sent on the wire.
+// Hashes and arrays are JSON-encoded; strings are sent as-is.
+func requestBody(funcName string, body interface{}) string {
+	_, isHash := rugo_hash_map(body)
+	if _, isArray := body.([]interface{}); isHash || isArray {
+		enc, err := rugo_to_json(body, "")
+		if err != nil {
+			panic(fmt.Sprintf("%s: cannot encode body as JSON: %v", funcName, err))
+		}
+		return enc
+	}
+	switch b := body.(type) {
+	case string:
+		return b
+	case nil:
+		return ""
+	default:
+		return rugo_to_string(body)
+	}
+}
+
+// extractOptions parses the optional trailing options hash. Only the
+// "headers" and "timeout" keys are accepted, so a misspelt option raises
+// instead of being sent as a request header.
+func extractOptions(funcName string, extra []interface{}) requestOptions {
+	var opts requestOptions
+	if len(extra) == 0 {
+		return opts
+	}
+	if len(extra) > 1 {
+		panic(fmt.Sprintf("%s: expected at most one options hash, got %d extra arguments", funcName, len(extra)))
+	}
+	h, ok := rugo_hash_map(extra[0])
+	if !ok {
+		panic(fmt.Sprintf("%s: options must be a hash, got %T", funcName, extra[0]))
+	}
+	for k, v := range h {
+		switch k {
+		case "timeout":
+			opts.timeout = httpTimeout(funcName, v)
+		case "headers":
+			headers, ok := rugo_hash_map(v)
+			if !ok {
+				panic(fmt.Sprintf("%s: headers option must be a hash, got %T", funcName, v))
+			}
+			opts.headers = headers
+		default:
+			panic(fmt.Sprintf("%s: unknown option %q (valid options: headers, timeout)", funcName, rugo_to_string(k)))
 		}
 	}
-	return nil
+	return opts
+}
+
+// httpTimeout converts a timeout option in seconds (int or float) to a duration.
+func httpTimeout(funcName string, v interface{}) time.Duration {
+	var secs float64
+	switch n := v.(type) {
+	case int:
+		secs = float64(n)
+	case float64:
+		secs = n
+	default:
+		panic(fmt.Sprintf("%s: timeout option must be a number of seconds, got %T", funcName, v))
+	}
+	if secs <= 0 {
+		panic(fmt.Sprintf("%s: timeout option must be positive, got %v", funcName, v))
+	}
+	return time.Duration(secs * float64(time.Second))
 }
 
 func (*HTTP) Get(url string, extra ...interface{}) interface{} {
-	headers := extractHeaders(extra)
-	return doRequest("GET", url, "", headers)
+	return doRequest("GET", url, "", extractOptions("http.get", extra))
 }
 
-func (*HTTP) Post(url string, body string, extra ...interface{}) interface{} {
-	headers := extractHeaders(extra)
-	return doRequest("POST", url, body, headers)
+func (*HTTP) Post(url string, body interface{}, extra ...interface{}) interface{} {
+	return doRequest("POST", url, requestBody("http.post", body), extractOptions("http.post", extra))
 }
 
-func (*HTTP) Put(url string, body string, extra ...interface{}) interface{} {
-	headers := extractHeaders(extra)
-	return doRequest("PUT", url, body, headers)
+func (*HTTP) Put(url string, body interface{}, extra ...interface{}) interface{} {
+	return doRequest("PUT", url, requestBody("http.put", body), extractOptions("http.put", extra))
 }
 
-func (*HTTP) Patch(url string, body string, extra ...interface{}) interface{} {
-	headers := extractHeaders(extra)
-	return doRequest("PATCH", url, body, headers)
+func (*HTTP) Patch(url string, body interface{}, extra ...interface{}) interface{} {
+	return doRequest("PATCH", url, requestBody("http.patch", body), extractOptions("http.patch", extra))
 }
 
 func (*HTTP) Delete(url string, extra ...interface{}) interface{} {
-	headers := extractHeaders(extra)
-	return doRequest("DELETE", url, "", headers)
+	return doRequest("DELETE", url, "", extractOptions("http.delete", extra))
 }
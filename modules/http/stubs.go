@@ -1,7 +1,20 @@
 package httpmod
 
-import "fmt"
+import (
+	"encoding/json"
+	"fmt"
+)
 
 // Runtime helper stubs for standalone compilation and testing.
 
 func rugo_to_string(v interface{}) string { return fmt.Sprintf("%v", v) }
+
+func rugo_hash_map(v interface{}) (map[interface{}]interface{}, bool) {
+	m, ok := v.(map[interface{}]interface{})
+	return m, ok
+}
+
+func rugo_to_json(v interface{}, indent string) (string, error) {
+	b, err := json.Marshal(v)
+	return string(b), err
+}
@@ -17,9 +17,10 @@ func init() {
 		Funcs: []modules.FuncDef{
 			{Name: "parse", Args: []modules.ArgType{modules.String}, Doc: "Parse a JSON string into a hash or array."},
 			{Name: "encode", Args: []modules.ArgType{modules.Any}, Doc: "Encode a value as a JSON string."},
-			{Name: "pretty", Args: []modules.ArgType{modules.Any}, Doc: "Encode a value as a pretty-printed JSON string with indentation."},
+			{Name: "get", Args: []modules.ArgType{modules.Any, modules.String}, ArgNames: []string{"obj", "path"}, Doc: "Return the value at a path like \"items[2].name\" or nil if any segment is missing."},
+			{Name: "pretty", Args: []modules.ArgType{modules.Any}, Variadic: true, MaxArgs: 2, Doc: "Encode a value as a pretty-printed JSON string with indentation. Optional second arg is the indent string (default two spaces)."},
 		},
-		GoImports: []string{"encoding/json", "math"},
+		GoImports: []string{"encoding/json", "math", "strconv"},
 		Runtime:   modules.CleanRuntime(runtime),
 	})
 }
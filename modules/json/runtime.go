@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"math"
+	"strconv"
 )
 
 // --- json module ---
@@ -22,42 +23,135 @@ func (*JSON) Parse(s string) interface{} {
 }
 
 func (*JSON) Encode(val interface{}) interface{} {
-	b, err := json.Marshal(prepareJSON(val))
+	out, err := rugo_to_json(val, "")
 	if err != nil {
 		panic(fmt.Sprintf("json.encode: %v", err))
 	}
-	return string(b)
+	return out
 }
 
-func (*JSON) Pretty(val interface{}) interface{} {
-	b, err := json.MarshalIndent(prepareJSON(val), "", "  ")
+func (*JSON) Pretty(val interface{}, opts ...interface{}) interface{} {
+	indent := "  "
+	if len(opts) > 0 {
+		s, ok := opts[0].(string)
+		if !ok {
+			panic(fmt.Sprintf("json.pretty: indent must be a string, got %s", rugo_type_label(opts[0])))
+		}
+		indent = s
+	}
+	out, err := rugo_to_json(val, indent)
 	if err != nil {
 		panic(fmt.Sprintf("json.pretty: %v", err))
 	}
-	return string(b)
+	return out
 }
 
-// prepareJSON converts Rugo types back to standard Go types for json.Marshal.
-func prepareJSON(v interface{}) interface{} {
-	switch val := v.(type) {
-	case map[interface{}]interface{}:
-		m := make(map[string]interface{}, len(val))
-		for k, child := range val {
-			m[fmt.Sprintf("%v", k)] = prepareJSON(child)
+// Get walks a path such as "items[2].name" or `meta["weird.key"]` through
+// nested hashes and arrays. A missing key, an out-of-range index or a
+// segment that hits a non-container yields nil; only a malformed path panics.
+func (*JSON) Get(obj interface{}, path string) interface{} {
+	cur := obj
+	for _, seg := range parseGetPath(path) {
+		if m, ok := rugo_hash_map(cur); ok {
+			cur = m
 		}
-		return m
-	case []interface{}:
-		for i, child := range val {
-			val[i] = prepareJSON(child)
+		switch c := cur.(type) {
+		case map[interface{}]interface{}:
+			if seg.isIndex {
+				cur = c[seg.index]
+			} else {
+				cur = c[seg.key]
+			}
+		case []interface{}:
+			if !seg.isIndex {
+				return nil
+			}
+			i := seg.index
+			if i < 0 {
+				i += len(c)
+			}
+			if i < 0 || i >= len(c) {
+				return nil
+			}
+			cur = c[i]
+		default:
+			return nil
+		}
+		if cur == nil {
+			return nil
+		}
+	}
+	return cur
+}
+
+// getSegment is one step of a json.get path: a hash key or an array index.
+type getSegment struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+// parseGetPath splits a json.get path into segments. Keys are separated by
+// dots; [N] is an index and ["key"] or ['key'] a key that may contain dots
+// or brackets.
+func parseGetPath(path string) []getSegment {
+	var segs []getSegment
+	invalid := func(why string) {
+		panic(fmt.Sprintf("json.get: invalid path %q: %s", path, why))
+	}
+	i := 0
+	for i < len(path) {
+		switch path[i] {
+		case '.':
+			if i == 0 || i == len(path)-1 || path[i+1] == '.' || path[i+1] == '[' {
+				invalid("empty key")
+			}
+			i++
+		case '[':
+			end := i + 1
+			if end < len(path) && (path[end] == '"' || path[end] == '\'') {
+				quote := path[end]
+				stop := end + 1
+				for stop < len(path) && path[stop] != quote {
+					stop++
+				}
+				if stop+1 >= len(path) || path[stop+1] != ']' {
+					invalid("unterminated quoted key")
+				}
+				segs = append(segs, getSegment{key: path[end+1 : stop]})
+				i = stop + 2
+			} else {
+				for end < len(path) && path[end] != ']' {
+					end++
+				}
+				if end >= len(path) {
+					invalid("missing ]")
+				}
+				n, err := strconv.Atoi(path[i+1 : end])
+				if err != nil {
+					invalid(fmt.Sprintf("index %q is not an integer", path[i+1:end]))
+				}
+				segs = append(segs, getSegment{index: n, isIndex: true})
+				i = end + 1
+			}
+			if i < len(path) && path[i] != '.' && path[i] != '[' {
+				invalid("expected . or [ after ]")
+			}
+		default:
+			end := i
+			for end < len(path) && path[end] != '.' && path[end] != '[' {
+				end++
+			}
+			segs = append(segs, getSegment{key: path[i:end]})
+			i = end
 		}
-		return val
-	default:
-		return v
 	}
+	return segs
 }
 
 // convertJSON recursively converts Go json.Unmarshal types to Rugo-friendly types.
-// Whole-number float64 values become int so they work naturally with conv.to_s etc.
+// Whole-number float64 values that fit an int become int, so parsed numbers
+// compare equal to integer literals. conv.from_json applies the same rules.
 func convertJSON(v interface{}) interface{} {
 	switch val := v.(type) {
 	case map[string]interface{}:
@@ -72,7 +166,7 @@ func convertJSON(v interface{}) interface{} {
 		}
 		return val
 	case float64:
-		if val == math.Trunc(val) && !math.IsInf(val, 0) && !math.IsNaN(val) {
+		if val == math.Trunc(val) && math.Abs(val) < 1<<63 {
 			return int(val)
 		}
 		return val
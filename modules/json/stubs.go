@@ -0,0 +1,26 @@
+package jsonmod
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Runtime helper stubs for standalone compilation and testing.
+
+func rugo_hash_map(v interface{}) (map[interface{}]interface{}, bool) {
+	m, ok := v.(map[interface{}]interface{})
+	return m, ok
+}
+
+func rugo_to_json(v interface{}, indent string) (string, error) {
+	var b []byte
+	var err error
+	if indent != "" {
+		b, err = json.MarshalIndent(v, "", indent)
+	} else {
+		b, err = json.Marshal(v)
+	}
+	return string(b), err
+}
+
+func rugo_type_label(obj interface{}) string { return fmt.Sprintf("%T", obj) }
@@ -0,0 +1,28 @@
+package logmod
+
+import (
+	_ "embed"
+
+	"github.com/rubiojr/rugo/modules"
+)
+
+//go:embed runtime.go
+var runtime string
+
+func init() {
+	modules.Register(&modules.Module{
+		Name: "log",
+		Type: "Log",
+		Doc:  "Leveled logging to stderr, as text or one JSON object per line.",
+		Funcs: []modules.FuncDef{
+			{Name: "debug", Args: []modules.ArgType{modules.String}, Variadic: true, MaxArgs: 2, ArgNames: []string{"msg"}, Doc: "Log a message at debug level. Optional second arg is a hash of fields."},
+			{Name: "info", Args: []modules.ArgType{modules.String}, Variadic: true, MaxArgs: 2, ArgNames: []string{"msg"}, Doc: "Log a message at info level. Optional second arg is a hash of fields."},
+			{Name: "warn", Args: []modules.ArgType{modules.String}, Variadic: true, MaxArgs: 2, ArgNames: []string{"msg"}, Doc: "Log a message at warn level. Optional second arg is a hash of fields."},
+			{Name: "error", Args: []modules.ArgType{modules.String}, Variadic: true, MaxArgs: 2, ArgNames: []string{"msg"}, Doc: "Log a message at error level. Optional second arg is a hash of fields."},
+			{Name: "set_level", Args: []modules.ArgType{modules.String}, ArgNames: []string{"level"}, Doc: "Set the minimum level to log: \"debug\", \"info\" (default), \"warn\" or \"error\"."},
+			{Name: "set_format", Args: []modules.ArgType{modules.String}, ArgNames: []string{"format"}, Doc: "Set the output format: \"text\" (default) or \"json\". Defaults to $RUGO_LOG_FORMAT when set."},
+		},
+		GoImports: []string{"encoding/json", "os", "strings", "sync", "time"},
+		Runtime:   modules.CleanRuntime(runtime),
+	})
+}
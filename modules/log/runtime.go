@@ -0,0 +1,121 @@
+package logmod
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// --- log module ---
+
+var logLevels = map[string]int{"debug": 0, "info": 1, "warn": 2, "error": 3}
+
+type Log struct {
+	mu        sync.Mutex
+	level     int
+	levelSet  bool
+	format    string
+	formatSet bool
+}
+
+func (l *Log) Debug(msg string, extra ...interface{}) interface{} {
+	l.write("debug", msg, extra)
+	return nil
+}
+
+func (l *Log) Info(msg string, extra ...interface{}) interface{} {
+	l.write("info", msg, extra)
+	return nil
+}
+
+func (l *Log) Warn(msg string, extra ...interface{}) interface{} {
+	l.write("warn", msg, extra)
+	return nil
+}
+
+func (l *Log) Error(msg string, extra ...interface{}) interface{} {
+	l.write("error", msg, extra)
+	return nil
+}
+
+func (l *Log) SetLevel(level string) interface{} {
+	n, ok := logLevels[level]
+	if !ok {
+		panic(fmt.Sprintf("log.set_level: unknown level %q (valid levels: debug, info, warn, error)", level))
+	}
+	l.mu.Lock()
+	l.level, l.levelSet = n, true
+	l.mu.Unlock()
+	return nil
+}
+
+func (l *Log) SetFormat(format string) interface{} {
+	if format != "text" && format != "json" {
+		panic(fmt.Sprintf("log.set_format: unknown format %q (valid formats: text, json)", format))
+	}
+	l.mu.Lock()
+	l.format, l.formatSet = format, true
+	l.mu.Unlock()
+	return nil
+}
+
+// logEntry fixes the key order of JSON log lines.
+type logEntry struct {
+	Level  string          `json:"level"`
+	Msg    string          `json:"msg"`
+	Time   string          `json:"time"`
+	Fields json.RawMessage `json:"fields"`
+}
+
+func (l *Log) write(level, msg string, extra []interface{}) {
+	var fields interface{}
+	if len(extra) > 0 {
+		if _, ok := rugo_hash_map(extra[0]); !ok {
+			panic(fmt.Sprintf("log.%s: fields must be a hash, got %T", level, extra[0]))
+		}
+		fields = extra[0]
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.levelSet {
+		l.level, l.levelSet = logLevels["info"], true
+	}
+	if !l.formatSet {
+		l.format, l.formatSet = "text", true
+		if os.Getenv("RUGO_LOG_FORMAT") == "json" {
+			l.format = "json"
+		}
+	}
+	if logLevels[level] < l.level {
+		return
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	if l.format == "json" {
+		raw := "{}"
+		if fields != nil {
+			var err error
+			if raw, err = rugo_to_json(fields, ""); err != nil {
+				panic(fmt.Sprintf("log.%s: %v", level, err))
+			}
+		}
+		b, err := json.Marshal(logEntry{Level: level, Msg: msg, Time: now, Fields: json.RawMessage(raw)})
+		if err != nil {
+			panic(fmt.Sprintf("log.%s: %v", level, err))
+		}
+		fmt.Fprintln(os.Stderr, string(b))
+		return
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s %-5s %s", now, strings.ToUpper(level), msg)
+	m, _ := rugo_hash_map(fields)
+	for _, k := range rugo_hash_keys(fields) {
+		fmt.Fprintf(&sb, " %s=%s", rugo_to_string(k), rugo_to_string(m[k]))
+	}
+	fmt.Fprintln(os.Stderr, sb.String())
+}
@@ -32,10 +32,32 @@ type FuncDef struct {
 	// Variadic, when true, passes remaining args beyond Args as ...interface{}.
 	// The implementation function should accept extra ...interface{} as its last parameter.
 	Variadic bool
+	// MaxArgs caps the total argument count of a Variadic function whose
+	// trailing arguments are optional (e.g. 3 for pad_left(s, width, char?)).
+	// Zero leaves variadic functions unbounded. Ignored when Variadic is false.
+	MaxArgs int
+	// Returns is the Go type the function always returns ("string", "int",
+	// "float64" or "bool"). When set, type inference keeps call results typed
+	// instead of falling back to interface{}.
+	Returns string
 	// Doc is the documentation string shown by `rugo doc`.
 	Doc string
 }
 
+// Arity returns the minimum and maximum number of arguments the function
+// accepts. Max is -1 for variadic functions without a MaxArgs cap.
+func (f *FuncDef) Arity() (min, max int) {
+	min = len(f.Args)
+	switch {
+	case !f.Variadic:
+		return min, min
+	case f.MaxArgs > 0:
+		return min, f.MaxArgs
+	default:
+		return min, -1
+	}
+}
+
 // Module represents a Rugo stdlib module that can be imported.
 type Module struct {
 	// Name is the rugo import name (e.g. "os", "http", "conv").
@@ -239,15 +261,15 @@ func (m *Module) FullRuntime() string {
 				minArgs, m.Name, f.Name, minArgs, argWord))
 		}
 
-		// For non-variadic functions, also check maximum arg count
-		if !f.Variadic {
+		// For non-variadic (or capped variadic) functions, also check maximum arg count
+		if _, maxArgs := f.Arity(); maxArgs >= 0 {
 			argWord := "arguments"
-			if minArgs == 1 {
+			if maxArgs == 1 {
 				argWord = "argument"
 			}
 			sb.WriteString(fmt.Sprintf(
-				"\tif len(args) > %d { panic(fmt.Sprintf(\"%s.%s() takes %d %s but %%d were given\", len(args))) }\n",
-				minArgs, m.Name, f.Name, minArgs, argWord))
+				"\tif len(args) > %d { panic(fmt.Sprintf(\"%s.%s() takes %s %s but %%d were given\", len(args))) }\n",
+				maxArgs, m.Name, f.Name, arityRange(minArgs, maxArgs), argWord))
 		}
 
 		var callArgs []string
@@ -273,6 +295,14 @@ func (m *Module) FullRuntime() string {
 	return sb.String()
 }
 
+// arityRange renders an argument count for error messages: "2" or "2 to 3".
+func arityRange(min, max int) string {
+	if min == max {
+		return fmt.Sprint(max)
+	}
+	return fmt.Sprintf("%d to %d", min, max)
+}
+
 func argConversion(index int, t ArgType) string {
 	switch t {
 	case String:
@@ -215,6 +215,41 @@ func TestFullRuntimeVariadic(t *testing.T) {
 	}
 }
 
+func TestFullRuntimeVariadicMaxArgs(t *testing.T) {
+	m := &Module{
+		Name: "test",
+		Type: "Test",
+		Funcs: []FuncDef{
+			{Name: "pad", Args: []ArgType{String, Int}, Variadic: true, MaxArgs: 3},
+		},
+	}
+
+	got := m.FullRuntime()
+
+	if !strings.Contains(got, `if len(args) > 3 { panic(fmt.Sprintf("test.pad() takes 2 to 3 arguments but %d were given", len(args))) }`) {
+		t.Errorf("missing max arity check for capped variadic:\n%s", got)
+	}
+}
+
+func TestFuncDefArity(t *testing.T) {
+	tests := []struct {
+		fd       FuncDef
+		min, max int
+	}{
+		{FuncDef{Args: []ArgType{String, String}}, 2, 2},
+		{FuncDef{}, 0, 0},
+		{FuncDef{Args: []ArgType{String}, Variadic: true}, 1, -1},
+		{FuncDef{Args: []ArgType{String}, Variadic: true, MaxArgs: 2}, 1, 2},
+		{FuncDef{Args: []ArgType{String}, MaxArgs: 5}, 1, 1},
+	}
+	for i, tt := range tests {
+		min, max := tt.fd.Arity()
+		if min != tt.min || max != tt.max {
+			t.Errorf("case %d: Arity() = (%d, %d), want (%d, %d)", i, min, max, tt.min, tt.max)
+		}
+	}
+}
+
 func TestFullRuntimePureVariadic(t *testing.T) {
 	m := &Module{
 		Name:  "test",
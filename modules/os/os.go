@@ -15,29 +15,39 @@ func init() {
 		Type: "OS",
 		Doc:  "Operating system operations: commands, files, environment, and process control.",
 		Funcs: []modules.FuncDef{
-			{Name: "exec", Args: []modules.ArgType{modules.String}, Doc: "Execute a shell command and return its output."},
+			{Name: "exec", Args: []modules.ArgType{modules.String}, ArgNames: []string{"command"}, Doc: "Execute a shell command and return its output."},
+			{Name: "exec_argv", Args: []modules.ArgType{modules.Any}, ArgNames: []string{"argv"}, Doc: "Run a program from an argv array directly, without a shell. Returns a hash with its \"stdout\", \"stderr\" and exit \"code\", like run."},
+			{Name: "run", Args: []modules.ArgType{modules.String}, ArgNames: []string{"command"}, Doc: "Run a shell command and return a hash with its \"stdout\", \"stderr\" and exit \"code\". Does not raise on a non-zero exit."},
 			{Name: "exit", Args: []modules.ArgType{modules.Int}, Doc: "Exit the program with the given status code."},
 			{Name: "file_exists", Args: []modules.ArgType{modules.String}, Doc: "Return true if the file or directory exists."},
 			{Name: "is_dir", Args: []modules.ArgType{modules.String}, Doc: "Return true if the path exists and is a directory."},
 			{Name: "read_line", Args: []modules.ArgType{modules.String}, Doc: "Print the prompt and read a line from stdin. Returns the input without trailing newline."},
 			{Name: "getenv", Args: []modules.ArgType{modules.String}, Doc: "Get the value of an environment variable."},
 			{Name: "setenv", Args: []modules.ArgType{modules.String, modules.String}, Doc: "Set an environment variable."},
+			{Name: "env_all", Args: []modules.ArgType{}, Doc: "Return a hash of every environment variable name to its value."},
 			{Name: "cwd", Args: []modules.ArgType{}, Doc: "Return the current working directory."},
 			{Name: "chdir", Args: []modules.ArgType{modules.String}, Doc: "Change the current working directory."},
 			{Name: "hostname", Args: []modules.ArgType{}, Doc: "Return the machine hostname."},
 			{Name: "read_file", Args: []modules.ArgType{modules.String}, Doc: "Read the entire contents of a file as a string."},
+			{Name: "read_lines", Args: []modules.ArgType{modules.String}, ArgNames: []string{"path"}, Doc: "Read a file into an array of lines, without line endings."},
 			{Name: "write_file", Args: []modules.ArgType{modules.String, modules.String}, ArgNames: []string{"path", "content"}, Doc: "Write a string to a file, creating or overwriting it."},
 			{Name: "remove", Args: []modules.ArgType{modules.String}, Doc: "Remove a file or directory (recursive)."},
 			{Name: "mkdir", Args: []modules.ArgType{modules.String}, Doc: "Create a directory and any necessary parents."},
+			{Name: "mkdir_p", Args: []modules.ArgType{modules.String}, Variadic: true, MaxArgs: 2, ArgNames: []string{"path"}, Doc: "Create a directory and any missing parents. No-op if it already exists. Optional second arg is the mode (default 0755)."},
+			{Name: "remove_all", Args: []modules.ArgType{modules.String}, ArgNames: []string{"path"}, Doc: "Remove a path and everything under it. No-op if it does not exist."},
 			{Name: "rename", Args: []modules.ArgType{modules.String, modules.String}, ArgNames: []string{"old", "new"}, Doc: "Rename or move a file or directory."},
-			{Name: "glob", Args: []modules.ArgType{modules.String}, Doc: "Return an array of file paths matching a glob pattern."},
+			{Name: "glob", Args: []modules.ArgType{modules.String}, Doc: "Return a sorted array of file paths matching a glob pattern. Supports ** for recursive matching."},
 			{Name: "tmp_dir", Args: []modules.ArgType{}, Doc: "Return the default temporary directory path."},
-			{Name: "args", Args: []modules.ArgType{}, Doc: "Return command-line arguments as an array."},
+			{Name: "tempfile", Args: []modules.ArgType{}, Variadic: true, MaxArgs: 1, ArgNames: []string{"prefix"}, Doc: "Create a new empty file in the temporary directory and return its path. The optional prefix starts the file name."},
+			{Name: "tempdir", Args: []modules.ArgType{}, Variadic: true, MaxArgs: 1, ArgNames: []string{"prefix"}, Doc: "Create a new directory in the temporary directory and return its path. The optional prefix starts the directory name."},
+			{Name: "with_tempdir", Args: []modules.ArgType{modules.Any}, ArgNames: []string{"fn"}, Doc: "Create a temporary directory, call fn with its path and remove the directory afterwards, even if fn raises. Returns fn's result."},
+			{Name: "args", Args: []modules.ArgType{}, Doc: "Return the script's command-line arguments (without the program name) as an array."},
+			{Name: "arg", Args: []modules.ArgType{modules.Int}, ArgNames: []string{"n"}, Doc: "Return the nth script argument (0-based), or nil when there is no such argument."},
 			{Name: "pid", Args: []modules.ArgType{}, Doc: "Return the current process ID."},
 			{Name: "symlink", Args: []modules.ArgType{modules.String, modules.String}, ArgNames: []string{"target", "link"}, Doc: "Create a symbolic link."},
 			{Name: "readlink", Args: []modules.ArgType{modules.String}, Doc: "Return the target of a symbolic link."},
 		},
-		GoImports: []string{"bufio", "path/filepath"},
+		GoImports: []string{"bufio", "io", "path/filepath"},
 		Runtime:   modules.CleanRuntime(runtime),
 	})
 }
@@ -3,9 +3,11 @@ package osmod
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
 )
 
@@ -14,6 +16,10 @@ import (
 type OS struct{}
 
 func (*OS) Exec(command string) interface{} {
+	if rugo_dry_shell {
+		rugo_dry_shell_print(command)
+		return ""
+	}
 	cmd := exec.Command("sh", "-c", command)
 	cmd.Stderr = os.Stderr
 	out, err := cmd.Output()
@@ -23,17 +29,75 @@ func (*OS) Exec(command string) interface{} {
 	return strings.TrimRight(string(out), "\n")
 }
 
+// ExecArgv runs argv[0] directly with the remaining elements as its
+// arguments — no shell, so nothing is split, globbed or expanded.
+func (*OS) ExecArgv(argv interface{}) interface{} {
+	arr, ok := argv.([]interface{})
+	if !ok {
+		panic(fmt.Sprintf("os.exec_argv: expected an argv array, got %T", argv))
+	}
+	if len(arr) == 0 {
+		panic("os.exec_argv: argv array must not be empty")
+	}
+	args := make([]string, len(arr))
+	for i, a := range arr {
+		switch v := a.(type) {
+		case string:
+			args[i] = v
+		case int, float64:
+			args[i] = fmt.Sprint(v)
+		default:
+			panic(fmt.Sprintf("os.exec_argv: argv elements must be strings, got %T at index %d", a, i))
+		}
+	}
+	return runCaptured(exec.Command(args[0], args[1:]...), "os.exec_argv", strings.Join(args, " "))
+}
+
+func (*OS) Run(command string) interface{} {
+	return runCaptured(exec.Command("sh", "-c", command), "os.run", command)
+}
+
+// runCaptured runs cmd and returns its trimmed stdout and stderr with the
+// exit code. A non-zero exit is not an error; failing to start is. In
+// --dry-shell mode cmdStr is printed instead and the result is empty with
+// a zero exit code.
+func runCaptured(cmd *exec.Cmd, fn, cmdStr string) interface{} {
+	if rugo_dry_shell {
+		rugo_dry_shell_print(cmdStr)
+		return map[interface{}]interface{}{"stdout": "", "stderr": "", "code": 0}
+	}
+	var stdout, stderr strings.Builder
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	code := 0
+	if err := cmd.Run(); err != nil {
+		exitErr, ok := err.(*exec.ExitError)
+		if !ok {
+			panic(fmt.Sprintf("%s failed: %v", fn, err))
+		}
+		code = exitErr.ExitCode()
+	}
+	return map[interface{}]interface{}{
+		"stdout": strings.TrimRight(stdout.String(), "\n"),
+		"stderr": strings.TrimRight(stderr.String(), "\n"),
+		"code":   code,
+	}
+}
+
 func (*OS) Exit(code int) interface{} {
+	rugo_run_at_exit()
 	os.Exit(code)
 	return nil
 }
 
 func (*OS) FileExists(path string) interface{} {
+	rugo_sandbox_check("os.file_exists", path, false)
 	_, err := os.Stat(path)
 	return !os.IsNotExist(err)
 }
 
 func (*OS) IsDir(path string) interface{} {
+	rugo_sandbox_check("os.is_dir", path, false)
 	fi, err := os.Stat(path)
 	return err == nil && fi.IsDir()
 }
@@ -65,6 +129,15 @@ func (*OS) Setenv(key, value string) interface{} {
 	return nil
 }
 
+func (*OS) EnvAll() interface{} {
+	env := make(map[interface{}]interface{})
+	for _, kv := range os.Environ() {
+		k, v, _ := strings.Cut(kv, "=")
+		env[k] = v
+	}
+	return env
+}
+
 func (*OS) Cwd() interface{} {
 	dir, err := os.Getwd()
 	if err != nil {
@@ -74,6 +147,7 @@ func (*OS) Cwd() interface{} {
 }
 
 func (*OS) Chdir(path string) interface{} {
+	rugo_sandbox_check("os.chdir", path, false)
 	if err := os.Chdir(path); err != nil {
 		panic(fmt.Sprintf("os.chdir failed: %v", err))
 	}
@@ -89,6 +163,7 @@ func (*OS) Hostname() interface{} {
 }
 
 func (*OS) ReadFile(path string) interface{} {
+	rugo_sandbox_check("os.read_file", path, false)
 	data, err := os.ReadFile(path)
 	if err != nil {
 		panic(fmt.Sprintf("os.read_file failed: %v", err))
@@ -96,7 +171,32 @@ func (*OS) ReadFile(path string) interface{} {
 	return string(data)
 }
 
+func (*OS) ReadLines(path string) interface{} {
+	rugo_sandbox_check("os.read_lines", path, false)
+	f, err := os.Open(path)
+	if err != nil {
+		panic(fmt.Sprintf("os.read_lines failed: %v", err))
+	}
+	defer f.Close()
+	// bufio.Reader rather than Scanner: lines of any length are fine.
+	r := bufio.NewReader(f)
+	lines := []interface{}{}
+	for {
+		line, err := r.ReadString('\n')
+		if line != "" {
+			lines = append(lines, strings.TrimRight(line, "\r\n"))
+		}
+		if err != nil {
+			if err != io.EOF {
+				panic(fmt.Sprintf("os.read_lines failed: %s: %v", path, err))
+			}
+			return lines
+		}
+	}
+}
+
 func (*OS) WriteFile(path, content string) interface{} {
+	rugo_sandbox_check("os.write_file", path, true)
 	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
 		panic(fmt.Sprintf("os.write_file failed: %v", err))
 	}
@@ -104,6 +204,7 @@ func (*OS) WriteFile(path, content string) interface{} {
 }
 
 func (*OS) Remove(path string) interface{} {
+	rugo_sandbox_check("os.remove", path, true)
 	if err := os.RemoveAll(path); err != nil {
 		panic(fmt.Sprintf("os.remove failed: %v", err))
 	}
@@ -111,13 +212,50 @@ func (*OS) Remove(path string) interface{} {
 }
 
 func (*OS) Mkdir(path string) interface{} {
+	rugo_sandbox_check("os.mkdir", path, true)
 	if err := os.MkdirAll(path, 0755); err != nil {
 		panic(fmt.Sprintf("os.mkdir failed: %v", err))
 	}
 	return nil
 }
 
+func (*OS) MkdirP(path string, opts ...interface{}) interface{} {
+	rugo_sandbox_check("os.mkdir_p", path, true)
+	mode := os.FileMode(0755)
+	if len(opts) > 0 {
+		m, ok := opts[0].(int)
+		if !ok {
+			panic(fmt.Sprintf("os.mkdir_p: mode must be an integer like 0755, got %s", rugo_type_label(opts[0])))
+		}
+		mode = os.FileMode(m)
+	}
+	// MkdirAll succeeds when the directory already exists.
+	if err := os.MkdirAll(path, mode); err != nil {
+		panic(fmt.Sprintf("os.mkdir_p failed: %s: %v", path, osErrCause(err)))
+	}
+	return nil
+}
+
+func (*OS) RemoveAll(path string) interface{} {
+	rugo_sandbox_check("os.remove_all", path, true)
+	if err := os.RemoveAll(path); err != nil {
+		panic(fmt.Sprintf("os.remove_all failed: %s: %v", path, osErrCause(err)))
+	}
+	return nil
+}
+
+// osErrCause drops the op and path a *PathError repeats, since the
+// messages above already name the path the caller passed.
+func osErrCause(err error) error {
+	if pe, ok := err.(*os.PathError); ok {
+		return pe.Err
+	}
+	return err
+}
+
 func (*OS) Rename(oldpath, newpath string) interface{} {
+	rugo_sandbox_check("os.rename", oldpath, true)
+	rugo_sandbox_check("os.rename", newpath, true)
 	if err := os.Rename(oldpath, newpath); err != nil {
 		panic(fmt.Sprintf("os.rename failed: %v", err))
 	}
@@ -125,10 +263,18 @@ func (*OS) Rename(oldpath, newpath string) interface{} {
 }
 
 func (*OS) Glob(pattern string) interface{} {
-	matches, err := filepath.Glob(pattern)
+	rugo_sandbox_check("os.glob", globRoot(pattern), false)
+	var matches []string
+	var err error
+	if strings.Contains(pattern, "**") {
+		matches, err = globRecursive(pattern)
+	} else {
+		matches, err = filepath.Glob(pattern)
+	}
 	if err != nil {
 		panic(fmt.Sprintf("os.glob failed: %v", err))
 	}
+	sort.Strings(matches)
 	result := make([]interface{}, len(matches))
 	for i, m := range matches {
 		result[i] = m
@@ -136,10 +282,137 @@ func (*OS) Glob(pattern string) interface{} {
 	return result
 }
 
+// globRoot returns the directory a glob pattern searches: its leading
+// path segments up to the first one with a wildcard.
+func globRoot(pattern string) string {
+	segs := strings.Split(filepath.ToSlash(pattern), "/")
+	root := ""
+	for _, s := range segs[:len(segs)-1] {
+		if strings.ContainsAny(s, "*?[\\") {
+			break
+		}
+		root += s + "/"
+	}
+	if root == "" {
+		return "."
+	}
+	return filepath.FromSlash(root)
+}
+
+// globRecursive matches patterns containing "**" by walking the longest
+// literal directory prefix and matching each path segment by segment.
+// A "**" segment matches zero or more directories.
+func globRecursive(pattern string) ([]string, error) {
+	segs := strings.Split(filepath.ToSlash(pattern), "/")
+	root := ""
+	i := 0
+	for ; i < len(segs)-1; i++ {
+		if strings.ContainsAny(segs[i], "*?[\\") {
+			break
+		}
+		root += segs[i] + "/"
+	}
+	rest := segs[i:]
+	for _, s := range rest {
+		if s != "**" {
+			if _, err := filepath.Match(s, ""); err != nil {
+				return nil, err
+			}
+		}
+	}
+	walkRoot := root
+	if walkRoot == "" {
+		walkRoot = "."
+	}
+	var matches []string
+	err := filepath.WalkDir(walkRoot, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		rel, rerr := filepath.Rel(walkRoot, path)
+		if rerr != nil || rel == "." {
+			return nil
+		}
+		if globMatchSegments(rest, strings.Split(filepath.ToSlash(rel), "/")) {
+			matches = append(matches, filepath.FromSlash(root+filepath.ToSlash(rel)))
+		}
+		return nil
+	})
+	return matches, err
+}
+
+func globMatchSegments(pattern, parts []string) bool {
+	if len(pattern) == 0 {
+		return len(parts) == 0
+	}
+	if pattern[0] == "**" {
+		for skip := 0; skip <= len(parts); skip++ {
+			if globMatchSegments(pattern[1:], parts[skip:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(parts) == 0 {
+		return false
+	}
+	ok, _ := filepath.Match(pattern[0], parts[0])
+	return ok && globMatchSegments(pattern[1:], parts[1:])
+}
+
 func (*OS) TmpDir() interface{} {
 	return os.TempDir()
 }
 
+func (*OS) Tempfile(opts ...interface{}) interface{} {
+	prefix := osTempPrefix("os.tempfile", opts)
+	rugo_sandbox_check("os.tempfile", os.TempDir(), true)
+	f, err := os.CreateTemp("", prefix+"*")
+	if err != nil {
+		panic(fmt.Sprintf("os.tempfile failed: %v", osErrCause(err)))
+	}
+	f.Close()
+	return f.Name()
+}
+
+func (*OS) Tempdir(opts ...interface{}) interface{} {
+	prefix := osTempPrefix("os.tempdir", opts)
+	rugo_sandbox_check("os.tempdir", os.TempDir(), true)
+	dir, err := os.MkdirTemp("", prefix+"*")
+	if err != nil {
+		panic(fmt.Sprintf("os.tempdir failed: %v", osErrCause(err)))
+	}
+	return dir
+}
+
+// WithTempdir runs fn with a fresh temporary directory and removes it once
+// fn returns or raises.
+func (o *OS) WithTempdir(fnArg interface{}) interface{} {
+	fn, ok := fnArg.(func(...interface{}) interface{})
+	if !ok {
+		panic(fmt.Sprintf("os.with_tempdir expects a function, got %s", rugo_type_label(fnArg)))
+	}
+	dir := o.Tempdir().(string)
+	defer os.RemoveAll(dir)
+	return fn(dir)
+}
+
+// osTempPrefix returns the optional name prefix passed to os.tempfile and
+// os.tempdir, defaulting to "rugo-".
+func osTempPrefix(fn string, opts []interface{}) string {
+	if len(opts) == 0 {
+		return "rugo-"
+	}
+	prefix, ok := opts[0].(string)
+	if !ok {
+		panic(fmt.Sprintf("%s: prefix must be a string, got %s", fn, rugo_type_label(opts[0])))
+	}
+	if strings.ContainsRune(prefix, os.PathSeparator) {
+		panic(fmt.Sprintf("%s: prefix must not contain a path separator: %q", fn, prefix))
+	}
+	return prefix
+}
+
 func (*OS) Args() interface{} {
 	args := os.Args[1:]
 	result := make([]interface{}, len(args))
@@ -149,11 +422,20 @@ func (*OS) Args() interface{} {
 	return result
 }
 
+func (*OS) Arg(n int) interface{} {
+	args := os.Args[1:]
+	if n < 0 || n >= len(args) {
+		return nil
+	}
+	return args[n]
+}
+
 func (*OS) Pid() interface{} {
 	return os.Getpid()
 }
 
 func (*OS) Symlink(oldname, newname string) interface{} {
+	rugo_sandbox_check("os.symlink", newname, true)
 	if err := os.Symlink(oldname, newname); err != nil {
 		panic(fmt.Sprintf("os.symlink failed: %v", err))
 	}
@@ -161,6 +443,7 @@ func (*OS) Symlink(oldname, newname string) interface{} {
 }
 
 func (*OS) Readlink(name string) interface{} {
+	rugo_sandbox_check("os.readlink", name, false)
 	target, err := os.Readlink(name)
 	if err != nil {
 		panic(fmt.Sprintf("os.readlink failed: %v", err))
@@ -0,0 +1,13 @@
+package osmod
+
+// Runtime helper stubs for standalone compilation and testing.
+
+func rugo_run_at_exit() {}
+
+var rugo_dry_shell bool
+
+func rugo_dry_shell_print(cmdStr string) {}
+
+func rugo_sandbox_check(op, path string, write bool) {}
+
+func rugo_type_label(obj interface{}) string { return "" }
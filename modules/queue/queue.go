@@ -15,7 +15,7 @@ func init() {
 		Type: "Queue",
 		Doc:  "Thread-safe queue for concurrent producer-consumer patterns.",
 		Funcs: []modules.FuncDef{
-			{Name: "new", Args: []modules.ArgType{}, Variadic: true, Doc: "Create a new thread-safe queue with optional capacity."},
+			{Name: "new", Args: []modules.ArgType{}, Variadic: true, MaxArgs: 1, Doc: "Create a new thread-safe queue with optional capacity."},
 		},
 		GoImports: []string{"sync/atomic", "time"},
 		Runtime:   modules.CleanRuntime(runtime),
@@ -7,7 +7,7 @@ import "embed"
 // Test files are excluded.
 //
 //go:embed module.go
-//go:embed ast/ast.go ast/runtime.go
+//go:embed ast/ast.go ast/runtime.go ast/stubs.go
 //go:embed base64/base64.go base64/runtime.go
 //go:embed bench/bench.go bench/runtime.go bench/stubs.go
 //go:embed cli/cli.go cli/runtime.go cli/stubs.go
@@ -19,14 +19,15 @@ import "embed"
 //go:embed fmt/fmt.go fmt/runtime.go fmt/stubs.go
 //go:embed hex/hex.go hex/runtime.go
 //go:embed http/http.go http/runtime.go http/stubs.go
-//go:embed json/json.go json/runtime.go
+//go:embed json/json.go json/runtime.go json/stubs.go
+//go:embed log/log.go log/runtime.go log/stubs.go
 //go:embed math/math.go math/runtime.go
-//go:embed os/os.go os/runtime.go
+//go:embed os/os.go os/runtime.go os/stubs.go
 //go:embed queue/queue.go queue/runtime.go queue/stubs.go
 //go:embed rand/rand.go rand/runtime.go
 //go:embed re/re.go re/runtime.go re/stubs.go
 //go:embed sqlite/runtime.go sqlite/sqlite.go
-//go:embed str/runtime.go str/str.go
+//go:embed str/runtime.go str/str.go str/stubs.go
 //go:embed test/runtime.go test/stubs.go test/test.go
 //go:embed time/runtime.go time/time.go
 //go:embed web/runtime.go web/stubs.go web/web.go
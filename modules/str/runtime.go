@@ -2,7 +2,10 @@ package strmod
 
 import (
 	"fmt"
+	"regexp"
+	"strconv"
 	"strings"
+	"unicode"
 	"unicode/utf8"
 )
 
@@ -35,8 +38,23 @@ func (*Str) EndsWith(s, suffix string) interface{} {
 	return strings.HasSuffix(s, suffix)
 }
 
-func (*Str) Replace(s, old, new string) interface{} {
-	return strings.ReplaceAll(s, old, new)
+func (*Str) Replace(s, old, new string, extra ...interface{}) interface{} {
+	if len(extra) == 0 {
+		return strings.ReplaceAll(s, old, new)
+	}
+	count, ok := extra[0].(int)
+	if !ok || count < 0 {
+		panic(fmt.Sprintf("str.replace: count must be a non-negative integer, got %v", extra[0]))
+	}
+	return strings.Replace(s, old, new, count)
+}
+
+func (*Str) ReplaceRegex(s, pattern, repl string) interface{} {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		panic(fmt.Sprintf("str.replace_regex: invalid pattern %q: %v", pattern, err))
+	}
+	return re.ReplaceAllString(s, repl)
 }
 
 func (*Str) Upper(s string) interface{} {
@@ -47,20 +65,42 @@ func (*Str) Lower(s string) interface{} {
 	return strings.ToLower(s)
 }
 
+func (*Str) Capitalize(s string) interface{} {
+	r, size := utf8.DecodeRuneInString(s)
+	if size == 0 {
+		return ""
+	}
+	return string(unicode.ToUpper(r)) + strings.ToLower(s[size:])
+}
+
 func (*Str) Index(s, substr string) interface{} {
 	return strings.Index(s, substr)
 }
 
-func (*Str) Join(v interface{}, sep string) interface{} {
+func (*Str) IndexOf(s, substr string) interface{} {
+	i := strings.Index(s, substr)
+	if i < 0 {
+		return -1
+	}
+	return utf8.RuneCountInString(s[:i])
+}
+
+// Join stringifies each element with rugo_to_string, as puts does, so
+// numbers, booleans, nil and nested collections join readably.
+func (*Str) Join(v interface{}, sep interface{}) interface{} {
 	parts, ok := v.([]interface{})
 	if !ok {
-		panic(fmt.Sprintf("str.join() expects an array, got %T", v))
+		panic(fmt.Sprintf("str.join() expects an array, got %s", rugo_type_label(v)))
+	}
+	s := ""
+	if sep != nil {
+		s = rugo_to_string(sep)
 	}
 	strs := make([]string, len(parts))
 	for i, p := range parts {
-		strs[i] = fmt.Sprintf("%v", p)
+		strs[i] = rugo_to_string(p)
 	}
-	return strings.Join(strs, sep)
+	return strings.Join(strs, s)
 }
 
 func (*Str) RuneCount(s string) interface{} {
@@ -72,6 +112,9 @@ func (*Str) Count(s, substr string) interface{} {
 }
 
 func (*Str) Repeat(s string, n int) interface{} {
+	if n <= 0 {
+		return ""
+	}
 	return strings.Repeat(s, n)
 }
 
@@ -92,6 +135,14 @@ func (*Str) Chars(s string) interface{} {
 	return result
 }
 
+func (*Str) Bytes(s string) interface{} {
+	result := make([]interface{}, len(s))
+	for i := 0; i < len(s); i++ {
+		result[i] = int(s[i])
+	}
+	return result
+}
+
 func (*Str) Fields(s string) interface{} {
 	parts := strings.Fields(s)
 	result := make([]interface{}, len(parts))
@@ -110,17 +161,13 @@ func (*Str) TrimSuffix(s, suffix string) interface{} {
 }
 
 func (*Str) PadLeft(s string, width int, extra ...interface{}) interface{} {
-	pad := " "
-	if len(extra) > 0 {
-		pad = fmt.Sprintf("%v", extra[0])
-	}
+	padRunes := padArg("pad_left", extra)
 	rc := utf8.RuneCountInString(s)
 	if rc >= width {
 		return s
 	}
 	needed := width - rc
 	var b strings.Builder
-	padRunes := []rune(pad)
 	for i := 0; i < needed; i++ {
 		b.WriteRune(padRunes[i%len(padRunes)])
 	}
@@ -129,10 +176,7 @@ func (*Str) PadLeft(s string, width int, extra ...interface{}) interface{} {
 }
 
 func (*Str) PadRight(s string, width int, extra ...interface{}) interface{} {
-	pad := " "
-	if len(extra) > 0 {
-		pad = fmt.Sprintf("%v", extra[0])
-	}
+	padRunes := padArg("pad_right", extra)
 	rc := utf8.RuneCountInString(s)
 	if rc >= width {
 		return s
@@ -140,13 +184,25 @@ func (*Str) PadRight(s string, width int, extra ...interface{}) interface{} {
 	needed := width - rc
 	var b strings.Builder
 	b.WriteString(s)
-	padRunes := []rune(pad)
 	for i := 0; i < needed; i++ {
 		b.WriteRune(padRunes[i%len(padRunes)])
 	}
 	return b.String()
 }
 
+// padArg returns the runes of the optional pad argument, defaulting to a
+// single space.
+func padArg(fn string, extra []interface{}) []rune {
+	if len(extra) == 0 {
+		return []rune{' '}
+	}
+	pad := fmt.Sprintf("%v", extra[0])
+	if pad == "" {
+		panic(fmt.Sprintf("str.%s: pad must not be empty", fn))
+	}
+	return []rune(pad)
+}
+
 func (*Str) EachLine(s string) interface{} {
 	lines := strings.Split(s, "\n")
 	result := make([]interface{}, len(lines))
@@ -156,11 +212,25 @@ func (*Str) EachLine(s string) interface{} {
 	return result
 }
 
-func (*Str) Center(s string, width int, extra ...interface{}) interface{} {
-	pad := " "
-	if len(extra) > 0 {
-		pad = fmt.Sprintf("%v", extra[0])
+// Lines splits s into {"num" => n, "text" => line} hashes with 1-based
+// line numbers. A trailing "\r" is stripped from every line and a final
+// newline does not start an extra empty line.
+func (*Str) Lines(s string) interface{} {
+	result := []interface{}{}
+	if s == "" {
+		return result
 	}
+	for i, l := range strings.Split(strings.TrimSuffix(s, "\n"), "\n") {
+		result = append(result, map[interface{}]interface{}{
+			"num":  i + 1,
+			"text": strings.TrimSuffix(l, "\r"),
+		})
+	}
+	return result
+}
+
+func (*Str) Center(s string, width int, extra ...interface{}) interface{} {
+	padRunes := padArg("center", extra)
 	rc := utf8.RuneCountInString(s)
 	if rc >= width {
 		return s
@@ -169,7 +239,6 @@ func (*Str) Center(s string, width int, extra ...interface{}) interface{} {
 	left := total / 2
 	right := total - left
 	var b strings.Builder
-	padRunes := []rune(pad)
 	for i := 0; i < left; i++ {
 		b.WriteRune(padRunes[i%len(padRunes)])
 	}
@@ -184,24 +253,29 @@ func (*Str) LastIndex(s, substr string) interface{} {
 	return strings.LastIndex(s, substr)
 }
 
-func (*Str) Slice(s string, start, end int) interface{} {
+// Slice returns up to length runes of s starting at rune index start,
+// or the rest of s when no length is given. A negative start counts from
+// the end; out-of-range bounds clamp instead of raising.
+func (*Str) Slice(s string, start int, extra ...interface{}) interface{} {
 	runes := []rune(s)
 	n := len(runes)
 	if start < 0 {
-		start += n
+		start = max(start+n, 0)
 	}
-	if end < 0 {
-		end += n
-	}
-	if start < 0 {
-		start = 0
-	}
-	if end > n {
-		end = n
-	}
-	if start > n || start >= end {
+	if start >= n {
 		return ""
 	}
+	end := n
+	if len(extra) > 0 {
+		length, ok := extra[0].(int)
+		if !ok {
+			panic(fmt.Sprintf("str.slice: length must be an integer, got %T", extra[0]))
+		}
+		if length <= 0 {
+			return ""
+		}
+		end = min(start+length, n)
+	}
 	return string(runes[start:end])
 }
 
@@ -212,3 +286,69 @@ func (*Str) Empty(s string) interface{} {
 func (*Str) ByteSize(s string) interface{} {
 	return len(s)
 }
+
+// Format substitutes placeholders in tmpl: {} takes the next positional
+// argument, {N} the Nth one and {name} a key of a single hash argument.
+// {{ and }} produce literal braces. Values render as puts and string
+// interpolation render them.
+func (*Str) Format(tmpl string, args ...interface{}) interface{} {
+	var named map[interface{}]interface{}
+	if len(args) == 1 {
+		named, _ = rugo_hash_map(args[0])
+	}
+	var b strings.Builder
+	next := 0
+	for i := 0; i < len(tmpl); i++ {
+		c := tmpl[i]
+		if c == '}' {
+			if i+1 < len(tmpl) && tmpl[i+1] == '}' {
+				b.WriteByte('}')
+				i++
+				continue
+			}
+			panic(fmt.Sprintf("str.format: unmatched '}' at position %d", i))
+		}
+		if c != '{' {
+			b.WriteByte(c)
+			continue
+		}
+		if i+1 < len(tmpl) && tmpl[i+1] == '{' {
+			b.WriteByte('{')
+			i++
+			continue
+		}
+		end := strings.IndexByte(tmpl[i+1:], '}')
+		if end < 0 {
+			panic(fmt.Sprintf("str.format: unmatched '{' at position %d", i))
+		}
+		key := tmpl[i+1 : i+1+end]
+		i += end + 1
+		b.WriteString(rugo_to_string(formatArg(key, args, named, &next)))
+	}
+	return b.String()
+}
+
+func formatArg(key string, args []interface{}, named map[interface{}]interface{}, next *int) interface{} {
+	if key == "" {
+		if *next >= len(args) {
+			panic(fmt.Sprintf("str.format: not enough arguments for placeholder {} (got %d)", len(args)))
+		}
+		v := args[*next]
+		*next++
+		return v
+	}
+	if n, err := strconv.Atoi(key); err == nil {
+		if n < 0 || n >= len(args) {
+			panic(fmt.Sprintf("str.format: no argument for placeholder {%d} (got %d)", n, len(args)))
+		}
+		return args[n]
+	}
+	if named == nil {
+		panic(fmt.Sprintf("str.format: placeholder {%s} requires a hash argument", key))
+	}
+	v, ok := named[key]
+	if !ok {
+		panic(fmt.Sprintf("str.format: no value for placeholder {%s}", key))
+	}
+	return v
+}
@@ -15,34 +15,40 @@ func init() {
 		Type: "Str",
 		Doc:  "String manipulation and searching.",
 		Funcs: []modules.FuncDef{
-			{Name: "contains", Args: []modules.ArgType{modules.String, modules.String}, Doc: "Return true if the string contains the substring."},
+			{Name: "contains", Args: []modules.ArgType{modules.String, modules.String}, Returns: "bool", Doc: "Return true if the string contains the substring."},
 			{Name: "split", Args: []modules.ArgType{modules.String, modules.String}, Doc: "Split a string by a separator into an array."},
 			{Name: "trim", Args: []modules.ArgType{modules.String}, Doc: "Remove leading and trailing whitespace."},
 			{Name: "starts_with", Args: []modules.ArgType{modules.String, modules.String}, Doc: "Return true if the string starts with the prefix."},
 			{Name: "ends_with", Args: []modules.ArgType{modules.String, modules.String}, Doc: "Return true if the string ends with the suffix."},
-			{Name: "replace", Args: []modules.ArgType{modules.String, modules.String, modules.String}, Doc: "Replace all occurrences of old with new in the string."},
-			{Name: "upper", Args: []modules.ArgType{modules.String}, Doc: "Convert a string to uppercase."},
-			{Name: "lower", Args: []modules.ArgType{modules.String}, Doc: "Convert a string to lowercase."},
-			{Name: "index", Args: []modules.ArgType{modules.String, modules.String}, Doc: "Return the index of the first occurrence of the substring, or -1."},
-			{Name: "join", Args: []modules.ArgType{modules.Any, modules.String}, Doc: "Join an array of strings with a separator."},
+			{Name: "replace", Args: []modules.ArgType{modules.String, modules.String, modules.String}, Variadic: true, MaxArgs: 4, Doc: "Replace all occurrences of old with new in the string. Optional count replaces only the first count occurrences."},
+			{Name: "replace_regex", Args: []modules.ArgType{modules.String, modules.String, modules.String}, Doc: "Replace every match of a regular expression; the replacement may use $1-style backreferences."},
+			{Name: "upper", Args: []modules.ArgType{modules.String}, Returns: "string", Doc: "Convert a string to uppercase."},
+			{Name: "lower", Args: []modules.ArgType{modules.String}, Returns: "string", Doc: "Convert a string to lowercase."},
+			{Name: "capitalize", Args: []modules.ArgType{modules.String}, Returns: "string", Doc: "Uppercase the first character and lowercase the rest. An empty string stays empty."},
+			{Name: "index", Args: []modules.ArgType{modules.String, modules.String}, Returns: "int", Doc: "Return the byte index of the first occurrence of the substring, or -1."},
+			{Name: "index_of", Args: []modules.ArgType{modules.String, modules.String}, Returns: "int", Doc: "Return the character (rune) index of the first occurrence of the substring, or -1."},
+			{Name: "join", Args: []modules.ArgType{modules.Any, modules.Any}, ArgNames: []string{"arr", "sep"}, Returns: "string", Doc: "Join an array with a separator, converting each element to a string the way puts does. A nil separator joins with no separator."},
 			{Name: "rune_count", Args: []modules.ArgType{modules.String}, Doc: "Return the number of Unicode characters (runes) in a string."},
-			{Name: "count", Args: []modules.ArgType{modules.String, modules.String}, Doc: "Count non-overlapping occurrences of a substring."},
-			{Name: "repeat", Args: []modules.ArgType{modules.String, modules.Int}, Doc: "Repeat a string n times."},
+			{Name: "count", Args: []modules.ArgType{modules.String, modules.String}, Returns: "int", Doc: "Count non-overlapping occurrences of a substring."},
+			{Name: "repeat", Args: []modules.ArgType{modules.String, modules.Int}, ArgNames: []string{"s", "n"}, Returns: "string", Doc: "Repeat a string n times. A count below one gives an empty string."},
 			{Name: "reverse", Args: []modules.ArgType{modules.String}, Doc: "Reverse a string by Unicode characters."},
 			{Name: "chars", Args: []modules.ArgType{modules.String}, Doc: "Split a string into an array of individual characters."},
+			{Name: "bytes", Args: []modules.ArgType{modules.String}, Doc: "Return the UTF-8 bytes of a string as an array of integers."},
 			{Name: "fields", Args: []modules.ArgType{modules.String}, Doc: "Split a string by whitespace into an array of words."},
-			{Name: "trim_prefix", Args: []modules.ArgType{modules.String, modules.String}, Doc: "Remove a prefix from a string if present."},
-			{Name: "trim_suffix", Args: []modules.ArgType{modules.String, modules.String}, Doc: "Remove a suffix from a string if present."},
-			{Name: "pad_left", Args: []modules.ArgType{modules.String, modules.Int}, Variadic: true, Doc: "Left-pad a string to a given width. Optional third arg is the pad character."},
-			{Name: "pad_right", Args: []modules.ArgType{modules.String, modules.Int}, Variadic: true, Doc: "Right-pad a string to a given width. Optional third arg is the pad character."},
+			{Name: "trim_prefix", Args: []modules.ArgType{modules.String, modules.String}, Returns: "string", Doc: "Remove a prefix from a string if present."},
+			{Name: "trim_suffix", Args: []modules.ArgType{modules.String, modules.String}, Returns: "string", Doc: "Remove a suffix from a string if present."},
+			{Name: "pad_left", Args: []modules.ArgType{modules.String, modules.Int}, Variadic: true, MaxArgs: 3, Doc: "Left-pad a string to a given width. Optional third arg is the pad character."},
+			{Name: "pad_right", Args: []modules.ArgType{modules.String, modules.Int}, Variadic: true, MaxArgs: 3, Doc: "Right-pad a string to a given width. Optional third arg is the pad character."},
 			{Name: "each_line", Args: []modules.ArgType{modules.String}, Doc: "Split a string into an array of lines."},
-			{Name: "center", Args: []modules.ArgType{modules.String, modules.Int}, Variadic: true, Doc: "Center a string within a given width. Optional third arg is the pad character."},
+			{Name: "lines", Args: []modules.ArgType{modules.String}, Doc: "Split a string into {\"num\" => n, \"text\" => line} hashes with 1-based line numbers. Handles CRLF line endings."},
+			{Name: "center", Args: []modules.ArgType{modules.String, modules.Int}, Variadic: true, MaxArgs: 3, Doc: "Center a string within a given width. Optional third arg is the pad character."},
 			{Name: "last_index", Args: []modules.ArgType{modules.String, modules.String}, Doc: "Return the index of the last occurrence of the substring, or -1."},
-			{Name: "slice", Args: []modules.ArgType{modules.String, modules.Int, modules.Int}, Doc: "Extract a substring by rune start and end indices. Supports negative indices."},
+			{Name: "slice", Args: []modules.ArgType{modules.String, modules.Int}, ArgNames: []string{"s", "start", "length"}, Variadic: true, MaxArgs: 3, Returns: "string", Doc: "Return length characters (runes) starting at rune index start, or the rest of the string without a length. A negative start counts from the end; out-of-range bounds clamp."},
 			{Name: "empty", Args: []modules.ArgType{modules.String}, Doc: "Return true if the string is empty."},
 			{Name: "byte_size", Args: []modules.ArgType{modules.String}, Doc: "Return the byte length of a string (not character count)."},
+			{Name: "format", Args: []modules.ArgType{modules.String}, Variadic: true, Doc: "Substitute {} / {N} placeholders with positional args, or {name} with keys of a single hash arg. {{ and }} are literal braces."},
 		},
-		GoImports: []string{"unicode/utf8"},
+		GoImports: []string{"regexp", "strconv", "unicode", "unicode/utf8"},
 		Runtime:   modules.CleanRuntime(runtime),
 	})
 }
@@ -0,0 +1,14 @@
+package strmod
+
+import "fmt"
+
+// Runtime helper stubs for standalone compilation and testing.
+
+func rugo_to_string(v interface{}) string { return fmt.Sprintf("%v", v) }
+
+func rugo_type_label(obj interface{}) string { return fmt.Sprintf("%T", obj) }
+
+func rugo_hash_map(v interface{}) (map[interface{}]interface{}, bool) {
+	m, ok := v.(map[interface{}]interface{})
+	return m, ok
+}
@@ -5,7 +5,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
-	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -72,14 +72,128 @@ func (*Test) Run(command string) interface{} {
 }
 
 func (*Test) AssertEq(actual, expected interface{}) interface{} {
-	if !reflect.DeepEqual(actual, expected) {
-		panic(rugoTestFail(fmt.Sprintf("assert_eq failed\n  expected: %v\n       got: %v", expected, actual)))
+	if !rugo_same(actual, expected) {
+		panic(rugoTestFail(assertEqMessage("assert_eq", actual, expected)))
 	}
 	return nil
 }
 
+func (*Test) AssertEqual(actual, expected interface{}) interface{} {
+	if !rugo_same(actual, expected) {
+		panic(rugoTestFail(assertEqMessage("assert_equal", actual, expected)))
+	}
+	return nil
+}
+
+// maxDiffLines caps the per-path differences listed by assertEqMessage.
+const maxDiffLines = 20
+
+// assertEqMessage formats an equality failure. For hashes and arrays it
+// appends one line per differing key or index, with the path to each.
+func assertEqMessage(name string, actual, expected interface{}) string {
+	msg := fmt.Sprintf("%s failed\n  expected: %s\n       got: %s", name, testRepr(expected), testRepr(actual))
+	var diffs []string
+	testDiff("", expected, actual, &diffs)
+	if len(diffs) == 0 || !testIsCollection(expected) && !testIsCollection(actual) {
+		return msg
+	}
+	msg += "\n  differences:"
+	for i, d := range diffs {
+		if i == maxDiffLines {
+			msg += fmt.Sprintf("\n    ... and %d more", len(diffs)-maxDiffLines)
+			break
+		}
+		msg += "\n    " + d
+	}
+	return msg
+}
+
+func testIsCollection(v interface{}) bool {
+	if _, ok := rugo_hash_map(v); ok {
+		return true
+	}
+	_, ok := v.([]interface{})
+	return ok
+}
+
+// testDiff appends a line for every leaf where actual differs from
+// expected. path is the index chain from the root, e.g. ["user"][0].
+func testDiff(path string, expected, actual interface{}, out *[]string) {
+	label := path
+	if label == "" {
+		label = "value"
+	}
+	if m, ok := rugo_hash_map(expected); ok {
+		expected = m
+	}
+	switch e := expected.(type) {
+	case map[interface{}]interface{}:
+		a, ok := rugo_hash_map(actual)
+		if !ok {
+			*out = append(*out, fmt.Sprintf("%s: expected a hash, got %s", label, testRepr(actual)))
+			return
+		}
+		keys := make([]interface{}, 0, len(e)+len(a))
+		for k := range e {
+			keys = append(keys, k)
+		}
+		for k := range a {
+			if _, ok := e[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Slice(keys, func(i, j int) bool { return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j]) })
+		for _, k := range keys {
+			kp := path + "[" + testRepr(k) + "]"
+			ev, inE := e[k]
+			av, inA := a[k]
+			switch {
+			case !inA:
+				*out = append(*out, fmt.Sprintf("%s: missing, expected %s", kp, testRepr(ev)))
+			case !inE:
+				*out = append(*out, fmt.Sprintf("%s: unexpected key, got %s", kp, testRepr(av)))
+			default:
+				testDiff(kp, ev, av, out)
+			}
+		}
+	case []interface{}:
+		a, ok := actual.([]interface{})
+		if !ok {
+			*out = append(*out, fmt.Sprintf("%s: expected an array, got %s", label, testRepr(actual)))
+			return
+		}
+		if len(a) != len(e) {
+			*out = append(*out, fmt.Sprintf("%s: expected %d elements, got %d", label, len(e), len(a)))
+		}
+		for i := 0; i < len(e) || i < len(a); i++ {
+			ip := fmt.Sprintf("%s[%d]", path, i)
+			switch {
+			case i >= len(a):
+				*out = append(*out, fmt.Sprintf("%s: missing, expected %s", ip, testRepr(e[i])))
+			case i >= len(e):
+				*out = append(*out, fmt.Sprintf("%s: unexpected element %s", ip, testRepr(a[i])))
+			default:
+				testDiff(ip, e[i], a[i], out)
+			}
+		}
+	default:
+		if !rugo_same(expected, actual) {
+			*out = append(*out, fmt.Sprintf("%s: expected %s, got %s", label, testRepr(expected), testRepr(actual)))
+		}
+	}
+}
+
+// testRepr formats a value for assertion messages, quoting strings so
+// "1" and 1 are told apart.
+func testRepr(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return strconv.Quote(s)
+	}
+	return rugo_to_string(v)
+}
+
 func (*Test) AssertNeq(actual, expected interface{}) interface{} {
-	if reflect.DeepEqual(actual, expected) {
+	if rugo_same(actual, expected) {
 		panic(rugoTestFail(fmt.Sprintf("assert_neq failed: both values are %v", actual)))
 	}
 	return nil
@@ -115,6 +229,43 @@ func (*Test) AssertNil(val interface{}) interface{} {
 	return nil
 }
 
+// AssertRaises calls a lambda that is expected to raise and returns the
+// error message. Called as assert_raises(substr, fn), the message must also
+// contain substr. Skips and failures raised inside the lambda propagate.
+func (*Test) AssertRaises(first interface{}, extra ...interface{}) interface{} {
+	fnArg, substr := first, ""
+	if len(extra) > 0 {
+		substr, fnArg = rugo_to_string(first), extra[0]
+	}
+	fn, ok := fnArg.(func(...interface{}) interface{})
+	if !ok {
+		panic(rugoTestFail(fmt.Sprintf("assert_raises expects a function, got %T", fnArg)))
+	}
+	msg, raised := rugo_test_catch(fn)
+	if !raised {
+		panic(rugoTestFail("assert_raises failed: no error was raised"))
+	}
+	if !strings.Contains(msg, substr) {
+		panic(rugoTestFail(fmt.Sprintf("assert_raises failed\n  message: %q\n   substr: %q", msg, substr)))
+	}
+	return msg
+}
+
+// rugo_test_catch runs fn and reports the message of any error it raised.
+func rugo_test_catch(fn func(...interface{}) interface{}) (msg string, raised bool) {
+	defer func() {
+		if e := recover(); e != nil {
+			switch e.(type) {
+			case rugoTestSkip, rugoTestFail:
+				panic(e)
+			}
+			msg, raised = fmt.Sprint(e), true
+		}
+	}()
+	fn()
+	return "", false
+}
+
 func (*Test) Fail(msg interface{}) interface{} {
 	panic(rugoTestFail(rugo_to_string(msg)))
 }
@@ -1,6 +1,9 @@
 package testmod
 
-import "fmt"
+import (
+	"fmt"
+	"reflect"
+)
 
 // Runtime helper stubs for standalone compilation and testing.
 
@@ -22,3 +25,10 @@ func rugo_to_bool(v interface{}) bool {
 		return true
 	}
 }
+
+func rugo_hash_map(v interface{}) (map[interface{}]interface{}, bool) {
+	m, ok := v.(map[interface{}]interface{})
+	return m, ok
+}
+
+func rugo_same(a, b interface{}) bool { return reflect.DeepEqual(a, b) }
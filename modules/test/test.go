@@ -17,6 +17,7 @@ func init() {
 		GoImports: []string{
 			"path/filepath",
 			"reflect",
+			"sort",
 			"strconv",
 			"time",
 		},
@@ -24,12 +25,14 @@ func init() {
 			{Name: "run", Args: []modules.ArgType{modules.String}, Doc: "Run a named test case."},
 			{Name: "tmpdir", Args: []modules.ArgType{}, Doc: "Create and return a temporary directory path."},
 			{Name: "write_file", Args: []modules.ArgType{modules.String, modules.String}, Doc: "Write content to a file at the given path."},
-			{Name: "assert_eq", Args: []modules.ArgType{modules.Any, modules.Any}, Doc: "Assert that two values are equal."},
+			{Name: "assert_eq", Args: []modules.ArgType{modules.Any, modules.Any}, Doc: "Assert that two values are equal. Failures on hashes and arrays list each differing key or index."},
+			{Name: "assert_equal", Args: []modules.ArgType{modules.Any, modules.Any}, Doc: "Alias of assert_eq."},
 			{Name: "assert_neq", Args: []modules.ArgType{modules.Any, modules.Any}, Doc: "Assert that two values are not equal."},
 			{Name: "assert_true", Args: []modules.ArgType{modules.Any}, Doc: "Assert that a value is true."},
 			{Name: "assert_false", Args: []modules.ArgType{modules.Any}, Doc: "Assert that a value is false."},
 			{Name: "assert_contains", Args: []modules.ArgType{modules.Any, modules.Any}, Doc: "Assert that a string or array contains the given value."},
 			{Name: "assert_nil", Args: []modules.ArgType{modules.Any}, Doc: "Assert that a value is nil."},
+			{Name: "assert_raises", Args: []modules.ArgType{modules.Any}, Variadic: true, MaxArgs: 2, ArgNames: []string{"fn"}, Doc: "Assert that calling fn raises an error and return its message. With two args, assert_raises(substr, fn) also checks the message contains substr."},
 			{Name: "fail", Args: []modules.ArgType{modules.Any}, Doc: "Fail the test with a message."},
 			{Name: "skip", Args: []modules.ArgType{modules.Any}, Doc: "Skip the current test with a reason."},
 		},
@@ -3,7 +3,6 @@
 package webmod
 
 import (
-	"encoding/json"
 	"fmt"
 	"io"
 	"log"
@@ -207,11 +206,11 @@ func (w *Web) Json(data interface{}, extra ...interface{}) interface{} {
 	if len(extra) > 0 {
 		status = rugo_to_int(extra[0])
 	}
-	b, err := json.Marshal(prepareWebJSON(data))
+	out, err := rugo_to_json(data, "")
 	if err != nil {
 		panic(fmt.Sprintf("web.json: %v", err))
 	}
-	return makeResponse(status, "application/json; charset=utf-8", string(b))
+	return makeResponse(status, "application/json; charset=utf-8", out)
 }
 
 func (*Web) Redirect(url string, extra ...interface{}) interface{} {
@@ -348,7 +347,7 @@ func (w *Web) callMiddleware(name string, req interface{}) interface{} {
 // --- Built-in middleware ---
 
 func (w *Web) mwLogger(req interface{}) interface{} {
-	if m, ok := req.(map[interface{}]interface{}); ok {
+	if m, ok := rugo_hash_map(req); ok {
 		method := fmt.Sprintf("%v", m["method"])
 		path := fmt.Sprintf("%v", m["path"])
 		addr := fmt.Sprintf("%v", m["remote_addr"])
@@ -360,12 +359,12 @@ func (w *Web) mwLogger(req interface{}) interface{} {
 // mwRealIP extracts the real client IP from X-Forwarded-For or X-Real-Ip headers
 // and overwrites req.remote_addr. Place before logger for accurate logging.
 func (w *Web) mwRealIP(req interface{}) interface{} {
-	m, ok := req.(map[interface{}]interface{})
+	m, ok := rugo_hash_map(req)
 	if !ok {
 		return nil
 	}
 
-	headers, _ := m["header"].(map[interface{}]interface{})
+	headers, _ := rugo_hash_map(m["header"])
 	if headers == nil {
 		return nil
 	}
@@ -404,7 +403,7 @@ func (w *Web) mwRateLimiter(req interface{}) interface{} {
 		w.rateLimiter = newTokenBucketLimiter(10)
 	}
 
-	m, ok := req.(map[interface{}]interface{})
+	m, ok := rugo_hash_map(req)
 	if !ok {
 		return nil
 	}
@@ -551,7 +550,7 @@ func (w *Web) buildReqHash(r *http.Request, params map[string]string) interface{
 // --- Internal: response writing ---
 
 func (w *Web) writeResponse(wr http.ResponseWriter, result interface{}) {
-	resp, ok := result.(map[interface{}]interface{})
+	resp, ok := rugo_hash_map(result)
 	if !ok {
 		// Plain string response
 		wr.Header().Set("Content-Type", "text/plain; charset=utf-8")
@@ -572,8 +571,9 @@ func (w *Web) writeResponse(wr http.ResponseWriter, result interface{}) {
 
 	// Custom headers
 	if hdrs, ok := resp["headers"]; ok {
-		if hm, ok := hdrs.(map[interface{}]interface{}); ok {
-			for k, v := range hm {
+		if hm, ok := rugo_hash_map(hdrs); ok {
+			for _, k := range rugo_hash_keys(hdrs) {
+				v := hm[k]
 				wr.Header().Set(rugo_to_string(k), rugo_to_string(v))
 			}
 		}
@@ -611,26 +611,6 @@ func makeResponse(status int, contentType, body string) interface{} {
 	}
 }
 
-// prepareWebJSON converts Rugo maps to Go maps for JSON marshaling.
-func prepareWebJSON(v interface{}) interface{} {
-	switch val := v.(type) {
-	case map[interface{}]interface{}:
-		m := make(map[string]interface{}, len(val))
-		for k, child := range val {
-			m[fmt.Sprintf("%v", k)] = prepareWebJSON(child)
-		}
-		return m
-	case []interface{}:
-		out := make([]interface{}, len(val))
-		for i, child := range val {
-			out[i] = prepareWebJSON(child)
-		}
-		return out
-	default:
-		return v
-	}
-}
-
 // Silence unused import warnings — these are used by the generated program.
 var _ = time.Now
 var _ = math.MaxInt
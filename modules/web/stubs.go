@@ -1,6 +1,9 @@
 package webmod
 
-import "fmt"
+import (
+	"encoding/json"
+	"fmt"
+)
 
 // Type conversion stubs for standalone compilation.
 // These mirror the runtime helpers provided by the generated program.
@@ -39,5 +42,24 @@ func rugo_to_bool(v interface{}) bool {
 	}
 }
 
+func rugo_hash_map(v interface{}) (map[interface{}]interface{}, bool) {
+	m, ok := v.(map[interface{}]interface{})
+	return m, ok
+}
+
+func rugo_hash_keys(v interface{}) []interface{} {
+	m, _ := v.(map[interface{}]interface{})
+	keys := make([]interface{}, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func rugo_to_json(v interface{}, indent string) (string, error) {
+	b, err := json.Marshal(v)
+	return string(b), err
+}
+
 // Dispatch map stub for standalone compilation.
 var rugo_web_dispatch = map[string]func(interface{}) interface{}{}
@@ -26,109 +26,116 @@ const (
 	RugoTOK_EOF = Symbol(0) // EOF
 	RugoTOK_00260026 = Symbol(1) // "&&"
 	RugoTOK_002d003e = Symbol(2) // "->"
-	RugoTOK_003d003e = Symbol(3) // "=>"
-	RugoTOK_as = Symbol(4) // "as"
-	RugoTOK_bench = Symbol(5) // "bench"
-	RugoTOK_break = Symbol(6) // "break"
-	RugoTOK_case = Symbol(7) // "case"
-	RugoTOK_def = Symbol(8) // "def"
-	RugoTOK_else = Symbol(9) // "else"
-	RugoTOK_elsif = Symbol(10) // "elsif"
-	RugoTOK_embed = Symbol(11) // "embed"
-	RugoTOK_end = Symbol(12) // "end"
-	RugoTOK_false = Symbol(13) // "false"
-	RugoTOK_fn = Symbol(14) // "fn"
-	RugoTOK_for = Symbol(15) // "for"
-	RugoTOK_if = Symbol(16) // "if"
-	RugoTOK_import = Symbol(17) // "import"
-	RugoTOK_in = Symbol(18) // "in"
-	RugoTOK_next = Symbol(19) // "next"
-	RugoTOK_nil = Symbol(20) // "nil"
-	RugoTOK_of = Symbol(21) // "of"
-	RugoTOK_or = Symbol(22) // "or"
-	RugoTOK_parallel = Symbol(23) // "parallel"
-	RugoTOK_rats = Symbol(24) // "rats"
-	RugoTOK_require = Symbol(25) // "require"
-	RugoTOK_return = Symbol(26) // "return"
-	RugoTOK_sandbox = Symbol(27) // "sandbox"
-	RugoTOK_spawn = Symbol(28) // "spawn"
-	RugoTOK_true = Symbol(29) // "true"
-	RugoTOK_try = Symbol(30) // "try"
-	RugoTOK_use = Symbol(31) // "use"
-	RugoTOK_while = Symbol(32) // "while"
-	RugoTOK_with = Symbol(33) // "with"
-	RugoTOK_007c007c = Symbol(34) // "||"
-	RugoTOK_0021 = Symbol(35) // '!'
-	RugoTOK_0025 = Symbol(36) // '%'
-	RugoTOK_0028 = Symbol(37) // '('
-	RugoTOK_0029 = Symbol(38) // ')'
-	RugoTOK_002a = Symbol(39) // '*'
-	RugoTOK_002b = Symbol(40) // '+'
-	RugoTOK_002c = Symbol(41) // ','
-	RugoTOK_002d = Symbol(42) // '-'
-	RugoTOK_002e = Symbol(43) // '.'
-	RugoTOK_002f = Symbol(44) // '/'
-	RugoTOK_003a = Symbol(45) // ':'
-	RugoTOK_003b = Symbol(46) // ';'
-	RugoTOK_003d = Symbol(47) // '='
-	RugoTOK_005b = Symbol(48) // '['
-	RugoTOK_005d = Symbol(49) // ']'
-	RugoTOK_007b = Symbol(50) // '{'
-	RugoTOK_007d = Symbol(51) // '}'
-	Rugocomp_op = Symbol(52) // comp_op
-	Rugofloat_lit = Symbol(53) // float_lit
-	Rugoident = Symbol(54) // ident
-	Rugointeger = Symbol(55) // integer
-	Rugoraw_str_lit = Symbol(56) // raw_str_lit
-	Rugostr_lit = Symbol(57) // str_lit
-	Rugowhite_space = Symbol(58) // white_space
-	RugoProgram = Symbol(59) // Program
-	RugoStatement = Symbol(60) // Statement
-	RugoUseStmt = Symbol(61) // UseStmt
-	RugoImportStmt = Symbol(62) // ImportStmt
-	RugoRequireStmt = Symbol(63) // RequireStmt
-	RugoEmbedStmt = Symbol(64) // EmbedStmt
-	RugoSandboxStmt = Symbol(65) // SandboxStmt
-	RugoSandboxPerm = Symbol(66) // SandboxPerm
-	RugoSandboxList = Symbol(67) // SandboxList
-	RugoFuncDef = Symbol(68) // FuncDef
-	RugoTestDef = Symbol(69) // TestDef
-	RugoBenchDef = Symbol(70) // BenchDef
-	RugoParamList = Symbol(71) // ParamList
-	RugoParam = Symbol(72) // Param
-	RugoBody = Symbol(73) // Body
-	RugoIfStmt = Symbol(74) // IfStmt
-	RugoCaseExpr = Symbol(75) // CaseExpr
-	RugoExprList = Symbol(76) // ExprList
-	RugoWhileStmt = Symbol(77) // WhileStmt
-	RugoForStmt = Symbol(78) // ForStmt
-	RugoBreakStmt = Symbol(79) // BreakStmt
-	RugoNextStmt = Symbol(80) // NextStmt
-	RugoReturnStmt = Symbol(81) // ReturnStmt
-	RugoAssignOrExpr = Symbol(82) // AssignOrExpr
-	RugoExpr = Symbol(83) // Expr
-	RugoOrExpr = Symbol(84) // OrExpr
-	RugoAndExpr = Symbol(85) // AndExpr
-	RugoCompExpr = Symbol(86) // CompExpr
-	RugoAddExpr = Symbol(87) // AddExpr
-	RugoMulExpr = Symbol(88) // MulExpr
-	RugoUnaryExpr = Symbol(89) // UnaryExpr
-	RugoPostfix = Symbol(90) // Postfix
-	RugoSuffix = Symbol(91) // Suffix
-	RugoArgList = Symbol(92) // ArgList
-	RugoPrimary = Symbol(93) // Primary
-	RugoTryExpr = Symbol(94) // TryExpr
-	RugoSpawnExpr = Symbol(95) // SpawnExpr
-	RugoParallelExpr = Symbol(96) // ParallelExpr
-	RugoFnExpr = Symbol(97) // FnExpr
-	RugoArrayLit = Symbol(98) // ArrayLit
-	RugoHashLit = Symbol(99) // HashLit
-	RugoHashEntry = Symbol(100) // HashEntry
+	RugoTOK_003c003c = Symbol(3) // "<<"
+	RugoTOK_003d003e = Symbol(4) // "=>"
+	RugoTOK_003e003e = Symbol(5) // ">>"
+	RugoTOK_as = Symbol(6) // "as"
+	RugoTOK_bench = Symbol(7) // "bench"
+	RugoTOK_break = Symbol(8) // "break"
+	RugoTOK_case = Symbol(9) // "case"
+	RugoTOK_def = Symbol(10) // "def"
+	RugoTOK_else = Symbol(11) // "else"
+	RugoTOK_elsif = Symbol(12) // "elsif"
+	RugoTOK_embed = Symbol(13) // "embed"
+	RugoTOK_end = Symbol(14) // "end"
+	RugoTOK_ensure = Symbol(15) // "ensure"
+	RugoTOK_false = Symbol(16) // "false"
+	RugoTOK_fn = Symbol(17) // "fn"
+	RugoTOK_for = Symbol(18) // "for"
+	RugoTOK_if = Symbol(19) // "if"
+	RugoTOK_import = Symbol(20) // "import"
+	RugoTOK_in = Symbol(21) // "in"
+	RugoTOK_next = Symbol(22) // "next"
+	RugoTOK_nil = Symbol(23) // "nil"
+	RugoTOK_of = Symbol(24) // "of"
+	RugoTOK_or = Symbol(25) // "or"
+	RugoTOK_parallel = Symbol(26) // "parallel"
+	RugoTOK_rats = Symbol(27) // "rats"
+	RugoTOK_require = Symbol(28) // "require"
+	RugoTOK_require_optional = Symbol(29) // "require_optional"
+	RugoTOK_return = Symbol(30) // "return"
+	RugoTOK_sandbox = Symbol(31) // "sandbox"
+	RugoTOK_spawn = Symbol(32) // "spawn"
+	RugoTOK_true = Symbol(33) // "true"
+	RugoTOK_try = Symbol(34) // "try"
+	RugoTOK_use = Symbol(35) // "use"
+	RugoTOK_while = Symbol(36) // "while"
+	RugoTOK_with = Symbol(37) // "with"
+	RugoTOK_007c007c = Symbol(38) // "||"
+	RugoTOK_0021 = Symbol(39) // '!'
+	RugoTOK_0025 = Symbol(40) // '%'
+	RugoTOK_0026 = Symbol(41) // '&'
+	RugoTOK_0028 = Symbol(42) // '('
+	RugoTOK_0029 = Symbol(43) // ')'
+	RugoTOK_002a = Symbol(44) // '*'
+	RugoTOK_002b = Symbol(45) // '+'
+	RugoTOK_002c = Symbol(46) // ','
+	RugoTOK_002d = Symbol(47) // '-'
+	RugoTOK_002e = Symbol(48) // '.'
+	RugoTOK_002f = Symbol(49) // '/'
+	RugoTOK_003a = Symbol(50) // ':'
+	RugoTOK_003b = Symbol(51) // ';'
+	RugoTOK_003d = Symbol(52) // '='
+	RugoTOK_005b = Symbol(53) // '['
+	RugoTOK_005d = Symbol(54) // ']'
+	RugoTOK_005e = Symbol(55) // '^'
+	RugoTOK_007b = Symbol(56) // '{'
+	RugoTOK_007c = Symbol(57) // '|'
+	RugoTOK_007d = Symbol(58) // '}'
+	Rugocomp_op = Symbol(59) // comp_op
+	Rugofloat_lit = Symbol(60) // float_lit
+	Rugoident = Symbol(61) // ident
+	Rugointeger = Symbol(62) // integer
+	Rugoraw_str_lit = Symbol(63) // raw_str_lit
+	Rugostr_lit = Symbol(64) // str_lit
+	Rugowhite_space = Symbol(65) // white_space
+	RugoProgram = Symbol(66) // Program
+	RugoStatement = Symbol(67) // Statement
+	RugoUseStmt = Symbol(68) // UseStmt
+	RugoImportStmt = Symbol(69) // ImportStmt
+	RugoRequireStmt = Symbol(70) // RequireStmt
+	RugoEmbedStmt = Symbol(71) // EmbedStmt
+	RugoSandboxStmt = Symbol(72) // SandboxStmt
+	RugoSandboxPerm = Symbol(73) // SandboxPerm
+	RugoSandboxList = Symbol(74) // SandboxList
+	RugoFuncDef = Symbol(75) // FuncDef
+	RugoTestDef = Symbol(76) // TestDef
+	RugoBenchDef = Symbol(77) // BenchDef
+	RugoParamList = Symbol(78) // ParamList
+	RugoParam = Symbol(79) // Param
+	RugoBody = Symbol(80) // Body
+	RugoIfStmt = Symbol(81) // IfStmt
+	RugoCaseExpr = Symbol(82) // CaseExpr
+	RugoExprList = Symbol(83) // ExprList
+	RugoWhileStmt = Symbol(84) // WhileStmt
+	RugoForStmt = Symbol(85) // ForStmt
+	RugoBreakStmt = Symbol(86) // BreakStmt
+	RugoNextStmt = Symbol(87) // NextStmt
+	RugoReturnStmt = Symbol(88) // ReturnStmt
+	RugoAssignOrExpr = Symbol(89) // AssignOrExpr
+	RugoExpr = Symbol(90) // Expr
+	RugoOrExpr = Symbol(91) // OrExpr
+	RugoAndExpr = Symbol(92) // AndExpr
+	RugoCompExpr = Symbol(93) // CompExpr
+	RugoAddExpr = Symbol(94) // AddExpr
+	RugoMulExpr = Symbol(95) // MulExpr
+	RugoUnaryExpr = Symbol(96) // UnaryExpr
+	RugoPostfix = Symbol(97) // Postfix
+	RugoSuffix = Symbol(98) // Suffix
+	RugoArgList = Symbol(99) // ArgList
+	RugoPrimary = Symbol(100) // Primary
+	RugoTryExpr = Symbol(101) // TryExpr
+	RugoSpawnExpr = Symbol(102) // SpawnExpr
+	RugoParallelExpr = Symbol(103) // ParallelExpr
+	RugoFnExpr = Symbol(104) // FnExpr
+	RugoArrayLit = Symbol(105) // ArrayLit
+	RugoHashLit = Symbol(106) // HashLit
+	RugoHashEntry = Symbol(107) // HashEntry
 )
 
-const RugoSymbolNames = "EOF\"&&\"\"->\"\"=>\"\"as\"\"bench\"\"break\"\"case\"\"def\"\"else\"\"elsif\"\"embed\"\"end\"\"false\"\"fn\"\"for\"\"if\"\"import\"\"in\"\"next\"\"nil\"\"of\"\"or\"\"parallel\"\"rats\"\"require\"\"return\"\"sandbox\"\"spawn\"\"true\"\"try\"\"use\"\"while\"\"with\"\"||\"'!''%''('')''*''+'',''-''.''/'':'';''=''['']''{''}'comp_opfloat_litidentintegerraw_str_litstr_litwhite_spaceProgramStatementUseStmtImportStmtRequireStmtEmbedStmtSandboxStmtSandboxPermSandboxListFuncDefTestDefBenchDefParamListParamBodyIfStmtCaseExprExprListWhileStmtForStmtBreakStmtNextStmtReturnStmtAssignOrExprExprOrExprAndExprCompExprAddExprMulExprUnaryExprPostfixSuffixArgListPrimaryTryExprSpawnExprParallelExprFnExprArrayLitHashLitHashEntry"
+const RugoSymbolNames = "EOF\"&&\"\"->\"\"<<\"\"=>\"\">>\"\"as\"\"bench\"\"break\"\"case\"\"def\"\"else\"\"elsif\"\"embed\"\"end\"\"ensure\"\"false\"\"fn\"\"for\"\"if\"\"import\"\"in\"\"next\"\"nil\"\"of\"\"or\"\"parallel\"\"rats\"\"require\"\"require_optional\"\"return\"\"sandbox\"\"spawn\"\"true\"\"try\"\"use\"\"while\"\"with\"\"||\"'!''%''&''('')''*''+'',''-''.''/'':'';''=''['']''^''{''|''}'comp_opfloat_litidentintegerraw_str_litstr_litwhite_spaceProgramStatementUseStmtImportStmtRequireStmtEmbedStmtSandboxStmtSandboxPermSandboxListFuncDefTestDefBenchDefParamListParamBodyIfStmtCaseExprExprListWhileStmtForStmtBreakStmtNextStmtReturnStmtAssignOrExprExprOrExprAndExprCompExprAddExprMulExprUnaryExprPostfixSuffixArgListPrimaryTryExprSpawnExprParallelExprFnExprArrayLitHashLitHashEntry"
 
-var SymbolIndex = [...]uint16{0, 3, 7, 11, 15, 19, 26, 33, 39, 44, 50, 57, 64, 69, 76, 80, 85, 89, 97, 101, 107, 112, 116, 120, 130, 136, 145, 153, 162, 169, 175, 180, 185, 192, 198, 202, 205, 208, 211, 214, 217, 220, 223, 226, 229, 232, 235, 238, 241, 244, 247, 250, 253, 260, 269, 274, 281, 292, 299, 310, 317, 326, 333, 343, 354, 363, 374, 385, 396, 403, 410, 418, 427, 432, 436, 442, 450, 458, 467, 474, 483, 491, 501, 513, 517, 523, 530, 538, 545, 552, 561, 568, 574, 581, 588, 595, 604, 616, 622, 630, 637, 646, }
+var SymbolIndex = [...]uint16{0, 3, 7, 11, 15, 19, 23, 27, 34, 41, 47, 52, 58, 65, 72, 77, 85, 92, 96, 101, 105, 113, 117, 123, 128, 132, 136, 146, 152, 161, 179, 187, 196, 203, 209, 214, 219, 226, 232, 236, 239, 242, 245, 248, 251, 254, 257, 260, 263, 266, 269, 272, 275, 278, 281, 284, 287, 290, 293, 296, 303, 312, 317, 324, 335, 342, 353, 360, 369, 376, 386, 397, 406, 417, 428, 439, 446, 453, 461, 470, 475, 479, 485, 493, 501, 510, 517, 526, 534, 544, 556, 560, 566, 573, 581, 588, 595, 604, 611, 617, 624, 631, 638, 647, 659, 665, 673, 680, 689, }
 
 
 func (s Symbol) String() string {
@@ -150,13 +157,13 @@ var errorSets = [...][]Symbol{
 {RugoTOK_in},
 {RugoTOK_next},
 {RugoTOK_00260026},
-{RugoHashLit, RugoArrayLit, RugoFnExpr, RugoParallelExpr, RugoSpawnExpr, RugoTryExpr, RugoPrimary, RugoPostfix, RugoUnaryExpr, RugoMulExpr, RugoAddExpr, RugoCompExpr, RugoAndExpr, RugoOrExpr, RugoExpr, RugoAssignOrExpr, RugoReturnStmt, RugoNextStmt, RugoBreakStmt, RugoForStmt, RugoWhileStmt, RugoCaseExpr, RugoIfStmt, RugoBenchDef, RugoTestDef, RugoFuncDef, RugoSandboxStmt, RugoEmbedStmt, RugoRequireStmt, RugoImportStmt, RugoUseStmt, RugoStatement, Rugostr_lit, Rugoraw_str_lit, Rugointeger, Rugoident, Rugofloat_lit, RugoTOK_007b, RugoTOK_005b, RugoTOK_003b, RugoTOK_002d, RugoTOK_0028, RugoTOK_0021, RugoTOK_while, RugoTOK_use, RugoTOK_try, RugoTOK_true, RugoTOK_spawn, RugoTOK_sandbox, RugoTOK_return, RugoTOK_require, RugoTOK_rats, RugoTOK_parallel, RugoTOK_nil, RugoTOK_next, RugoTOK_import, RugoTOK_if, RugoTOK_for, RugoTOK_fn, RugoTOK_false, RugoTOK_end, RugoTOK_embed, RugoTOK_def, RugoTOK_case, RugoTOK_break, RugoTOK_bench, RugoTOK_002d003e},
-{RugoHashLit, RugoArrayLit, RugoFnExpr, RugoParallelExpr, RugoSpawnExpr, RugoTryExpr, RugoPrimary, RugoPostfix, RugoUnaryExpr, RugoMulExpr, RugoAddExpr, RugoCompExpr, RugoAndExpr, RugoOrExpr, RugoExpr, RugoAssignOrExpr, RugoReturnStmt, RugoNextStmt, RugoBreakStmt, RugoForStmt, RugoWhileStmt, RugoCaseExpr, RugoIfStmt, RugoBenchDef, RugoTestDef, RugoFuncDef, RugoSandboxStmt, RugoEmbedStmt, RugoRequireStmt, RugoImportStmt, RugoUseStmt, RugoStatement, Rugostr_lit, Rugoraw_str_lit, Rugointeger, Rugoident, Rugofloat_lit, RugoTOK_007b, RugoTOK_005b, RugoTOK_003b, RugoTOK_002d, RugoTOK_0028, RugoTOK_0021, RugoTOK_while, RugoTOK_use, RugoTOK_try, RugoTOK_true, RugoTOK_spawn, RugoTOK_sandbox, RugoTOK_return, RugoTOK_require, RugoTOK_rats, RugoTOK_parallel, RugoTOK_of, RugoTOK_nil, RugoTOK_next, RugoTOK_import, RugoTOK_if, RugoTOK_for, RugoTOK_fn, RugoTOK_false, RugoTOK_end, RugoTOK_embed, RugoTOK_elsif, RugoTOK_else, RugoTOK_def, RugoTOK_case, RugoTOK_break, RugoTOK_bench, RugoTOK_002d003e},
-{RugoHashLit, RugoArrayLit, RugoFnExpr, RugoParallelExpr, RugoSpawnExpr, RugoTryExpr, RugoPrimary, RugoPostfix, RugoUnaryExpr, RugoMulExpr, RugoAddExpr, RugoCompExpr, RugoAndExpr, RugoOrExpr, RugoExpr, RugoAssignOrExpr, RugoReturnStmt, RugoNextStmt, RugoBreakStmt, RugoForStmt, RugoWhileStmt, RugoCaseExpr, RugoIfStmt, RugoBenchDef, RugoTestDef, RugoFuncDef, RugoSandboxStmt, RugoEmbedStmt, RugoRequireStmt, RugoImportStmt, RugoUseStmt, RugoStatement, Rugostr_lit, Rugoraw_str_lit, Rugointeger, Rugoident, Rugofloat_lit, RugoTOK_007b, RugoTOK_005b, RugoTOK_003b, RugoTOK_002d, RugoTOK_0028, RugoTOK_0021, RugoTOK_while, RugoTOK_use, RugoTOK_try, RugoTOK_true, RugoTOK_spawn, RugoTOK_sandbox, RugoTOK_return, RugoTOK_require, RugoTOK_rats, RugoTOK_parallel, RugoTOK_nil, RugoTOK_next, RugoTOK_import, RugoTOK_if, RugoTOK_for, RugoTOK_fn, RugoTOK_false, RugoTOK_end, RugoTOK_embed, RugoTOK_elsif, RugoTOK_else, RugoTOK_def, RugoTOK_case, RugoTOK_break, RugoTOK_bench, RugoTOK_002d003e},
+{RugoHashLit, RugoArrayLit, RugoFnExpr, RugoParallelExpr, RugoSpawnExpr, RugoTryExpr, RugoPrimary, RugoPostfix, RugoUnaryExpr, RugoMulExpr, RugoAddExpr, RugoCompExpr, RugoAndExpr, RugoOrExpr, RugoExpr, RugoAssignOrExpr, RugoReturnStmt, RugoNextStmt, RugoBreakStmt, RugoForStmt, RugoWhileStmt, RugoCaseExpr, RugoIfStmt, RugoBenchDef, RugoTestDef, RugoFuncDef, RugoSandboxStmt, RugoEmbedStmt, RugoRequireStmt, RugoImportStmt, RugoUseStmt, RugoStatement, Rugostr_lit, Rugoraw_str_lit, Rugointeger, Rugoident, Rugofloat_lit, RugoTOK_007b, RugoTOK_005b, RugoTOK_003b, RugoTOK_002d, RugoTOK_0028, RugoTOK_0021, RugoTOK_while, RugoTOK_use, RugoTOK_try, RugoTOK_true, RugoTOK_spawn, RugoTOK_sandbox, RugoTOK_return, RugoTOK_require_optional, RugoTOK_require, RugoTOK_rats, RugoTOK_parallel, RugoTOK_nil, RugoTOK_next, RugoTOK_import, RugoTOK_if, RugoTOK_for, RugoTOK_fn, RugoTOK_false, RugoTOK_end, RugoTOK_embed, RugoTOK_def, RugoTOK_case, RugoTOK_break, RugoTOK_bench, RugoTOK_002d003e},
+{RugoHashLit, RugoArrayLit, RugoFnExpr, RugoParallelExpr, RugoSpawnExpr, RugoTryExpr, RugoPrimary, RugoPostfix, RugoUnaryExpr, RugoMulExpr, RugoAddExpr, RugoCompExpr, RugoAndExpr, RugoOrExpr, RugoExpr, RugoAssignOrExpr, RugoReturnStmt, RugoNextStmt, RugoBreakStmt, RugoForStmt, RugoWhileStmt, RugoCaseExpr, RugoIfStmt, RugoBenchDef, RugoTestDef, RugoFuncDef, RugoSandboxStmt, RugoEmbedStmt, RugoRequireStmt, RugoImportStmt, RugoUseStmt, RugoStatement, Rugostr_lit, Rugoraw_str_lit, Rugointeger, Rugoident, Rugofloat_lit, RugoTOK_007b, RugoTOK_005b, RugoTOK_003b, RugoTOK_002d, RugoTOK_0028, RugoTOK_0021, RugoTOK_while, RugoTOK_use, RugoTOK_try, RugoTOK_true, RugoTOK_spawn, RugoTOK_sandbox, RugoTOK_return, RugoTOK_require_optional, RugoTOK_require, RugoTOK_rats, RugoTOK_parallel, RugoTOK_of, RugoTOK_nil, RugoTOK_next, RugoTOK_import, RugoTOK_if, RugoTOK_for, RugoTOK_fn, RugoTOK_false, RugoTOK_end, RugoTOK_embed, RugoTOK_elsif, RugoTOK_else, RugoTOK_def, RugoTOK_case, RugoTOK_break, RugoTOK_bench, RugoTOK_002d003e},
+{RugoHashLit, RugoArrayLit, RugoFnExpr, RugoParallelExpr, RugoSpawnExpr, RugoTryExpr, RugoPrimary, RugoPostfix, RugoUnaryExpr, RugoMulExpr, RugoAddExpr, RugoCompExpr, RugoAndExpr, RugoOrExpr, RugoExpr, RugoAssignOrExpr, RugoReturnStmt, RugoNextStmt, RugoBreakStmt, RugoForStmt, RugoWhileStmt, RugoCaseExpr, RugoIfStmt, RugoBenchDef, RugoTestDef, RugoFuncDef, RugoSandboxStmt, RugoEmbedStmt, RugoRequireStmt, RugoImportStmt, RugoUseStmt, RugoStatement, Rugostr_lit, Rugoraw_str_lit, Rugointeger, Rugoident, Rugofloat_lit, RugoTOK_007b, RugoTOK_005b, RugoTOK_003b, RugoTOK_002d, RugoTOK_0028, RugoTOK_0021, RugoTOK_while, RugoTOK_use, RugoTOK_try, RugoTOK_true, RugoTOK_spawn, RugoTOK_sandbox, RugoTOK_return, RugoTOK_require_optional, RugoTOK_require, RugoTOK_rats, RugoTOK_parallel, RugoTOK_nil, RugoTOK_next, RugoTOK_import, RugoTOK_if, RugoTOK_for, RugoTOK_fn, RugoTOK_false, RugoTOK_end, RugoTOK_embed, RugoTOK_elsif, RugoTOK_else, RugoTOK_def, RugoTOK_case, RugoTOK_break, RugoTOK_bench, RugoTOK_002d003e},
 {RugoTOK_or},
 {RugoTOK_parallel},
 {RugoTOK_rats},
-{RugoTOK_require},
+{RugoTOK_require_optional, RugoTOK_require},
 {RugoTOK_return},
 {RugoTOK_sandbox},
 {RugoTOK_spawn},
@@ -164,14 +171,14 @@ var errorSets = [...][]Symbol{
 {RugoTOK_use},
 {RugoTOK_while},
 {RugoTOK_007c007c},
-{RugoTOK_002f, RugoTOK_002a, RugoTOK_0025},
+{RugoTOK_002f, RugoTOK_002a, RugoTOK_0026, RugoTOK_0025, RugoTOK_003e003e, RugoTOK_003c003c},
 {RugoTOK_005b, RugoTOK_002e, RugoTOK_0028},
 {RugoTOK_0028},
 {RugoParam, Rugoident, RugoTOK_0029},
 {RugoTOK_0029},
 {RugoTOK_003d003e},
 {RugoTOK_with, RugoTOK_as},
-{RugoTOK_002d, RugoTOK_002b},
+{RugoTOK_007c, RugoTOK_005e, RugoTOK_002d, RugoTOK_002b},
 {RugoTOK_005d, RugoTOK_002c},
 {RugoTOK_007d, RugoTOK_002c},
 {RugoTOK_002c},
@@ -181,12 +188,12 @@ var errorSets = [...][]Symbol{
 {RugoTOK_005b},
 {RugoTOK_005d},
 {RugoTOK_as},
-{RugoHashLit, RugoArrayLit, RugoFnExpr, RugoParallelExpr, RugoSpawnExpr, RugoTryExpr, RugoPrimary, RugoPostfix, RugoUnaryExpr, RugoMulExpr, RugoAddExpr, RugoCompExpr, RugoAndExpr, RugoOrExpr, RugoExpr, RugoAssignOrExpr, RugoReturnStmt, RugoNextStmt, RugoBreakStmt, RugoForStmt, RugoWhileStmt, RugoCaseExpr, RugoIfStmt, RugoBenchDef, RugoTestDef, RugoFuncDef, RugoSandboxStmt, RugoEmbedStmt, RugoRequireStmt, RugoImportStmt, RugoUseStmt, RugoStatement, Rugostr_lit, Rugoraw_str_lit, Rugointeger, Rugoident, Rugofloat_lit, RugoTOK_007b, RugoTOK_005b, RugoTOK_003b, RugoTOK_002d, RugoTOK_0028, RugoTOK_0021, RugoTOK_while, RugoTOK_use, RugoTOK_try, RugoTOK_true, RugoTOK_spawn, RugoTOK_sandbox, RugoTOK_return, RugoTOK_require, RugoTOK_rats, RugoTOK_parallel, RugoTOK_nil, RugoTOK_next, RugoTOK_import, RugoTOK_if, RugoTOK_for, RugoTOK_fn, RugoTOK_false, RugoTOK_end, RugoTOK_embed, RugoTOK_def, RugoTOK_case, RugoTOK_break, RugoTOK_bench},
-{RugoHashLit, RugoArrayLit, RugoFnExpr, RugoParallelExpr, RugoSpawnExpr, RugoTryExpr, RugoPrimary, RugoPostfix, RugoUnaryExpr, RugoMulExpr, RugoAddExpr, RugoCompExpr, RugoAndExpr, RugoOrExpr, RugoExpr, RugoAssignOrExpr, RugoReturnStmt, RugoNextStmt, RugoBreakStmt, RugoForStmt, RugoWhileStmt, RugoCaseExpr, RugoIfStmt, RugoBenchDef, RugoTestDef, RugoFuncDef, RugoSandboxStmt, RugoEmbedStmt, RugoRequireStmt, RugoImportStmt, RugoUseStmt, Rugostr_lit, Rugoraw_str_lit, Rugointeger, Rugoident, Rugofloat_lit, RugoTOK_007b, RugoTOK_005b, RugoTOK_003b, RugoTOK_002d, RugoTOK_0028, RugoTOK_0021, RugoTOK_while, RugoTOK_use, RugoTOK_try, RugoTOK_true, RugoTOK_spawn, RugoTOK_sandbox, RugoTOK_return, RugoTOK_require, RugoTOK_rats, RugoTOK_parallel, RugoTOK_nil, RugoTOK_next, RugoTOK_import, RugoTOK_if, RugoTOK_for, RugoTOK_fn, RugoTOK_false, RugoTOK_embed, RugoTOK_def, RugoTOK_case, RugoTOK_break, RugoTOK_bench},
-{RugoHashLit, RugoArrayLit, RugoFnExpr, RugoParallelExpr, RugoSpawnExpr, RugoTryExpr, RugoPrimary, RugoPostfix, RugoUnaryExpr, RugoMulExpr, RugoAddExpr, RugoCompExpr, RugoAndExpr, RugoOrExpr, RugoExpr, RugoCaseExpr, Rugostr_lit, Rugoraw_str_lit, Rugointeger, Rugoident, Rugofloat_lit, RugoTOK_007b, RugoTOK_005b, RugoTOK_002d, RugoTOK_0028, RugoTOK_0021, RugoTOK_while, RugoTOK_use, RugoTOK_try, RugoTOK_true, RugoTOK_spawn, RugoTOK_sandbox, RugoTOK_return, RugoTOK_require, RugoTOK_rats, RugoTOK_parallel, RugoTOK_nil, RugoTOK_next, RugoTOK_import, RugoTOK_if, RugoTOK_for, RugoTOK_fn, RugoTOK_false, RugoTOK_embed, RugoTOK_def, RugoTOK_case, RugoTOK_break, RugoTOK_bench},
-{RugoHashLit, RugoArrayLit, RugoFnExpr, RugoParallelExpr, RugoSpawnExpr, RugoTryExpr, RugoPrimary, RugoPostfix, RugoUnaryExpr, RugoMulExpr, RugoAddExpr, RugoCompExpr, RugoAndExpr, RugoOrExpr, RugoExpr, RugoAssignOrExpr, RugoReturnStmt, RugoNextStmt, RugoBreakStmt, RugoForStmt, RugoWhileStmt, RugoCaseExpr, RugoIfStmt, RugoBenchDef, RugoTestDef, RugoFuncDef, RugoSandboxStmt, RugoEmbedStmt, RugoRequireStmt, RugoImportStmt, RugoUseStmt, RugoStatement, Rugostr_lit, Rugoraw_str_lit, Rugointeger, Rugoident, Rugofloat_lit, RugoTOK_007b, RugoTOK_005b, RugoTOK_003b, RugoTOK_002d, RugoTOK_0028, RugoTOK_0021, RugoTOK_while, RugoTOK_use, RugoTOK_try, RugoTOK_true, RugoTOK_spawn, RugoTOK_sandbox, RugoTOK_return, RugoTOK_require, RugoTOK_rats, RugoTOK_parallel, RugoTOK_nil, RugoTOK_next, RugoTOK_import, RugoTOK_if, RugoTOK_for, RugoTOK_fn, RugoTOK_false, RugoTOK_end, RugoTOK_embed, RugoTOK_elsif, RugoTOK_else, RugoTOK_def, RugoTOK_case, RugoTOK_break, RugoTOK_bench},
+{RugoHashLit, RugoArrayLit, RugoFnExpr, RugoParallelExpr, RugoSpawnExpr, RugoTryExpr, RugoPrimary, RugoPostfix, RugoUnaryExpr, RugoMulExpr, RugoAddExpr, RugoCompExpr, RugoAndExpr, RugoOrExpr, RugoExpr, RugoAssignOrExpr, RugoReturnStmt, RugoNextStmt, RugoBreakStmt, RugoForStmt, RugoWhileStmt, RugoCaseExpr, RugoIfStmt, RugoBenchDef, RugoTestDef, RugoFuncDef, RugoSandboxStmt, RugoEmbedStmt, RugoRequireStmt, RugoImportStmt, RugoUseStmt, RugoStatement, Rugostr_lit, Rugoraw_str_lit, Rugointeger, Rugoident, Rugofloat_lit, RugoTOK_007b, RugoTOK_005b, RugoTOK_003b, RugoTOK_002d, RugoTOK_0028, RugoTOK_0021, RugoTOK_while, RugoTOK_use, RugoTOK_try, RugoTOK_true, RugoTOK_spawn, RugoTOK_sandbox, RugoTOK_return, RugoTOK_require_optional, RugoTOK_require, RugoTOK_rats, RugoTOK_parallel, RugoTOK_nil, RugoTOK_next, RugoTOK_import, RugoTOK_if, RugoTOK_for, RugoTOK_fn, RugoTOK_false, RugoTOK_end, RugoTOK_embed, RugoTOK_def, RugoTOK_case, RugoTOK_break, RugoTOK_bench},
+{RugoHashLit, RugoArrayLit, RugoFnExpr, RugoParallelExpr, RugoSpawnExpr, RugoTryExpr, RugoPrimary, RugoPostfix, RugoUnaryExpr, RugoMulExpr, RugoAddExpr, RugoCompExpr, RugoAndExpr, RugoOrExpr, RugoExpr, RugoAssignOrExpr, RugoReturnStmt, RugoNextStmt, RugoBreakStmt, RugoForStmt, RugoWhileStmt, RugoCaseExpr, RugoIfStmt, RugoBenchDef, RugoTestDef, RugoFuncDef, RugoSandboxStmt, RugoEmbedStmt, RugoRequireStmt, RugoImportStmt, RugoUseStmt, Rugostr_lit, Rugoraw_str_lit, Rugointeger, Rugoident, Rugofloat_lit, RugoTOK_007b, RugoTOK_005b, RugoTOK_003b, RugoTOK_002d, RugoTOK_0028, RugoTOK_0021, RugoTOK_while, RugoTOK_use, RugoTOK_try, RugoTOK_true, RugoTOK_spawn, RugoTOK_sandbox, RugoTOK_return, RugoTOK_require_optional, RugoTOK_require, RugoTOK_rats, RugoTOK_parallel, RugoTOK_nil, RugoTOK_next, RugoTOK_import, RugoTOK_if, RugoTOK_for, RugoTOK_fn, RugoTOK_false, RugoTOK_embed, RugoTOK_def, RugoTOK_case, RugoTOK_break, RugoTOK_bench},
+{RugoHashLit, RugoArrayLit, RugoFnExpr, RugoParallelExpr, RugoSpawnExpr, RugoTryExpr, RugoPrimary, RugoPostfix, RugoUnaryExpr, RugoMulExpr, RugoAddExpr, RugoCompExpr, RugoAndExpr, RugoOrExpr, RugoExpr, RugoCaseExpr, Rugostr_lit, Rugoraw_str_lit, Rugointeger, Rugoident, Rugofloat_lit, RugoTOK_007b, RugoTOK_005b, RugoTOK_002d, RugoTOK_0028, RugoTOK_0021, RugoTOK_while, RugoTOK_use, RugoTOK_try, RugoTOK_true, RugoTOK_spawn, RugoTOK_sandbox, RugoTOK_return, RugoTOK_require_optional, RugoTOK_require, RugoTOK_rats, RugoTOK_parallel, RugoTOK_nil, RugoTOK_next, RugoTOK_import, RugoTOK_if, RugoTOK_for, RugoTOK_fn, RugoTOK_false, RugoTOK_embed, RugoTOK_def, RugoTOK_case, RugoTOK_break, RugoTOK_bench},
+{RugoHashLit, RugoArrayLit, RugoFnExpr, RugoParallelExpr, RugoSpawnExpr, RugoTryExpr, RugoPrimary, RugoPostfix, RugoUnaryExpr, RugoMulExpr, RugoAddExpr, RugoCompExpr, RugoAndExpr, RugoOrExpr, RugoExpr, RugoAssignOrExpr, RugoReturnStmt, RugoNextStmt, RugoBreakStmt, RugoForStmt, RugoWhileStmt, RugoCaseExpr, RugoIfStmt, RugoBenchDef, RugoTestDef, RugoFuncDef, RugoSandboxStmt, RugoEmbedStmt, RugoRequireStmt, RugoImportStmt, RugoUseStmt, RugoStatement, Rugostr_lit, Rugoraw_str_lit, Rugointeger, Rugoident, Rugofloat_lit, RugoTOK_007b, RugoTOK_005b, RugoTOK_003b, RugoTOK_002d, RugoTOK_0028, RugoTOK_0021, RugoTOK_while, RugoTOK_use, RugoTOK_try, RugoTOK_true, RugoTOK_spawn, RugoTOK_sandbox, RugoTOK_return, RugoTOK_require_optional, RugoTOK_require, RugoTOK_rats, RugoTOK_parallel, RugoTOK_nil, RugoTOK_next, RugoTOK_import, RugoTOK_if, RugoTOK_for, RugoTOK_fn, RugoTOK_false, RugoTOK_end, RugoTOK_embed, RugoTOK_elsif, RugoTOK_else, RugoTOK_def, RugoTOK_case, RugoTOK_break, RugoTOK_bench},
 {RugoTOK_007b},
-{Rugocomp_op},
+{Rugocomp_op, RugoTOK_in},
 {Rugostr_lit, Rugoident},
 {Rugoident},
 {Rugostr_lit, Rugointeger},
@@ -212,6 +219,8 @@ var errorSets = [...][]Symbol{
 {RugoTOK_def},
 {RugoTOK_of, RugoTOK_end, RugoTOK_elsif, RugoTOK_else},
 {RugoTOK_end, RugoTOK_elsif, RugoTOK_else},
+{RugoHashLit, RugoArrayLit, RugoFnExpr, RugoParallelExpr, RugoSpawnExpr, RugoTryExpr, RugoPrimary, RugoPostfix, RugoUnaryExpr, RugoMulExpr, RugoAddExpr, RugoCompExpr, RugoAndExpr, RugoOrExpr, RugoExpr, RugoAssignOrExpr, RugoReturnStmt, RugoNextStmt, RugoBreakStmt, RugoForStmt, RugoWhileStmt, RugoCaseExpr, RugoIfStmt, RugoBenchDef, RugoTestDef, RugoFuncDef, RugoSandboxStmt, RugoEmbedStmt, RugoRequireStmt, RugoImportStmt, RugoUseStmt, RugoStatement, Rugostr_lit, Rugoraw_str_lit, Rugointeger, Rugoident, Rugofloat_lit, RugoTOK_007b, RugoTOK_005b, RugoTOK_003b, RugoTOK_002d, RugoTOK_0028, RugoTOK_0021, RugoTOK_while, RugoTOK_use, RugoTOK_try, RugoTOK_true, RugoTOK_spawn, RugoTOK_sandbox, RugoTOK_return, RugoTOK_require_optional, RugoTOK_require, RugoTOK_rats, RugoTOK_parallel, RugoTOK_nil, RugoTOK_next, RugoTOK_import, RugoTOK_if, RugoTOK_for, RugoTOK_fn, RugoTOK_false, RugoTOK_ensure, RugoTOK_end, RugoTOK_embed, RugoTOK_def, RugoTOK_case, RugoTOK_break, RugoTOK_bench},
+{RugoTOK_ensure, RugoTOK_end},
 }
 
 
@@ -235,62 +244,69 @@ type Symbol int32
 //	id   0: $
 //	id   1: &&
 //	id   2: ->
-//	id   3: =>
-//	id   4: as
-//	id   5: bench
-//	id   6: break
-//	id   7: case
-//	id   8: def
-//	id   9: else
-//	id  10: elsif
-//	id  11: embed
-//	id  12: end
-//	id  13: false
-//	id  14: fn
-//	id  15: for
-//	id  16: if
-//	id  17: import
-//	id  18: in
-//	id  19: next
-//	id  20: nil
-//	id  21: of
-//	id  22: or
-//	id  23: parallel
-//	id  24: rats
-//	id  25: require
-//	id  26: return
-//	id  27: sandbox
-//	id  28: spawn
-//	id  29: true
-//	id  30: try
-//	id  31: use
-//	id  32: while
-//	id  33: with
-//	id  34: \|\|
-//	id  35: !
-//	id  36: %
-//	id  37: \(
-//	id  38: \)
-//	id  39: \*
-//	id  40: \+
-//	id  41: ,
-//	id  42: -
-//	id  43: \.
-//	id  44: /
-//	id  45: :
-//	id  46: ;
-//	id  47: =
-//	id  48: \[
-//	id  49: \]
-//	id  50: \{
-//	id  51: \}
-//	id  52: ((==)|(!=)|(<=)|(>=)|(<)|(>))
-//	id  53: ([0-9]+\.[0-9]+)
-//	id  54: ([a-zA-Z_][a-zA-Z0-9_]*)
-//	id  55: ([0-9]+)
-//	id  56: (')((([^'\\])|(\\)([^\n])))*(')
-//	id  57: (")((([^"\\])|(\\)([^\n])))*(")
-//	id  58: ( |\t|\n|\r)
+//	id   3: <<
+//	id   4: =>
+//	id   5: >>
+//	id   6: as
+//	id   7: bench
+//	id   8: break
+//	id   9: case
+//	id  10: def
+//	id  11: else
+//	id  12: elsif
+//	id  13: embed
+//	id  14: end
+//	id  15: ensure
+//	id  16: false
+//	id  17: fn
+//	id  18: for
+//	id  19: if
+//	id  20: import
+//	id  21: in
+//	id  22: next
+//	id  23: nil
+//	id  24: of
+//	id  25: or
+//	id  26: parallel
+//	id  27: rats
+//	id  28: require
+//	id  29: require_optional
+//	id  30: return
+//	id  31: sandbox
+//	id  32: spawn
+//	id  33: true
+//	id  34: try
+//	id  35: use
+//	id  36: while
+//	id  37: with
+//	id  38: \|\|
+//	id  39: !
+//	id  40: %
+//	id  41: &
+//	id  42: \(
+//	id  43: \)
+//	id  44: \*
+//	id  45: \+
+//	id  46: ,
+//	id  47: -
+//	id  48: \.
+//	id  49: /
+//	id  50: :
+//	id  51: ;
+//	id  52: =
+//	id  53: \[
+//	id  54: \]
+//	id  55: \^
+//	id  56: \{
+//	id  57: \|
+//	id  58: \}
+//	id  59: ((==)|(!=)|(<=>)|(<=)|(>=)|(<)|(>))
+//	id  60: ([0-9]+\.[0-9]+)
+//	id  61: ([a-zA-Z_][a-zA-Z0-9_]*)
+//	id  62: ([0-9]+)
+//	id  63: (')((([^'\\])|(\\)([^\n])))*(')
+//	id  64: (")((([^"\\])|(\\)([^\n])))*(")
+//	id  65: ( |\t|\n|\r)
 //
 // ID == -1 is returned when no lexeme was recognized.
 func (*Parser) scan(s []byte) (id, length int) {
@@ -336,6 +352,7 @@ _ = accept2
 	if accept('>') { goto l217 }
 	if accept('[') { goto l231 }
 	if accept(']') { goto l233 }
+	if accept('^') { goto l900 }
 	if accept('a') { goto l235 }
 	if accept('b') { goto l245 }
 	if accept('c') { goto l297 }
@@ -372,23 +389,23 @@ l86out:
 	if r == endOfText { goto l838 }
 	return id, length
 l91:
-	id, length = 58, pos
+	id, length = 65, pos
 	return id, length
 l93:
-	id, length = 58, pos
+	id, length = 65, pos
 	return id, length
 l95:
-	id, length = 58, pos
+	id, length = 65, pos
 	return id, length
 l97:
-	id, length = 58, pos
+	id, length = 65, pos
 	return id, length
 l99:
-	id, length = 35, pos
+	id, length = 39, pos
 	if accept('=') { goto l103 }
 	return id, length
 l103:
-	id, length = 52, pos
+	id, length = 59, pos
 	return id, length
 l105:
 	if accept('"') { goto l112 }
@@ -401,7 +418,7 @@ l105:
 l109out:
 	return id, length
 l112:
-	id, length = 57, pos
+	id, length = 64, pos
 	return id, length
 l114:
 	if accept2('\t') { goto l117 }
@@ -430,9 +447,10 @@ l124:
 l128out:
 	return id, length
 l131:
-	id, length = 36, pos
+	id, length = 40, pos
 	return id, length
 l133:
+	id, length = 41, pos
 	if accept('&') { goto l136 }
 	return id, length
 l136:
@@ -449,7 +467,7 @@ l138:
 l142out:
 	return id, length
 l145:
-	id, length = 56, pos
+	id, length = 63, pos
 	return id, length
 l147:
 	if accept2('\t') { goto l150 }
@@ -478,35 +496,35 @@ l157:
 l161out:
 	return id, length
 l164:
-	id, length = 37, pos
+	id, length = 42, pos
 	return id, length
 l166:
-	id, length = 38, pos
+	id, length = 43, pos
 	return id, length
 l168:
-	id, length = 39, pos
+	id, length = 44, pos
 	return id, length
 l170:
-	id, length = 40, pos
+	id, length = 45, pos
 	return id, length
 l172:
-	id, length = 41, pos
+	id, length = 46, pos
 	return id, length
 l174:
-	id, length = 42, pos
+	id, length = 47, pos
 	if accept('>') { goto l178 }
 	return id, length
 l178:
 	id, length = 2, pos
 	return id, length
 l180:
-	id, length = 43, pos
+	id, length = 48, pos
 	return id, length
 l182:
-	id, length = 44, pos
+	id, length = 49, pos
 	return id, length
 l184:
-	id, length = 55, pos
+	id, length = 62, pos
 	if accept('.') { goto l190 }
 	if r < '0' { goto l187out }
 	if accept2('9') { goto l184 }
@@ -518,44 +536,47 @@ l190:
 l190out:
 	return id, length
 l193:
-	id, length = 53, pos
+	id, length = 60, pos
 	if r < '0' { goto l194out }
 	if accept2('9') { goto l193 }
 l194out:
 	return id, length
 l197:
-	id, length = 45, pos
+	id, length = 50, pos
 	return id, length
 l199:
-	id, length = 46, pos
+	id, length = 51, pos
 	return id, length
 l201:
-	id, length = 52, pos
+	id, length = 59, pos
+	if accept('<') { goto l896 }
 	if accept('=') { goto l205 }
 	return id, length
 l205:
-	id, length = 52, pos
+	id, length = 59, pos
+	if accept('>') { goto l894 }
 	return id, length
 l207:
-	id, length = 47, pos
+	id, length = 52, pos
 	if accept('=') { goto l213 }
 	if accept('>') { goto l215 }
 	return id, length
 l213:
-	id, length = 52, pos
+	id, length = 59, pos
 	return id, length
 l215:
-	id, length = 3, pos
+	id, length = 4, pos
 	return id, length
 l217:
-	id, length = 52, pos
+	id, length = 59, pos
 	if accept('=') { goto l221 }
+	if accept('>') { goto l898 }
 	return id, length
 l221:
-	id, length = 52, pos
+	id, length = 59, pos
 	return id, length
 l223:
-	id, length = 54, pos
+	id, length = 61, pos
 	if r < '0' { goto l224out }
 	if accept2('9') { goto l227 }
 	if r < 'A' { goto l224out }
@@ -566,7 +587,7 @@ l223:
 l224out:
 	return id, length
 l227:
-	id, length = 54, pos
+	id, length = 61, pos
 	if r < '0' { goto l228out }
 	if accept2('9') { goto l227 }
 	if r < 'A' { goto l228out }
@@ -577,13 +598,13 @@ l227:
 l228out:
 	return id, length
 l231:
-	id, length = 48, pos
+	id, length = 53, pos
 	return id, length
 l233:
-	id, length = 49, pos
+	id, length = 54, pos
 	return id, length
 l235:
-	id, length = 54, pos
+	id, length = 61, pos
 	if accept('s') { goto l241 }
 	if r < '0' { goto l238out }
 	if accept2('9') { goto l227 }
@@ -597,7 +618,7 @@ l235:
 l238out:
 	return id, length
 l241:
-	id, length = 4, pos
+	id, length = 6, pos
 	if r < '0' { goto l242out }
 	if accept2('9') { goto l227 }
 	if r < 'A' { goto l242out }
@@ -608,7 +629,7 @@ l241:
 l242out:
 	return id, length
 l245:
-	id, length = 54, pos
+	id, length = 61, pos
 	if accept('e') { goto l253 }
 	if accept('r') { goto l275 }
 	if r < '0' { goto l250out }
@@ -625,7 +646,7 @@ l245:
 l250out:
 	return id, length
 l253:
-	id, length = 54, pos
+	id, length = 61, pos
 	if accept('n') { goto l259 }
 	if r < '0' { goto l256out }
 	if accept2('9') { goto l227 }
@@ -639,7 +660,7 @@ l253:
 l256out:
 	return id, length
 l259:
-	id, length = 54, pos
+	id, length = 61, pos
 	if accept('c') { goto l265 }
 	if r < '0' { goto l262out }
 	if accept2('9') { goto l227 }
@@ -653,7 +674,7 @@ l259:
 l262out:
 	return id, length
 l265:
-	id, length = 54, pos
+	id, length = 61, pos
 	if accept('h') { goto l271 }
 	if r < '0' { goto l268out }
 	if accept2('9') { goto l227 }
@@ -667,7 +688,7 @@ l265:
 l268out:
 	return id, length
 l271:
-	id, length = 5, pos
+	id, length = 7, pos
 	if r < '0' { goto l272out }
 	if accept2('9') { goto l227 }
 	if r < 'A' { goto l272out }
@@ -678,7 +699,7 @@ l271:
 l272out:
 	return id, length
 l275:
-	id, length = 54, pos
+	id, length = 61, pos
 	if accept('e') { goto l281 }
 	if r < '0' { goto l278out }
 	if accept2('9') { goto l227 }
@@ -692,7 +713,7 @@ l275:
 l278out:
 	return id, length
 l281:
-	id, length = 54, pos
+	id, length = 61, pos
 	if accept('a') { goto l287 }
 	if r < '0' { goto l284out }
 	if accept2('9') { goto l227 }
@@ -704,7 +725,7 @@ l281:
 l284out:
 	return id, length
 l287:
-	id, length = 54, pos
+	id, length = 61, pos
 	if accept('k') { goto l293 }
 	if r < '0' { goto l290out }
 	if accept2('9') { goto l227 }
@@ -718,7 +739,7 @@ l287:
 l290out:
 	return id, length
 l293:
-	id, length = 6, pos
+	id, length = 8, pos
 	if r < '0' { goto l294out }
 	if accept2('9') { goto l227 }
 	if r < 'A' { goto l294out }
@@ -729,7 +750,7 @@ l293:
 l294out:
 	return id, length
 l297:
-	id, length = 54, pos
+	id, length = 61, pos
 	if accept('a') { goto l303 }
 	if r < '0' { goto l300out }
 	if accept2('9') { goto l227 }
@@ -741,7 +762,7 @@ l297:
 l300out:
 	return id, length
 l303:
-	id, length = 54, pos
+	id, length = 61, pos
 	if accept('s') { goto l309 }
 	if r < '0' { goto l306out }
 	if accept2('9') { goto l227 }
@@ -755,7 +776,7 @@ l303:
 l306out:
 	return id, length
 l309:
-	id, length = 54, pos
+	id, length = 61, pos
 	if accept('e') { goto l315 }
 	if r < '0' { goto l312out }
 	if accept2('9') { goto l227 }
@@ -769,7 +790,7 @@ l309:
 l312out:
 	return id, length
 l315:
-	id, length = 7, pos
+	id, length = 9, pos
 	if r < '0' { goto l316out }
 	if accept2('9') { goto l227 }
 	if r < 'A' { goto l316out }
@@ -780,7 +801,7 @@ l315:
 l316out:
 	return id, length
 l319:
-	id, length = 54, pos
+	id, length = 61, pos
 	if accept('e') { goto l325 }
 	if r < '0' { goto l322out }
 	if accept2('9') { goto l227 }
@@ -794,7 +815,7 @@ l319:
 l322out:
 	return id, length
 l325:
-	id, length = 54, pos
+	id, length = 61, pos
 	if accept('f') { goto l331 }
 	if r < '0' { goto l328out }
 	if accept2('9') { goto l227 }
@@ -808,7 +829,7 @@ l325:
 l328out:
 	return id, length
 l331:
-	id, length = 8, pos
+	id, length = 10, pos
 	if r < '0' { goto l332out }
 	if accept2('9') { goto l227 }
 	if r < 'A' { goto l332out }
@@ -819,7 +840,7 @@ l331:
 l332out:
 	return id, length
 l335:
-	id, length = 54, pos
+	id, length = 61, pos
 	if accept('l') { goto l345 }
 	if accept('m') { goto l373 }
 	if accept('n') { goto l395 }
@@ -835,7 +856,7 @@ l335:
 l342out:
 	return id, length
 l345:
-	id, length = 54, pos
+	id, length = 61, pos
 	if accept('s') { goto l351 }
 	if r < '0' { goto l348out }
 	if accept2('9') { goto l227 }
@@ -849,7 +870,7 @@ l345:
 l348out:
 	return id, length
 l351:
-	id, length = 54, pos
+	id, length = 61, pos
 	if accept('e') { goto l359 }
 	if accept('i') { goto l363 }
 	if r < '0' { goto l356out }
@@ -866,7 +887,7 @@ l351:
 l356out:
 	return id, length
 l359:
-	id, length = 9, pos
+	id, length = 11, pos
 	if r < '0' { goto l360out }
 	if accept2('9') { goto l227 }
 	if r < 'A' { goto l360out }
@@ -877,7 +898,7 @@ l359:
 l360out:
 	return id, length
 l363:
-	id, length = 54, pos
+	id, length = 61, pos
 	if accept('f') { goto l369 }
 	if r < '0' { goto l366out }
 	if accept2('9') { goto l227 }
@@ -891,7 +912,7 @@ l363:
 l366out:
 	return id, length
 l369:
-	id, length = 10, pos
+	id, length = 12, pos
 	if r < '0' { goto l370out }
 	if accept2('9') { goto l227 }
 	if r < 'A' { goto l370out }
@@ -902,7 +923,7 @@ l369:
 l370out:
 	return id, length
 l373:
-	id, length = 54, pos
+	id, length = 61, pos
 	if accept('b') { goto l379 }
 	if r < '0' { goto l376out }
 	if accept2('9') { goto l227 }
@@ -915,7 +936,7 @@ l373:
 l376out:
 	return id, length
 l379:
-	id, length = 54, pos
+	id, length = 61, pos
 	if accept('e') { goto l385 }
 	if r < '0' { goto l382out }
 	if accept2('9') { goto l227 }
@@ -929,7 +950,7 @@ l379:
 l382out:
 	return id, length
 l385:
-	id, length = 54, pos
+	id, length = 61, pos
 	if accept('d') { goto l391 }
 	if r < '0' { goto l388out }
 	if accept2('9') { goto l227 }
@@ -943,7 +964,7 @@ l385:
 l388out:
 	return id, length
 l391:
-	id, length = 11, pos
+	id, length = 13, pos
 	if r < '0' { goto l392out }
 	if accept2('9') { goto l227 }
 	if r < 'A' { goto l392out }
@@ -954,21 +975,24 @@ l391:
 l392out:
 	return id, length
 l395:
-	id, length = 54, pos
+	id, length = 61, pos
 	if accept('d') { goto l401 }
-	if r < '0' { goto l398out }
+	if accept('s') { goto l902 }
+	if r < '0' { goto l400out }
 	if accept2('9') { goto l227 }
-	if r < 'A' { goto l398out }
+	if r < 'A' { goto l400out }
 	if accept2('Z') { goto l227 }
 	if accept('_') { goto l227 }
-	if r < 'a' { goto l398out }
+	if r < 'a' { goto l400out }
 	if accept2('c') { goto l227 }
-	if r < 'e' { goto l398out }
+	if r < 'e' { goto l400out }
+	if accept2('r') { goto l227 }
+	if r < 't' { goto l400out }
 	if accept2('z') { goto l227 }
-l398out:
+l400out:
 	return id, length
 l401:
-	id, length = 12, pos
+	id, length = 14, pos
 	if r < '0' { goto l402out }
 	if accept2('9') { goto l227 }
 	if r < 'A' { goto l402out }
@@ -979,7 +1003,7 @@ l401:
 l402out:
 	return id, length
 l405:
-	id, length = 54, pos
+	id, length = 61, pos
 	if accept('a') { goto l415 }
 	if accept('n') { goto l437 }
 	if accept('o') { goto l441 }
@@ -995,7 +1019,7 @@ l405:
 l412out:
 	return id, length
 l415:
-	id, length = 54, pos
+	id, length = 61, pos
 	if accept('l') { goto l421 }
 	if r < '0' { goto l418out }
 	if accept2('9') { goto l227 }
@@ -1009,7 +1033,7 @@ l415:
 l418out:
 	return id, length
 l421:
-	id, length = 54, pos
+	id, length = 61, pos
 	if accept('s') { goto l427 }
 	if r < '0' { goto l424out }
 	if accept2('9') { goto l227 }
@@ -1023,7 +1047,7 @@ l421:
 l424out:
 	return id, length
 l427:
-	id, length = 54, pos
+	id, length = 61, pos
 	if accept('e') { goto l433 }
 	if r < '0' { goto l430out }
 	if accept2('9') { goto l227 }
@@ -1037,7 +1061,7 @@ l427:
 l430out:
 	return id, length
 l433:
-	id, length = 13, pos
+	id, length = 16, pos
 	if r < '0' { goto l434out }
 	if accept2('9') { goto l227 }
 	if r < 'A' { goto l434out }
@@ -1048,7 +1072,7 @@ l433:
 l434out:
 	return id, length
 l437:
-	id, length = 14, pos
+	id, length = 17, pos
 	if r < '0' { goto l438out }
 	if accept2('9') { goto l227 }
 	if r < 'A' { goto l438out }
@@ -1059,7 +1083,7 @@ l437:
 l438out:
 	return id, length
 l441:
-	id, length = 54, pos
+	id, length = 61, pos
 	if accept('r') { goto l447 }
 	if r < '0' { goto l444out }
 	if accept2('9') { goto l227 }
@@ -1073,7 +1097,7 @@ l441:
 l444out:
 	return id, length
 l447:
-	id, length = 15, pos
+	id, length = 18, pos
 	if r < '0' { goto l448out }
 	if accept2('9') { goto l227 }
 	if r < 'A' { goto l448out }
@@ -1084,7 +1108,7 @@ l447:
 l448out:
 	return id, length
 l451:
-	id, length = 54, pos
+	id, length = 61, pos
 	if accept('f') { goto l461 }
 	if accept('m') { goto l465 }
 	if accept('n') { goto l493 }
@@ -1102,7 +1126,7 @@ l451:
 l458out:
 	return id, length
 l461:
-	id, length = 16, pos
+	id, length = 19, pos
 	if r < '0' { goto l462out }
 	if accept2('9') { goto l227 }
 	if r < 'A' { goto l462out }
@@ -1113,7 +1137,7 @@ l461:
 l462out:
 	return id, length
 l465:
-	id, length = 54, pos
+	id, length = 61, pos
 	if accept('p') { goto l471 }
 	if r < '0' { goto l468out }
 	if accept2('9') { goto l227 }
@@ -1127,7 +1151,7 @@ l465:
 l468out:
 	return id, length
 l471:
-	id, length = 54, pos
+	id, length = 61, pos
 	if accept('o') { goto l477 }
 	if r < '0' { goto l474out }
 	if accept2('9') { goto l227 }
@@ -1141,7 +1165,7 @@ l471:
 l474out:
 	return id, length
 l477:
-	id, length = 54, pos
+	id, length = 61, pos
 	if accept('r') { goto l483 }
 	if r < '0' { goto l480out }
 	if accept2('9') { goto l227 }
@@ -1155,7 +1179,7 @@ l477:
 l480out:
 	return id, length
 l483:
-	id, length = 54, pos
+	id, length = 61, pos
 	if accept('t') { goto l489 }
 	if r < '0' { goto l486out }
 	if accept2('9') { goto l227 }
@@ -1169,7 +1193,7 @@ l483:
 l486out:
 	return id, length
 l489:
-	id, length = 17, pos
+	id, length = 20, pos
 	if r < '0' { goto l490out }
 	if accept2('9') { goto l227 }
 	if r < 'A' { goto l490out }
@@ -1180,7 +1204,7 @@ l489:
 l490out:
 	return id, length
 l493:
-	id, length = 18, pos
+	id, length = 21, pos
 	if r < '0' { goto l494out }
 	if accept2('9') { goto l227 }
 	if r < 'A' { goto l494out }
@@ -1191,7 +1215,7 @@ l493:
 l494out:
 	return id, length
 l497:
-	id, length = 54, pos
+	id, length = 61, pos
 	if accept('e') { goto l505 }
 	if accept('i') { goto l521 }
 	if r < '0' { goto l502out }
@@ -1208,7 +1232,7 @@ l497:
 l502out:
 	return id, length
 l505:
-	id, length = 54, pos
+	id, length = 61, pos
 	if accept('x') { goto l511 }
 	if r < '0' { goto l508out }
 	if accept2('9') { goto l227 }
@@ -1222,7 +1246,7 @@ l505:
 l508out:
 	return id, length
 l511:
-	id, length = 54, pos
+	id, length = 61, pos
 	if accept('t') { goto l517 }
 	if r < '0' { goto l514out }
 	if accept2('9') { goto l227 }
@@ -1236,7 +1260,7 @@ l511:
 l514out:
 	return id, length
 l517:
-	id, length = 19, pos
+	id, length = 22, pos
 	if r < '0' { goto l518out }
 	if accept2('9') { goto l227 }
 	if r < 'A' { goto l518out }
@@ -1247,7 +1271,7 @@ l517:
 l518out:
 	return id, length
 l521:
-	id, length = 54, pos
+	id, length = 61, pos
 	if accept('l') { goto l527 }
 	if r < '0' { goto l524out }
 	if accept2('9') { goto l227 }
@@ -1261,7 +1285,7 @@ l521:
 l524out:
 	return id, length
 l527:
-	id, length = 20, pos
+	id, length = 23, pos
 	if r < '0' { goto l528out }
 	if accept2('9') { goto l227 }
 	if r < 'A' { goto l528out }
@@ -1272,7 +1296,7 @@ l527:
 l528out:
 	return id, length
 l531:
-	id, length = 54, pos
+	id, length = 61, pos
 	if accept('f') { goto l539 }
 	if accept('r') { goto l543 }
 	if r < '0' { goto l536out }
@@ -1289,7 +1313,7 @@ l531:
 l536out:
 	return id, length
 l539:
-	id, length = 21, pos
+	id, length = 24, pos
 	if r < '0' { goto l540out }
 	if accept2('9') { goto l227 }
 	if r < 'A' { goto l540out }
@@ -1300,7 +1324,7 @@ l539:
 l540out:
 	return id, length
 l543:
-	id, length = 22, pos
+	id, length = 25, pos
 	if r < '0' { goto l544out }
 	if accept2('9') { goto l227 }
 	if r < 'A' { goto l544out }
@@ -1311,7 +1335,7 @@ l543:
 l544out:
 	return id, length
 l547:
-	id, length = 54, pos
+	id, length = 61, pos
 	if accept('a') { goto l553 }
 	if r < '0' { goto l550out }
 	if accept2('9') { goto l227 }
@@ -1323,7 +1347,7 @@ l547:
 l550out:
 	return id, length
 l553:
-	id, length = 54, pos
+	id, length = 61, pos
 	if accept('r') { goto l559 }
 	if r < '0' { goto l556out }
 	if accept2('9') { goto l227 }
@@ -1337,7 +1361,7 @@ l553:
 l556out:
 	return id, length
 l559:
-	id, length = 54, pos
+	id, length = 61, pos
 	if accept('a') { goto l565 }
 	if r < '0' { goto l562out }
 	if accept2('9') { goto l227 }
@@ -1349,7 +1373,7 @@ l559:
 l562out:
 	return id, length
 l565:
-	id, length = 54, pos
+	id, length = 61, pos
 	if accept('l') { goto l571 }
 	if r < '0' { goto l568out }
 	if accept2('9') { goto l227 }
@@ -1363,7 +1387,7 @@ l565:
 l568out:
 	return id, length
 l571:
-	id, length = 54, pos
+	id, length = 61, pos
 	if accept('l') { goto l577 }
 	if r < '0' { goto l574out }
 	if accept2('9') { goto l227 }
@@ -1377,7 +1401,7 @@ l571:
 l574out:
 	return id, length
 l577:
-	id, length = 54, pos
+	id, length = 61, pos
 	if accept('e') { goto l583 }
 	if r < '0' { goto l580out }
 	if accept2('9') { goto l227 }
@@ -1391,7 +1415,7 @@ l577:
 l580out:
 	return id, length
 l583:
-	id, length = 54, pos
+	id, length = 61, pos
 	if accept('l') { goto l589 }
 	if r < '0' { goto l586out }
 	if accept2('9') { goto l227 }
@@ -1405,7 +1429,7 @@ l583:
 l586out:
 	return id, length
 l589:
-	id, length = 23, pos
+	id, length = 26, pos
 	if r < '0' { goto l590out }
 	if accept2('9') { goto l227 }
 	if r < 'A' { goto l590out }
@@ -1416,7 +1440,7 @@ l589:
 l590out:
 	return id, length
 l593:
-	id, length = 54, pos
+	id, length = 61, pos
 	if accept('a') { goto l601 }
 	if accept('e') { goto l617 }
 	if r < '0' { goto l598out }
@@ -1431,7 +1455,7 @@ l593:
 l598out:
 	return id, length
 l601:
-	id, length = 54, pos
+	id, length = 61, pos
 	if accept('t') { goto l607 }
 	if r < '0' { goto l604out }
 	if accept2('9') { goto l227 }
@@ -1445,7 +1469,7 @@ l601:
 l604out:
 	return id, length
 l607:
-	id, length = 54, pos
+	id, length = 61, pos
 	if accept('s') { goto l613 }
 	if r < '0' { goto l610out }
 	if accept2('9') { goto l227 }
@@ -1459,7 +1483,7 @@ l607:
 l610out:
 	return id, length
 l613:
-	id, length = 24, pos
+	id, length = 27, pos
 	if r < '0' { goto l614out }
 	if accept2('9') { goto l227 }
 	if r < 'A' { goto l614out }
@@ -1470,7 +1494,7 @@ l613:
 l614out:
 	return id, length
 l617:
-	id, length = 54, pos
+	id, length = 61, pos
 	if accept('q') { goto l625 }
 	if accept('t') { goto l653 }
 	if r < '0' { goto l622out }
@@ -1487,7 +1511,7 @@ l617:
 l622out:
 	return id, length
 l625:
-	id, length = 54, pos
+	id, length = 61, pos
 	if accept('u') { goto l631 }
 	if r < '0' { goto l628out }
 	if accept2('9') { goto l227 }
@@ -1501,7 +1525,7 @@ l625:
 l628out:
 	return id, length
 l631:
-	id, length = 54, pos
+	id, length = 61, pos
 	if accept('i') { goto l637 }
 	if r < '0' { goto l634out }
 	if accept2('9') { goto l227 }
@@ -1515,7 +1539,7 @@ l631:
 l634out:
 	return id, length
 l637:
-	id, length = 54, pos
+	id, length = 61, pos
 	if accept('r') { goto l643 }
 	if r < '0' { goto l640out }
 	if accept2('9') { goto l227 }
@@ -1529,7 +1553,7 @@ l637:
 l640out:
 	return id, length
 l643:
-	id, length = 54, pos
+	id, length = 61, pos
 	if accept('e') { goto l649 }
 	if r < '0' { goto l646out }
 	if accept2('9') { goto l227 }
@@ -1543,18 +1567,18 @@ l643:
 l646out:
 	return id, length
 l649:
-	id, length = 25, pos
-	if r < '0' { goto l650out }
+	id, length = 28, pos
+	if accept('_') { goto l840 }
+	if r < '0' { goto l652out }
 	if accept2('9') { goto l227 }
-	if r < 'A' { goto l650out }
+	if r < 'A' { goto l652out }
 	if accept2('Z') { goto l227 }
-	if accept('_') { goto l227 }
-	if r < 'a' { goto l650out }
+	if r < 'a' { goto l652out }
 	if accept2('z') { goto l227 }
-l650out:
+l652out:
 	return id, length
 l653:
-	id, length = 54, pos
+	id, length = 61, pos
 	if accept('u') { goto l659 }
 	if r < '0' { goto l656out }
 	if accept2('9') { goto l227 }
@@ -1568,7 +1592,7 @@ l653:
 l656out:
 	return id, length
 l659:
-	id, length = 54, pos
+	id, length = 61, pos
 	if accept('r') { goto l665 }
 	if r < '0' { goto l662out }
 	if accept2('9') { goto l227 }
@@ -1582,7 +1606,7 @@ l659:
 l662out:
 	return id, length
 l665:
-	id, length = 54, pos
+	id, length = 61, pos
 	if accept('n') { goto l671 }
 	if r < '0' { goto l668out }
 	if accept2('9') { goto l227 }
@@ -1596,7 +1620,7 @@ l665:
 l668out:
 	return id, length
 l671:
-	id, length = 26, pos
+	id, length = 30, pos
 	if r < '0' { goto l672out }
 	if accept2('9') { goto l227 }
 	if r < 'A' { goto l672out }
@@ -1607,7 +1631,7 @@ l671:
 l672out:
 	return id, length
 l675:
-	id, length = 54, pos
+	id, length = 61, pos
 	if accept('a') { goto l683 }
 	if accept('p') { goto l717 }
 	if r < '0' { goto l680out }
@@ -1622,7 +1646,7 @@ l675:
 l680out:
 	return id, length
 l683:
-	id, length = 54, pos
+	id, length = 61, pos
 	if accept('n') { goto l689 }
 	if r < '0' { goto l686out }
 	if accept2('9') { goto l227 }
@@ -1636,7 +1660,7 @@ l683:
 l686out:
 	return id, length
 l689:
-	id, length = 54, pos
+	id, length = 61, pos
 	if accept('d') { goto l695 }
 	if r < '0' { goto l692out }
 	if accept2('9') { goto l227 }
@@ -1650,7 +1674,7 @@ l689:
 l692out:
 	return id, length
 l695:
-	id, length = 54, pos
+	id, length = 61, pos
 	if accept('b') { goto l701 }
 	if r < '0' { goto l698out }
 	if accept2('9') { goto l227 }
@@ -1663,7 +1687,7 @@ l695:
 l698out:
 	return id, length
 l701:
-	id, length = 54, pos
+	id, length = 61, pos
 	if accept('o') { goto l707 }
 	if r < '0' { goto l704out }
 	if accept2('9') { goto l227 }
@@ -1677,7 +1701,7 @@ l701:
 l704out:
 	return id, length
 l707:
-	id, length = 54, pos
+	id, length = 61, pos
 	if accept('x') { goto l713 }
 	if r < '0' { goto l710out }
 	if accept2('9') { goto l227 }
@@ -1691,7 +1715,7 @@ l707:
 l710out:
 	return id, length
 l713:
-	id, length = 27, pos
+	id, length = 31, pos
 	if r < '0' { goto l714out }
 	if accept2('9') { goto l227 }
 	if r < 'A' { goto l714out }
@@ -1702,7 +1726,7 @@ l713:
 l714out:
 	return id, length
 l717:
-	id, length = 54, pos
+	id, length = 61, pos
 	if accept('a') { goto l723 }
 	if r < '0' { goto l720out }
 	if accept2('9') { goto l227 }
@@ -1714,7 +1738,7 @@ l717:
 l720out:
 	return id, length
 l723:
-	id, length = 54, pos
+	id, length = 61, pos
 	if accept('w') { goto l729 }
 	if r < '0' { goto l726out }
 	if accept2('9') { goto l227 }
@@ -1728,7 +1752,7 @@ l723:
 l726out:
 	return id, length
 l729:
-	id, length = 54, pos
+	id, length = 61, pos
 	if accept('n') { goto l735 }
 	if r < '0' { goto l732out }
 	if accept2('9') { goto l227 }
@@ -1742,7 +1766,7 @@ l729:
 l732out:
 	return id, length
 l735:
-	id, length = 28, pos
+	id, length = 32, pos
 	if r < '0' { goto l736out }
 	if accept2('9') { goto l227 }
 	if r < 'A' { goto l736out }
@@ -1753,7 +1777,7 @@ l735:
 l736out:
 	return id, length
 l739:
-	id, length = 54, pos
+	id, length = 61, pos
 	if accept('r') { goto l745 }
 	if r < '0' { goto l742out }
 	if accept2('9') { goto l227 }
@@ -1767,7 +1791,7 @@ l739:
 l742out:
 	return id, length
 l745:
-	id, length = 54, pos
+	id, length = 61, pos
 	if accept('u') { goto l753 }
 	if accept('y') { goto l763 }
 	if r < '0' { goto l750out }
@@ -1783,7 +1807,7 @@ l745:
 l750out:
 	return id, length
 l753:
-	id, length = 54, pos
+	id, length = 61, pos
 	if accept('e') { goto l759 }
 	if r < '0' { goto l756out }
 	if accept2('9') { goto l227 }
@@ -1797,7 +1821,7 @@ l753:
 l756out:
 	return id, length
 l759:
-	id, length = 29, pos
+	id, length = 33, pos
 	if r < '0' { goto l760out }
 	if accept2('9') { goto l227 }
 	if r < 'A' { goto l760out }
@@ -1808,7 +1832,7 @@ l759:
 l760out:
 	return id, length
 l763:
-	id, length = 30, pos
+	id, length = 34, pos
 	if r < '0' { goto l764out }
 	if accept2('9') { goto l227 }
 	if r < 'A' { goto l764out }
@@ -1819,7 +1843,7 @@ l763:
 l764out:
 	return id, length
 l767:
-	id, length = 54, pos
+	id, length = 61, pos
 	if accept('s') { goto l773 }
 	if r < '0' { goto l770out }
 	if accept2('9') { goto l227 }
@@ -1833,7 +1857,7 @@ l767:
 l770out:
 	return id, length
 l773:
-	id, length = 54, pos
+	id, length = 61, pos
 	if accept('e') { goto l779 }
 	if r < '0' { goto l776out }
 	if accept2('9') { goto l227 }
@@ -1847,7 +1871,7 @@ l773:
 l776out:
 	return id, length
 l779:
-	id, length = 31, pos
+	id, length = 35, pos
 	if r < '0' { goto l780out }
 	if accept2('9') { goto l227 }
 	if r < 'A' { goto l780out }
@@ -1858,7 +1882,7 @@ l779:
 l780out:
 	return id, length
 l783:
-	id, length = 54, pos
+	id, length = 61, pos
 	if accept('h') { goto l791 }
 	if accept('i') { goto l813 }
 	if r < '0' { goto l788out }
@@ -1873,7 +1897,7 @@ l783:
 l788out:
 	return id, length
 l791:
-	id, length = 54, pos
+	id, length = 61, pos
 	if accept('i') { goto l797 }
 	if r < '0' { goto l794out }
 	if accept2('9') { goto l227 }
@@ -1887,7 +1911,7 @@ l791:
 l794out:
 	return id, length
 l797:
-	id, length = 54, pos
+	id, length = 61, pos
 	if accept('l') { goto l803 }
 	if r < '0' { goto l800out }
 	if accept2('9') { goto l227 }
@@ -1901,7 +1925,7 @@ l797:
 l800out:
 	return id, length
 l803:
-	id, length = 54, pos
+	id, length = 61, pos
 	if accept('e') { goto l809 }
 	if r < '0' { goto l806out }
 	if accept2('9') { goto l227 }
@@ -1915,7 +1939,7 @@ l803:
 l806out:
 	return id, length
 l809:
-	id, length = 32, pos
+	id, length = 36, pos
 	if r < '0' { goto l810out }
 	if accept2('9') { goto l227 }
 	if r < 'A' { goto l810out }
@@ -1926,7 +1950,7 @@ l809:
 l810out:
 	return id, length
 l813:
-	id, length = 54, pos
+	id, length = 61, pos
 	if accept('t') { goto l819 }
 	if r < '0' { goto l816out }
 	if accept2('9') { goto l227 }
@@ -1940,7 +1964,7 @@ l813:
 l816out:
 	return id, length
 l819:
-	id, length = 54, pos
+	id, length = 61, pos
 	if accept('h') { goto l825 }
 	if r < '0' { goto l822out }
 	if accept2('9') { goto l227 }
@@ -1954,7 +1978,7 @@ l819:
 l822out:
 	return id, length
 l825:
-	id, length = 33, pos
+	id, length = 37, pos
 	if r < '0' { goto l826out }
 	if accept2('9') { goto l227 }
 	if r < 'A' { goto l826out }
@@ -1965,20 +1989,207 @@ l825:
 l826out:
 	return id, length
 l829:
-	id, length = 50, pos
+	id, length = 56, pos
 	return id, length
 l831:
+	id, length = 57, pos
 	if accept('|') { goto l834 }
 	return id, length
 l834:
-	id, length = 34, pos
+	id, length = 38, pos
 	return id, length
 l836:
-	id, length = 51, pos
+	id, length = 58, pos
 	return id, length
 l838:
 	id, length = 0, pos
 	return id, length
+l840:
+	id, length = 61, pos
+	if accept('o') { goto l846 }
+	if r < '0' { goto l843out }
+	if accept2('9') { goto l227 }
+	if r < 'A' { goto l843out }
+	if accept2('Z') { goto l227 }
+	if accept('_') { goto l227 }
+	if r < 'a' { goto l843out }
+	if accept2('n') { goto l227 }
+	if r < 'p' { goto l843out }
+	if accept2('z') { goto l227 }
+l843out:
+	return id, length
+l846:
+	id, length = 61, pos
+	if accept('p') { goto l852 }
+	if r < '0' { goto l849out }
+	if accept2('9') { goto l227 }
+	if r < 'A' { goto l849out }
+	if accept2('Z') { goto l227 }
+	if accept('_') { goto l227 }
+	if r < 'a' { goto l849out }
+	if accept2('o') { goto l227 }
+	if r < 'q' { goto l849out }
+	if accept2('z') { goto l227 }
+l849out:
+	return id, length
+l852:
+	id, length = 61, pos
+	if accept('t') { goto l858 }
+	if r < '0' { goto l855out }
+	if accept2('9') { goto l227 }
+	if r < 'A' { goto l855out }
+	if accept2('Z') { goto l227 }
+	if accept('_') { goto l227 }
+	if r < 'a' { goto l855out }
+	if accept2('s') { goto l227 }
+	if r < 'u' { goto l855out }
+	if accept2('z') { goto l227 }
+l855out:
+	return id, length
+l858:
+	id, length = 61, pos
+	if accept('i') { goto l864 }
+	if r < '0' { goto l861out }
+	if accept2('9') { goto l227 }
+	if r < 'A' { goto l861out }
+	if accept2('Z') { goto l227 }
+	if accept('_') { goto l227 }
+	if r < 'a' { goto l861out }
+	if accept2('h') { goto l227 }
+	if r < 'j' { goto l861out }
+	if accept2('z') { goto l227 }
+l861out:
+	return id, length
+l864:
+	id, length = 61, pos
+	if accept('o') { goto l870 }
+	if r < '0' { goto l867out }
+	if accept2('9') { goto l227 }
+	if r < 'A' { goto l867out }
+	if accept2('Z') { goto l227 }
+	if accept('_') { goto l227 }
+	if r < 'a' { goto l867out }
+	if accept2('n') { goto l227 }
+	if r < 'p' { goto l867out }
+	if accept2('z') { goto l227 }
+l867out:
+	return id, length
+l870:
+	id, length = 61, pos
+	if accept('n') { goto l876 }
+	if r < '0' { goto l873out }
+	if accept2('9') { goto l227 }
+	if r < 'A' { goto l873out }
+	if accept2('Z') { goto l227 }
+	if accept('_') { goto l227 }
+	if r < 'a' { goto l873out }
+	if accept2('m') { goto l227 }
+	if r < 'o' { goto l873out }
+	if accept2('z') { goto l227 }
+l873out:
+	return id, length
+l876:
+	id, length = 61, pos
+	if accept('a') { goto l882 }
+	if r < '0' { goto l879out }
+	if accept2('9') { goto l227 }
+	if r < 'A' { goto l879out }
+	if accept2('Z') { goto l227 }
+	if accept('_') { goto l227 }
+	if r < 'b' { goto l879out }
+	if accept2('z') { goto l227 }
+l879out:
+	return id, length
+l882:
+	id, length = 61, pos
+	if accept('l') { goto l888 }
+	if r < '0' { goto l885out }
+	if accept2('9') { goto l227 }
+	if r < 'A' { goto l885out }
+	if accept2('Z') { goto l227 }
+	if accept('_') { goto l227 }
+	if r < 'a' { goto l885out }
+	if accept2('k') { goto l227 }
+	if r < 'm' { goto l885out }
+	if accept2('z') { goto l227 }
+l885out:
+	return id, length
+l888:
+	id, length = 29, pos
+	if r < '0' { goto l889out }
+	if accept2('9') { goto l227 }
+	if r < 'A' { goto l889out }
+	if accept2('Z') { goto l227 }
+	if accept('_') { goto l227 }
+	if r < 'a' { goto l889out }
+	if accept2('z') { goto l227 }
+l889out:
+	return id, length
+l894:
+	id, length = 59, pos
+	return id, length
+l896:
+	id, length = 3, pos
+	return id, length
+l898:
+	id, length = 5, pos
+	return id, length
+l900:
+	id, length = 55, pos
+	return id, length
+l902:
+	id, length = 61, pos
+	if accept('u') { goto l908 }
+	if r < '0' { goto l905out }
+	if accept2('9') { goto l227 }
+	if r < 'A' { goto l905out }
+	if accept2('Z') { goto l227 }
+	if accept('_') { goto l227 }
+	if r < 'a' { goto l905out }
+	if accept2('t') { goto l227 }
+	if r < 'v' { goto l905out }
+	if accept2('z') { goto l227 }
+l905out:
+	return id, length
+l908:
+	id, length = 61, pos
+	if accept('r') { goto l914 }
+	if r < '0' { goto l911out }
+	if accept2('9') { goto l227 }
+	if r < 'A' { goto l911out }
+	if accept2('Z') { goto l227 }
+	if accept('_') { goto l227 }
+	if r < 'a' { goto l911out }
+	if accept2('q') { goto l227 }
+	if r < 's' { goto l911out }
+	if accept2('z') { goto l227 }
+l911out:
+	return id, length
+l914:
+	id, length = 61, pos
+	if accept('e') { goto l920 }
+	if r < '0' { goto l917out }
+	if accept2('9') { goto l227 }
+	if r < 'A' { goto l917out }
+	if accept2('Z') { goto l227 }
+	if accept('_') { goto l227 }
+	if r < 'a' { goto l917out }
+	if accept2('d') { goto l227 }
+	if r < 'f' { goto l917out }
+	if accept2('z') { goto l227 }
+l917out:
+	return id, length
+l920:
+	id, length = 15, pos
+	if r < '0' { goto l921out }
+	if accept2('9') { goto l227 }
+	if r < 'A' { goto l921out }
+	if accept2('Z') { goto l227 }
+	if accept('_') { goto l227 }
+	if r < 'a' { goto l921out }
+	if accept2('z') { goto l227 }
+l921out:
+	return id, length
 }
 
 
@@ -2013,7 +2224,7 @@ func (p *Parser) init(name string, src []byte) (err error) {
 //			call MulExpr and goto state 1
 //	State 1
 //		Accept
-//		on  '+', '-'
+//		on  '+', '-', '^', '|'
 //			shift and goto state 0
 // AddExpr is used internally from Parse.
 func (p *Parser) AddExpr() (r []int32) {
@@ -2030,7 +2241,7 @@ return p.stop(r, accept, errorSet)
 state1:
 accept, errorSet = true, 31
 switch Symbol(p.tok.Ch) {
-	case  RugoTOK_002b, RugoTOK_002d:
+	case  RugoTOK_002b, RugoTOK_002d, RugoTOK_005e, RugoTOK_007c:
 r = append(r, p.shift())
 goto state0
 }
@@ -2245,7 +2456,7 @@ return p.stop(r, accept, errorSet)
 //	State 2
 //		on  "end"
 //			shift and goto state 3
-//		on  "bench", "break", "case", "def", "embed", "false", "fn", "for", "if", "import", "next", "nil", "parallel", "rats", "require", "return", "sandbox", "spawn", "true", "try", "use", "while", '!', '(', '-', ';', '[', '{', float_lit, ident, integer, raw_str_lit, str_lit
+//		on  "bench", "break", "case", "def", "embed", "false", "fn", "for", "if", "import", "next", "nil", "parallel", "rats", "require", "require_optional", "return", "sandbox", "spawn", "true", "try", "use", "while", '!', '(', '-', ';', '[', '{', float_lit, ident, integer, raw_str_lit, str_lit
 //			call Body and goto state 4
 //	State 3
 //		Accept
@@ -2278,7 +2489,7 @@ switch Symbol(p.tok.Ch) {
 	case  RugoTOK_end:
 r = append(r, p.shift())
 goto state3
-	case  RugoTOK_bench, RugoTOK_break, RugoTOK_case, RugoTOK_def, RugoTOK_embed, RugoTOK_false, RugoTOK_fn, RugoTOK_for, RugoTOK_if, RugoTOK_import, RugoTOK_next, RugoTOK_nil, RugoTOK_parallel, RugoTOK_rats, RugoTOK_require, RugoTOK_return, RugoTOK_sandbox, RugoTOK_spawn, RugoTOK_true, RugoTOK_try, RugoTOK_use, RugoTOK_while, RugoTOK_0021, RugoTOK_0028, RugoTOK_002d, RugoTOK_003b, RugoTOK_005b, RugoTOK_007b, Rugofloat_lit, Rugoident, Rugointeger, Rugoraw_str_lit, Rugostr_lit:
+	case  RugoTOK_bench, RugoTOK_break, RugoTOK_case, RugoTOK_def, RugoTOK_embed, RugoTOK_false, RugoTOK_fn, RugoTOK_for, RugoTOK_if, RugoTOK_import, RugoTOK_next, RugoTOK_nil, RugoTOK_parallel, RugoTOK_rats, RugoTOK_require, RugoTOK_require_optional, RugoTOK_return, RugoTOK_sandbox, RugoTOK_spawn, RugoTOK_true, RugoTOK_try, RugoTOK_use, RugoTOK_while, RugoTOK_0021, RugoTOK_0028, RugoTOK_002d, RugoTOK_003b, RugoTOK_005b, RugoTOK_007b, Rugofloat_lit, Rugoident, Rugointeger, Rugoraw_str_lit, Rugostr_lit:
 r = p.add(r, p.Body())
 goto state4
 }
@@ -2306,13 +2517,13 @@ return p.stop(r, accept, errorSet)
 //		Accept
 //		on  ';'
 //			shift and goto state 1
-//		on  "bench", "break", "case", "def", "embed", "false", "fn", "for", "if", "import", "next", "nil", "parallel", "rats", "require", "return", "sandbox", "spawn", "true", "try", "use", "while", '!', '(', '-', '[', '{', float_lit, ident, integer, raw_str_lit, str_lit
+//		on  "bench", "break", "case", "def", "embed", "false", "fn", "for", "if", "import", "next", "nil", "parallel", "rats", "require", "require_optional", "return", "sandbox", "spawn", "true", "try", "use", "while", '!', '(', '-', '[', '{', float_lit, ident, integer, raw_str_lit, str_lit
 //			call Statement and goto state 1
 //	State 1
 //		Accept
 //		on  ';'
 //			shift and goto state 1
-//		on  "bench", "break", "case", "def", "embed", "false", "fn", "for", "if", "import", "next", "nil", "parallel", "rats", "require", "return", "sandbox", "spawn", "true", "try", "use", "while", '!', '(', '-', '[', '{', float_lit, ident, integer, raw_str_lit, str_lit
+//		on  "bench", "break", "case", "def", "embed", "false", "fn", "for", "if", "import", "next", "nil", "parallel", "rats", "require", "require_optional", "return", "sandbox", "spawn", "true", "try", "use", "while", '!', '(', '-', '[', '{', float_lit, ident, integer, raw_str_lit, str_lit
 //			call Statement and goto state 1
 // Body is used internally from Parse.
 func (p *Parser) Body() (r []int32) {
@@ -2324,7 +2535,7 @@ switch Symbol(p.tok.Ch) {
 	case  RugoTOK_003b:
 r = append(r, p.shift())
 goto state1
-	case  RugoTOK_bench, RugoTOK_break, RugoTOK_case, RugoTOK_def, RugoTOK_embed, RugoTOK_false, RugoTOK_fn, RugoTOK_for, RugoTOK_if, RugoTOK_import, RugoTOK_next, RugoTOK_nil, RugoTOK_parallel, RugoTOK_rats, RugoTOK_require, RugoTOK_return, RugoTOK_sandbox, RugoTOK_spawn, RugoTOK_true, RugoTOK_try, RugoTOK_use, RugoTOK_while, RugoTOK_0021, RugoTOK_0028, RugoTOK_002d, RugoTOK_005b, RugoTOK_007b, Rugofloat_lit, Rugoident, Rugointeger, Rugoraw_str_lit, Rugostr_lit:
+	case  RugoTOK_bench, RugoTOK_break, RugoTOK_case, RugoTOK_def, RugoTOK_embed, RugoTOK_false, RugoTOK_fn, RugoTOK_for, RugoTOK_if, RugoTOK_import, RugoTOK_next, RugoTOK_nil, RugoTOK_parallel, RugoTOK_rats, RugoTOK_require, RugoTOK_require_optional, RugoTOK_return, RugoTOK_sandbox, RugoTOK_spawn, RugoTOK_true, RugoTOK_try, RugoTOK_use, RugoTOK_while, RugoTOK_0021, RugoTOK_0028, RugoTOK_002d, RugoTOK_005b, RugoTOK_007b, Rugofloat_lit, Rugoident, Rugointeger, Rugoraw_str_lit, Rugostr_lit:
 r = p.add(r, p.Statement())
 goto state1
 }
@@ -2335,7 +2546,7 @@ switch Symbol(p.tok.Ch) {
 	case  RugoTOK_003b:
 r = append(r, p.shift())
 goto state1
-	case  RugoTOK_bench, RugoTOK_break, RugoTOK_case, RugoTOK_def, RugoTOK_embed, RugoTOK_false, RugoTOK_fn, RugoTOK_for, RugoTOK_if, RugoTOK_import, RugoTOK_next, RugoTOK_nil, RugoTOK_parallel, RugoTOK_rats, RugoTOK_require, RugoTOK_return, RugoTOK_sandbox, RugoTOK_spawn, RugoTOK_true, RugoTOK_try, RugoTOK_use, RugoTOK_while, RugoTOK_0021, RugoTOK_0028, RugoTOK_002d, RugoTOK_005b, RugoTOK_007b, Rugofloat_lit, Rugoident, Rugointeger, Rugoraw_str_lit, Rugostr_lit:
+	case  RugoTOK_bench, RugoTOK_break, RugoTOK_case, RugoTOK_def, RugoTOK_embed, RugoTOK_false, RugoTOK_fn, RugoTOK_for, RugoTOK_if, RugoTOK_import, RugoTOK_next, RugoTOK_nil, RugoTOK_parallel, RugoTOK_rats, RugoTOK_require, RugoTOK_require_optional, RugoTOK_return, RugoTOK_sandbox, RugoTOK_spawn, RugoTOK_true, RugoTOK_try, RugoTOK_use, RugoTOK_while, RugoTOK_0021, RugoTOK_0028, RugoTOK_002d, RugoTOK_005b, RugoTOK_007b, Rugofloat_lit, Rugoident, Rugointeger, Rugoraw_str_lit, Rugostr_lit:
 r = p.add(r, p.Statement())
 goto state1
 }
@@ -2396,7 +2607,7 @@ return p.stop(r, accept, errorSet)
 //			shift and goto state 4
 //		on  "end"
 //			shift and goto state 6
-//		on  "bench", "break", "case", "def", "embed", "false", "fn", "for", "if", "import", "next", "nil", "parallel", "rats", "require", "return", "sandbox", "spawn", "true", "try", "use", "while", '!', '(', '-', ';', '[', '{', float_lit, ident, integer, raw_str_lit, str_lit
+//		on  "bench", "break", "case", "def", "embed", "false", "fn", "for", "if", "import", "next", "nil", "parallel", "rats", "require", "require_optional", "return", "sandbox", "spawn", "true", "try", "use", "while", '!', '(', '-', ';', '[', '{', float_lit, ident, integer, raw_str_lit, str_lit
 //			call Body and goto state 5
 //	State 4
 //		on  "case", "false", "fn", "nil", "parallel", "spawn", "true", "try", '!', '(', '-', '[', '{', float_lit, ident, integer, raw_str_lit, str_lit
@@ -2418,7 +2629,7 @@ return p.stop(r, accept, errorSet)
 //			shift and goto state 7
 //		on  "->"
 //			shift and goto state 9
-//		on  "bench", "break", "case", "def", "embed", "false", "fn", "for", "if", "import", "next", "nil", "parallel", "rats", "require", "return", "sandbox", "spawn", "true", "try", "use", "while", '!', '(', '-', ';', '[', '{', float_lit, ident, integer, raw_str_lit, str_lit
+//		on  "bench", "break", "case", "def", "embed", "false", "fn", "for", "if", "import", "next", "nil", "parallel", "rats", "require", "require_optional", "return", "sandbox", "spawn", "true", "try", "use", "while", '!', '(', '-', ';', '[', '{', float_lit, ident, integer, raw_str_lit, str_lit
 //			call Body and goto state 10
 //	State 9
 //		on  "case", "false", "fn", "nil", "parallel", "spawn", "true", "try", '!', '(', '-', '[', '{', float_lit, ident, integer, raw_str_lit, str_lit
@@ -2444,7 +2655,7 @@ return p.stop(r, accept, errorSet)
 //			shift and goto state 6
 //		on  "elsif"
 //			shift and goto state 7
-//		on  "bench", "break", "case", "def", "embed", "false", "fn", "for", "if", "import", "next", "nil", "parallel", "rats", "require", "return", "sandbox", "spawn", "true", "try", "use", "while", '!', '(', '-', ';', '[', '{', float_lit, ident, integer, raw_str_lit, str_lit
+//		on  "bench", "break", "case", "def", "embed", "false", "fn", "for", "if", "import", "next", "nil", "parallel", "rats", "require", "require_optional", "return", "sandbox", "spawn", "true", "try", "use", "while", '!', '(', '-', ';', '[', '{', float_lit, ident, integer, raw_str_lit, str_lit
 //			call Body and goto state 2
 // CaseExpr is used internally from Parse.
 func (p *Parser) CaseExpr() (r []int32) {
@@ -2492,7 +2703,7 @@ goto state4
 	case  RugoTOK_end:
 r = append(r, p.shift())
 goto state6
-	case  RugoTOK_bench, RugoTOK_break, RugoTOK_case, RugoTOK_def, RugoTOK_embed, RugoTOK_false, RugoTOK_fn, RugoTOK_for, RugoTOK_if, RugoTOK_import, RugoTOK_next, RugoTOK_nil, RugoTOK_parallel, RugoTOK_rats, RugoTOK_require, RugoTOK_return, RugoTOK_sandbox, RugoTOK_spawn, RugoTOK_true, RugoTOK_try, RugoTOK_use, RugoTOK_while, RugoTOK_0021, RugoTOK_0028, RugoTOK_002d, RugoTOK_003b, RugoTOK_005b, RugoTOK_007b, Rugofloat_lit, Rugoident, Rugointeger, Rugoraw_str_lit, Rugostr_lit:
+	case  RugoTOK_bench, RugoTOK_break, RugoTOK_case, RugoTOK_def, RugoTOK_embed, RugoTOK_false, RugoTOK_fn, RugoTOK_for, RugoTOK_if, RugoTOK_import, RugoTOK_next, RugoTOK_nil, RugoTOK_parallel, RugoTOK_rats, RugoTOK_require, RugoTOK_require_optional, RugoTOK_return, RugoTOK_sandbox, RugoTOK_spawn, RugoTOK_true, RugoTOK_try, RugoTOK_use, RugoTOK_while, RugoTOK_0021, RugoTOK_0028, RugoTOK_002d, RugoTOK_003b, RugoTOK_005b, RugoTOK_007b, Rugofloat_lit, Rugoident, Rugointeger, Rugoraw_str_lit, Rugostr_lit:
 r = p.add(r, p.Body())
 goto state5
 }
@@ -2539,7 +2750,7 @@ goto state7
 	case  RugoTOK_002d003e:
 r = append(r, p.shift())
 goto state9
-	case  RugoTOK_bench, RugoTOK_break, RugoTOK_case, RugoTOK_def, RugoTOK_embed, RugoTOK_false, RugoTOK_fn, RugoTOK_for, RugoTOK_if, RugoTOK_import, RugoTOK_next, RugoTOK_nil, RugoTOK_parallel, RugoTOK_rats, RugoTOK_require, RugoTOK_return, RugoTOK_sandbox, RugoTOK_spawn, RugoTOK_true, RugoTOK_try, RugoTOK_use, RugoTOK_while, RugoTOK_0021, RugoTOK_0028, RugoTOK_002d, RugoTOK_003b, RugoTOK_005b, RugoTOK_007b, Rugofloat_lit, Rugoident, Rugointeger, Rugoraw_str_lit, Rugostr_lit:
+	case  RugoTOK_bench, RugoTOK_break, RugoTOK_case, RugoTOK_def, RugoTOK_embed, RugoTOK_false, RugoTOK_fn, RugoTOK_for, RugoTOK_if, RugoTOK_import, RugoTOK_next, RugoTOK_nil, RugoTOK_parallel, RugoTOK_rats, RugoTOK_require, RugoTOK_require_optional, RugoTOK_return, RugoTOK_sandbox, RugoTOK_spawn, RugoTOK_true, RugoTOK_try, RugoTOK_use, RugoTOK_while, RugoTOK_0021, RugoTOK_0028, RugoTOK_002d, RugoTOK_003b, RugoTOK_005b, RugoTOK_007b, Rugofloat_lit, Rugoident, Rugointeger, Rugoraw_str_lit, Rugostr_lit:
 r = p.add(r, p.Body())
 goto state10
 }
@@ -2592,7 +2803,7 @@ goto state6
 	case  RugoTOK_elsif:
 r = append(r, p.shift())
 goto state7
-	case  RugoTOK_bench, RugoTOK_break, RugoTOK_case, RugoTOK_def, RugoTOK_embed, RugoTOK_false, RugoTOK_fn, RugoTOK_for, RugoTOK_if, RugoTOK_import, RugoTOK_next, RugoTOK_nil, RugoTOK_parallel, RugoTOK_rats, RugoTOK_require, RugoTOK_return, RugoTOK_sandbox, RugoTOK_spawn, RugoTOK_true, RugoTOK_try, RugoTOK_use, RugoTOK_while, RugoTOK_0021, RugoTOK_0028, RugoTOK_002d, RugoTOK_003b, RugoTOK_005b, RugoTOK_007b, Rugofloat_lit, Rugoident, Rugointeger, Rugoraw_str_lit, Rugostr_lit:
+	case  RugoTOK_bench, RugoTOK_break, RugoTOK_case, RugoTOK_def, RugoTOK_embed, RugoTOK_false, RugoTOK_fn, RugoTOK_for, RugoTOK_if, RugoTOK_import, RugoTOK_next, RugoTOK_nil, RugoTOK_parallel, RugoTOK_rats, RugoTOK_require, RugoTOK_require_optional, RugoTOK_return, RugoTOK_sandbox, RugoTOK_spawn, RugoTOK_true, RugoTOK_try, RugoTOK_use, RugoTOK_while, RugoTOK_0021, RugoTOK_0028, RugoTOK_002d, RugoTOK_003b, RugoTOK_005b, RugoTOK_007b, Rugofloat_lit, Rugoident, Rugointeger, Rugoraw_str_lit, Rugostr_lit:
 r = p.add(r, p.Body())
 goto state2
 }
@@ -2603,14 +2814,14 @@ return p.stop(r, accept, errorSet)
 // CompExpr grammar:
 //
 //	
-//	CompExpr    = AddExpr [ comp_op AddExpr ] .
+//	CompExpr    = AddExpr [ ( comp_op | "in" ) AddExpr ] .
 //
 //	State 0
 //		on  "case", "false", "fn", "nil", "parallel", "spawn", "true", "try", '!', '(', '-', '[', '{', float_lit, ident, integer, raw_str_lit, str_lit
 //			call AddExpr and goto state 1
 //	State 1
 //		Accept
-//		on  comp_op
+//		on  "in", comp_op
 //			shift and goto state 2
 //	State 2
 //		on  "case", "false", "fn", "nil", "parallel", "spawn", "true", "try", '!', '(', '-', '[', '{', float_lit, ident, integer, raw_str_lit, str_lit
@@ -2632,7 +2843,7 @@ return p.stop(r, accept, errorSet)
 state1:
 accept, errorSet = true, 46
 switch Symbol(p.tok.Ch) {
-	case  Rugocomp_op:
+	case  RugoTOK_in, Rugocomp_op:
 r = append(r, p.shift())
 goto state2
 }
@@ -2796,7 +3007,7 @@ return p.stop(r, accept, errorSet)
 //	State 3
 //		on  "end"
 //			shift and goto state 4
-//		on  "bench", "break", "case", "def", "embed", "false", "fn", "for", "if", "import", "next", "nil", "parallel", "rats", "require", "return", "sandbox", "spawn", "true", "try", "use", "while", '!', '(', '-', ';', '[', '{', float_lit, ident, integer, raw_str_lit, str_lit
+//		on  "bench", "break", "case", "def", "embed", "false", "fn", "for", "if", "import", "next", "nil", "parallel", "rats", "require", "require_optional", "return", "sandbox", "spawn", "true", "try", "use", "while", '!', '(', '-', ';', '[', '{', float_lit, ident, integer, raw_str_lit, str_lit
 //			call Body and goto state 5
 //	State 4
 //		Accept
@@ -2843,7 +3054,7 @@ switch Symbol(p.tok.Ch) {
 	case  RugoTOK_end:
 r = append(r, p.shift())
 goto state4
-	case  RugoTOK_bench, RugoTOK_break, RugoTOK_case, RugoTOK_def, RugoTOK_embed, RugoTOK_false, RugoTOK_fn, RugoTOK_for, RugoTOK_if, RugoTOK_import, RugoTOK_next, RugoTOK_nil, RugoTOK_parallel, RugoTOK_rats, RugoTOK_require, RugoTOK_return, RugoTOK_sandbox, RugoTOK_spawn, RugoTOK_true, RugoTOK_try, RugoTOK_use, RugoTOK_while, RugoTOK_0021, RugoTOK_0028, RugoTOK_002d, RugoTOK_003b, RugoTOK_005b, RugoTOK_007b, Rugofloat_lit, Rugoident, Rugointeger, Rugoraw_str_lit, Rugostr_lit:
+	case  RugoTOK_bench, RugoTOK_break, RugoTOK_case, RugoTOK_def, RugoTOK_embed, RugoTOK_false, RugoTOK_fn, RugoTOK_for, RugoTOK_if, RugoTOK_import, RugoTOK_next, RugoTOK_nil, RugoTOK_parallel, RugoTOK_rats, RugoTOK_require, RugoTOK_require_optional, RugoTOK_return, RugoTOK_sandbox, RugoTOK_spawn, RugoTOK_true, RugoTOK_try, RugoTOK_use, RugoTOK_while, RugoTOK_0021, RugoTOK_0028, RugoTOK_002d, RugoTOK_003b, RugoTOK_005b, RugoTOK_007b, Rugofloat_lit, Rugoident, Rugointeger, Rugoraw_str_lit, Rugostr_lit:
 r = p.add(r, p.Body())
 goto state5
 }
@@ -2892,7 +3103,7 @@ return p.stop(r, accept, errorSet)
 //	State 4
 //		on  "end"
 //			shift and goto state 5
-//		on  "bench", "break", "case", "def", "embed", "false", "fn", "for", "if", "import", "next", "nil", "parallel", "rats", "require", "return", "sandbox", "spawn", "true", "try", "use", "while", '!', '(', '-', ';', '[', '{', float_lit, ident, integer, raw_str_lit, str_lit
+//		on  "bench", "break", "case", "def", "embed", "false", "fn", "for", "if", "import", "next", "nil", "parallel", "rats", "require", "require_optional", "return", "sandbox", "spawn", "true", "try", "use", "while", '!', '(', '-', ';', '[', '{', float_lit, ident, integer, raw_str_lit, str_lit
 //			call Body and goto state 6
 //	State 5
 //		Accept
@@ -2950,7 +3161,7 @@ switch Symbol(p.tok.Ch) {
 	case  RugoTOK_end:
 r = append(r, p.shift())
 goto state5
-	case  RugoTOK_bench, RugoTOK_break, RugoTOK_case, RugoTOK_def, RugoTOK_embed, RugoTOK_false, RugoTOK_fn, RugoTOK_for, RugoTOK_if, RugoTOK_import, RugoTOK_next, RugoTOK_nil, RugoTOK_parallel, RugoTOK_rats, RugoTOK_require, RugoTOK_return, RugoTOK_sandbox, RugoTOK_spawn, RugoTOK_true, RugoTOK_try, RugoTOK_use, RugoTOK_while, RugoTOK_0021, RugoTOK_0028, RugoTOK_002d, RugoTOK_003b, RugoTOK_005b, RugoTOK_007b, Rugofloat_lit, Rugoident, Rugointeger, Rugoraw_str_lit, Rugostr_lit:
+	case  RugoTOK_bench, RugoTOK_break, RugoTOK_case, RugoTOK_def, RugoTOK_embed, RugoTOK_false, RugoTOK_fn, RugoTOK_for, RugoTOK_if, RugoTOK_import, RugoTOK_next, RugoTOK_nil, RugoTOK_parallel, RugoTOK_rats, RugoTOK_require, RugoTOK_require_optional, RugoTOK_return, RugoTOK_sandbox, RugoTOK_spawn, RugoTOK_true, RugoTOK_try, RugoTOK_use, RugoTOK_while, RugoTOK_0021, RugoTOK_0028, RugoTOK_002d, RugoTOK_003b, RugoTOK_005b, RugoTOK_007b, Rugofloat_lit, Rugoident, Rugointeger, Rugoraw_str_lit, Rugostr_lit:
 r = p.add(r, p.Body())
 goto state6
 }
@@ -3007,7 +3218,7 @@ return p.stop(r, accept, errorSet)
 //	State 4
 //		on  "end"
 //			shift and goto state 5
-//		on  "bench", "break", "case", "def", "embed", "false", "fn", "for", "if", "import", "next", "nil", "parallel", "rats", "require", "return", "sandbox", "spawn", "true", "try", "use", "while", '!', '(', '-', ';', '[', '{', float_lit, ident, integer, raw_str_lit, str_lit
+//		on  "bench", "break", "case", "def", "embed", "false", "fn", "for", "if", "import", "next", "nil", "parallel", "rats", "require", "require_optional", "return", "sandbox", "spawn", "true", "try", "use", "while", '!', '(', '-', ';', '[', '{', float_lit, ident, integer, raw_str_lit, str_lit
 //			call Body and goto state 6
 //	State 5
 //		Accept
@@ -3062,7 +3273,7 @@ switch Symbol(p.tok.Ch) {
 	case  RugoTOK_end:
 r = append(r, p.shift())
 goto state5
-	case  RugoTOK_bench, RugoTOK_break, RugoTOK_case, RugoTOK_def, RugoTOK_embed, RugoTOK_false, RugoTOK_fn, RugoTOK_for, RugoTOK_if, RugoTOK_import, RugoTOK_next, RugoTOK_nil, RugoTOK_parallel, RugoTOK_rats, RugoTOK_require, RugoTOK_return, RugoTOK_sandbox, RugoTOK_spawn, RugoTOK_true, RugoTOK_try, RugoTOK_use, RugoTOK_while, RugoTOK_0021, RugoTOK_0028, RugoTOK_002d, RugoTOK_003b, RugoTOK_005b, RugoTOK_007b, Rugofloat_lit, Rugoident, Rugointeger, Rugoraw_str_lit, Rugostr_lit:
+	case  RugoTOK_bench, RugoTOK_break, RugoTOK_case, RugoTOK_def, RugoTOK_embed, RugoTOK_false, RugoTOK_fn, RugoTOK_for, RugoTOK_if, RugoTOK_import, RugoTOK_next, RugoTOK_nil, RugoTOK_parallel, RugoTOK_rats, RugoTOK_require, RugoTOK_require_optional, RugoTOK_return, RugoTOK_sandbox, RugoTOK_spawn, RugoTOK_true, RugoTOK_try, RugoTOK_use, RugoTOK_while, RugoTOK_0021, RugoTOK_0028, RugoTOK_002d, RugoTOK_003b, RugoTOK_005b, RugoTOK_007b, Rugofloat_lit, Rugoident, Rugointeger, Rugoraw_str_lit, Rugostr_lit:
 r = p.add(r, p.Body())
 goto state6
 }
@@ -3228,12 +3439,12 @@ return p.stop(r, accept, errorSet)
 //			shift and goto state 3
 //		on  "end"
 //			shift and goto state 4
-//		on  "bench", "break", "case", "def", "embed", "false", "fn", "for", "if", "import", "next", "nil", "parallel", "rats", "require", "return", "sandbox", "spawn", "true", "try", "use", "while", '!', '(', '-', ';', '[', '{', float_lit, ident, integer, raw_str_lit, str_lit
+//		on  "bench", "break", "case", "def", "embed", "false", "fn", "for", "if", "import", "next", "nil", "parallel", "rats", "require", "require_optional", "return", "sandbox", "spawn", "true", "try", "use", "while", '!', '(', '-', ';', '[', '{', float_lit, ident, integer, raw_str_lit, str_lit
 //			call Body and goto state 6
 //	State 3
 //		on  "end"
 //			shift and goto state 4
-//		on  "bench", "break", "case", "def", "embed", "false", "fn", "for", "if", "import", "next", "nil", "parallel", "rats", "require", "return", "sandbox", "spawn", "true", "try", "use", "while", '!', '(', '-', ';', '[', '{', float_lit, ident, integer, raw_str_lit, str_lit
+//		on  "bench", "break", "case", "def", "embed", "false", "fn", "for", "if", "import", "next", "nil", "parallel", "rats", "require", "require_optional", "return", "sandbox", "spawn", "true", "try", "use", "while", '!', '(', '-', ';', '[', '{', float_lit, ident, integer, raw_str_lit, str_lit
 //			call Body and goto state 5
 //	State 4
 //		Accept
@@ -3279,7 +3490,7 @@ goto state3
 	case  RugoTOK_end:
 r = append(r, p.shift())
 goto state4
-	case  RugoTOK_bench, RugoTOK_break, RugoTOK_case, RugoTOK_def, RugoTOK_embed, RugoTOK_false, RugoTOK_fn, RugoTOK_for, RugoTOK_if, RugoTOK_import, RugoTOK_next, RugoTOK_nil, RugoTOK_parallel, RugoTOK_rats, RugoTOK_require, RugoTOK_return, RugoTOK_sandbox, RugoTOK_spawn, RugoTOK_true, RugoTOK_try, RugoTOK_use, RugoTOK_while, RugoTOK_0021, RugoTOK_0028, RugoTOK_002d, RugoTOK_003b, RugoTOK_005b, RugoTOK_007b, Rugofloat_lit, Rugoident, Rugointeger, Rugoraw_str_lit, Rugostr_lit:
+	case  RugoTOK_bench, RugoTOK_break, RugoTOK_case, RugoTOK_def, RugoTOK_embed, RugoTOK_false, RugoTOK_fn, RugoTOK_for, RugoTOK_if, RugoTOK_import, RugoTOK_next, RugoTOK_nil, RugoTOK_parallel, RugoTOK_rats, RugoTOK_require, RugoTOK_require_optional, RugoTOK_return, RugoTOK_sandbox, RugoTOK_spawn, RugoTOK_true, RugoTOK_try, RugoTOK_use, RugoTOK_while, RugoTOK_0021, RugoTOK_0028, RugoTOK_002d, RugoTOK_003b, RugoTOK_005b, RugoTOK_007b, Rugofloat_lit, Rugoident, Rugointeger, Rugoraw_str_lit, Rugostr_lit:
 r = p.add(r, p.Body())
 goto state6
 }
@@ -3290,7 +3501,7 @@ switch Symbol(p.tok.Ch) {
 	case  RugoTOK_end:
 r = append(r, p.shift())
 goto state4
-	case  RugoTOK_bench, RugoTOK_break, RugoTOK_case, RugoTOK_def, RugoTOK_embed, RugoTOK_false, RugoTOK_fn, RugoTOK_for, RugoTOK_if, RugoTOK_import, RugoTOK_next, RugoTOK_nil, RugoTOK_parallel, RugoTOK_rats, RugoTOK_require, RugoTOK_return, RugoTOK_sandbox, RugoTOK_spawn, RugoTOK_true, RugoTOK_try, RugoTOK_use, RugoTOK_while, RugoTOK_0021, RugoTOK_0028, RugoTOK_002d, RugoTOK_003b, RugoTOK_005b, RugoTOK_007b, Rugofloat_lit, Rugoident, Rugointeger, Rugoraw_str_lit, Rugostr_lit:
+	case  RugoTOK_bench, RugoTOK_break, RugoTOK_case, RugoTOK_def, RugoTOK_embed, RugoTOK_false, RugoTOK_fn, RugoTOK_for, RugoTOK_if, RugoTOK_import, RugoTOK_next, RugoTOK_nil, RugoTOK_parallel, RugoTOK_rats, RugoTOK_require, RugoTOK_require_optional, RugoTOK_return, RugoTOK_sandbox, RugoTOK_spawn, RugoTOK_true, RugoTOK_try, RugoTOK_use, RugoTOK_while, RugoTOK_0021, RugoTOK_0028, RugoTOK_002d, RugoTOK_003b, RugoTOK_005b, RugoTOK_007b, Rugofloat_lit, Rugoident, Rugointeger, Rugoraw_str_lit, Rugostr_lit:
 r = p.add(r, p.Body())
 goto state5
 }
@@ -3395,7 +3606,7 @@ return p.stop(r, accept, errorSet)
 //			call UnaryExpr and goto state 1
 //	State 1
 //		Accept
-//		on  '%', '*', '/'
+//		on  "<<", ">>", '%', '&', '*', '/'
 //			shift and goto state 0
 // MulExpr is used internally from Parse.
 func (p *Parser) MulExpr() (r []int32) {
@@ -3412,7 +3623,7 @@ return p.stop(r, accept, errorSet)
 state1:
 accept, errorSet = true, 24
 switch Symbol(p.tok.Ch) {
-	case  RugoTOK_0025, RugoTOK_002a, RugoTOK_002f:
+	case  RugoTOK_003c003c, RugoTOK_003e003e, RugoTOK_0025, RugoTOK_0026, RugoTOK_002a, RugoTOK_002f:
 r = append(r, p.shift())
 goto state0
 }
@@ -3494,7 +3705,7 @@ return p.stop(r, accept, errorSet)
 //	State 1
 //		on  "end"
 //			shift and goto state 2
-//		on  "bench", "break", "case", "def", "embed", "false", "fn", "for", "if", "import", "next", "nil", "parallel", "rats", "require", "return", "sandbox", "spawn", "true", "try", "use", "while", '!', '(', '-', ';', '[', '{', float_lit, ident, integer, raw_str_lit, str_lit
+//		on  "bench", "break", "case", "def", "embed", "false", "fn", "for", "if", "import", "next", "nil", "parallel", "rats", "require", "require_optional", "return", "sandbox", "spawn", "true", "try", "use", "while", '!', '(', '-', ';', '[', '{', float_lit, ident, integer, raw_str_lit, str_lit
 //			call Body and goto state 3
 //	State 2
 //		Accept
@@ -3519,7 +3730,7 @@ switch Symbol(p.tok.Ch) {
 	case  RugoTOK_end:
 r = append(r, p.shift())
 goto state2
-	case  RugoTOK_bench, RugoTOK_break, RugoTOK_case, RugoTOK_def, RugoTOK_embed, RugoTOK_false, RugoTOK_fn, RugoTOK_for, RugoTOK_if, RugoTOK_import, RugoTOK_next, RugoTOK_nil, RugoTOK_parallel, RugoTOK_rats, RugoTOK_require, RugoTOK_return, RugoTOK_sandbox, RugoTOK_spawn, RugoTOK_true, RugoTOK_try, RugoTOK_use, RugoTOK_while, RugoTOK_0021, RugoTOK_0028, RugoTOK_002d, RugoTOK_003b, RugoTOK_005b, RugoTOK_007b, Rugofloat_lit, Rugoident, Rugointeger, Rugoraw_str_lit, Rugostr_lit:
+	case  RugoTOK_bench, RugoTOK_break, RugoTOK_case, RugoTOK_def, RugoTOK_embed, RugoTOK_false, RugoTOK_fn, RugoTOK_for, RugoTOK_if, RugoTOK_import, RugoTOK_next, RugoTOK_nil, RugoTOK_parallel, RugoTOK_rats, RugoTOK_require, RugoTOK_require_optional, RugoTOK_return, RugoTOK_sandbox, RugoTOK_spawn, RugoTOK_true, RugoTOK_try, RugoTOK_use, RugoTOK_while, RugoTOK_0021, RugoTOK_0028, RugoTOK_002d, RugoTOK_003b, RugoTOK_005b, RugoTOK_007b, Rugofloat_lit, Rugoident, Rugointeger, Rugoraw_str_lit, Rugostr_lit:
 r = p.add(r, p.Body())
 goto state3
 }
@@ -3781,13 +3992,13 @@ return p.stop(r, accept, errorSet)
 //		Accept
 //		on  ';'
 //			shift and goto state 1
-//		on  "bench", "break", "case", "def", "embed", "false", "fn", "for", "if", "import", "next", "nil", "parallel", "rats", "require", "return", "sandbox", "spawn", "true", "try", "use", "while", '!', '(', '-', '[', '{', float_lit, ident, integer, raw_str_lit, str_lit
+//		on  "bench", "break", "case", "def", "embed", "false", "fn", "for", "if", "import", "next", "nil", "parallel", "rats", "require", "require_optional", "return", "sandbox", "spawn", "true", "try", "use", "while", '!', '(', '-', '[', '{', float_lit, ident, integer, raw_str_lit, str_lit
 //			call Statement and goto state 1
 //	State 1
 //		Accept
 //		on  ';'
 //			shift and goto state 1
-//		on  "bench", "break", "case", "def", "embed", "false", "fn", "for", "if", "import", "next", "nil", "parallel", "rats", "require", "return", "sandbox", "spawn", "true", "try", "use", "while", '!', '(', '-', '[', '{', float_lit, ident, integer, raw_str_lit, str_lit
+//		on  "bench", "break", "case", "def", "embed", "false", "fn", "for", "if", "import", "next", "nil", "parallel", "rats", "require", "require_optional", "return", "sandbox", "spawn", "true", "try", "use", "while", '!', '(', '-', '[', '{', float_lit, ident, integer, raw_str_lit, str_lit
 //			call Statement and goto state 1
 // Program is used internally from Parse.
 func (p *Parser) Program() (r []int32) {
@@ -3799,7 +4010,7 @@ switch Symbol(p.tok.Ch) {
 	case  RugoTOK_003b:
 r = append(r, p.shift())
 goto state1
-	case  RugoTOK_bench, RugoTOK_break, RugoTOK_case, RugoTOK_def, RugoTOK_embed, RugoTOK_false, RugoTOK_fn, RugoTOK_for, RugoTOK_if, RugoTOK_import, RugoTOK_next, RugoTOK_nil, RugoTOK_parallel, RugoTOK_rats, RugoTOK_require, RugoTOK_return, RugoTOK_sandbox, RugoTOK_spawn, RugoTOK_true, RugoTOK_try, RugoTOK_use, RugoTOK_while, RugoTOK_0021, RugoTOK_0028, RugoTOK_002d, RugoTOK_005b, RugoTOK_007b, Rugofloat_lit, Rugoident, Rugointeger, Rugoraw_str_lit, Rugostr_lit:
+	case  RugoTOK_bench, RugoTOK_break, RugoTOK_case, RugoTOK_def, RugoTOK_embed, RugoTOK_false, RugoTOK_fn, RugoTOK_for, RugoTOK_if, RugoTOK_import, RugoTOK_next, RugoTOK_nil, RugoTOK_parallel, RugoTOK_rats, RugoTOK_require, RugoTOK_require_optional, RugoTOK_return, RugoTOK_sandbox, RugoTOK_spawn, RugoTOK_true, RugoTOK_try, RugoTOK_use, RugoTOK_while, RugoTOK_0021, RugoTOK_0028, RugoTOK_002d, RugoTOK_005b, RugoTOK_007b, Rugofloat_lit, Rugoident, Rugointeger, Rugoraw_str_lit, Rugostr_lit:
 r = p.add(r, p.Statement())
 goto state1
 }
@@ -3810,7 +4021,7 @@ switch Symbol(p.tok.Ch) {
 	case  RugoTOK_003b:
 r = append(r, p.shift())
 goto state1
-	case  RugoTOK_bench, RugoTOK_break, RugoTOK_case, RugoTOK_def, RugoTOK_embed, RugoTOK_false, RugoTOK_fn, RugoTOK_for, RugoTOK_if, RugoTOK_import, RugoTOK_next, RugoTOK_nil, RugoTOK_parallel, RugoTOK_rats, RugoTOK_require, RugoTOK_return, RugoTOK_sandbox, RugoTOK_spawn, RugoTOK_true, RugoTOK_try, RugoTOK_use, RugoTOK_while, RugoTOK_0021, RugoTOK_0028, RugoTOK_002d, RugoTOK_005b, RugoTOK_007b, Rugofloat_lit, Rugoident, Rugointeger, Rugoraw_str_lit, Rugostr_lit:
+	case  RugoTOK_bench, RugoTOK_break, RugoTOK_case, RugoTOK_def, RugoTOK_embed, RugoTOK_false, RugoTOK_fn, RugoTOK_for, RugoTOK_if, RugoTOK_import, RugoTOK_next, RugoTOK_nil, RugoTOK_parallel, RugoTOK_rats, RugoTOK_require, RugoTOK_require_optional, RugoTOK_return, RugoTOK_sandbox, RugoTOK_spawn, RugoTOK_true, RugoTOK_try, RugoTOK_use, RugoTOK_while, RugoTOK_0021, RugoTOK_0028, RugoTOK_002d, RugoTOK_005b, RugoTOK_007b, Rugofloat_lit, Rugoident, Rugointeger, Rugoraw_str_lit, Rugostr_lit:
 r = p.add(r, p.Statement())
 goto state1
 }
@@ -3828,10 +4039,10 @@ return r
 // RequireStmt grammar:
 //
 //	
-//	RequireStmt = "require" str_lit [ "as" ( str_lit | ident ) | "with" ident { ',' ident } ] .
+//	RequireStmt = ( "require" | "require_optional" ) str_lit [ "as" ( str_lit | ident ) | "with" ident { ',' ident } ] .
 //
 //	State 0
-//		on  "require"
+//		on  "require", "require_optional"
 //			shift and goto state 1
 //	State 1
 //		on  str_lit
@@ -3861,7 +4072,7 @@ func (p *Parser) RequireStmt() (r []int32) {
 // state0:
 accept, errorSet = false, 16
 switch Symbol(p.tok.Ch) {
-	case  RugoTOK_require:
+	case  RugoTOK_require, RugoTOK_require_optional:
 r = append(r, p.shift())
 goto state1
 }
@@ -4134,7 +4345,7 @@ return p.stop(r, accept, errorSet)
 //	State 1
 //		on  "end"
 //			shift and goto state 2
-//		on  "bench", "break", "case", "def", "embed", "false", "fn", "for", "if", "import", "next", "nil", "parallel", "rats", "require", "return", "sandbox", "spawn", "true", "try", "use", "while", '!', '(', '-', ';', '[', '{', float_lit, ident, integer, raw_str_lit, str_lit
+//		on  "bench", "break", "case", "def", "embed", "false", "fn", "for", "if", "import", "next", "nil", "parallel", "rats", "require", "require_optional", "return", "sandbox", "spawn", "true", "try", "use", "while", '!', '(', '-', ';', '[', '{', float_lit, ident, integer, raw_str_lit, str_lit
 //			call Body and goto state 3
 //	State 2
 //		Accept
@@ -4159,7 +4370,7 @@ switch Symbol(p.tok.Ch) {
 	case  RugoTOK_end:
 r = append(r, p.shift())
 goto state2
-	case  RugoTOK_bench, RugoTOK_break, RugoTOK_case, RugoTOK_def, RugoTOK_embed, RugoTOK_false, RugoTOK_fn, RugoTOK_for, RugoTOK_if, RugoTOK_import, RugoTOK_next, RugoTOK_nil, RugoTOK_parallel, RugoTOK_rats, RugoTOK_require, RugoTOK_return, RugoTOK_sandbox, RugoTOK_spawn, RugoTOK_true, RugoTOK_try, RugoTOK_use, RugoTOK_while, RugoTOK_0021, RugoTOK_0028, RugoTOK_002d, RugoTOK_003b, RugoTOK_005b, RugoTOK_007b, Rugofloat_lit, Rugoident, Rugointeger, Rugoraw_str_lit, Rugostr_lit:
+	case  RugoTOK_bench, RugoTOK_break, RugoTOK_case, RugoTOK_def, RugoTOK_embed, RugoTOK_false, RugoTOK_fn, RugoTOK_for, RugoTOK_if, RugoTOK_import, RugoTOK_next, RugoTOK_nil, RugoTOK_parallel, RugoTOK_rats, RugoTOK_require, RugoTOK_require_optional, RugoTOK_return, RugoTOK_sandbox, RugoTOK_spawn, RugoTOK_true, RugoTOK_try, RugoTOK_use, RugoTOK_while, RugoTOK_0021, RugoTOK_0028, RugoTOK_002d, RugoTOK_003b, RugoTOK_005b, RugoTOK_007b, Rugofloat_lit, Rugoident, Rugointeger, Rugoraw_str_lit, Rugostr_lit:
 r = p.add(r, p.Body())
 goto state3
 }
@@ -4204,7 +4415,7 @@ return p.stop(r, accept, errorSet)
 //			call UseStmt and goto state 1
 //		on  "import"
 //			call ImportStmt and goto state 1
-//		on  "require"
+//		on  "require", "require_optional"
 //			call RequireStmt and goto state 1
 //		on  "embed"
 //			call EmbedStmt and goto state 1
@@ -4245,7 +4456,7 @@ goto state1
 	case  RugoTOK_import:
 r = p.add(r, p.ImportStmt())
 goto state1
-	case  RugoTOK_require:
+	case  RugoTOK_require, RugoTOK_require_optional:
 r = p.add(r, p.RequireStmt())
 goto state1
 	case  RugoTOK_embed:
@@ -4431,7 +4642,7 @@ return p.stop(r, accept, errorSet)
 //	State 2
 //		on  "end"
 //			shift and goto state 3
-//		on  "bench", "break", "case", "def", "embed", "false", "fn", "for", "if", "import", "next", "nil", "parallel", "rats", "require", "return", "sandbox", "spawn", "true", "try", "use", "while", '!', '(', '-', ';', '[', '{', float_lit, ident, integer, raw_str_lit, str_lit
+//		on  "bench", "break", "case", "def", "embed", "false", "fn", "for", "if", "import", "next", "nil", "parallel", "rats", "require", "require_optional", "return", "sandbox", "spawn", "true", "try", "use", "while", '!', '(', '-', ';', '[', '{', float_lit, ident, integer, raw_str_lit, str_lit
 //			call Body and goto state 4
 //	State 3
 //		Accept
@@ -4464,7 +4675,7 @@ switch Symbol(p.tok.Ch) {
 	case  RugoTOK_end:
 r = append(r, p.shift())
 goto state3
-	case  RugoTOK_bench, RugoTOK_break, RugoTOK_case, RugoTOK_def, RugoTOK_embed, RugoTOK_false, RugoTOK_fn, RugoTOK_for, RugoTOK_if, RugoTOK_import, RugoTOK_next, RugoTOK_nil, RugoTOK_parallel, RugoTOK_rats, RugoTOK_require, RugoTOK_return, RugoTOK_sandbox, RugoTOK_spawn, RugoTOK_true, RugoTOK_try, RugoTOK_use, RugoTOK_while, RugoTOK_0021, RugoTOK_0028, RugoTOK_002d, RugoTOK_003b, RugoTOK_005b, RugoTOK_007b, Rugofloat_lit, Rugoident, Rugointeger, Rugoraw_str_lit, Rugostr_lit:
+	case  RugoTOK_bench, RugoTOK_break, RugoTOK_case, RugoTOK_def, RugoTOK_embed, RugoTOK_false, RugoTOK_fn, RugoTOK_for, RugoTOK_if, RugoTOK_import, RugoTOK_next, RugoTOK_nil, RugoTOK_parallel, RugoTOK_rats, RugoTOK_require, RugoTOK_require_optional, RugoTOK_return, RugoTOK_sandbox, RugoTOK_spawn, RugoTOK_true, RugoTOK_try, RugoTOK_use, RugoTOK_while, RugoTOK_0021, RugoTOK_0028, RugoTOK_002d, RugoTOK_003b, RugoTOK_005b, RugoTOK_007b, Rugofloat_lit, Rugoident, Rugointeger, Rugoraw_str_lit, Rugostr_lit:
 r = p.add(r, p.Body())
 goto state4
 }
@@ -4487,7 +4698,7 @@ return p.stop(r, accept, errorSet)
 //
 //	
 //	
-//	TryExpr      = "try" Expr "or" ident Body "end" .
+//	TryExpr      = "try" Expr "or" ident Body [ "ensure" Body ] "end" .
 //
 //	State 0
 //		on  "try"
@@ -4504,13 +4715,25 @@ return p.stop(r, accept, errorSet)
 //	State 4
 //		on  "end"
 //			shift and goto state 5
-//		on  "bench", "break", "case", "def", "embed", "false", "fn", "for", "if", "import", "next", "nil", "parallel", "rats", "require", "return", "sandbox", "spawn", "true", "try", "use", "while", '!', '(', '-', ';', '[', '{', float_lit, ident, integer, raw_str_lit, str_lit
+//		on  "ensure"
+//			shift and goto state 7
+//		on  "bench", "break", "case", "def", "embed", "false", "fn", "for", "if", "import", "next", "nil", "parallel", "rats", "require", "require_optional", "return", "sandbox", "spawn", "true", "try", "use", "while", '!', '(', '-', ';', '[', '{', float_lit, ident, integer, raw_str_lit, str_lit
 //			call Body and goto state 6
 //	State 5
 //		Accept
 //	State 6
 //		on  "end"
 //			shift and goto state 5
+//		on  "ensure"
+//			shift and goto state 7
+//	State 7
+//		on  "end"
+//			shift and goto state 5
+//		on  "bench", "break", "case", "def", "embed", "false", "fn", "for", "if", "import", "next", "nil", "parallel", "rats", "require", "require_optional", "return", "sandbox", "spawn", "true", "try", "use", "while", '!', '(', '-', ';', '[', '{', float_lit, ident, integer, raw_str_lit, str_lit
+//			call Body and goto state 8
+//	State 8
+//		on  "end"
+//			shift and goto state 5
 // TryExpr is used internally from Parse.
 func (p *Parser) TryExpr() (r []int32) {
 		accept, errorSet := false, 0
@@ -4548,12 +4771,15 @@ goto state4
 }
 return p.stop(r, accept, errorSet)
 state4:
-accept, errorSet = false, 41
+accept, errorSet = false, 72
 switch Symbol(p.tok.Ch) {
 	case  RugoTOK_end:
 r = append(r, p.shift())
 goto state5
-	case  RugoTOK_bench, RugoTOK_break, RugoTOK_case, RugoTOK_def, RugoTOK_embed, RugoTOK_false, RugoTOK_fn, RugoTOK_for, RugoTOK_if, RugoTOK_import, RugoTOK_next, RugoTOK_nil, RugoTOK_parallel, RugoTOK_rats, RugoTOK_require, RugoTOK_return, RugoTOK_sandbox, RugoTOK_spawn, RugoTOK_true, RugoTOK_try, RugoTOK_use, RugoTOK_while, RugoTOK_0021, RugoTOK_0028, RugoTOK_002d, RugoTOK_003b, RugoTOK_005b, RugoTOK_007b, Rugofloat_lit, Rugoident, Rugointeger, Rugoraw_str_lit, Rugostr_lit:
+	case  RugoTOK_ensure:
+r = append(r, p.shift())
+goto state7
+	case  RugoTOK_bench, RugoTOK_break, RugoTOK_case, RugoTOK_def, RugoTOK_embed, RugoTOK_false, RugoTOK_fn, RugoTOK_for, RugoTOK_if, RugoTOK_import, RugoTOK_next, RugoTOK_nil, RugoTOK_parallel, RugoTOK_rats, RugoTOK_require, RugoTOK_require_optional, RugoTOK_return, RugoTOK_sandbox, RugoTOK_spawn, RugoTOK_true, RugoTOK_try, RugoTOK_use, RugoTOK_while, RugoTOK_0021, RugoTOK_0028, RugoTOK_002d, RugoTOK_003b, RugoTOK_005b, RugoTOK_007b, Rugofloat_lit, Rugoident, Rugointeger, Rugoraw_str_lit, Rugostr_lit:
 r = p.add(r, p.Body())
 goto state6
 }
@@ -4562,6 +4788,28 @@ state5:
 accept, errorSet = true, 0
 return p.stop(r, accept, errorSet)
 state6:
+accept, errorSet = false, 73
+switch Symbol(p.tok.Ch) {
+	case  RugoTOK_end:
+r = append(r, p.shift())
+goto state5
+	case  RugoTOK_ensure:
+r = append(r, p.shift())
+goto state7
+}
+return p.stop(r, accept, errorSet)
+state7:
+accept, errorSet = false, 41
+switch Symbol(p.tok.Ch) {
+	case  RugoTOK_end:
+r = append(r, p.shift())
+goto state5
+	case  RugoTOK_bench, RugoTOK_break, RugoTOK_case, RugoTOK_def, RugoTOK_embed, RugoTOK_false, RugoTOK_fn, RugoTOK_for, RugoTOK_if, RugoTOK_import, RugoTOK_next, RugoTOK_nil, RugoTOK_parallel, RugoTOK_rats, RugoTOK_require, RugoTOK_require_optional, RugoTOK_return, RugoTOK_sandbox, RugoTOK_spawn, RugoTOK_true, RugoTOK_try, RugoTOK_use, RugoTOK_while, RugoTOK_0021, RugoTOK_0028, RugoTOK_002d, RugoTOK_003b, RugoTOK_005b, RugoTOK_007b, Rugofloat_lit, Rugoident, Rugointeger, Rugoraw_str_lit, Rugostr_lit:
+r = p.add(r, p.Body())
+goto state8
+}
+return p.stop(r, accept, errorSet)
+state8:
 accept, errorSet = false, 1
 switch Symbol(p.tok.Ch) {
 	case  RugoTOK_end:
@@ -4620,7 +4868,7 @@ return p.stop(r, accept, errorSet)
 //
 //	
 //	
-//	UseStmt     = "use" str_lit .
+//	UseStmt     = "use" str_lit [ "as" ident ] .
 //
 //	State 0
 //		on  "use"
@@ -4630,6 +4878,13 @@ return p.stop(r, accept, errorSet)
 //			shift and goto state 2
 //	State 2
 //		Accept
+//		on  "as"
+//			shift and goto state 3
+//	State 3
+//		on  ident
+//			shift and goto state 4
+//	State 4
+//		Accept
 // UseStmt is used internally from Parse.
 func (p *Parser) UseStmt() (r []int32) {
 		accept, errorSet := false, 0
@@ -4651,6 +4906,22 @@ goto state2
 }
 return p.stop(r, accept, errorSet)
 state2:
+accept, errorSet = true, 40
+switch Symbol(p.tok.Ch) {
+	case  RugoTOK_as:
+r = append(r, p.shift())
+goto state3
+}
+return p.stop(r, accept, errorSet)
+state3:
+accept, errorSet = false, 48
+switch Symbol(p.tok.Ch) {
+	case  Rugoident:
+r = append(r, p.shift())
+goto state4
+}
+return p.stop(r, accept, errorSet)
+state4:
 accept, errorSet = true, 0
 return p.stop(r, accept, errorSet)
 }
@@ -4670,7 +4941,7 @@ return p.stop(r, accept, errorSet)
 //	State 2
 //		on  "end"
 //			shift and goto state 3
-//		on  "bench", "break", "case", "def", "embed", "false", "fn", "for", "if", "import", "next", "nil", "parallel", "rats", "require", "return", "sandbox", "spawn", "true", "try", "use", "while", '!', '(', '-', ';', '[', '{', float_lit, ident, integer, raw_str_lit, str_lit
+//		on  "bench", "break", "case", "def", "embed", "false", "fn", "for", "if", "import", "next", "nil", "parallel", "rats", "require", "require_optional", "return", "sandbox", "spawn", "true", "try", "use", "while", '!', '(', '-', ';', '[', '{', float_lit, ident, integer, raw_str_lit, str_lit
 //			call Body and goto state 4
 //	State 3
 //		Accept
@@ -4703,7 +4974,7 @@ switch Symbol(p.tok.Ch) {
 	case  RugoTOK_end:
 r = append(r, p.shift())
 goto state3
-	case  RugoTOK_bench, RugoTOK_break, RugoTOK_case, RugoTOK_def, RugoTOK_embed, RugoTOK_false, RugoTOK_fn, RugoTOK_for, RugoTOK_if, RugoTOK_import, RugoTOK_next, RugoTOK_nil, RugoTOK_parallel, RugoTOK_rats, RugoTOK_require, RugoTOK_return, RugoTOK_sandbox, RugoTOK_spawn, RugoTOK_true, RugoTOK_try, RugoTOK_use, RugoTOK_while, RugoTOK_0021, RugoTOK_0028, RugoTOK_002d, RugoTOK_003b, RugoTOK_005b, RugoTOK_007b, Rugofloat_lit, Rugoident, Rugointeger, Rugoraw_str_lit, Rugostr_lit:
+	case  RugoTOK_bench, RugoTOK_break, RugoTOK_case, RugoTOK_def, RugoTOK_embed, RugoTOK_false, RugoTOK_fn, RugoTOK_for, RugoTOK_if, RugoTOK_import, RugoTOK_next, RugoTOK_nil, RugoTOK_parallel, RugoTOK_rats, RugoTOK_require, RugoTOK_require_optional, RugoTOK_return, RugoTOK_sandbox, RugoTOK_spawn, RugoTOK_true, RugoTOK_try, RugoTOK_use, RugoTOK_while, RugoTOK_0021, RugoTOK_0028, RugoTOK_002d, RugoTOK_003b, RugoTOK_005b, RugoTOK_007b, Rugofloat_lit, Rugoident, Rugointeger, Rugoraw_str_lit, Rugostr_lit:
 r = p.add(r, p.Body())
 goto state4
 }
@@ -0,0 +1,91 @@
+package preprocess
+
+import "fmt"
+
+// BlockError reports an unbalanced block: an opener that is never closed,
+// or an `end` with no opener to close.
+type BlockError struct {
+	Keyword string // the unclosed opener, or "end" for a stray end
+	Line    int    // 1-based line of Keyword in the checked source
+	Col     int    // 1-based column of Keyword
+}
+
+func (e *BlockError) Error() string {
+	if e.Keyword == "end" {
+		return "unexpected \"end\" — no matching block to close (def, if, while, for, etc.)"
+	}
+	return fmt.Sprintf("%q at line %d is never closed — missing \"end\"", e.Keyword, e.Line)
+}
+
+// CheckBlocks matches the block openers in blockOpenerKeywords against
+// `end` keywords in preprocessed source and returns the first imbalance,
+// or nil when every block is closed. Strings and method names (x.end) are
+// ignored.
+//
+// When blocks are unclosed, indentation picks the culprit: an opener whose
+// `end` sits at a shallower indent than the opener line most likely lost its
+// own `end` and borrowed an outer block's one.
+func CheckBlocks(src string) *BlockError {
+	type opener struct {
+		keyword    string
+		line, col  int
+		indent     int
+		lineLeader bool // opener is the first word on its line
+	}
+	var stack []opener
+	var suspect *opener
+
+	lineStart, indent := 0, 0
+	st := NewStringTracker(src)
+	for ch, ok := st.Next(); ok; ch, ok = st.Next() {
+		pos := st.Pos()
+		if ch == '\n' {
+			lineStart = pos + 1
+			continue
+		}
+		if pos == lineStart {
+			indent = 0
+			for lineStart+indent < len(src) && (src[lineStart+indent] == ' ' || src[lineStart+indent] == '\t') {
+				indent++
+			}
+		}
+		if st.InString() || !isIdentByte(ch) {
+			continue
+		}
+		if pos > 0 && (isIdentByte(src[pos-1]) || src[pos-1] == '.') {
+			continue
+		}
+		end := pos
+		for end < len(src) && isIdentByte(src[end]) {
+			end++
+		}
+		word := src[pos:end]
+		st.Skip(end - pos - 1)
+
+		leader := pos == lineStart+indent
+		switch {
+		case word == "end":
+			if len(stack) == 0 {
+				return &BlockError{Keyword: "end", Line: st.Line(), Col: pos - lineStart + 1}
+			}
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if suspect == nil && leader && top.lineLeader && indent < top.indent {
+				suspect = &top
+			}
+		case blockOpenerKeywords[word]:
+			stack = append(stack, opener{
+				keyword: word, line: st.Line(), col: pos - lineStart + 1,
+				indent: indent, lineLeader: leader,
+			})
+		}
+	}
+	if len(stack) == 0 {
+		return nil
+	}
+	b := stack[len(stack)-1]
+	if suspect != nil {
+		b = *suspect
+	}
+	return &BlockError{Keyword: b.keyword, Line: b.line, Col: b.col}
+}
@@ -0,0 +1,47 @@
+package preprocess
+
+import "testing"
+
+func TestCheckBlocks(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		keyword string // "" means balanced
+		line    int
+	}{
+		{"balanced", "def a()\n  if x\n    puts(1)\n  end\nend\n", "", 0},
+		{"inline fn", "f = fn(x) x + 1 end\n", "", 0},
+		{"keywords in strings", "puts(\"if end def\")\nputs('while')\n", "", 0},
+		{"method named end", "r.end\nx.if_set\n", "", 0},
+		{"identifiers containing keywords", "endpoint = 1\nifx = define\n", "", 0},
+		{"unclosed at eof", "x = 1\nwhile x\n  puts(x)\n", "while", 2},
+		{"stray end", "def a()\nend\nend\n", "end", 3},
+		{"indentation blames inner block", "def a()\n  if x\n    puts(1)\nend\n\ndef b()\nend\n", "if", 2},
+		{"innermost unclosed without indentation hint", "def a()\nif x\nputs(1)\nend\n", "def", 1},
+		{"try ensure", "x = try f() or e\n  0\nensure\n  puts(1)\nend\n", "", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			be := CheckBlocks(tt.input)
+			if tt.keyword == "" {
+				if be != nil {
+					t.Fatalf("expected balanced, got %v at line %d", be, be.Line)
+				}
+				return
+			}
+			if be == nil {
+				t.Fatalf("expected %q at line %d, got balanced", tt.keyword, tt.line)
+			}
+			if be.Keyword != tt.keyword || be.Line != tt.line {
+				t.Errorf("got %q at line %d, want %q at line %d", be.Keyword, be.Line, tt.keyword, tt.line)
+			}
+		})
+	}
+}
+
+func TestBlockErrorMessage(t *testing.T) {
+	be := &BlockError{Keyword: "if", Line: 12, Col: 3}
+	if got, want := be.Error(), `"if" at line 12 is never closed — missing "end"`; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
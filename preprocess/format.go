@@ -0,0 +1,526 @@
+package preprocess
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Format returns src in canonical form:
+//
+//   - blocks indent by two spaces per level, following the same block
+//     keywords as CheckBlocks (plus the `unless` and `with` sugar), with
+//     else/elsif/ensure/of and a try's `or` lined up with their opener
+//   - lines inside an unclosed (, [ or { indent one more level
+//   - binary operators get a single space on each side
+//   - trailing whitespace is trimmed and the file ends in one newline
+//
+// Comments, string literals and heredoc bodies are kept verbatim. Lines
+// that run as shell commands keep their spacing, since it is part of the
+// command. Formatting formatted source returns it unchanged. An `end`
+// without a block, or a block without `end`, is an error.
+func Format(src string) (string, error) {
+	lines := strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n")
+	funcs := ScanFuncDefs(src)
+	vars := scanFormatVars(lines)
+
+	var out []string
+	var stack []openBlock // innermost last
+	var brackets []int    // bracket depths that added an indent level
+	depth := 0            // unclosed ( [ { across lines
+	inBacktick := false
+	continued := false // the previous line ended with an operator
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if inBacktick {
+			// Inside a multi-line backtick command: keep it as written.
+			out = append(out, line)
+			inBacktick = endsInBacktick(line, true)
+			continue
+		}
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			out = append(out, "")
+			continue
+		}
+		code, comment := splitComment(trimmed)
+		code = strings.TrimRight(code, " \t")
+
+		// Indentation: one level inside the innermost block's opening line,
+		// or that line's level when closing or continuing the block, plus
+		// the bracket levels opened inside the block.
+		level, from := 0, 0
+		if len(stack) > 0 {
+			top := stack[len(stack)-1]
+			level, from = top.level+1, min(top.brackets, len(brackets))
+			if first := leadingWord(code); first == "end" || closesOrContinues(first, top.word) {
+				level = top.level
+			}
+		}
+		lead := leadingClosers(code)
+		for _, b := range brackets[from:] {
+			if b <= depth-lead {
+				level++
+			}
+		}
+		// Operator and method-chain continuation lines hang one level in.
+		if code != "" && (continued || code[0] == '.' && !strings.HasPrefix(code, "..")) {
+			level++
+		}
+
+		h, _, heredoc := findHeredocOpener(code)
+		if code != "" && !heredoc && !isShellLine(code, funcs, vars) {
+			code = spaceOperators(code)
+		}
+		formatted := code
+		if comment != "" {
+			if code == "" {
+				formatted = comment
+			} else {
+				formatted += commentGap(trimmed, comment) + comment
+			}
+		}
+		out = append(out, strings.Repeat("  ", level)+formatted)
+		if code != "" {
+			continued = continuedBy(code)
+		}
+
+		// Update block and bracket state for the following lines.
+		opened := false
+		for _, w := range lineBlockWords(code, lines, i) {
+			if w == "end" {
+				if len(stack) == 0 {
+					return "", fmt.Errorf("line %d: unexpected \"end\" — no matching block to close", i+1)
+				}
+				stack = stack[:len(stack)-1]
+				continue
+			}
+			stack = append(stack, openBlock{word: w, level: level, brackets: len(brackets)})
+			opened = true
+		}
+		before := depth
+		depth += bracketDelta(code)
+		if depth < 0 {
+			depth = 0
+		}
+		for len(brackets) > 0 && brackets[len(brackets)-1] > depth {
+			brackets = brackets[:len(brackets)-1]
+		}
+		// A line that opens a block inside brackets (foo(fn(x) ... end))
+		// is indented by the block alone.
+		if depth > before && !opened {
+			brackets = append(brackets, depth)
+		}
+
+		if heredoc {
+			// Copy the body and the closing delimiter verbatim.
+			for i+1 < len(lines) {
+				i++
+				out = append(out, lines[i])
+				if strings.TrimSpace(lines[i]) == h.delimiter {
+					break
+				}
+			}
+			continue
+		}
+		inBacktick = endsInBacktick(code, false)
+	}
+	if depth > 0 {
+		return "", fmt.Errorf("unclosed bracket — missing ), ] or }")
+	}
+	if len(stack) > 0 {
+		return "", fmt.Errorf("%q is never closed — missing \"end\"", stack[len(stack)-1].word)
+	}
+
+	for len(out) > 0 && out[len(out)-1] == "" {
+		out = out[:len(out)-1]
+	}
+	if len(out) == 0 {
+		return "", nil
+	}
+	return strings.Join(out, "\n") + "\n", nil
+}
+
+// openBlock is a block opened by Format: its keyword, the indent level of
+// the line that opened it, and how many bracket levels were open then.
+type openBlock struct {
+	word     string
+	level    int
+	brackets int
+}
+
+// closesOrContinues reports whether a line starting with first continues
+// the innermost open block (else, elsif, ensure, of, or a try's `or`).
+func closesOrContinues(first, open string) bool {
+	switch first {
+	case "else", "elsif", "ensure", "of":
+		return true
+	case "or":
+		return open == "try"
+	}
+	return false
+}
+
+// lineBlockWords returns the block openers and `end` keywords of a line of
+// code in order. Unlike blockWords it runs on unexpanded source, so it
+// skips postfix if/unless/while, one-line try and spawn forms, and a `do`
+// that does not end the line.
+func lineBlockWords(code string, lines []string, i int) []string {
+	var words []string
+	st := NewStringTracker(code)
+	for ch, ok := st.Next(); ok; ch, ok = st.Next() {
+		pos := st.Pos()
+		if !st.InCode() || !isIdentByte(ch) {
+			continue
+		}
+		end := pos
+		for end < len(code) && isIdentByte(code[end]) {
+			end++
+		}
+		st.Skip(end - pos - 1)
+		leader := pos == 0
+		if pos > 0 && (isIdentByte(code[pos-1]) || code[pos-1] == '.') {
+			continue
+		}
+		// Hash keys and keyword arguments (`end: 3`) are not keywords.
+		if end < len(code) && code[end] == ':' && (end+1 >= len(code) || code[end+1] != ':') {
+			continue
+		}
+		word := code[pos:end]
+		rest := strings.TrimSpace(code[end:])
+		switch word {
+		case "end":
+		case "if", "unless", "while":
+			prev := strings.TrimRight(code[:pos], " \t")
+			if !leader && (prev == "" || strings.IndexByte("=(,[", prev[len(prev)-1]) < 0) {
+				continue // postfix form
+			}
+		case "with":
+			if !leader {
+				continue
+			}
+		case "do":
+			if rest != "" {
+				continue
+			}
+		case "spawn":
+			if rest != "" {
+				continue // spawn EXPR one-liner
+			}
+		case "try":
+			if next, _ := scanFirstToken(rest); rest != "" && next != "spawn" && next != "parallel" {
+				orIdx := findTopLevelOr(rest)
+				if orIdx < 0 {
+					continue
+				}
+				tok, after := scanFirstToken(strings.TrimSpace(rest[orIdx+2:]))
+				if !isIdent(tok) || RugoKeywords[tok] || strings.TrimSpace(after) != "" || !tryBlockFollows(lines, i+1) {
+					continue // try EXPR or DEFAULT one-liner
+				}
+			}
+		default:
+			if !blockOpenerKeywords[word] {
+				continue
+			}
+		}
+		words = append(words, word)
+	}
+	return words
+}
+
+// leadingWord returns the identifier a line of code starts with, if any.
+func leadingWord(code string) string {
+	end := 0
+	for end < len(code) && isIdentByte(code[end]) {
+		end++
+	}
+	return code[:end]
+}
+
+// splitComment splits a trimmed line into its code and its # comment.
+func splitComment(line string) (code, comment string) {
+	st := NewStringTracker(line)
+	for ch, ok := st.Next(); ok; ch, ok = st.Next() {
+		if ch == '#' && st.InCode() {
+			return line[:st.Pos()], line[st.Pos():]
+		}
+	}
+	return line, ""
+}
+
+// commentGap returns the whitespace between the code and the trailing
+// comment of a trimmed line, so aligned comments stay aligned.
+func commentGap(trimmed, comment string) string {
+	code := trimmed[:len(trimmed)-len(comment)]
+	gap := code[len(strings.TrimRight(code, " \t")):]
+	if gap == "" {
+		return " "
+	}
+	return gap
+}
+
+// leadingClosers counts the closing brackets a line of code starts with.
+func leadingClosers(code string) int {
+	n := 0
+	for n < len(code) && IsCloseBracket(code[n]) {
+		n++
+	}
+	return n
+}
+
+// bracketDelta returns the net number of brackets a line of code opens.
+func bracketDelta(code string) int {
+	delta := 0
+	st := NewStringTracker(code)
+	for ch, ok := st.Next(); ok; ch, ok = st.Next() {
+		if !st.InCode() {
+			continue
+		}
+		if IsOpenBracket(ch) {
+			delta++
+		} else if IsCloseBracket(ch) {
+			delta--
+		}
+	}
+	return delta
+}
+
+// endsInBacktick reports whether a backtick command is still open at the
+// end of line. open is whether one was already open at its start.
+func endsInBacktick(line string, open bool) bool {
+	if open {
+		line = "`" + line
+	}
+	st := NewStringTracker(line)
+	for _, ok := st.Next(); ok; _, ok = st.Next() {
+	}
+	return st.inBt
+}
+
+// isShellLine reports whether a line of code may run a shell command,
+// using the same rules as the preprocessor. The expression of a one-line
+// try or spawn is checked on its own, and pipelines always count.
+func isShellLine(code string, funcs, vars map[string]bool) bool {
+	if FindTopLevel(code, func(ch byte, pos int, src string) bool {
+		return ch == '|' && (pos == 0 || src[pos-1] != '|') && (pos+1 >= len(src) || src[pos+1] != '|')
+	}) >= 0 {
+		return true
+	}
+	candidates := []string{code}
+	expr := code
+	if eq := strings.Index(expr, "= "); eq > 0 && isIdent(strings.TrimSpace(expr[:eq])) {
+		expr = strings.TrimSpace(expr[eq+1:])
+	}
+	for _, kw := range []string{"try ", "spawn "} {
+		if rest, ok := strings.CutPrefix(expr, kw); ok {
+			if orIdx := findTopLevelOr(rest); orIdx >= 0 {
+				rest = rest[:orIdx]
+			}
+			candidates = append(candidates, strings.TrimSpace(rest))
+		}
+	}
+	for _, c := range candidates {
+		if processed, _ := preprocessLine(c, funcs, vars); strings.Contains(processed, `__shell__("`) {
+			return true
+		}
+	}
+	return false
+}
+
+// continuedBy reports whether a line of code ends with a binary operator,
+// so the expression carries on to the next line.
+func continuedBy(code string) bool {
+	for _, op := range []string{"+", "-", "*", "/", "%", "&&", "||", "=", "<", ">", "=>"} {
+		if strings.HasSuffix(code, op) && !strings.HasSuffix(code, "->") {
+			return true
+		}
+	}
+	return false
+}
+
+// scanFormatVars collects the names a file binds — assignment targets,
+// parameters, loop variables and error names — so shell detection can tell
+// `x -1` on a variable from a command like `ls -la`.
+func scanFormatVars(lines []string) map[string]bool {
+	vars := make(map[string]bool)
+	addList := func(s string) {
+		for _, p := range strings.Split(s, ",") {
+			p = strings.TrimSpace(p)
+			if eq := strings.Index(p, "="); eq >= 0 {
+				p = strings.TrimSpace(p[:eq])
+			}
+			if isIdent(p) {
+				vars[p] = true
+			}
+		}
+	}
+	for _, line := range lines {
+		code, _ := splitComment(strings.TrimSpace(line))
+		first, rest := scanFirstToken(code)
+		switch first {
+		case "for":
+			if in := strings.Index(rest, " in "); in >= 0 {
+				addList(rest[:in])
+			}
+		case "or", "with":
+			if i := strings.LastIndex(code, " "); i >= 0 {
+				addList(code[i+1:])
+			}
+		default:
+			if eq := FindTopLevel(code, func(ch byte, pos int, src string) bool {
+				return ch == '=' && (pos+1 >= len(src) || src[pos+1] != '=') && (pos == 0 || strings.IndexByte("=!<>", src[pos-1]) < 0)
+			}); eq > 0 {
+				addList(strings.TrimRight(code[:eq], "+-*/%|&"))
+			}
+		}
+		// Parameters of def and fn.
+		for _, kw := range []string{"def ", "fn("} {
+			for j := strings.Index(code, kw); j >= 0; {
+				open := strings.IndexByte(code[j:], '(')
+				if open < 0 {
+					break
+				}
+				close := findMatchingClose(code, j+open, '(', ')')
+				if close < 0 {
+					break
+				}
+				addList(code[j+open+1 : close])
+				next := strings.Index(code[close:], kw)
+				if next < 0 {
+					break
+				}
+				j = close + next
+			}
+		}
+	}
+	return vars
+}
+
+// spacedOperators are the binary operators written with one space on each
+// side. formatOperators lists every operator token, longest first, so the
+// scanner never splits one (e.g. `..` or `<<~` stay intact).
+var (
+	spacedOperators = map[string]bool{
+		"<=>": true, "||=": true, "&&=": true, "==": true, "!=": true,
+		"<=": true, ">=": true, "&&": true, "||": true, "+=": true,
+		"-=": true, "*=": true, "/=": true, "%=": true, "=>": true,
+		"->": true, "=": true, "+": true, "-": true, "*": true, "/": true,
+		"%": true, "<": true, ">": true,
+	}
+	formatOperators = []string{
+		"<=>", "...", "||=", "&&=",
+		"==", "!=", "<=", ">=", "&&", "||", "+=", "-=", "*=", "/=", "%=",
+		"=>", "->", "<<", ">>", "..", "&.", "::", "**",
+	}
+)
+
+// spaceOperators puts one space on each side of the binary operators in a
+// line of code. A +, -, * or / that may be unary or a paren-free argument
+// (`puts -x`) is left as written.
+func spaceOperators(code string) string {
+	type token struct {
+		text  string
+		space bool // whitespace
+		op    bool
+	}
+	var toks []token
+	for i := 0; i < len(code); {
+		c := code[i]
+		switch {
+		case c == ' ' || c == '\t':
+			j := i
+			for j < len(code) && (code[j] == ' ' || code[j] == '\t') {
+				j++
+			}
+			toks = append(toks, token{text: code[i:j], space: true})
+			i = j
+		case c == '"' || c == '\'' || c == '`':
+			j := i + 1
+			for j < len(code) && code[j] != c {
+				if code[j] == '\\' && c != '`' {
+					j++
+				}
+				j++
+			}
+			if j < len(code) {
+				j++
+			} else {
+				j = len(code)
+			}
+			toks = append(toks, token{text: code[i:j]})
+			i = j
+		case c >= '0' && c <= '9':
+			j := i + 1
+			for j < len(code) && (isIdentByte(code[j]) || code[j] == '.' && j+1 < len(code) && code[j+1] >= '0' && code[j+1] <= '9') {
+				if (code[j] == 'e' || code[j] == 'E') && !strings.HasPrefix(code[i:], "0x") && j+1 < len(code) && (code[j+1] == '-' || code[j+1] == '+') {
+					j++
+				}
+				j++
+			}
+			toks = append(toks, token{text: code[i:j]})
+			i = j
+		case isIdentByte(c):
+			j := i
+			for j < len(code) && isIdentByte(code[j]) {
+				j++
+			}
+			toks = append(toks, token{text: code[i:j]})
+			i = j
+		default:
+			text := code[i : i+1]
+			for _, op := range formatOperators {
+				if strings.HasPrefix(code[i:], op) {
+					text = op
+					break
+				}
+			}
+			toks = append(toks, token{text: text, op: strings.IndexByte("+-*/%<>=!&|^", c) >= 0})
+			i += len(text)
+		}
+	}
+
+	// isOperand reports whether a token ends an operand, so an operator
+	// after it is binary.
+	isOperand := func(t token) bool {
+		if t.space || t.op {
+			return false
+		}
+		c := t.text[0]
+		if isIdentByte(c) {
+			return !RugoKeywords[t.text] || t.text == "true" || t.text == "false" || t.text == "nil" || t.text == "end"
+		}
+		return c == '"' || c == '\'' || c == '`' || IsCloseBracket(c) || c == '?'
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(toks); i++ {
+		t := toks[i]
+		if !t.op || !spacedOperators[t.text] {
+			sb.WriteString(t.text)
+			continue
+		}
+		p := i - 1
+		for p >= 0 && toks[p].space {
+			p--
+		}
+		n := i + 1
+		for n < len(toks) && toks[n].space {
+			n++
+		}
+		if p < 0 || n >= len(toks) || !isOperand(toks[p]) {
+			sb.WriteString(t.text)
+			continue
+		}
+		spaceBefore, spaceAfter := p < i-1, n > i+1
+		if spaceBefore && !spaceAfter && len(t.text) == 1 && strings.IndexByte("+-*/", t.text[0]) >= 0 && isIdentByte(toks[p].text[0]) {
+			sb.WriteString(t.text) // `puts -x`: unary argument or binary, keep it
+			continue
+		}
+		s := strings.TrimRight(sb.String(), " \t")
+		sb.Reset()
+		sb.WriteString(s)
+		sb.WriteString(" " + t.text + " ")
+		i = n - 1
+	}
+	return sb.String()
+}
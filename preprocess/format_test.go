@@ -0,0 +1,50 @@
+package preprocess
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+	}{
+		{"block indentation", "def f(x)\nif x\nputs(x)\nelse\nputs(0)\nend\nend", "def f(x)\n  if x\n    puts(x)\n  else\n    puts(0)\n  end\nend\n"},
+		{"operators", "x=a+b*2\nok = x>=1&&x!=3\nh = {\"a\"=>1}\n", "x = a + b * 2\nok = x >= 1 && x != 3\nh = {\"a\" => 1}\n"},
+		{"unary minus", "y = -x\nz = a * -1\nputs -x\nn = 1e-5\n", "y = -x\nz = a * -1\nputs -x\nn = 1e-5\n"},
+		{"binary minus", "a = 5\nz = a-1\nw = f(x)-xs[0]-2\nv = (a-b)*2\n", "a = 5\nz = a - 1\nw = f(x) - xs[0] - 2\nv = (a - b) * 2\n"},
+		{"hyphenated command kept", "v = apt-get\n", "v = apt-get\n"},
+		{"postfix if", "return x if x>1\nputs 1 unless done\n", "return x if x > 1\nputs 1 unless done\n"},
+		{"case of", "r = case x\nof 1 -> \"one\"\nof 2\n\"two\"\nelse\n\"other\"\nend\n", "r = case x\nof 1 -> \"one\"\nof 2\n  \"two\"\nelse\n  \"other\"\nend\n"},
+		{"try block and one-liner", "r = try f() or err\n\"caught\"\nend\ns = try f() or \"x\"\n", "r = try f() or err\n  \"caught\"\nend\ns = try f() or \"x\"\n"},
+		{"try parallel", "r = try parallel\nf()\nend or err\nnil\nend\n", "r = try parallel\n  f()\nend or err\n  nil\nend\n"},
+		{"lambda argument", "ys = xs.map(fn(v)\nv+1\nend)\nf = fn(a) a*2 end\n", "ys = xs.map(fn(v)\n  v + 1\nend)\nf = fn(a) a * 2 end\n"},
+		{"brackets", "xs = [\n1,\n[2,\n3],\n]\n", "xs = [\n  1,\n  [2,\n    3],\n]\n"},
+		{"continuation lines", "s = \"a\" +\n\"b\"\nr = xs\n.map(fn(x) x end)\n", "s = \"a\" +\n  \"b\"\nr = xs\n  .map(fn(x) x end)\n"},
+		{"comments and strings kept", "  # note  \nx=1   # one  two\nputs(\"a+b  c\")\n", "# note\nx = 1   # one  two\nputs(\"a+b  c\")\n"},
+		{"shell lines kept", "ls -la /tmp\nr = try ls /x 2>/dev/null or \"\"\n", "ls -la /tmp\nr = try ls /x 2>/dev/null or \"\"\n"},
+		{"heredoc body kept", "def f()\ns = <<~EOS\n   a  +  b\n  EOS\nend\n", "def f()\n  s = <<~EOS\n   a  +  b\n  EOS\nend\n"},
+		{"trailing blank lines", "x = 1\n\n\n", "x = 1\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Format(tt.in)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			again, err := Format(got)
+			require.NoError(t, err)
+			assert.Equal(t, got, again, "not idempotent")
+		})
+	}
+}
+
+func TestFormatErrors(t *testing.T) {
+	_, err := Format("def f()\nend\nend\n")
+	assert.EqualError(t, err, "line 3: unexpected \"end\" — no matching block to close")
+	_, err = Format("if x\nputs(1)\n")
+	assert.EqualError(t, err, "\"if\" is never closed — missing \"end\"")
+	_, err = Format("x = [\n1\n")
+	assert.EqualError(t, err, "unclosed bracket — missing ), ] or }")
+}
@@ -0,0 +1,31 @@
+package preprocess
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpandMultiReturn(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		expect string
+	}{
+		{"two values", "  return a / b, a % b", "  return [a / b, a % b]"},
+		{"single value unchanged", "return a", "return a"},
+		{"bare return unchanged", "return", "return"},
+		{"comma inside call", "return f(1, 2)", "return f(1, 2)"},
+		{"comma inside string", `return "a,b"`, `return "a,b"`},
+		{"single-line def", "def divmod(a, b) return a / b, a % b end", "def divmod(a, b) return [a / b, a % b] end"},
+		{"inside lambda argument", "map(fn(v) return v, 1 end)", "map(fn(v) return [v, 1] end)"},
+		{"nested lambda value", "return fn(x) return x, 1 end, 2", "return [fn(x) return [x, 1] end, 2]"},
+		{"longer identifier", "my_return a, b", "my_return a, b"},
+		{"collections", `return {"a" => 1}, [1, 2]`, `return [{"a" => 1}, [1, 2]]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expect, expandMultiReturn(tt.input))
+		})
+	}
+}
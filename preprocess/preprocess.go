@@ -9,15 +9,16 @@ import (
 )
 
 var RugoKeywords = map[string]bool{
-	"if": true, "elsif": true, "else": true, "end": true,
+	"if": true, "elsif": true, "else": true, "end": true, "unless": true,
 	"while": true, "for": true, "in": true, "def": true,
-	"return": true, "require": true, "break": true, "next": true,
+	"return": true, "require": true, "require_optional": true,
+	"break": true, "next": true,
 	"true": true, "false": true, "nil": true, "import": true, "use": true,
-	"rats": true, "try": true, "or": true,
+	"rats": true, "try": true, "or": true, "ensure": true,
 	"spawn": true, "parallel": true, "bench": true, "fn": true,
 	"struct": true, "with": true, "sandbox": true, "do": true,
 	"case": true, "of": true,
-	"embed": true,
+	"embed": true, "const": true,
 }
 
 // blockKeywordSet contains keywords that form their own block with `end`
@@ -30,7 +31,8 @@ var rugoBuiltins = map[string]bool{
 	"puts": true, "print": true,
 	"len": true, "append": true,
 	"raise": true, "type_of": true,
-	"exit": true,
+	"exit": true, "die": true,
+	"assert": true, "rethrow": true,
 }
 
 // stripComments removes # comments from source, respecting string and backtick boundaries.
@@ -105,6 +107,180 @@ func StripComments(src string) (string, error) {
 	return sb.String(), nil
 }
 
+// Run applies the full preprocessing pipeline to raw Rugo source: heredoc
+// expansion, comment stripping, struct expansion and line-level
+// preprocessing. It is the single entry point used by the compiler and the
+// AST parser so both see identical source.
+//
+// Returns the parser-ready source (always newline-terminated), a line map
+// from its lines to the original source lines (nil if 1:1), and the structs
+// that were expanded. Errors are prefixed with the offending line number.
+func Run(src string) (string, []int, []StructInfo, error) {
+	return RunWithOptions(src, Options{})
+}
+
+// Options adjusts the preprocessing pipeline.
+type Options struct {
+	// Strict disables the implicit shell fallback: a line whose first word
+	// is not a known function, variable or keyword is a compile error
+	// instead of a shell command. Backticks, hyphenated commands
+	// (`apt-get ...`) and path commands (`./run.sh`) are still allowed.
+	Strict bool
+
+	// predicates holds the names expandPredicateNames renamed, so shell
+	// fallback lines can be given back their original text.
+	predicates map[string]bool
+}
+
+// RunWithOptions is Run with the given options applied.
+func RunWithOptions(src string, opts Options) (string, []int, []StructInfo, error) {
+	// Expand heredocs before comment stripping (bodies may contain #).
+	cleaned, heredocLineMap, err := ExpandHeredocs(src)
+	if err != nil {
+		return "", nil, nil, err
+	}
+
+	cleaned, err = StripComments(cleaned)
+	if err != nil {
+		return "", nil, nil, err
+	}
+
+	// Rename predicates (valid? → valid_p) before anything scans names.
+	cleaned, opts.predicates, err = expandPredicateNames(cleaned, heredocLineMap)
+	if err != nil {
+		return "", nil, nil, err
+	}
+
+	// Expand struct definitions and method definitions before other preprocessing
+	cleaned, structLineMap, structs, err := ExpandStructDefs(cleaned)
+	if err != nil {
+		return "", nil, nil, err
+	}
+
+	// Scan for user-defined function names (quick pass for def lines)
+	userFuncs := ScanFuncDefs(cleaned)
+
+	// Paren-free calls + shell fallback
+	cleaned, lineMap, err := preprocess(cleaned, userFuncs, opts)
+	if err != nil {
+		return "", nil, nil, err
+	}
+
+	// Compose all line maps: preprocess → struct → heredoc → original source.
+	// Each map translates from its output lines to its input lines (1-indexed).
+	lineMap = composeLineMaps(lineMap, structLineMap)
+	lineMap = composeLineMaps(lineMap, heredocLineMap)
+
+	if !strings.HasSuffix(cleaned, "\n") {
+		cleaned += "\n"
+	}
+	return cleaned, lineMap, structs, nil
+}
+
+// composeLineMaps maps each entry of outer through inner. A nil map is
+// the identity.
+func composeLineMaps(outer, inner []int) []int {
+	if inner == nil {
+		return outer
+	}
+	if outer == nil {
+		return inner
+	}
+	for i, line := range outer {
+		if line > 0 && line <= len(inner) {
+			outer[i] = inner[line-1]
+		}
+	}
+	return outer
+}
+
+// OriginalPosition maps a 1-based line and column of the preprocessed
+// source cleaned back to the original source src, using the lineMap
+// returned by Run. Parse and compile errors both go through it so they
+// report the same location.
+func OriginalPosition(src, cleaned string, lineMap []int, line, col int) (int, int) {
+	return originalLine(lineMap, line), OriginalColumn(src, cleaned, lineMap, line, col)
+}
+
+// originalLine maps a 1-based line of preprocessed source back to the
+// original source. Lines past the end of the map (an error at EOF) keep
+// their distance from the last mapped line.
+func originalLine(lineMap []int, line int) int {
+	if len(lineMap) == 0 || line <= 0 {
+		return line
+	}
+	if line > len(lineMap) {
+		return lineMap[len(lineMap)-1] + line - len(lineMap)
+	}
+	return lineMap[line-1]
+}
+
+// OriginalColumn maps a 1-based column on a line of the preprocessed source
+// cleaned back to the original source src, using the lineMap returned by
+// Run. Lines the preprocessor left alone keep their column. On a rewritten
+// line (`puts x` → `puts(x)`) the token under the column is looked up on the
+// original line, matching the same occurrence when it appears more than
+// once. When the token cannot be found, col is returned unchanged.
+func OriginalColumn(src, cleaned string, lineMap []int, line, col int) int {
+	cleanedLines := strings.Split(cleaned, "\n")
+	if line <= 0 || line > len(cleanedLines) || col <= 0 {
+		return col
+	}
+	origLine := line
+	if lineMap != nil {
+		if line > len(lineMap) {
+			return col
+		}
+		origLine = lineMap[line-1]
+	}
+	srcLines := strings.Split(src, "\n")
+	if origLine <= 0 || origLine > len(srcLines) {
+		return col
+	}
+	have, want := cleanedLines[line-1], srcLines[origLine-1]
+	if have == want || col > len(have) {
+		return col
+	}
+
+	end := col
+	if isIdentByte(have[col-1]) {
+		for end < len(have) && isIdentByte(have[end]) {
+			end++
+		}
+	}
+	tok := have[col-1 : end]
+	nth := 0
+	for _, off := range tokenOffsets(have, tok) {
+		if off < col-1 {
+			nth++
+		}
+	}
+	if offs := tokenOffsets(want, tok); nth < len(offs) {
+		return offs[nth] + 1
+	}
+	return col
+}
+
+// tokenOffsets returns the byte offsets of tok in s. Identifier tokens
+// only match whole words, so `x` is not found inside `xs`.
+func tokenOffsets(s, tok string) []int {
+	word := isIdentByte(tok[0])
+	var offs []int
+	for i := 0; i+len(tok) <= len(s); {
+		j := strings.Index(s[i:], tok)
+		if j < 0 {
+			break
+		}
+		at := i + j
+		i = at + len(tok)
+		if word && ((at > 0 && isIdentByte(s[at-1])) || (i < len(s) && isIdentByte(s[i]))) {
+			continue
+		}
+		offs = append(offs, at)
+	}
+	return offs
+}
+
 // preprocess performs line-level transformations:
 // 1. Parenthesis-free function calls: `puts "foo"` → `puts("foo")`
 // 2. Shell fallback: unknown idents → `__shell__("cmd line")`
@@ -116,6 +292,10 @@ func StripComments(src string) (string, error) {
 // Returns the preprocessed source and a line map (preprocessed line 0-indexed
 // → original line 1-indexed). If lineMap is nil, the mapping is 1:1.
 func Preprocess(src string, allFuncs map[string]bool) (string, []int, error) {
+	return preprocess(src, allFuncs, Options{})
+}
+
+func preprocess(src string, allFuncs map[string]bool, opts Options) (string, []int, error) {
 	// Reject user-written semicolons — they are reserved for internal use
 	// by the preprocessor to disambiguate array literals on new lines.
 	if err := RejectSemicolons(src); err != nil {
@@ -127,9 +307,46 @@ func Preprocess(src string, allFuncs map[string]bool) (string, []int, error) {
 		return "", nil, err
 	}
 
+	// Rewrite import renames before hash colon syntax sees "name: GoFunc".
+	src, importedFuncs, err := expandImportRenames(src)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(importedFuncs) > 0 {
+		funcs := make(map[string]bool, len(allFuncs)+len(importedFuncs))
+		for name := range allFuncs {
+			funcs[name] = true
+		}
+		for name := range importedFuncs {
+			funcs[name] = true
+		}
+		allFuncs = funcs
+	}
+
+	// Rewrite constant declarations: "const x = 1" → "__const__x = 1"
+	src, err = expandConst(src)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// Rewrite safe navigation: "a&.b" → "a.__safe__b"
+	src = ExpandSafeNav(src)
+
+	// Rewrite parallel(limit: N) before hash colon syntax sees "limit:".
+	src, err = expandParallelLimit(src)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// Rewrite bench "name", iterations: N before hash colon syntax too.
+	src, err = expandBenchOptions(src)
+	if err != nil {
+		return "", nil, err
+	}
+
 	// Rewrite hash colon syntax before other transformations:
 	//   {foo: "bar"}  →  {"foo" => "bar"}
-	src, err := ExpandHashColonSyntax(src)
+	src, err = ExpandHashColonSyntax(src)
 	if err != nil {
 		return "", nil, err
 	}
@@ -137,6 +354,9 @@ func Preprocess(src string, allFuncs map[string]bool) (string, []int, error) {
 	// Desugar compound assignment operators before other transformations.
 	src = ExpandCompoundAssign(src)
 
+	// Desugar multiple return values: return a, b → return [a, b].
+	src = expandMultiReturn(src)
+
 	// Desugar array destructuring: a, b, c = expr → temp + index assignments.
 	src = expandDestructuring(src)
 
@@ -144,6 +364,32 @@ func Preprocess(src string, allFuncs map[string]bool) (string, []int, error) {
 	// a consistent form. "def name(params)" is left unchanged.
 	src = expandDefParens(src)
 
+	// Rewrite "memo def name(...)" to a def carrying the __memo__ marker.
+	src = expandMemo(src)
+
+	// Rewrite defined?(...) to the internal __defined__ builtin.
+	src = expandDefinedPredicate(src)
+
+	// Rewrite with resource blocks: "with EXPR as NAME" → "if __with__("NAME", EXPR)"
+	src, err = expandWith(src)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// Desugar unless into a negated if, in block and postfix form:
+	// "unless COND" → "if !(COND)", "STMT unless COND" → "if !(COND)\nSTMT\nend"
+	src, err = expandUnless(src)
+	if err != nil {
+		return "", nil, err
+	}
+	src = expandPostfixUnless(src)
+
+	// Mark the else clause of a while loop: "else" → "__while_else__()"
+	src, err = expandWhileElse(src)
+	if err != nil {
+		return "", nil, err
+	}
+
 	// Expand postfix if: "STMT if COND" → "if COND\nSTMT\nend"
 	src = expandPostfixIf(src)
 
@@ -170,10 +416,17 @@ func Preprocess(src string, allFuncs map[string]bool) (string, []int, error) {
 	// Expand inline fn bodies so paren-free calls inside them get preprocessed.
 	src, tryLineMap = expandInlineFn(src, tryLineMap)
 
+	// Rewrite spreads in literals: [...a, b] → __array_concat__(a, [b]).
+	src, err = expandSpreads(src, tryLineMap)
+	if err != nil {
+		return "", nil, err
+	}
+
 	lines := strings.Split(src, "\n")
 	var result []string
 
-	topLevelFuncs := make(map[string]bool)
+	// Imports apply to the whole file, so renamed Go functions are known from the top.
+	topLevelFuncs := importedFuncs
 	knownVars := make(map[string]bool)
 	var blockStack []string // tracks "def", "if", "while"
 	defDepth := 0
@@ -186,7 +439,7 @@ func Preprocess(src string, allFuncs map[string]bool) (string, []int, error) {
 		if isIdent(firstToken) {
 			rt := strings.TrimSpace(rest)
 			if len(rt) > 0 && rt[0] == '=' && (len(rt) < 2 || rt[1] != '=') {
-				knownVars[firstToken] = true
+				knownVars[strings.TrimPrefix(firstToken, ConstPrefix)] = true
 			}
 		}
 
@@ -223,6 +476,11 @@ func Preprocess(src string, allFuncs map[string]bool) (string, []int, error) {
 			}
 		}
 
+		// Track with bindings: `if __with__("name", ...)` from expandWith
+		if name, ok := withBinding(trimmed); ok {
+			knownVars[name] = true
+		}
+
 		// Track fn (lambda) parameters: `fn(a, b)` or `fn(a, b = nil)`
 		// Look for fn( anywhere on the line (it may appear in arrays, hashes,
 		// function args, etc.) and track all parameter names found.
@@ -270,7 +528,7 @@ func Preprocess(src string, allFuncs map[string]bool) (string, []int, error) {
 
 		// Expand pipes before normal line processing
 		var pipeErr error
-		line, pipeErr = expandPipeLine(line, funcs)
+		line, pipeErr = expandPipeLine(line, funcs, knownVars)
 		if pipeErr != nil {
 			origLine := i + 1
 			if tryLineMap != nil && i < len(tryLineMap) {
@@ -279,9 +537,10 @@ func Preprocess(src string, allFuncs map[string]bool) (string, []int, error) {
 			return "", nil, fmt.Errorf("line %d: %s", origLine, pipeErr.Error())
 		}
 
-		processed := preprocessLine(line, funcs, knownVars)
+		processed, implicitShell := preprocessLine(line, funcs, knownVars)
 		// Detect orphan "or" on shell fallback lines
 		if strings.Contains(processed, `__shell__("`) {
+			processed = restorePredicateNames(processed, opts.predicates)
 			if hasOrphanOr(trimmed) {
 				origLine := i + 1
 				if tryLineMap != nil && i < len(tryLineMap) {
@@ -297,6 +556,13 @@ func Preprocess(src string, allFuncs map[string]bool) (string, []int, error) {
 				}
 				return "", nil, fmt.Errorf("line %d: unknown keyword `%s` — did you mean `%s`?", origLine, firstToken, closest)
 			}
+			if opts.Strict && implicitShell {
+				origLine := i + 1
+				if tryLineMap != nil && i < len(tryLineMap) {
+					origLine = tryLineMap[i]
+				}
+				return "", nil, fmt.Errorf("line %d: `%s` is not a known function or variable (strict mode disables the shell fallback; use backticks to run a command)", origLine, strictShellWord(trimmed))
+			}
 		}
 		result = append(result, processed)
 
@@ -353,6 +619,22 @@ func Preprocess(src string, allFuncs map[string]bool) (string, []int, error) {
 	// Desugar bare append: append(x, ...) → x = append(x, ...)
 	joined = ExpandBareAppend(joined)
 
+	// Rewrite range literals: "0..10" → "__range__(0, 10, true)". This runs
+	// after shell lines became string literals, so `echo a..b` is untouched.
+	joined = expandRanges(joined)
+
+	// Give one-argument asserts their source text as the failure message.
+	joined = expandAssertMessages(joined)
+
+	// Give loops a value: `x = for ...` with `break EXPR` / `next EXPR`.
+	joined, err = expandLoopValues(joined, tryLineMap)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// Bind with resources: `if __with__("f", EXPR)` → `f = EXPR; if __with__(f)`
+	joined = splitWithBindings(joined)
+
 	// Insert ';' after sandbox lines to disambiguate from the next statement.
 	// Without this, `sandbox\nputs(...)` would make the parser try to match
 	// `puts` as a SandboxPerm ident.
@@ -372,10 +654,14 @@ func Preprocess(src string, allFuncs map[string]bool) (string, []int, error) {
 	return joined, tryLineMap, nil
 }
 
-func preprocessLine(line string, userFuncs map[string]bool, knownVars map[string]bool) string {
+// preprocessLine rewrites a single line for paren-free calls and the shell
+// fallback. The boolean result reports whether the line became a shell
+// command only because its first word is not a known function or variable
+// (hyphenated and path commands are unambiguous and do not count).
+func preprocessLine(line string, userFuncs map[string]bool, knownVars map[string]bool) (string, bool) {
 	trimmed := strings.TrimSpace(line)
 	if trimmed == "" {
-		return line
+		return line, false
 	}
 
 	// Extract leading whitespace
@@ -387,12 +673,12 @@ func preprocessLine(line string, userFuncs map[string]bool, knownVars map[string
 
 	firstToken, rest := scanFirstToken(trimmed)
 	if firstToken == "" {
-		return line
+		return line, false
 	}
 
 	// Keywords — never touch
 	if RugoKeywords[firstToken] {
-		return line
+		return line, false
 	}
 
 	// If first token is not an identifier, check for dotted ident (module.func paren-free call)
@@ -402,28 +688,29 @@ func preprocessLine(line string, userFuncs map[string]bool, knownVars map[string
 			// If the object part is a known variable, this is field access (e.g. u.name),
 			// not a paren-free namespace call — leave it alone.
 			if knownVars[parts[0]] {
-				return line
+				return line, false
 			}
 			rt := strings.TrimSpace(rest)
 			if rt == "" {
 				// Bare dotted ident: `cli.run` → `cli.run()`
-				return indent + firstToken + "()"
+				return indent + firstToken + "()", false
 			}
 			if rt[0] != '(' && rt[0] != '=' && !isOperatorStart(rt[0]) {
-				return indent + firstToken + "(" + rt + ")"
+				return indent + firstToken + "(" + rt + ")", false
 			}
 		}
 		// Hyphenated command: `docker-compose up`, `apt-get install`, etc.
-		// Hyphens are invalid in Rugo identifiers, so this is always a shell command.
-		if isHyphenatedCommand(firstToken) {
-			return indent + `__shell__("` + shellEscape(trimmed) + `")`
+		// Hyphens are invalid in Rugo identifiers, so this is a shell command
+		// unless it starts with a variable (`n-1` subtracts).
+		if isHyphenatedCommand(firstToken) && !startsWithVar(firstToken, knownVars) {
+			return indent + `__shell__("` + shellEscape(trimmed) + `")`, false
 		}
 		// Path-based command: `./script`, `../foo`, `/usr/bin/ls`
 		// Paths are invalid in Rugo identifiers, so this is always a shell command.
 		if isPathCommand(firstToken) {
-			return indent + `__shell__("` + shellEscape(trimmed) + `")`
+			return indent + `__shell__("` + shellEscape(trimmed) + `")`, false
 		}
-		return line
+		return line, false
 	}
 
 	// Check what follows the first token
@@ -437,70 +724,83 @@ func preprocessLine(line string, userFuncs map[string]bool, knownVars map[string
 			rhsRestTrimmed := strings.TrimSpace(rhsRest)
 			// Unknown ident with flag-like args: `ls -la`, `uname -a`
 			if len(rhsRestTrimmed) > 0 && isOperatorStart(rhsRestTrimmed[0]) {
-				return indent + firstToken + " = " + `__shell__("` + shellEscape(rhs) + `")`
+				return indent + firstToken + " = " + `__shell__("` + shellEscape(rhs) + `")`, true
 			}
 			// Unknown ident with non-expression args: `echo hello`
 			if len(rhsRestTrimmed) > 0 && rhsRestTrimmed[0] != '(' && rhsRestTrimmed[0] != '[' && rhsRestTrimmed[0] != '.' {
-				return indent + firstToken + " = " + `__shell__("` + shellEscape(rhs) + `")`
+				return indent + firstToken + " = " + `__shell__("` + shellEscape(rhs) + `")`, true
 			}
 		}
 		if rhsTok != "" && isPathCommand(rhsTok) {
-			return indent + firstToken + " = " + `__shell__("` + shellEscape(rhs) + `")`
+			return indent + firstToken + " = " + `__shell__("` + shellEscape(rhs) + `")`, false
 		}
-		if rhsTok != "" && isHyphenatedCommand(rhsTok) {
-			return indent + firstToken + " = " + `__shell__("` + shellEscape(rhs) + `")`
+		if rhsTok != "" && isHyphenatedCommand(rhsTok) && !startsWithVar(rhsTok, knownVars) {
+			return indent + firstToken + " = " + `__shell__("` + shellEscape(rhs) + `")`, false
 		}
-		return line
+		return line, false
 	}
 
 	// Already has parens: `foo(...)` — leave alone
 	if len(restTrimmed) > 0 && restTrimmed[0] == '(' {
-		return line
+		return line, false
 	}
 
 	// Dot access: `ns.func(...)` — leave alone (handled by parser)
 	if len(restTrimmed) > 0 && restTrimmed[0] == '.' {
-		return line
+		return line, false
 	}
 
 	// Index access: `arr[0]` — leave alone
 	if len(restTrimmed) > 0 && restTrimmed[0] == '[' {
-		return line
+		return line, false
 	}
 
-	// Operator follows: `x + y`, `x == y` etc — leave alone (it's an expression)
-	// But only if the first token could be a variable (known func/builtin/var or we
-	// can't tell), not an unknown command like `ls -la`
-	if len(restTrimmed) > 0 && isOperatorStart(restTrimmed[0]) {
+	// Operator follows: `x + y`, `x == y`, `x in xs` etc — leave alone (it's an
+	// expression). But only if the first token could be a variable (known
+	// func/builtin/var or we can't tell), not an unknown command like `ls -la`
+	if len(restTrimmed) > 0 && (isOperatorStart(restTrimmed[0]) || startsWithInOperator(restTrimmed)) {
 		if rugoBuiltins[firstToken] || userFuncs[firstToken] || knownVars[firstToken] {
-			return line
+			return line, false
 		}
 		// Unknown ident followed by operator — it's a shell command
 		// e.g. `ls -la`, `uname -a`
-		return indent + `__shell__("` + shellEscape(trimmed) + `")`
+		return indent + `__shell__("` + shellEscape(trimmed) + `")`, true
 	}
 
 	// Empty rest — bare ident. If it's a known variable, leave it alone (expression).
 	// If it's a known function/builtin, it's a no-arg call. Otherwise it's a shell command.
 	if restTrimmed == "" {
 		if knownVars[firstToken] {
-			return line
+			return line, false
 		}
 		if rugoBuiltins[firstToken] || userFuncs[firstToken] {
-			return indent + firstToken + "()"
+			return indent + firstToken + "()", false
 		}
 		// Shell: single command like `ls`
-		return indent + `__shell__("` + shellEscape(firstToken) + `")`
+		return indent + `__shell__("` + shellEscape(firstToken) + `")`, true
 	}
 
 	// If the ident is a known builtin or user function, it's a paren-free call
 	if rugoBuiltins[firstToken] || userFuncs[firstToken] {
 		// Rewrite `func arg1, arg2` → `func(arg1, arg2)`
-		return indent + firstToken + "(" + restTrimmed + ")"
+		return indent + firstToken + "(" + restTrimmed + ")", false
 	}
 
 	// Otherwise it's a shell command — the whole line is the command
-	return indent + `__shell__("` + shellEscape(trimmed) + `")`
+	return indent + `__shell__("` + shellEscape(trimmed) + `")`, true
+}
+
+// strictShellWord returns the word a strict-mode error should point at:
+// the command name on the right of an assignment, else the first word.
+func strictShellWord(trimmed string) string {
+	tok, rest := scanFirstToken(trimmed)
+	rest = strings.TrimSpace(rest)
+	if len(rest) > 1 && rest[0] == '=' && rest[1] != '=' {
+		if rhsTok, _ := scanFirstToken(strings.TrimSpace(rest[1:])); rhsTok != "" {
+			return rhsTok
+		}
+	}
+	return tok
 }
 
 // closestKeywordOrBuiltin returns the closest keyword or builtin to s
@@ -607,8 +907,616 @@ func expandDefParens(src string) string {
 	return strings.Join(lines, "\n")
 }
 
+// MemoBuiltin is the internal builtin that marks a memoized function. It is
+// emitted as the first statement of the function body, where the walker
+// lifts it into FuncDef.Memo.
+const MemoBuiltin = "__memo__"
+
+// expandMemo rewrites the memo prefix of a function definition into a
+// leading call to the internal __memo__ builtin:
+//
+//	memo def fib(n)  →  def fib(n) __memo__()
+//
+// Lines where memo is not followed by def (e.g. a variable named memo) are
+// left unchanged.
+func expandMemo(src string) string {
+	lines := strings.Split(src, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		first, rest := scanFirstToken(trimmed)
+		if first != "memo" {
+			continue
+		}
+		def := strings.TrimSpace(rest)
+		if !strings.HasPrefix(def, "def ") {
+			continue
+		}
+		if !strings.Contains(def, "(") {
+			def += "()"
+		}
+		indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		lines[i] = indent + def + " " + MemoBuiltin + "()"
+	}
+	return strings.Join(lines, "\n")
+}
+
+// expandDefinedPredicate rewrites the defined? builtin to the internal
+// __defined__ name, since '?' is not valid in identifiers:
+//
+//	defined?("plugin.run")  →  __defined__("plugin.run")
+//
+// The call is resolved at compile time against the known functions and
+// variables. String contents are left untouched.
+func expandDefinedPredicate(src string) string {
+	if !strings.Contains(src, "defined?") {
+		return src
+	}
+	var sb strings.Builder
+	st := NewStringTracker(src)
+	for {
+		ch, ok := st.Next()
+		if !ok {
+			break
+		}
+		pos := st.Pos()
+		if st.InCode() && st.LookingAt("defined?") && (pos == 0 || !isAlphaNum(src[pos-1]) && src[pos-1] != '_' && src[pos-1] != '.') {
+			sb.WriteString("__defined__")
+			st.Skip(len("defined?") - 1)
+			continue
+		}
+		sb.WriteByte(ch)
+	}
+	return sb.String()
+}
+
+// PredicateSuffix replaces the trailing `?` of a predicate name, so
+// `valid?` is compiled as `valid_p`.
+const PredicateSuffix = "_p"
+
+// expandPredicateNames rewrites Ruby-style predicate names ending in `?` to
+// plain identifiers, at definitions and call sites alike:
+//
+//	def valid?(x)     →  def valid_p(x)
+//	if user.admin?()  →  if user.admin_p()
+//
+// Only names declared with a `def name?` or `def Type.name?` header in src
+// are rewritten, so a `?` anywhere else (a shell glob such as
+// `ls file?.txt`) is left alone; lines that still end up in the shell
+// fallback get their names back from restorePredicateNames. Names inside
+// #{} interpolation are rewritten too, since those are parsed on their own
+// later. defined? is left for expandDefinedPredicate. A `?` is only
+// accepted as the last character of a defined name.
+func expandPredicateNames(src string, lineMap []int) (string, map[string]bool, error) {
+	preds, err := scanPredicateDefs(src, lineMap)
+	if err != nil || len(preds) == 0 {
+		return src, nil, err
+	}
+	var sb strings.Builder
+	st := NewStringTracker(src)
+	interp := 0 // brace depth inside #{} of a double-quoted string
+	for {
+		ch, ok := st.Next()
+		if !ok {
+			break
+		}
+		pos := st.Pos()
+		if st.InDoubleString() {
+			switch {
+			case ch == '#' && st.LookingAt("#{") && !isEscaped(src, pos):
+				interp++
+				sb.WriteString("#{")
+				st.Skip(1)
+				continue
+			case ch == '{' && interp > 0:
+				interp++
+			case ch == '}' && interp > 0:
+				interp--
+			}
+		}
+		if ch != '?' || (!st.InCode() && interp == 0) || pos == 0 || !isIdentByte(src[pos-1]) {
+			sb.WriteByte(ch)
+			continue
+		}
+		start := pos
+		for start > 0 && isIdentByte(src[start-1]) {
+			start--
+		}
+		name := src[start:pos]
+		if !preds[name] || (pos+1 < len(src) && (isIdentByte(src[pos+1]) || src[pos+1] == '?')) {
+			sb.WriteByte(ch)
+			continue
+		}
+		sb.WriteString(PredicateSuffix)
+	}
+	return sb.String(), preds, nil
+}
+
+// scanPredicateDefs returns the names declared with a trailing `?` in def
+// headers, without the `?`. A `?` anywhere but the end of a def name is an
+// error.
+func scanPredicateDefs(src string, lineMap []int) (map[string]bool, error) {
+	var preds map[string]bool
+	for i, line := range strings.Split(src, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if !strings.HasPrefix(trimmed, "def ") {
+			continue
+		}
+		name, _ := scanFirstToken(strings.TrimSpace(trimmed[4:]))
+		q := strings.IndexByte(name, '?')
+		if q < 0 {
+			continue
+		}
+		if q != len(name)-1 {
+			line := i + 1
+			if lineMap != nil && line <= len(lineMap) {
+				line = lineMap[line-1]
+			}
+			return nil, fmt.Errorf("line %d: `?` can only end a name — e.g. `def valid?(x)`", line)
+		}
+		base := name[:q]
+		if dot := strings.LastIndexByte(base, '.'); dot >= 0 {
+			base = base[dot+1:]
+		}
+		if isIdent(base) && base != "defined" {
+			if preds == nil {
+				preds = make(map[string]bool)
+			}
+			preds[base] = true
+		}
+	}
+	return preds, nil
+}
+
+// restorePredicateNames undoes expandPredicateNames inside the commands of
+// a shell fallback line, so `ls file?.txt` runs as written even when a
+// `file?` function is defined.
+func restorePredicateNames(line string, preds map[string]bool) string {
+	if len(preds) == 0 {
+		return line
+	}
+	const marker = `__shell__("`
+	var sb strings.Builder
+	for {
+		at := strings.Index(line, marker)
+		if at < 0 {
+			break
+		}
+		start := at + len(marker)
+		end := start
+		for end < len(line) && line[end] != '"' {
+			if line[end] == '\\' {
+				end++
+			}
+			end++
+		}
+		end = min(end, len(line))
+		cmd := line[start:end]
+		for name := range preds {
+			mangled := name + PredicateSuffix
+			offs := tokenOffsets(cmd, mangled)
+			for k := len(offs) - 1; k >= 0; k-- {
+				cmd = cmd[:offs[k]] + name + "?" + cmd[offs[k]+len(mangled):]
+			}
+		}
+		sb.WriteString(line[:start])
+		sb.WriteString(cmd)
+		line = line[end:]
+	}
+	sb.WriteString(line)
+	return sb.String()
+}
+
+// RangeBuiltin is the internal builtin a range literal is rewritten to.
+// Its third argument is true for an inclusive range (a..b) and false for
+// an exclusive one (a...b).
+const RangeBuiltin = "__range__"
+
+// expandRanges rewrites range literals to the internal __range__ builtin:
+//
+//	for i in 0..n      →  for i in __range__(0, n, true)
+//	x = a...len(b)     →  x = __range__(a, len(b), false)
+//
+// Operands are simple terms: numbers, identifiers with .field chains,
+// calls, indexing and parenthesized expressions, with an optional leading
+// minus. Anything else around the dots (e.g. `cd ..`) is left alone.
+func expandRanges(src string) string {
+	if !strings.Contains(src, "..") {
+		return src
+	}
+	var sb strings.Builder
+	st := NewStringTracker(src)
+	for ch, ok := st.Next(); ok; ch, ok = st.Next() {
+		pos := st.Pos()
+		if ch != '.' || !st.InCode() || !st.LookingAt("..") || (pos > 0 && src[pos-1] == '.') {
+			sb.WriteByte(ch)
+			continue
+		}
+		dots := 2
+		if st.LookingAt("...") {
+			dots = 3
+		}
+		if pos+dots < len(src) && src[pos+dots] == '.' {
+			sb.WriteByte(ch)
+			continue
+		}
+		out := sb.String()
+		lstart := rangeOperandStart(out)
+		rend := rangeOperandEnd(src, pos+dots)
+		if lstart < 0 || rend < 0 {
+			sb.WriteByte(ch)
+			continue
+		}
+		left := strings.TrimSpace(out[lstart:])
+		right := strings.TrimSpace(src[pos+dots : rend])
+		sb.Reset()
+		sb.WriteString(out[:lstart])
+		fmt.Fprintf(&sb, "%s(%s, %s, %t)", RangeBuiltin, left, right, dots == 2)
+		st.Skip(rend - pos - 1)
+	}
+	return sb.String()
+}
+
+// rangeOperandStart returns where the range operand ending s begins, or -1
+// when s does not end in one. Spaces before the dots are allowed.
+func rangeOperandStart(s string) int {
+	i := len(s)
+	for i > 0 && s[i-1] == ' ' {
+		i--
+	}
+	end := i
+	for i > 0 {
+		c := s[i-1]
+		switch {
+		case isIdentByte(c):
+			i--
+		case c == '.' && i > 1 && isIdentByte(s[i-2]):
+			i--
+		case c == ')' || c == ']':
+			depth := 0
+			for i > 0 {
+				i--
+				if s[i] == ')' || s[i] == ']' {
+					depth++
+				} else if s[i] == '(' || s[i] == '[' {
+					depth--
+					if depth == 0 {
+						break
+					}
+				}
+			}
+			if depth != 0 {
+				return -1
+			}
+		default:
+			if i == end {
+				return -1
+			}
+			// A minus touching the operand is unary unless it directly
+			// follows another operand (a-1..b).
+			if c == '-' && (i == 1 || !isIdentByte(s[i-2]) && s[i-2] != ')' && s[i-2] != ']') {
+				i--
+			}
+			return i
+		}
+	}
+	if i == end {
+		return -1
+	}
+	return i
+}
+
+// rangeOperandEnd returns the end of the range operand starting at or
+// after pos in src, or -1 when there is none.
+func rangeOperandEnd(src string, pos int) int {
+	i := pos
+	for i < len(src) && src[i] == ' ' {
+		i++
+	}
+	if i < len(src) && src[i] == '-' {
+		i++
+	}
+	start := i
+	for i < len(src) {
+		c := src[i]
+		switch {
+		case isIdentByte(c):
+			i++
+		case c == '.' && i > start && i+1 < len(src) && isIdentByte(src[i+1]):
+			i++
+		case c == '(' || c == '[':
+			close := byte(')')
+			if c == '[' {
+				close = ']'
+			}
+			end := findMatchingClose(src, i, c, close)
+			if end < 0 {
+				return -1
+			}
+			i = end + 1
+		default:
+			if i == start {
+				return -1
+			}
+			return i
+		}
+	}
+	if i == start {
+		return -1
+	}
+	return i
+}
+
+// expandAssertMessages adds a default message holding the condition's
+// source text to one-argument assert calls:
+//
+//	assert(x > 0)  →  assert(x > 0, 'assertion failed: x > 0')
+//
+// The text goes in a raw string; a condition that cannot be written as a
+// string literal keeps the runtime's generic message.
+func expandAssertMessages(src string) string {
+	if !strings.Contains(src, "assert(") {
+		return src
+	}
+	var sb strings.Builder
+	last := 0
+	st := NewStringTracker(src)
+	for ch, ok := st.Next(); ok; ch, ok = st.Next() {
+		pos := st.Pos()
+		if ch != 'a' || !st.InCode() || !st.LookingAt("assert(") {
+			continue
+		}
+		if pos > 0 && (isIdentByte(src[pos-1]) || src[pos-1] == '.') {
+			continue
+		}
+		open := pos + len("assert")
+		close := findMatchingClose(src, open, '(', ')')
+		if close < 0 {
+			continue
+		}
+		arg := strings.TrimSpace(src[open+1 : close])
+		commas := FindAllTopLevel(arg, func(c byte, _ int, _ string) bool { return c == ',' })
+		if arg == "" || len(commas) > 0 || strings.ContainsAny(arg, "'\n") {
+			continue
+		}
+		sb.WriteString(src[last:close])
+		sb.WriteString(", 'assertion failed: " + arg + "'")
+		last = close
+	}
+	sb.WriteString(src[last:])
+	return sb.String()
+}
+
+// expandLoopValues lets a for or while loop produce a value. The loop's
+// result variable is set to nil before the loop, and `break EXPR` /
+// `next EXPR` inside it assign to that variable before leaving the
+// iteration:
+//
+//	found = for x in arr  →  found = nil; for x in arr
+//	  break x             →    found = x; break
+//
+// A value can only be given to the innermost loop when that loop's result
+// is assigned. Statement-position loops are left unchanged. lineMap maps
+// preprocessed lines back to source lines for error messages.
+func expandLoopValues(src string, lineMap []int) (string, error) {
+	if !strings.Contains(src, "break ") && !strings.Contains(src, "next ") {
+		return src, nil
+	}
+	type frame struct {
+		loop    bool   // a for or while block
+		barrier bool   // def or fn: break cannot cross it
+		target  string // assigned result variable, "" in statement position
+	}
+	var stack []frame
+	lines := strings.Split(src, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		first, rest := scanFirstToken(trimmed)
+		rest = strings.TrimSpace(rest)
+
+		if (first == "break" || first == "next") && rest != "" {
+			target := ""
+			for j := len(stack) - 1; j >= 0; j-- {
+				if stack[j].loop || stack[j].barrier {
+					target = stack[j].target
+					break
+				}
+			}
+			if target == "" {
+				origLine := i + 1
+				if lineMap != nil && i < len(lineMap) {
+					origLine = lineMap[i]
+				}
+				return "", fmt.Errorf("line %d: `%s` with a value needs a loop whose result is assigned — e.g. `x = for item in items`", origLine, first)
+			}
+			lines[i] = indent + target + " = " + rest + "; " + first
+			continue
+		}
+
+		loopTarget := ""
+		if isIdent(first) && !RugoKeywords[first] && strings.HasPrefix(rest, "=") && !strings.HasPrefix(rest, "==") {
+			rhs := strings.TrimSpace(rest[1:])
+			if kw, _ := scanFirstToken(rhs); kw == "for" || kw == "while" {
+				loopTarget = first
+				lines[i] = indent + first + " = nil; " + rhs
+			}
+		}
+		for _, word := range blockWords(trimmed) {
+			switch {
+			case word == "end":
+				if len(stack) > 0 {
+					stack = stack[:len(stack)-1]
+				}
+			case word == "for" || word == "while":
+				stack = append(stack, frame{loop: true, target: loopTarget})
+				loopTarget = ""
+			default:
+				stack = append(stack, frame{barrier: word == "def" || word == "fn"})
+			}
+		}
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// blockWords returns the block openers (see blockOpenerKeywords) and `end`
+// keywords of a line in order, skipping strings and method names (x.end).
+func blockWords(line string) []string {
+	var words []string
+	st := NewStringTracker(line)
+	for ch, ok := st.Next(); ok; ch, ok = st.Next() {
+		pos := st.Pos()
+		if !st.InCode() || !isIdentByte(ch) {
+			continue
+		}
+		if pos > 0 && (isIdentByte(line[pos-1]) || line[pos-1] == '.') {
+			continue
+		}
+		end := pos
+		for end < len(line) && isIdentByte(line[end]) {
+			end++
+		}
+		st.Skip(end - pos - 1)
+		if word := line[pos:end]; word == "end" || blockOpenerKeywords[word] {
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+// ArrayConcatBuiltin and HashMergeBuiltin are the internal builtins an
+// array or hash literal with `...` spread elements is rewritten to. Each
+// argument is a spread source or a literal holding a run of plain elements.
+const (
+	ArrayConcatBuiltin = "__array_concat__"
+	HashMergeBuiltin   = "__hash_merge__"
+)
+
+// expandSpreads rewrites array and hash literals that contain `...`
+// spread elements:
+//
+//	[...xs, 1, 2, ...ys]       →  __array_concat__(xs, [1, 2], ys)
+//	{...base, "port" => 8080}  →  __hash_merge__(base, {"port" => 8080})
+//
+// Element text is kept as is, so multi-line literals keep their line
+// count. A spread in call arguments or an index is an error.
+func expandSpreads(src string, lineMap []int) (string, error) {
+	if !strings.Contains(src, "...") {
+		return src, nil
+	}
+	out, pos, err := rewriteSpreads(src, 0)
+	if err != nil {
+		line := strings.Count(src[:pos], "\n") + 1
+		if lineMap != nil && line-1 < len(lineMap) {
+			line = lineMap[line-1]
+		}
+		return "", fmt.Errorf("line %d: %v", line, err)
+	}
+	return out, nil
+}
+
+// rewriteSpreads rewrites the bracketed groups of s, innermost first. s
+// starts at offset base of the whole source; on error the returned
+// position is the offending bracket's offset in the whole source.
+func rewriteSpreads(s string, base int) (string, int, error) {
+	var sb strings.Builder
+	last := 0
+	st := NewStringTracker(s)
+	for ch, ok := st.Next(); ok; ch, ok = st.Next() {
+		pos := st.Pos()
+		if !st.InCode() || !IsOpenBracket(ch) {
+			continue
+		}
+		closeCh := byte(')')
+		switch ch {
+		case '[':
+			closeCh = ']'
+		case '{':
+			closeCh = '}'
+		}
+		end := findMatchingClose(s, pos, ch, closeCh)
+		if end < 0 {
+			continue
+		}
+		inner, errPos, err := rewriteSpreads(s[pos+1:end], base+pos+1)
+		if err != nil {
+			return "", errPos, err
+		}
+		commas := FindAllTopLevel(inner, func(c byte, _ int, _ string) bool { return c == ',' })
+		elems := splitAtPositions(inner, commas)
+		hasSpread := false
+		for _, e := range elems {
+			if strings.HasPrefix(strings.TrimSpace(e), "...") {
+				hasSpread = true
+				break
+			}
+		}
+		sb.WriteString(s[last:pos])
+		switch {
+		case !hasSpread:
+			sb.WriteByte(ch)
+			sb.WriteString(inner)
+			sb.WriteByte(closeCh)
+		case ch == '(' || ch == '[' && pos > 0 && (isIdentByte(s[pos-1]) || IsCloseBracket(s[pos-1]) || s[pos-1] == '"'):
+			return "", base + pos, fmt.Errorf("`...` spread is only supported inside array and hash literals")
+		default:
+			call, err := spreadCall(ch, closeCh, elems)
+			if err != nil {
+				return "", base + pos, err
+			}
+			sb.WriteString(call)
+		}
+		last = end + 1
+		st.Skip(end - pos)
+	}
+	sb.WriteString(s[last:])
+	return sb.String(), 0, nil
+}
+
+// spreadCall builds the __array_concat__ or __hash_merge__ call for the
+// elements of a literal, grouping runs of plain elements into literals.
+func spreadCall(open, close byte, elems []string) (string, error) {
+	var parts, run []string
+	flush := func() {
+		if len(run) > 0 {
+			parts = append(parts, string(open)+strings.Join(run, ",")+string(close))
+			run = nil
+		}
+	}
+	for _, e := range elems {
+		trimmed := strings.TrimSpace(e)
+		if !strings.HasPrefix(trimmed, "...") {
+			run = append(run, e)
+			continue
+		}
+		if strings.TrimSpace(trimmed[3:]) == "" {
+			return "", fmt.Errorf("`...` needs a value to spread — e.g. `[...items]`")
+		}
+		flush()
+		parts = append(parts, strings.Replace(e, "...", "", 1))
+	}
+	flush()
+	name := ArrayConcatBuiltin
+	if open == '{' {
+		name = HashMergeBuiltin
+	}
+	return name + "(" + strings.Join(parts, ",") + ")", nil
+}
+
+// KwArgBuiltin is the internal builtin a keyword argument is rewritten to.
+// The compiler binds `__kw__("name", value)` call arguments to the named
+// parameter of the called function.
+const KwArgBuiltin = "__kw__"
+
+// ExpandHashColonSyntax rewrites `ident: value` pairs. In a hash literal
+// they become arrow pairs:
+//
 //	{foo: "bar"}  →  {"foo" => "bar"}
 //
+// In call arguments (directly inside parens, or after a paren-free call)
+// they become keyword arguments:
+//
+//	create_user(name: "Ada")  →  create_user(__kw__("name", "Ada"))
+//
 // Only bare identifiers followed by ": " are rewritten. String contents
 // are left untouched. The arrow syntax {expr => val} is unaffected.
 func ExpandHashColonSyntax(src string) (string, error) {
@@ -621,6 +1529,17 @@ func ExpandHashColonSyntax(src string) (string, error) {
 	escaped := false
 	braceDepth := 0
 	line := 1
+	// Open brackets, and the bracket depth of each keyword argument whose
+	// value is still being copied (closed at the next , or ) at that depth,
+	// or at the end of the line for a paren-free call).
+	var brackets []byte
+	var kwDepths []int
+	closeKw := func(depth int) {
+		if n := len(kwDepths); n > 0 && kwDepths[n-1] == depth {
+			sb.WriteByte(')')
+			kwDepths = kwDepths[:n-1]
+		}
+	}
 
 	i := 0
 	for i < len(src) {
@@ -628,6 +1547,9 @@ func ExpandHashColonSyntax(src string) (string, error) {
 
 		if ch == '\n' {
 			line++
+			if !inDouble && !inSingle && !inBacktick && len(brackets) == 0 {
+				closeKw(0)
+			}
 		}
 
 		if escaped {
@@ -666,10 +1588,22 @@ func ExpandHashColonSyntax(src string) (string, error) {
 		}
 
 		if !inDouble && !inSingle && !inBacktick {
-			if ch == '{' {
+			switch ch {
+			case '{':
 				braceDepth++
-			} else if ch == '}' && braceDepth > 0 {
-				braceDepth--
+				brackets = append(brackets, ch)
+			case '(', '[':
+				brackets = append(brackets, ch)
+			case '}', ')', ']':
+				if ch == '}' && braceDepth > 0 {
+					braceDepth--
+				}
+				closeKw(len(brackets))
+				if len(brackets) > 0 {
+					brackets = brackets[:len(brackets)-1]
+				}
+			case ',':
+				closeKw(len(brackets))
 			}
 		}
 
@@ -691,6 +1625,16 @@ func ExpandHashColonSyntax(src string) (string, error) {
 					sb.WriteString(ident)
 					continue
 				}
+				inCall := len(brackets) > 0 && brackets[len(brackets)-1] == '('
+				parenFree := len(brackets) == 0 && linePrefix != ""
+				if inCall || parenFree {
+					sb.WriteString(KwArgBuiltin + `("`)
+					sb.WriteString(ident)
+					sb.WriteString(`",`)
+					kwDepths = append(kwDepths, len(brackets))
+					i++ // skip the ':'
+					continue
+				}
 				sb.WriteByte('"')
 				sb.WriteString(ident)
 				sb.WriteString(`" =>`)
@@ -719,6 +1663,7 @@ func ExpandHashColonSyntax(src string) (string, error) {
 		sb.WriteByte(ch)
 		i++
 	}
+	closeKw(0)
 
 	return sb.String(), nil
 }
@@ -806,6 +1751,20 @@ func isOperatorStart(ch byte) bool {
 	return false
 }
 
+// startsWithVar reports whether the hyphenated word tok begins with a
+// known variable, making it a subtraction such as `n-1` rather than a
+// command such as `apt-get`.
+func startsWithVar(tok string, knownVars map[string]bool) bool {
+	name, _, _ := strings.Cut(tok, "-")
+	return knownVars[name]
+}
+
+// startsWithInOperator reports whether s starts with the `in` membership
+// operator followed by its operand, as in the rest of `x in xs`.
+func startsWithInOperator(s string) bool {
+	return strings.HasPrefix(s, "in ") || strings.HasPrefix(s, "in\t")
+}
+
 // shellEscape escapes a string for embedding in a Go/rugo string literal.
 func shellEscape(s string) string {
 	s = strings.ReplaceAll(s, `\`, `\\`)
@@ -867,6 +1826,10 @@ func ScanFuncDefs(src string) map[string]bool {
 		if strings.HasPrefix(trimmed, "def ") {
 			rest := strings.TrimSpace(trimmed[4:])
 			name, _ := scanFirstToken(rest)
+			// Predicate names register under their compiled name.
+			if base, ok := strings.CutSuffix(name, "?"); ok {
+				name = base + PredicateSuffix
+			}
 			if isIdent(name) {
 				funcs[name] = true
 			}
@@ -875,6 +1838,14 @@ func ScanFuncDefs(src string) map[string]bool {
 	return funcs
 }
 
+// ComparatorPrefix names the function a `def Type.<=>(other)` method is
+// rewritten to. The compiler registers these as the type's comparator.
+const ComparatorPrefix = "__cmp_"
+
+// ExitPrefix names the function a `def Type.__exit__()` method is rewritten
+// to. The compiler registers these as the type's `with` cleanup hook.
+const ExitPrefix = "__exit_"
+
 // expandStructDefs rewrites struct definitions and method definitions.
 //
 // struct Dog
@@ -900,12 +1871,22 @@ func ScanFuncDefs(src string) map[string]bool {
 // becomes:
 //
 //	def bark(self)
-func ExpandStructDefs(src string) (string, []int, []StructInfo) {
+//
+// A field written as `breed = "mutt"` becomes a defaulted constructor
+// parameter (`def Dog(name, breed = "mutt")`), so defaulted fields must
+// come after the required ones.
+//
+// The comparison method def Dog.<=>(other) becomes def __cmp_Dog(self, other).
+//
+// Since self is injected, a method that declares it explicitly
+// (def Dog.bark(self)) is an error.
+func ExpandStructDefs(src string) (string, []int, []StructInfo, error) {
 	lines := strings.Split(src, "\n")
 	var result []string
 	var lineMap []int
 	var structs []StructInfo
 	structNames := make(map[string]bool)
+	methods := make(map[string][]string)
 
 	// First pass: collect struct names
 	for _, line := range lines {
@@ -935,8 +1916,9 @@ func ExpandStructDefs(src string) (string, []int, []StructInfo) {
 				continue
 			}
 
-			// Collect field names until "end"
+			// Collect field names (and `field = default` lines) until "end"
 			var fields []string
+			var defaults map[string]string
 			i++
 			for i < len(lines) {
 				ft := strings.TrimSpace(lines[i])
@@ -946,14 +1928,28 @@ func ExpandStructDefs(src string) (string, []int, []StructInfo) {
 				}
 				if isIdent(ft) {
 					fields = append(fields, ft)
+				} else if field, def, ok := structFieldDefault(ft); ok {
+					fields = append(fields, field)
+					if defaults == nil {
+						defaults = make(map[string]string)
+					}
+					defaults[field] = def
 				}
 				i++
 			}
 
-			structs = append(structs, StructInfo{Name: name, Fields: fields, Line: origLine})
+			structs = append(structs, StructInfo{Name: name, Fields: fields, Defaults: defaults, Line: origLine})
 
-			// Generate constructor: def Name(field1, field2)
-			params := strings.Join(fields, ", ")
+			// Generate constructor: def Name(field1, field2 = default)
+			var paramList []string
+			for _, f := range fields {
+				if def, ok := defaults[f]; ok {
+					paramList = append(paramList, f+" = "+def)
+				} else {
+					paramList = append(paramList, f)
+				}
+			}
+			params := strings.Join(paramList, ", ")
 			var pairs []string
 			for _, f := range fields {
 				pairs = append(pairs, fmt.Sprintf(`"%s" => %s`, f, f))
@@ -995,12 +1991,25 @@ func ExpandStructDefs(src string) (string, []int, []StructInfo) {
 					parenIdx := strings.Index(afterDot, "(")
 					if parenIdx >= 0 {
 						methodName := afterDot[:parenIdx]
+						if methodName == "<=>" {
+							// Comparison method: registered as the type's comparator.
+							methodName = ComparatorPrefix + typeName
+						} else if methodName == "__exit__" {
+							// Cleanup method: run when a with block ends.
+							methodName = ExitPrefix + typeName
+						}
 						paramsStr := afterDot[parenIdx+1:]
 						// Remove closing paren if present
 						if idx := strings.Index(paramsStr, ")"); idx >= 0 {
 							paramsStr = paramsStr[:idx]
 						}
 						paramsStr = strings.TrimSpace(paramsStr)
+						for _, p := range strings.Split(paramsStr, ",") {
+							if name, _, _ := strings.Cut(p, "="); strings.TrimSpace(name) == "self" {
+								return "", nil, nil, fmt.Errorf("line %d: `def %s.%s` must not declare `self` — it is added automatically", origLine, typeName, afterDot[:parenIdx])
+							}
+						}
+						methods[typeName] = append(methods[typeName], methodName)
 						if paramsStr != "" {
 							paramsStr = "self, " + paramsStr
 						} else {
@@ -1020,15 +2029,45 @@ func ExpandStructDefs(src string) (string, []int, []StructInfo) {
 		i++
 	}
 
-	return strings.Join(result, "\n"), lineMap, structs
+	for i := range structs {
+		structs[i].Methods = methods[structs[i].Name]
+	}
+	return strings.Join(result, "\n"), lineMap, structs, nil
+}
+
+// structFieldDefault splits a `field = default` struct line into the field
+// name and its default expression.
+func structFieldDefault(line string) (field, def string, ok bool) {
+	eq := strings.Index(line, "=")
+	if eq <= 0 || strings.HasPrefix(line[eq:], "==") || strings.HasPrefix(line[eq:], "=>") {
+		return "", "", false
+	}
+	field = strings.TrimSpace(line[:eq])
+	def = strings.TrimSpace(line[eq+1:])
+	if !isIdent(field) || def == "" {
+		return "", "", false
+	}
+	return field, def, true
 }
 
 // processInterpolation converts "Hello #{expr}" to format string + args.
 // Returns the format string and a list of expression strings.
+// A backslash before #{ escapes it: \#{ is a literal #{. Backslashes in
+// front of #{ come in pairs for each literal backslash (the walker doubles
+// them), so \\#{x} is a backslash followed by an interpolation.
 func ProcessInterpolation(s string) (format string, exprs []string, err error) {
 	var buf strings.Builder
 	i := 0
 	for i < len(s) {
+		if n := backslashesBeforeInterp(s, i); n > 0 {
+			buf.WriteString(strings.Repeat(`\`, n/2))
+			i += n
+			if n%2 == 1 {
+				buf.WriteString("#{")
+				i += 2
+			}
+			continue
+		}
 		if i+1 < len(s) && s[i] == '#' && s[i+1] == '{' {
 			// Find matching }
 			depth := 1
@@ -1057,8 +2096,16 @@ func ProcessInterpolation(s string) (format string, exprs []string, err error) {
 }
 
 // hasInterpolation checks if a string contains #{} interpolation.
+// Escaped \#{ sequences don't count.
 func HasInterpolation(s string) bool {
 	for i := 0; i+1 < len(s); i++ {
+		if n := backslashesBeforeInterp(s, i); n > 0 {
+			if n%2 == 0 {
+				return true
+			}
+			i += n + 1
+			continue
+		}
 		if s[i] == '#' && s[i+1] == '{' {
 			return true
 		}
@@ -1066,6 +2113,28 @@ func HasInterpolation(s string) bool {
 	return false
 }
 
+// isEscaped reports whether src[pos] follows an odd run of backslashes.
+func isEscaped(src string, pos int) bool {
+	n := 0
+	for pos-n-1 >= 0 && src[pos-n-1] == '\\' {
+		n++
+	}
+	return n%2 == 1
+}
+
+// backslashesBeforeInterp returns the length of the run of backslashes
+// starting at s[i] when it is directly followed by #{, and 0 otherwise.
+func backslashesBeforeInterp(s string, i int) int {
+	j := i
+	for j < len(s) && s[j] == '\\' {
+		j++
+	}
+	if j == i || !strings.HasPrefix(s[j:], "#{") {
+		return 0
+	}
+	return j - i
+}
+
 // heredocOpener describes a parsed heredoc opening token (e.g. <<~'DELIM').
 type heredocOpener struct {
 	delimiter string // e.g. "HTML", "SQL"
@@ -1819,24 +2888,135 @@ func isAlphaNum(ch byte) bool {
 	return isAlpha(ch) || (ch >= '0' && ch <= '9')
 }
 
+func isIdentByte(ch byte) bool {
+	return isAlphaNum(ch) || ch == '_'
+}
+
 // isInsideString reports whether position pos in line falls inside a string literal.
 func isInsideString(line string, pos int) bool {
 	return IsInsideString(line, pos)
 }
 
-// findTopLevelOr finds " or " at the top level (not inside parens, brackets, or strings).
-// Returns the index of the start of " or " in s, or -1 if not found.
-func findTopLevelOr(s string) int {
-	pos := FindTopLevel(s, func(ch byte, pos int, src string) bool {
-		if ch != ' ' {
-			return false
+// findTopLevelOr finds " or " at the top level (not inside parens, brackets, or strings).
+// Returns the index of the start of " or " in s, or -1 if not found.
+func findTopLevelOr(s string) int {
+	pos := FindTopLevel(s, func(ch byte, pos int, src string) bool {
+		if ch != ' ' {
+			return false
+		}
+		return pos+4 <= len(src) && src[pos:pos+4] == " or "
+	})
+	if pos < 0 {
+		return -1
+	}
+	return pos + 1 // return index of 'o' in "or"
+}
+
+// expandMultiReturn desugars returning several values into returning an
+// array, so the caller can destructure it:
+//
+//	return a / b, a % b    → return [a / b, a % b]
+//
+// The values run to the end of the line, a closing bracket, or the `end`
+// of a single-line def, and must contain a comma outside strings and
+// brackets. Returns nested in a lambda are rewritten on their own.
+func expandMultiReturn(src string) string {
+	lines := strings.Split(src, "\n")
+	for i, line := range lines {
+		if !strings.Contains(line, "return") {
+			continue
+		}
+		positions := findReturnKeywords(line)
+		// Rewrite right to left so a return nested in the values of an
+		// outer one is handled first and earlier offsets stay valid.
+		for j := len(positions) - 1; j >= 0; j-- {
+			start := positions[j] + len("return")
+			end, multi := returnValuesEnd(line, start)
+			if !multi {
+				continue
+			}
+			values := strings.TrimSpace(line[start:end])
+			rest := strings.TrimLeft(line[end:], " \t")
+			if rest != "" && !IsCloseBracket(rest[0]) {
+				rest = " " + rest
+			}
+			lines[i] = line[:start] + " [" + values + "]" + rest
+			line = lines[i]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// findReturnKeywords returns the offsets of `return` keywords in line,
+// outside strings and not part of a longer identifier or a method name.
+func findReturnKeywords(line string) []int {
+	var positions []int
+	sc := NewStringTracker(line)
+	for ch, ok := sc.Next(); ok; ch, ok = sc.Next() {
+		pos := sc.Pos()
+		if sc.InString() || ch != 'r' || !strings.HasPrefix(line[pos:], "return") {
+			continue
+		}
+		if pos > 0 && (isIdentByte(line[pos-1]) || line[pos-1] == '.') {
+			continue
+		}
+		if after := pos + len("return"); after < len(line) && (isIdentByte(line[after]) || line[after] == '?') {
+			continue
+		}
+		positions = append(positions, pos)
+	}
+	return positions
+}
+
+// returnValuesEnd scans the values of a return starting at offset start
+// and returns where they end and whether they hold a top-level comma.
+// The values stop at a bracket closing an enclosing one, a postfix
+// if/unless, or an `end` not matched by a fn or do inside the values.
+func returnValuesEnd(line string, start int) (int, bool) {
+	depth, blocks := 0, 0
+	multi := false
+	sc := NewStringTracker(line)
+	sc.Skip(start)
+	for ch, ok := sc.Next(); ok; ch, ok = sc.Next() {
+		pos := sc.Pos()
+		if sc.InString() {
+			continue
+		}
+		switch {
+		case IsOpenBracket(ch):
+			depth++
+		case IsCloseBracket(ch):
+			if depth == 0 {
+				return pos, multi
+			}
+			depth--
+		case ch == ',' && depth == 0 && blocks == 0:
+			multi = true
+		case isAlpha(ch) && depth == 0 && (pos == 0 || !isIdentByte(line[pos-1])):
+			word := line[pos:]
+			for k := 0; k < len(word); k++ {
+				if !isIdentByte(word[k]) {
+					word = word[:k]
+					break
+				}
+			}
+			switch word {
+			case "fn", "do":
+				blocks++
+			case "end":
+				if blocks == 0 {
+					return pos, multi
+				}
+				blocks--
+			case "if", "unless":
+				if blocks == 0 {
+					return pos, multi
+				}
+			}
+			sc.Skip(len(word) - 1)
 		}
-		return pos+4 <= len(src) && src[pos:pos+4] == " or "
-	})
-	if pos < 0 {
-		return -1
 	}
-	return pos + 1 // return index of 'o' in "or"
+	return len(line), multi
 }
 
 // expandDestructuring desugars array destructuring assignments.
@@ -2099,10 +3279,12 @@ var rugoVoidBuiltins = map[string]bool{
 //   - Shell command on right → piped value fed to stdin
 //
 // If ALL segments are shell commands, the line is returned unchanged so the
-// shell handles native pipes (e.g. `ls | grep foo`).
+// shell handles native pipes (e.g. `ls | grep foo`). When every segment after
+// the first is a plain value (see isValueSegment), the | is bitwise or and
+// the line is also returned unchanged (e.g. `flags = READ | WRITE`).
 // Returns an error if a void-returning builtin (puts, print) appears as a
 // non-final segment.
-func expandPipeLine(line string, funcs map[string]bool) (string, error) {
+func expandPipeLine(line string, funcs, knownVars map[string]bool) (string, error) {
 	trimmed := strings.TrimSpace(line)
 	if trimmed == "" {
 		return line, nil
@@ -2127,6 +3309,18 @@ func expandPipeLine(line string, funcs map[string]bool) (string, error) {
 	// Split into segments
 	segments := splitAtPositions(expr, pipes)
 
+	// A value can't receive a piped input, so `x | 4` or `a | b` is bitwise or.
+	bitwise := true
+	for _, seg := range segments[1:] {
+		if !isValueSegment(strings.TrimSpace(seg), funcs, knownVars) {
+			bitwise = false
+			break
+		}
+	}
+	if bitwise {
+		return line, nil
+	}
+
 	// If ALL segments are shell commands, return unchanged (shell handles native pipes)
 	hasRugo := false
 	for _, seg := range segments {
@@ -2227,6 +3421,25 @@ func isRugoSegment(seg string, funcs map[string]bool) bool {
 	return false
 }
 
+// isValueSegment reports whether a segment to the right of a | is an operand
+// rather than a pipe target: a number, a parenthesized expression, or an
+// expression starting with a known variable (including `v.field` and
+// `v[i]`) that is not also a function name.
+func isValueSegment(seg string, funcs, knownVars map[string]bool) bool {
+	if seg == "" {
+		return false
+	}
+	if seg[0] == '(' || seg[0] >= '0' && seg[0] <= '9' {
+		return true
+	}
+	if seg[0] == '-' && len(seg) > 1 && seg[1] >= '0' && seg[1] <= '9' {
+		return true
+	}
+	tok, _ := scanFirstToken(seg)
+	name, _, _ := strings.Cut(tok, ".")
+	return knownVars[name] && !funcs[name] && !rugoBuiltins[name]
+}
+
 // isShellPipeSegment returns true if the segment should be treated as a shell
 // command in a pipe chain.
 func isShellPipeSegment(seg string, funcs map[string]bool) bool {
@@ -2757,18 +3970,20 @@ func findDoAssignment(s string) int {
 // preprocessing. Structs are expanded into constructor functions before
 // parsing, so they don't appear in the AST as nodes.
 type StructInfo struct {
-	Name   string   // struct name (e.g. "Dog")
-	Fields []string // field names
-	Line   int      // 1-based line number of the struct keyword in original source
+	Name     string            // struct name (e.g. "Dog")
+	Fields   []string          // field names
+	Defaults map[string]string // default expressions for `field = default` lines
+	Line     int               // 1-based line number of the struct keyword in original source
+	Methods  []string          // function names of its methods (def Dog.bark → "bark")
 }
 
 // blockStartKeywords are tokens that, when they start a line, indicate
 // a block-level statement. Lines starting with these are never postfix-if.
 var blockStartKeywords = map[string]bool{
-	"if": true, "elsif": true, "else": true, "end": true,
+	"if": true, "elsif": true, "else": true, "end": true, "unless": true,
 	"while": true, "for": true, "def": true, "return": true,
-	"require": true, "import": true, "use": true,
-	"rats": true, "try": true, "spawn": true, "parallel": true,
+	"require": true, "require_optional": true, "import": true, "use": true,
+	"rats": true, "try": true, "ensure": true, "spawn": true, "parallel": true,
 	"bench": true, "fn": true, "struct": true, "sandbox": true,
 	"setup": true, "teardown": true, "setup_file": true, "teardown_file": true,
 }
@@ -2814,18 +4029,482 @@ func expandPostfixIf(src string) string {
 // that appears at the top level (outside strings, backticks, and brackets).
 // Returns -1 if none found.
 func findLastTopLevelIf(line string) int {
+	return findLastTopLevelKeyword(line, "if")
+}
+
+// findLastTopLevelKeyword returns the byte offset of the space before the
+// last top-level occurrence of kw surrounded by spaces (or ending the line).
+// Returns -1 if none found.
+func findLastTopLevelKeyword(line, kw string) int {
+	word := " " + kw
 	positions := FindAllTopLevel(line, func(ch byte, pos int, src string) bool {
-		if ch != ' ' {
-			return false
-		}
-		// Check for " if " starting at this position
-		if pos+4 > len(src) {
+		if ch != ' ' || pos+len(word) > len(src) || src[pos:pos+len(word)] != word {
 			return false
 		}
-		return src[pos:pos+4] == " if " || (src[pos:pos+3] == " if" && pos+3 == len(src))
+		end := pos + len(word)
+		return end == len(src) || src[end] == ' '
 	})
 	if len(positions) == 0 {
 		return -1
 	}
 	return positions[len(positions)-1]
 }
+
+// expandUnless rewrites block "unless COND" → "if !(COND)". The condition
+// is wrapped in parens so the negation applies to the whole expression.
+// Like Ruby, an unless block cannot have elsif or else clauses.
+func expandUnless(src string) (string, error) {
+	lines := strings.Split(src, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		first, rest := scanFirstToken(trimmed)
+		if first != "unless" {
+			continue
+		}
+		cond := strings.TrimSpace(rest)
+		if cond == "" {
+			return "", fmt.Errorf("line %d: `unless` requires a condition", i+1)
+		}
+		if j, kw := blockElseClause(lines, i); j >= 0 {
+			return "", fmt.Errorf("line %d: `unless` does not support `%s` — use `if` instead", j+1, kw)
+		}
+		indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		lines[i] = indent + "if !(" + cond + ")"
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// WhileElseBuiltin is the internal builtin that marks where the else
+// clause of a while loop begins. The walker moves the statements after it
+// into WhileStmt.ElseBody.
+const WhileElseBuiltin = "__while_else__"
+
+// expandWhileElse rewrites the else line of a while loop into a call to
+// the internal __while_else__ builtin, since the grammar has no else for
+// loops:
+//
+//	while i < n        while i < n
+//	  ...          →     ...
+//	else                 __while_else__()
+//	  puts "none"        puts "none"
+//	end                end
+func expandWhileElse(src string) (string, error) {
+	if !strings.Contains(src, "els") {
+		return src, nil
+	}
+	lines := strings.Split(src, "\n")
+	for i, line := range lines {
+		words := blockWords(strings.TrimSpace(line))
+		if len(words) != 1 || words[0] != "while" {
+			continue
+		}
+		j, kw := blockElseClause(lines, i)
+		if j < 0 {
+			continue
+		}
+		if kw == "elsif" {
+			return "", fmt.Errorf("line %d: `while` does not support `elsif` — only `else`", j+1)
+		}
+		indent := lines[j][:len(lines[j])-len(strings.TrimLeft(lines[j], " \t"))]
+		lines[j] = indent + WhileElseBuiltin + "()"
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// ParallelLimitBuiltin is the internal builtin that carries the limit of a
+// `parallel(limit: N)` block. It is emitted as the first statement of the
+// block body, where the walker lifts it into ParallelExpr.Limit.
+const ParallelLimitBuiltin = "__parallel_limit__"
+
+// expandParallelLimit rewrites the limit option of a parallel block into a
+// leading call to the internal __parallel_limit__ builtin:
+//
+//	x = parallel(limit: 4)  →  x = parallel __parallel_limit__(4)
+//
+// The grammar has no arguments for parallel, and the body may itself start
+// with '(', so the option travels as a body statement instead.
+func expandParallelLimit(src string) (string, error) {
+	lines := strings.Split(src, "\n")
+	for i, line := range lines {
+		at := -1
+		st := NewStringTracker(line)
+		for ch, ok := st.Next(); ok; ch, ok = st.Next() {
+			pos := st.Pos()
+			if ch == 'p' && st.InCode() && st.LookingAt("parallel(") && (pos == 0 || !isIdentByte(line[pos-1])) {
+				at = pos
+				break
+			}
+		}
+		if at < 0 {
+			continue
+		}
+		open := at + len("parallel")
+		close := findMatchingClose(line, open, '(', ')')
+		if close < 0 || strings.TrimSpace(line[close+1:]) != "" {
+			return "", fmt.Errorf("line %d: `parallel(...)` must end the line — e.g. `parallel(limit: 4)`", i+1)
+		}
+		opt := strings.TrimSpace(line[open+1 : close])
+		limit, ok := strings.CutPrefix(opt, "limit:")
+		limit = strings.TrimSpace(limit)
+		if !ok || limit == "" {
+			return "", fmt.Errorf("line %d: `parallel` only accepts a limit — e.g. `parallel(limit: 4)`", i+1)
+		}
+		lines[i] = line[:open] + " " + ParallelLimitBuiltin + "(" + limit + ")"
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// ConstPrefix marks the target of a `const` declaration. The walker strips
+// it and sets AssignStmt.Const.
+const ConstPrefix = "__const__"
+
+// expandConst rewrites `const NAME = value` into an assignment whose target
+// carries ConstPrefix, so the rest of the pipeline sees a plain assignment
+// (multi-line values included):
+//
+//	const max_retries = 5  →  __const__max_retries = 5
+func expandConst(src string) (string, error) {
+	if !strings.Contains(src, "const") {
+		return src, nil
+	}
+	lines := strings.Split(src, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		tok, rest := scanFirstToken(trimmed)
+		if tok != "const" {
+			continue
+		}
+		name, after := scanFirstToken(strings.TrimSpace(rest))
+		after = strings.TrimSpace(after)
+		if !isIdent(name) || RugoKeywords[name] || !strings.HasPrefix(after, "=") || strings.HasPrefix(after, "==") {
+			return "", fmt.Errorf("line %d: `const` declares a single name with a value — e.g. `const MAX = 10`", i+1)
+		}
+		indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		lines[i] = indent + ConstPrefix + name + " " + after
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// SafeNavPrefix marks the field of a safe navigation `&.`. The walker
+// strips it and sets DotExpr.Safe.
+const SafeNavPrefix = "__safe__"
+
+// ExpandSafeNav rewrites the safe navigation operator into a plain dot
+// whose field carries SafeNavPrefix, since the grammar only knows '.':
+//
+//	user&.profile&.email  →  user.__safe__profile.__safe__email
+//	user&.greet("hi")     →  user.__safe__greet("hi")
+//
+// Only `&.` directly followed by a name is rewritten, so a bitwise `&`
+// before a float like `x &.5` is left alone.
+func ExpandSafeNav(src string) string {
+	if !strings.Contains(src, "&.") {
+		return src
+	}
+	lines := strings.Split(src, "\n")
+	for i, line := range lines {
+		if !strings.Contains(line, "&.") {
+			continue
+		}
+		var sb strings.Builder
+		st := NewStringTracker(line)
+		for ch, ok := st.Next(); ok; ch, ok = st.Next() {
+			pos := st.Pos()
+			if ch == '&' && st.InCode() && st.LookingAt("&.") && pos+2 < len(line) &&
+				isIdentByte(line[pos+2]) && !(line[pos+2] >= '0' && line[pos+2] <= '9') {
+				sb.WriteString("." + SafeNavPrefix)
+				st.Skip(1) // the '.'
+				continue
+			}
+			sb.WriteByte(ch)
+		}
+		lines[i] = sb.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// BenchOptionsBuiltin is the internal builtin that carries the options of
+// a `bench "name", iterations: N` block. It is emitted as the first
+// statement of the block body, where the walker lifts it into
+// BenchDef.Iterations and BenchDef.MinTime.
+const BenchOptionsBuiltin = "__bench_options__"
+
+// expandBenchOptions rewrites the options of a bench block into a leading
+// call to the internal __bench_options__ builtin, taking option name and
+// value pairs:
+//
+//	bench "sort", iterations: 500  →  bench "sort"; __bench_options__("iterations", 500)
+//	bench "sort", min_time: 0.5    →  bench "sort"; __bench_options__("min_time", 0.5)
+func expandBenchOptions(src string) (string, error) {
+	if !strings.Contains(src, "bench") {
+		return src, nil
+	}
+	lines := strings.Split(src, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if tok, _ := scanFirstToken(trimmed); tok != "bench" {
+			continue
+		}
+		isComma := func(ch byte, _ int, _ string) bool { return ch == ',' }
+		commas := FindAllTopLevel(line, isComma)
+		if len(commas) == 0 {
+			continue
+		}
+		rest := line[commas[0]+1:]
+		usage := fmt.Errorf("line %d: bench options must be `iterations: N` or `min_time: SECONDS` — e.g. `bench \"sort\", iterations: 1000`", i+1)
+		var args []string
+		seen := make(map[string]bool)
+		for _, opt := range splitAtPositions(rest, FindAllTopLevel(rest, isComma)) {
+			name, value, ok := strings.Cut(strings.TrimSpace(opt), ":")
+			name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+			if !ok || value == "" || (name != "iterations" && name != "min_time") {
+				return "", usage
+			}
+			if seen[name] {
+				return "", fmt.Errorf("line %d: bench option %s given twice", i+1, name)
+			}
+			seen[name] = true
+			args = append(args, `"`+name+`"`, value)
+		}
+		if seen["iterations"] && seen["min_time"] {
+			return "", fmt.Errorf("line %d: bench takes either iterations: or min_time:, not both", i+1)
+		}
+		lines[i] = line[:commas[0]] + "; " + BenchOptionsBuiltin + "(" + strings.Join(args, ", ") + ")"
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// ImportFuncsBuiltin is the internal builtin that carries the function
+// renames of a Go bridge import. It follows the import on the same line,
+// where the walker lifts it into ImportStmt.Funcs.
+const ImportFuncsBuiltin = "__import_funcs__"
+
+// expandImportRenames rewrites the rename block of an import into a
+// trailing call to the internal __import_funcs__ builtin:
+//
+//	import "strings" as {title_case: ToTitle}
+//	  →  import "strings"; __import_funcs__("title_case", "ToTitle")
+//	import "strings" as s {title_case: ToTitle}
+//	  →  import "strings" as s; __import_funcs__("title_case", "ToTitle")
+//
+// The renamed names are returned so paren-free calls to them are
+// recognized like calls to user functions.
+func expandImportRenames(src string) (string, map[string]bool, error) {
+	names := make(map[string]bool)
+	if !strings.Contains(src, "import") {
+		return src, names, nil
+	}
+	lines := strings.Split(src, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if !strings.HasPrefix(trimmed, "import ") || !strings.HasSuffix(trimmed, "}") {
+			continue
+		}
+		usage := fmt.Errorf("line %d: import renames must be `name: GoFunc` pairs — e.g. `import \"strings\" as {title_case: ToTitle}`", i+1)
+		rest := strings.TrimSpace(trimmed[len("import "):])
+		if !strings.HasPrefix(rest, `"`) {
+			continue
+		}
+		end := strings.IndexByte(rest[1:], '"')
+		if end < 0 {
+			continue
+		}
+		pkg := rest[:end+2]
+		rest = strings.TrimSpace(rest[end+2:])
+		as, rest := scanFirstToken(rest)
+		if as != "as" {
+			continue
+		}
+		open := strings.IndexByte(rest, '{')
+		if open < 0 {
+			continue
+		}
+		head := "import " + pkg
+		if alias := strings.TrimSpace(rest[:open]); alias != "" {
+			if !isIdent(alias) {
+				return "", nil, usage
+			}
+			head += " as " + alias
+		}
+		var args []string
+		seen := make(map[string]bool)
+		for _, pair := range strings.Split(rest[open+1:len(rest)-1], ",") {
+			name, goName, ok := strings.Cut(pair, ":")
+			name, goName = strings.TrimSpace(name), strings.TrimSpace(goName)
+			if !ok || !isIdent(name) || !isIdent(goName) {
+				return "", nil, usage
+			}
+			if seen[name] {
+				return "", nil, fmt.Errorf("line %d: `%s` is renamed more than once in this import", i+1, name)
+			}
+			seen[name] = true
+			names[name] = true
+			args = append(args, `"`+name+`"`, `"`+goName+`"`)
+		}
+		indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		lines[i] = indent + head + "; " + ImportFuncsBuiltin + "(" + strings.Join(args, ", ") + ")"
+	}
+	return strings.Join(lines, "\n"), names, nil
+}
+
+// WithBuiltin is the internal builtin a `with` block is rewritten to. The
+// compiler lowers `if __with__(name)` to acquire, deferred cleanup and body.
+const WithBuiltin = "__with__"
+
+// expandWith rewrites a `with` resource block into an if statement around
+// the internal __with__ builtin, keeping the binding name as its first
+// argument so later passes know the variable:
+//
+//	with open_db(path) as db  →  if __with__("db", open_db(path))
+//
+// splitWithBindings later turns that into `db = open_db(path); if __with__(db)`.
+// Like unless, a with block cannot have elsif or else clauses.
+func expandWith(src string) (string, error) {
+	lines := strings.Split(src, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		first, rest := scanFirstToken(trimmed)
+		if first != "with" {
+			continue
+		}
+		expr, name, ok := splitWithAs(strings.TrimSpace(rest))
+		if !ok {
+			return "", fmt.Errorf("line %d: `with` requires a resource and a name — e.g. `with open_file(path) as f`", i+1)
+		}
+		if !isIdent(name) || RugoKeywords[name] {
+			return "", fmt.Errorf("line %d: `with` expects a variable name after `as`, got %q", i+1, name)
+		}
+		if j, kw := blockElseClause(lines, i); j >= 0 {
+			return "", fmt.Errorf("line %d: `with` does not support `%s`", j+1, kw)
+		}
+		indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		lines[i] = indent + "if " + WithBuiltin + "(\"" + name + "\", " + expr + ")"
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// splitWithAs splits "EXPR as NAME" at the last ` as ` outside strings.
+func splitWithAs(s string) (expr, name string, ok bool) {
+	at := -1
+	st := NewStringTracker(s)
+	for {
+		_, more := st.Next()
+		if !more {
+			break
+		}
+		if st.InCode() && st.LookingAt(" as ") {
+			at = st.Pos()
+		}
+	}
+	if at <= 0 {
+		return "", "", false
+	}
+	expr = strings.TrimSpace(s[:at])
+	name = strings.TrimSpace(s[at+len(" as "):])
+	return expr, name, expr != "" && name != ""
+}
+
+// withBinding returns the variable name of a line rewritten by expandWith.
+func withBinding(trimmed string) (string, bool) {
+	rest, ok := strings.CutPrefix(trimmed, "if "+WithBuiltin+"(\"")
+	if !ok {
+		return "", false
+	}
+	end := strings.Index(rest, "\"")
+	if end < 0 {
+		return "", false
+	}
+	return rest[:end], true
+}
+
+// splitWithBindings moves the resource expression out of each __with__
+// condition into an assignment on the same line, so the binding is an
+// ordinary variable for the rest of the compiler:
+//
+//	if __with__("db", open_db(path))  →  db = open_db(path); if __with__(db)
+func splitWithBindings(src string) string {
+	if !strings.Contains(src, WithBuiltin+"(\"") {
+		return src
+	}
+	lines := strings.Split(src, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		name, ok := withBinding(trimmed)
+		if !ok || !strings.HasSuffix(trimmed, ")") {
+			continue
+		}
+		prefix := "if " + WithBuiltin + "(\"" + name + "\","
+		expr := strings.TrimSpace(trimmed[len(prefix) : len(trimmed)-1])
+		indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		lines[i] = indent + name + " = " + expr + "; if " + WithBuiltin + "(" + name + ")"
+	}
+	return strings.Join(lines, "\n")
+}
+
+// blockElseClause scans the body of the block opened at line start and
+// returns the index and keyword of an elsif or else that belongs to it,
+// or -1 when there is none.
+func blockElseClause(lines []string, start int) (int, string) {
+	depth := 1
+	for i := start + 1; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if trimmed == "" {
+			continue
+		}
+		if _, isDo := extractDoPrefix(lines[i]); isDo {
+			depth++
+			continue
+		}
+		first, _ := scanFirstToken(trimmed)
+		depth += countFnOpens(trimmed)
+		switch first {
+		case "def", "if", "unless", "while", "for", "try", "spawn", "parallel",
+			"rats", "bench", "struct", "case", "with":
+			depth++
+		case "elsif", "else":
+			if depth == 1 {
+				return i, first
+			}
+		}
+		depth -= countEnds(trimmed)
+		if depth <= 0 {
+			return -1, ""
+		}
+	}
+	return -1, ""
+}
+
+// expandPostfixUnless rewrites "STMT unless COND" → "if !(COND)\nSTMT\nend",
+// mirroring expandPostfixIf.
+func expandPostfixUnless(src string) string {
+	lines := strings.Split(src, "\n")
+	var result []string
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+
+		firstTok, _ := scanFirstToken(trimmed)
+		if blockStartKeywords[firstTok] || trimmed == "" {
+			result = append(result, line)
+			continue
+		}
+
+		idx := findLastTopLevelKeyword(trimmed, "unless")
+		if idx < 0 {
+			result = append(result, line)
+			continue
+		}
+
+		stmt := strings.TrimSpace(trimmed[:idx])
+		cond := strings.TrimSpace(trimmed[idx+len(" unless"):])
+		if stmt == "" || cond == "" {
+			result = append(result, line)
+			continue
+		}
+
+		result = append(result, indent+"if !("+cond+")")
+		result = append(result, indent+"  "+stmt)
+		result = append(result, indent+"end")
+	}
+	return strings.Join(result, "\n")
+}
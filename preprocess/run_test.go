@@ -0,0 +1,300 @@
+package preprocess
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunComposesLineMaps(t *testing.T) {
+	// Heredoc (lines 1-4) and struct (lines 5-7) both change the line
+	// count; the final map must still point at the original lines.
+	src := "x = <<~TXT\n  a\nTXT\nputs(x)\nstruct Dog\n  name\nend\nputs(Dog(\"rex\").name)\n"
+	out, lineMap, structs, err := Run(src)
+	require.NoError(t, err)
+	require.Len(t, structs, 1)
+	assert.Equal(t, "Dog", structs[0].Name)
+
+	lines := strings.Split(out, "\n")
+	require.Len(t, lineMap, len(lines))
+	assert.Equal(t, 1, lineMap[0])
+	for i, line := range lines {
+		if strings.Contains(line, `Dog("rex")`) {
+			assert.Equal(t, 8, lineMap[i], "line %q", line)
+		}
+		if strings.HasPrefix(line, "def Dog(") {
+			assert.Equal(t, 5, lineMap[i], "line %q", line)
+		}
+	}
+}
+
+func TestRunErrorsCarryLineNumbers(t *testing.T) {
+	_, _, _, err := Run("puts(1)\nx = \"open\n")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "2: unterminated string literal")
+}
+
+func TestOriginalColumn(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		tok  string // token on the last preprocessed line to locate
+		want int
+	}{
+		{"unchanged line", "x = 1\ny = x + 2\n", "2", 9},
+		{"paren-free call", "x = 1\nputs \"a\", x x\n", "x x", 11},
+		{"repeated token", "x = 1\nputs \"a\", x x\n", "x)", 13},
+		{"unless rewrite", "x = 1\nunless x == 2\nend\n", "2", 13},
+		{"predicate rename", "def ok?(x)\nend\nputs ok?(1), ok?(2), 3\n", "3", 22},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, lineMap, _, err := Run(tt.src)
+			require.NoError(t, err)
+			lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+			last := len(lines)
+			for strings.TrimSpace(lines[last-1]) == "end" {
+				last--
+			}
+			col := strings.Index(lines[last-1], tt.tok) + 1
+			require.Positive(t, col, "token %q not in %q", tt.tok, lines[last-1])
+			assert.Equal(t, tt.want, OriginalColumn(tt.src, out, lineMap, last, col))
+		})
+	}
+}
+
+func TestOriginalPosition(t *testing.T) {
+	src := "x = <<~EOS\n  a\n  b\nEOS\nputs x\n"
+	out, lineMap, _, err := Run(src)
+	require.NoError(t, err)
+
+	line, _ := OriginalPosition(src, out, lineMap, 2, 1)
+	assert.Equal(t, 5, line, "line after a collapsed heredoc")
+	line, _ = OriginalPosition(src, out, lineMap, len(lineMap)+2, 1)
+	assert.Equal(t, lineMap[len(lineMap)-1]+2, line, "lines past the map keep their distance from the last mapped line")
+	line, col := OriginalPosition(src, out, nil, 3, 4)
+	assert.Equal(t, []int{3, 4}, []int{line, col}, "no line map")
+}
+
+func TestRunTerminatesWithNewline(t *testing.T) {
+	out, _, _, err := Run("puts(1)")
+	require.NoError(t, err)
+	assert.Equal(t, byte('\n'), out[len(out)-1])
+}
+
+func TestExpandStructDefsFieldDefaults(t *testing.T) {
+	src := "struct Config\n  host\n  port = 8080\n  tags = []\nend\n"
+	out, _, structs, err := ExpandStructDefs(src)
+	require.NoError(t, err)
+	require.Len(t, structs, 1)
+	assert.Equal(t, []string{"host", "port", "tags"}, structs[0].Fields)
+	assert.Equal(t, map[string]string{"port": "8080", "tags": "[]"}, structs[0].Defaults)
+	assert.Contains(t, out, "def Config(host, port = 8080, tags = [])")
+	assert.Contains(t, out, "def new(host, port = 8080, tags = [])")
+	assert.Contains(t, out, `"port" => port`)
+}
+
+func TestExpandStructDefsMethods(t *testing.T) {
+	src := "struct Dog\n  name\nend\ndef Dog.bark()\nend\ndef Dog.<=>(other)\nend\n"
+	_, _, structs, err := ExpandStructDefs(src)
+	require.NoError(t, err)
+	require.Len(t, structs, 1)
+	assert.Equal(t, []string{"bark", ComparatorPrefix + "Dog"}, structs[0].Methods)
+}
+
+func TestExpandStructDefsExplicitSelf(t *testing.T) {
+	for _, def := range []string{"def Dog.bark(self)", "def Dog.rename(self, name)", "def Dog.rename(name, self = nil)"} {
+		_, _, _, err := ExpandStructDefs("struct Dog\n  name\nend\n" + def + "\nend\n")
+		require.Error(t, err, def)
+		assert.Contains(t, err.Error(), "line 4: `def Dog.")
+		assert.Contains(t, err.Error(), "must not declare `self`")
+	}
+}
+
+func TestExpandParallelLimit(t *testing.T) {
+	out, err := expandParallelLimit("x = parallel(limit: n + 1)\n  f(1)\nend\nys = parallel_map(a, f)\nputs(\"parallel(x)\")\n")
+	require.NoError(t, err)
+	assert.Equal(t, "x = parallel __parallel_limit__(n + 1)\n  f(1)\nend\nys = parallel_map(a, f)\nputs(\"parallel(x)\")\n", out)
+
+	_, err = expandParallelLimit("x = parallel(workers: 2)\n")
+	assert.ErrorContains(t, err, "line 1: `parallel` only accepts a limit")
+	_, err = expandParallelLimit("x = parallel(limit: 2) + 1\n")
+	assert.ErrorContains(t, err, "must end the line")
+}
+
+func TestExpandConst(t *testing.T) {
+	out, err := expandConst("const max = 5\ndef f()\n  const cfg = {\n    \"a\" => 1\n  }\nend\nputs(\"const x = 1\")\n")
+	require.NoError(t, err)
+	assert.Equal(t, "__const__max = 5\ndef f()\n  __const__cfg = {\n    \"a\" => 1\n  }\nend\nputs(\"const x = 1\")\n", out)
+
+	for _, src := range []string{"const x\n", "const x == 1\n", "const = 1\n", "const a, b = f()\n"} {
+		_, err := expandConst(src)
+		assert.ErrorContains(t, err, "line 1: `const` declares a single name", src)
+	}
+}
+
+func TestExpandSafeNav(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"puts user&.profile&.email", "puts user.__safe__profile.__safe__email"},
+		{"x = a&.greet(\"b&.c\")", "x = a.__safe__greet(\"b&.c\")"},
+		{"x = flags &.5", "x = flags &.5"},
+		{"x = a && b", "x = a && b"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, ExpandSafeNav(tt.in), tt.in)
+	}
+}
+
+func TestExpandBenchOptions(t *testing.T) {
+	out, err := expandBenchOptions("bench \"a, b\", iterations: n * 2\n  f()\nend\nbench \"c\", min_time: 0.5\nend\nbench \"d\"\nend\n")
+	require.NoError(t, err)
+	assert.Equal(t, "bench \"a, b\"; __bench_options__(\"iterations\", n * 2)\n  f()\nend\nbench \"c\"; __bench_options__(\"min_time\", 0.5)\nend\nbench \"d\"\nend\n", out)
+
+	_, err = expandBenchOptions("bench \"x\", loops: 3\nend\n")
+	assert.ErrorContains(t, err, "line 1: bench options must be")
+	_, err = expandBenchOptions("bench \"x\", iterations: 3, min_time: 1\nend\n")
+	assert.ErrorContains(t, err, "either iterations: or min_time:")
+	_, err = expandBenchOptions("bench \"x\", iterations: 3, iterations: 4\nend\n")
+	assert.ErrorContains(t, err, "given twice")
+}
+
+func TestExpandWhileElse(t *testing.T) {
+	out, err := expandWhileElse("while i < n\n  if ok\n    f()\n  else\n    g()\n  end\nelse\n  h()\nend\n")
+	require.NoError(t, err)
+	assert.Equal(t, "while i < n\n  if ok\n    f()\n  else\n    g()\n  end\n__while_else__()\n  h()\nend\n", out)
+
+	_, err = expandWhileElse("x = 1\nwhile x\n  f()\nelsif y\nend\n")
+	assert.ErrorContains(t, err, "line 4: `while` does not support `elsif`")
+}
+
+func TestExpandMemo(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"memo def fib(n)\n", "def fib(n) __memo__()\n"},
+		{"  memo def config\n", "  def config() __memo__()\n"},
+		{"memo = {}\n", "memo = {}\n"},
+		{"memo[k] = v\n", "memo[k] = v\n"},
+		{"puts \"memo def x\"\n", "puts \"memo def x\"\n"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, expandMemo(tt.in), "input %q", tt.in)
+	}
+}
+
+func TestExpandPredicateNames(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"def valid?(x)\n", "def valid_p(x)\n"},
+		{"def User.admin?()\nend\nif user.admin?()\n", "def User.admin_p()\nend\nif user.admin_p()\n"},
+		{"def empty?(s)\nend\nputs \"ok? #{empty?(s)}\"\n", "def empty_p(s)\nend\nputs \"ok? #{empty_p(s)}\"\n"},
+		{"def a?()\nend\nputs 'a?'\n", "def a_p()\nend\nputs 'a?'\n"},
+		{"x = defined?(\"f\")\n", "x = defined?(\"f\")\n"},
+		{"def a?()\nend\nputs \"\\#{a?}\"\n", "def a_p()\nend\nputs \"\\#{a?}\"\n"},
+		{"ls file?.txt\n", "ls file?.txt\n"},
+		{"def ok?()\nend\nputs is_ok?(1)\n", "def ok_p()\nend\nputs is_ok?(1)\n"},
+	}
+	for _, tt := range tests {
+		got, _, err := expandPredicateNames(tt.in, nil)
+		require.NoError(t, err, "input %q", tt.in)
+		assert.Equal(t, tt.want, got, "input %q", tt.in)
+	}
+
+	_, _, err := expandPredicateNames("x = 1\ndef a?b(x)\n", nil)
+	assert.ErrorContains(t, err, "line 2: `?` can only end a name")
+	_, _, err = expandPredicateNames("def ok??(x)\n", []int{7})
+	assert.ErrorContains(t, err, "line 7: `?` can only end a name")
+
+	assert.True(t, ScanFuncDefs("def valid?(x)\nend\n")["valid_p"])
+}
+
+func TestPredicateNamesSkipShellLines(t *testing.T) {
+	src := "def file?(x)\n  return true\nend\nls file?.txt\nputs file?(1)\n"
+	out, _, _, err := Run(src)
+	require.NoError(t, err)
+	assert.Contains(t, out, `__shell__("ls file?.txt")`)
+	assert.Contains(t, out, "puts(file_p(1))")
+}
+
+func TestExpandImportRenames(t *testing.T) {
+	out, names, err := expandImportRenames("import \"strings\" as {title_case: ToTitle, up: ToUpper}\n")
+	require.NoError(t, err)
+	assert.Equal(t, "import \"strings\"; __import_funcs__(\"title_case\", \"ToTitle\", \"up\", \"ToUpper\")\n", out)
+	assert.Equal(t, map[string]bool{"title_case": true, "up": true}, names)
+
+	out, _, err = expandImportRenames("import \"path/filepath\" as fp {base: Base}\nimport \"os\" as o\n")
+	require.NoError(t, err)
+	assert.Equal(t, "import \"path/filepath\" as fp; __import_funcs__(\"base\", \"Base\")\nimport \"os\" as o\n", out)
+
+	_, _, err = expandImportRenames("import \"strings\" as {title_case}\n")
+	assert.ErrorContains(t, err, "line 1: import renames must be `name: GoFunc` pairs")
+	_, _, err = expandImportRenames("\nimport \"strings\" as {t: ToTitle, t: ToUpper}\n")
+	assert.ErrorContains(t, err, "line 2: `t` is renamed more than once")
+}
+
+func TestExpandLoopValues(t *testing.T) {
+	out, err := expandLoopValues("found = for x in arr\n  if x > 1\n    break x * 2\n  end\n  next x\nend\n", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "found = nil; for x in arr\n  if x > 1\n    found = x * 2; break\n  end\n  found = x; next\nend\n", out)
+
+	out, err = expandLoopValues("n = while true\n  for y in ys\n    break\n  end\n  break \"x.end\"\nend\n", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "n = nil; while true\n  for y in ys\n    break\n  end\n  n = \"x.end\"; break\nend\n", out)
+
+	_, err = expandLoopValues("for x in arr\n  break x\nend\n", nil)
+	assert.ErrorContains(t, err, "line 2: `break` with a value needs a loop whose result is assigned")
+	_, err = expandLoopValues("r = for x in arr\n  f = fn(a)\n    next a\n  end\nend\n", []int{1, 2, 7})
+	assert.ErrorContains(t, err, "line 7: `next` with a value")
+}
+
+func TestExpandSpreads(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"x = [...xs, 1, 2, ...ys]\n", "x = __array_concat__(xs,[ 1, 2], ys)\n"},
+		{"h = {...base, \"k\" => [...a]}\n", "h = __hash_merge__(base,{ \"k\" => __array_concat__(a)})\n"},
+		{"h = {\n  ...base,\n  \"k\" => 1\n}\n", "h = __hash_merge__(\n  base,{\n  \"k\" => 1\n})\n"},
+		{"for i in 0...n\n", "for i in 0...n\n"},
+		{"puts(\"[...xs]\")\n", "puts(\"[...xs]\")\n"},
+	}
+	for _, tt := range tests {
+		out, err := expandSpreads(tt.in, nil)
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, out, "input %q", tt.in)
+	}
+
+	_, err := expandSpreads("x = 1\nf(a, ...xs)\n", nil)
+	assert.ErrorContains(t, err, "line 2: `...` spread is only supported inside array and hash literals")
+	_, err = expandSpreads("x = ys[...xs]\n", nil)
+	assert.ErrorContains(t, err, "only supported inside array and hash literals")
+	_, err = expandSpreads("x = [1, ...]\n", []int{4})
+	assert.ErrorContains(t, err, "line 4: `...` needs a value to spread")
+}
+
+func TestExpandAssertMessages(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"assert(x > 0)\n", "assert(x > 0, 'assertion failed: x > 0')\n"},
+		{"assert(f(a, b))\n", "assert(f(a, b), 'assertion failed: f(a, b)')\n"},
+		{"assert(ok, \"custom\")\n", "assert(ok, \"custom\")\n"},
+		{"assert(h['k'])\n", "assert(h['k'])\n"},
+		{"test.assert(x)\n", "test.assert(x)\n"},
+		{"puts(\"assert(x)\")\n", "puts(\"assert(x)\")\n"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, expandAssertMessages(tt.in), "input %q", tt.in)
+	}
+}
+
+func TestExpandRanges(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"for i in 0..10\n", "for i in __range__(0, 10, true)\n"},
+		{"x = a...len(b)\n", "x = __range__(a, len(b), false)\n"},
+		{"for i in -2..-4\n", "for i in __range__(-2, -4, true)\n"},
+		{"x = foo.bar(1)..arr[2]\n", "x = __range__(foo.bar(1), arr[2], true)\n"},
+		{"y = [1, (n + 1) .. m]\n", "y = [1, __range__((n + 1), m, true)]\n"},
+		{"cd ..\n", "cd ..\n"},
+		{"ls ../..\n", "ls ../..\n"},
+		{"puts(\"0..3\")\n", "puts(\"0..3\")\n"},
+		{"x = 1.5\n", "x = 1.5\n"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, expandRanges(tt.in), "input %q", tt.in)
+	}
+}
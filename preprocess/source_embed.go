@@ -2,5 +2,5 @@ package preprocess
 
 import "embed"
 
-//go:embed preprocess.go string_tracker.go
+//go:embed blocks.go format.go preprocess.go string_tracker.go
 var Sources embed.FS
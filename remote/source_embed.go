@@ -5,5 +5,5 @@ import "embed"
 // Sources embeds all non-test Go source files needed to reconstruct
 // the remote package in an external module cache.
 //
-//go:embed lockfile.go remote.go resolver.go
+//go:embed lockfile.go remote.go resolver.go url.go
 var Sources embed.FS
@@ -0,0 +1,199 @@
+package remote
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// urlFetchTimeout bounds the download of a single URL require.
+const urlFetchTimeout = 30 * time.Second
+
+// checksumPragma marks the expected SHA-256 of a URL require, given as the
+// URL fragment: require "https://example.com/lib.rugo#sha256:<hex>".
+const checksumPragma = "sha256:"
+
+// IsURLRequire returns true if the require path is a URL to a single Rugo
+// file. Only https:// is accepted.
+func IsURLRequire(requirePath string) bool {
+	return strings.HasPrefix(requirePath, "https://")
+}
+
+// splitURLRequire separates the fetch URL from the optional #sha256:
+// checksum pragma.
+func splitURLRequire(requirePath string) (rawURL, checksum string, err error) {
+	rawURL, frag, hasFrag := strings.Cut(requirePath, "#")
+	if !hasFrag {
+		return rawURL, "", nil
+	}
+	checksum, ok := strings.CutPrefix(frag, checksumPragma)
+	if !ok {
+		return "", "", fmt.Errorf("require %q: unsupported pragma #%s (expected #%s<hex>)", requirePath, frag, checksumPragma)
+	}
+	checksum = strings.ToLower(checksum)
+	if len(checksum) != sha256.Size*2 || !isHex(checksum) {
+		return "", "", fmt.Errorf("require %q: invalid sha256 checksum %q (expected 64 hex characters)", requirePath, checksum)
+	}
+	return rawURL, checksum, nil
+}
+
+// URLNamespace returns the default namespace for a URL require: the file
+// name without its extension ("https://example.com/str-utils.rugo" →
+// "str_utils").
+func URLNamespace(requirePath string) string {
+	rawURL, _, _ := strings.Cut(requirePath, "#")
+	p := rawURL
+	if u, err := url.Parse(rawURL); err == nil {
+		p = u.Path
+	}
+	base := path.Base(p)
+	return sanitizeNamespace(strings.TrimSuffix(base, path.Ext(base)))
+}
+
+// ResolveURLRequire resolves a relative require found in a file that was
+// itself required by URL against that file's URL, so "helpers" in
+// https://example.com/lib/main.rugo becomes
+// https://example.com/lib/helpers.rugo. The .rugo extension is added when
+// the path has none, as for local requires. Absolute paths are rejected:
+// a downloaded file has no business reading the local filesystem.
+func ResolveURLRequire(parentURL, requirePath string) (string, error) {
+	if path.IsAbs(requirePath) || filepath.IsAbs(requirePath) {
+		return "", fmt.Errorf("require %q in %s: a file required by URL can only require relative paths, URLs or remote modules", requirePath, parentURL)
+	}
+	base, err := url.Parse(parentURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid require URL %q: %w", parentURL, err)
+	}
+	ref, err := url.Parse(filepath.ToSlash(requirePath))
+	if err != nil || ref.Scheme != "" || ref.Host != "" {
+		return "", fmt.Errorf("require %q in %s: not a relative path", requirePath, parentURL)
+	}
+	if !isRugoFile(ref.Path) {
+		ref.Path += ".rugo"
+	}
+	return base.ResolveReference(ref).String(), nil
+}
+
+// FetchURL returns the path of a local copy of the Rugo file at a URL
+// require, downloading it into the cache on first use. The cache lives
+// under the user cache directory (~/.cache/rugo/url on Linux), keyed by a
+// hash of the URL. Setting RUGO_NO_CACHE forces a fresh download. When
+// the require carries a #sha256: pragma the content is verified before
+// it is used or cached. A 404 response wraps os.ErrNotExist.
+func FetchURL(requirePath string) (string, error) {
+	rawURL, checksum, err := splitURLRequire(requirePath)
+	if err != nil {
+		return "", err
+	}
+	dest, err := urlCachePath(rawURL)
+	if err != nil {
+		return "", err
+	}
+
+	if os.Getenv("RUGO_NO_CACHE") == "" {
+		if data, err := os.ReadFile(dest); err == nil && verifyChecksum(data, checksum) == nil {
+			return dest, nil
+		}
+	}
+
+	data, err := download(rawURL)
+	if err != nil {
+		return "", err
+	}
+	if err := verifyChecksum(data, checksum); err != nil {
+		return "", fmt.Errorf("require %q: %w", rawURL, err)
+	}
+	if err := writeFileAtomic(dest, data); err != nil {
+		return "", fmt.Errorf("caching %s: %w", rawURL, err)
+	}
+	return dest, nil
+}
+
+// urlCachePath returns the cache location for a URL:
+// <cache>/rugo/url/<sha256 of URL>/<file name>.
+func urlCachePath(rawURL string) (string, error) {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot determine cache directory: %w", err)
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid require URL %q: %w", rawURL, err)
+	}
+	name := path.Base(u.Path)
+	if name == "/" || name == "." || !isRugoFile(name) {
+		return "", fmt.Errorf("require %q: URL must point to a Rugo source file (.rugo or .rg)", rawURL)
+	}
+	sum := sha256.Sum256([]byte(rawURL))
+	return filepath.Join(base, "rugo", "url", hex.EncodeToString(sum[:]), name), nil
+}
+
+func download(rawURL string) ([]byte, error) {
+	client := &http.Client{Timeout: urlFetchTimeout}
+	resp, err := client.Get(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("fetching %s: %w", rawURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("fetching %s: %s: %w", rawURL, resp.Status, os.ErrNotExist)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching %s: %s", rawURL, resp.Status)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("fetching %s: reading body: %w", rawURL, err)
+	}
+	return data, nil
+}
+
+func verifyChecksum(data []byte, want string) error {
+	if want == "" {
+		return nil
+	}
+	sum := sha256.Sum256(data)
+	if got := hex.EncodeToString(sum[:]); got != want {
+		return fmt.Errorf("checksum mismatch: expected sha256:%s, got sha256:%s", want, got)
+	}
+	return nil
+}
+
+// writeFileAtomic writes data to a temp file next to dest and renames it
+// into place, so concurrent runs never see a partial file.
+func writeFileAtomic(dest string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(dest), ".download-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), dest); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
+
+func isHex(s string) bool {
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
@@ -0,0 +1,195 @@
+package remote
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+const urlLib = "def hello()\n  return \"hi\"\nend\n"
+
+// serveLib starts an HTTPS server for /lib.rugo and counts the requests it
+// gets. The default transport trusts the server's certificate until the
+// test ends.
+func serveLib(t *testing.T, body string) (*httptest.Server, *int32) {
+	t.Helper()
+	var hits int32
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		if r.URL.Path != "/lib.rugo" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	trustServer(t, srv)
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("RUGO_NO_CACHE", "")
+	return srv, &hits
+}
+
+// trustServer points http.DefaultTransport at srv's TLS client transport.
+func trustServer(t *testing.T, srv *httptest.Server) {
+	t.Helper()
+	orig := http.DefaultTransport
+	http.DefaultTransport = srv.Client().Transport
+	t.Cleanup(func() { http.DefaultTransport = orig })
+}
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestIsURLRequire(t *testing.T) {
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"https://example.com/lib.rugo", true},
+		{"https://example.com/lib.rugo#sha256:abc", true},
+		{"http://localhost:8080/lib.rugo", false},
+		{"http://127.0.0.1:8080/lib.rugo", false},
+		{"http://example.com/lib.rugo", false},
+		{"github.com/user/repo", false},
+		{"helpers", false},
+	}
+	for _, tt := range tests {
+		if got := IsURLRequire(tt.path); got != tt.want {
+			t.Errorf("IsURLRequire(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestURLNamespace(t *testing.T) {
+	tests := map[string]string{
+		"https://example.com/lib.rugo":                   "lib",
+		"https://example.com/a/str-utils.rg":             "str_utils",
+		"https://example.com/lib.rugo?raw=1":             "lib",
+		"https://example.com/lib.rugo#sha256:" + "ab":    "lib",
+		"http://localhost:8080/nested/path/helpers.rugo": "helpers",
+	}
+	for in, want := range tests {
+		if got := URLNamespace(in); got != want {
+			t.Errorf("URLNamespace(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestResolveURLRequire(t *testing.T) {
+	parent := "https://example.com/lib/main.rugo#sha256:ab"
+	tests := map[string]string{
+		"helpers":            "https://example.com/lib/helpers.rugo",
+		"./helpers.rg":       "https://example.com/lib/helpers.rg",
+		"util/str":           "https://example.com/lib/util/str.rugo",
+		"../shared/fmt.rugo": "https://example.com/shared/fmt.rugo",
+	}
+	for in, want := range tests {
+		got, err := ResolveURLRequire(parent, in)
+		if err != nil || got != want {
+			t.Errorf("ResolveURLRequire(%q) = %q, %v, want %q", in, got, err, want)
+		}
+	}
+	if _, err := ResolveURLRequire(parent, "/etc/lib.rugo"); err == nil || !strings.Contains(err.Error(), "only require relative paths") {
+		t.Errorf("expected absolute path to be rejected, got %v", err)
+	}
+}
+
+func TestFetchURLCaches(t *testing.T) {
+	srv, hits := serveLib(t, urlLib)
+
+	path, err := FetchURL(srv.URL + "/lib.rugo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(path)
+	if string(data) != urlLib {
+		t.Errorf("cached content = %q, want %q", data, urlLib)
+	}
+	if !strings.HasSuffix(path, "lib.rugo") {
+		t.Errorf("cached path %q should keep the file name", path)
+	}
+
+	again, err := FetchURL(srv.URL + "/lib.rugo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again != path || atomic.LoadInt32(hits) != 1 {
+		t.Errorf("second fetch should use the cache (path %q, hits %d)", again, *hits)
+	}
+}
+
+func TestFetchURLNoCache(t *testing.T) {
+	srv, hits := serveLib(t, urlLib)
+	t.Setenv("RUGO_NO_CACHE", "1")
+	for i := 0; i < 2; i++ {
+		if _, err := FetchURL(srv.URL + "/lib.rugo"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := atomic.LoadInt32(hits); n != 2 {
+		t.Errorf("RUGO_NO_CACHE should download every time, got %d requests", n)
+	}
+}
+
+func TestFetchURLChecksum(t *testing.T) {
+	srv, _ := serveLib(t, urlLib)
+
+	if _, err := FetchURL(srv.URL + "/lib.rugo#sha256:" + sha256Hex(urlLib)); err != nil {
+		t.Fatalf("matching checksum should succeed: %v", err)
+	}
+
+	bad := sha256Hex("something else")
+	_, err := FetchURL(srv.URL + "/lib.rugo#sha256:" + bad)
+	if err == nil || !strings.Contains(err.Error(), "checksum mismatch: expected sha256:"+bad) {
+		t.Errorf("expected checksum mismatch error, got %v", err)
+	}
+}
+
+func TestFetchURLChecksumMismatchNotCached(t *testing.T) {
+	srv, hits := serveLib(t, urlLib)
+	bad := sha256Hex("something else")
+	FetchURL(srv.URL + "/lib.rugo#sha256:" + bad)
+	if _, err := FetchURL(srv.URL + "/lib.rugo"); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(hits); n != 2 {
+		t.Errorf("mismatched content must not be cached, got %d requests", n)
+	}
+}
+
+func TestFetchURLErrors(t *testing.T) {
+	srv, _ := serveLib(t, urlLib)
+
+	_, err := FetchURL(srv.URL + "/missing.rugo")
+	if !errors.Is(err, os.ErrNotExist) || !strings.Contains(err.Error(), "404") {
+		t.Errorf("expected 404 error wrapping os.ErrNotExist, got %v", err)
+	}
+
+	_, err = FetchURL(srv.URL + "/lib.rugo#md5:abc")
+	if err == nil || !strings.Contains(err.Error(), "unsupported pragma #md5:abc") {
+		t.Errorf("expected pragma error, got %v", err)
+	}
+
+	_, err = FetchURL(srv.URL + "/lib.rugo#sha256:xyz")
+	if err == nil || !strings.Contains(err.Error(), "invalid sha256 checksum") {
+		t.Errorf("expected invalid checksum error, got %v", err)
+	}
+
+	_, err = FetchURL(srv.URL + "/lib.txt")
+	if err == nil || !strings.Contains(err.Error(), "must point to a Rugo source file") {
+		t.Errorf("expected extension error, got %v", err)
+	}
+
+	_, err = FetchURL("https://127.0.0.1:1/lib.rugo")
+	if err == nil || !strings.Contains(err.Error(), "fetching https://127.0.0.1:1/lib.rugo") {
+		t.Errorf("expected network error, got %v", err)
+	}
+}